@@ -152,6 +152,10 @@ type ClusterStatus struct {
 	// Indicates cluster is upgrading
 	// +optional
 	Upgrading bool `json:"upgrading"`
+	// Indicates the node ID of the broker being decommissioned before the
+	// cluster is scaled down
+	// +optional
+	DecommissioningNode *int32 `json:"decommissioningNode,omitempty"`
 }
 
 // NodesList shows where client can find Redpanda brokers
@@ -85,11 +85,13 @@ func (r *Cluster) ValidateUpdate(old runtime.Object) error {
 	oldCluster := old.(*Cluster)
 	var allErrs field.ErrorList
 
-	if r.Spec.Replicas != nil && oldCluster.Spec.Replicas != nil && *r.Spec.Replicas < *oldCluster.Spec.Replicas {
+	// Scaling down is handled by the operator, which decommissions the
+	// removed brokers one by one, but the cluster can't be emptied.
+	if r.Spec.Replicas != nil && oldCluster.Spec.Replicas != nil && *r.Spec.Replicas < *oldCluster.Spec.Replicas && *r.Spec.Replicas < 1 {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec").Child("replicas"),
 				r.Spec.Replicas,
-				"scaling down is not supported"))
+				"scaling down to less than one replica is not supported"))
 	}
 
 	allErrs = append(allErrs, r.validateKafkaListeners()...)
@@ -24,7 +24,7 @@ import (
 )
 
 func TestValidateUpdate(t *testing.T) {
-	var replicas1 int32 = 1
+	var replicas0 int32
 	var replicas2 int32 = 2
 
 	redpandaCluster := &v1alpha1.Cluster{
@@ -44,7 +44,7 @@ func TestValidateUpdate(t *testing.T) {
 	}
 
 	updatedCluster := redpandaCluster.DeepCopy()
-	updatedCluster.Spec.Replicas = &replicas1
+	updatedCluster.Spec.Replicas = &replicas0
 	updatedCluster.Spec.Configuration = v1alpha1.RedpandaConfig{
 		KafkaAPI: []v1alpha1.KafkaAPI{
 			{Port: 123,
@@ -129,6 +129,14 @@ func TestValidateUpdate_NoError(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("scale down", func(t *testing.T) {
+		var scaleDown int32 = *redpandaCluster.Spec.Replicas - 1
+		updatedScaleDown := redpandaCluster.DeepCopy()
+		updatedScaleDown.Spec.Replicas = &scaleDown
+		err := updatedScaleDown.ValidateUpdate(redpandaCluster)
+		assert.NoError(t, err)
+	})
+
 	t.Run("change image and tag", func(t *testing.T) {
 		updatedImage := redpandaCluster.DeepCopy()
 		updatedImage.Spec.Image = "differentimage"
@@ -187,6 +187,11 @@ func (in *ClusterSpec) DeepCopy() *ClusterSpec {
 func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	*out = *in
 	in.Nodes.DeepCopyInto(&out.Nodes)
+	if in.DecommissioningNode != nil {
+		in, out := &in.DecommissioningNode, &out.DecommissioningNode
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
@@ -43,10 +43,11 @@ var (
 // ClusterReconciler reconciles a Cluster object
 type ClusterReconciler struct {
 	client.Client
-	Log                  logr.Logger
-	configuratorSettings resources.ConfiguratorSettings
-	clusterDomain        string
-	Scheme               *runtime.Scheme
+	Log                   logr.Logger
+	configuratorSettings  resources.ConfiguratorSettings
+	clusterDomain         string
+	adminAPIClientFactory resources.AdminAPIClientFactory
+	Scheme                *runtime.Scheme
 }
 
 //+kubebuilder:rbac:groups=redpanda.vectorized.io,resources=clusters,verbs=get;list;watch;create;update;patch;delete
@@ -163,6 +164,7 @@ func (r *ClusterReconciler) Reconcile(
 		pki.PandaproxyAPINodeCert(),
 		sa.Key().Name,
 		r.configuratorSettings,
+		r.getAdminAPIClientFactory(),
 		log)
 	toApply := []resources.Reconciler{
 		headlessSvc,
@@ -210,7 +212,7 @@ func (r *ClusterReconciler) Reconcile(
 			urls = append(urls, fmt.Sprintf("%s-%d.%s:%d", redpandaCluster.Name, i, headlessSvc.HeadlessServiceFQDN(r.clusterDomain), redpandaCluster.AdminAPIInternal().Port))
 		}
 
-		adminAPI, err := admin.NewAdminAPI(urls, nil)
+		adminAPI, err := r.getAdminAPIClientFactory()(urls, nil)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -343,6 +345,21 @@ func (r *ClusterReconciler) WithClusterDomain(
 	return r
 }
 
+// WithAdminAPIClientFactory set the factory used to create Admin API clients
+func (r *ClusterReconciler) WithAdminAPIClientFactory(
+	adminAPIClientFactory resources.AdminAPIClientFactory,
+) *ClusterReconciler {
+	r.adminAPIClientFactory = adminAPIClientFactory
+	return r
+}
+
+func (r *ClusterReconciler) getAdminAPIClientFactory() resources.AdminAPIClientFactory {
+	if r.adminAPIClientFactory == nil {
+		return admin.NewAdminAPI
+	}
+	return r.adminAPIClientFactory
+}
+
 func (r *ClusterReconciler) createExternalNodesList(
 	ctx context.Context,
 	pods []corev1.Pod,
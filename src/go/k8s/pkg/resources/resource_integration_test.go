@@ -2,6 +2,8 @@ package resources_test
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
@@ -9,12 +11,15 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/api/admin"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/deprecated/scheme"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -83,6 +88,7 @@ func TestEnsure_StatefulSet(t *testing.T) {
 			ConfiguratorTag:       "latest",
 			ImagePullPolicy:       "Always",
 		},
+		admin.NewAdminAPI,
 		ctrl.Log.WithName("test"))
 
 	err := sts.Ensure(context.Background())
@@ -104,6 +110,104 @@ func TestEnsure_StatefulSet(t *testing.T) {
 	}
 }
 
+func TestEnsure_StatefulSetScaleDown(t *testing.T) {
+	cluster := pandaCluster()
+	cluster = cluster.DeepCopy()
+	cluster.Name = "ensure-integration-scale-down-cluster"
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	require.NoError(t, c.Create(context.Background(), cluster))
+
+	// The node IDs don't match the pods' ordinals, so the broker to
+	// decommission must be the one the last pod reports.
+	brokers := []admin.Broker{
+		{NodeID: 10, MembershipStatus: "active"},
+		{NodeID: 11, MembershipStatus: "active"},
+		{NodeID: 12, MembershipStatus: "active"},
+	}
+	decommissioned := []int{}
+	factory := func(urls []string, _ *tls.Config) (admin.AdminAPI, error) {
+		return &admin.MockAdminAPI{
+			MockNodeConfig: func() (admin.NodeConfig, error) {
+				require.Len(t, urls, 1)
+				require.True(t, strings.HasPrefix(urls[0], cluster.Name+"-2."))
+				return admin.NodeConfig{NodeID: 12}, nil
+			},
+			MockBrokers: func() ([]admin.Broker, error) {
+				return brokers, nil
+			},
+			MockDecommissionBroker: func(id int) error {
+				decommissioned = append(decommissioned, id)
+				for i := range brokers {
+					if brokers[i].NodeID == id {
+						brokers[i].MembershipStatus = "draining"
+					}
+				}
+				return nil
+			},
+		}, nil
+	}
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		res.ConfiguratorSettings{
+			ConfiguratorBaseImage: "vectorized/configurator",
+			ConfiguratorTag:       "latest",
+			ImagePullPolicy:       "Always",
+		},
+		factory,
+		ctrl.Log.WithName("test"))
+
+	err := sts.Ensure(context.Background())
+	require.NoError(t, err)
+
+	actualReplicas := func() int32 {
+		actual := &v1.StatefulSet{}
+		require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+		return *actual.Spec.Replicas
+	}
+	actualDecommissioningNode := func() *int32 {
+		actual := &redpandav1alpha1.Cluster{}
+		key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+		require.NoError(t, c.Get(context.Background(), key, actual))
+		return actual.Status.DecommissioningNode
+	}
+	require.Equal(t, int32(3), actualReplicas())
+
+	// The last pod's broker is decommissioned, but the StatefulSet keeps its
+	// replicas until the broker leaves the cluster.
+	cluster.Spec.Replicas = pointer.Int32Ptr(2)
+	var requeueErr *res.RequeueAfterError
+	err = sts.Ensure(context.Background())
+	require.True(t, errors.As(err, &requeueErr))
+	require.Equal(t, []int{12}, decommissioned)
+	require.Equal(t, int32(3), actualReplicas())
+	require.Equal(t, int32(12), *actualDecommissioningNode())
+
+	err = sts.Ensure(context.Background())
+	require.True(t, errors.As(err, &requeueErr))
+	require.Equal(t, []int{12}, decommissioned)
+	require.Equal(t, int32(3), actualReplicas())
+
+	// Once the broker is gone, the StatefulSet is scaled down.
+	brokers = brokers[:2]
+	err = sts.Ensure(context.Background())
+	require.True(t, errors.As(err, &requeueErr))
+	require.Equal(t, int32(2), actualReplicas())
+	require.Nil(t, actualDecommissioningNode())
+}
+
 func TestEnsure_ConfigMap(t *testing.T) {
 	cluster := pandaCluster()
 	cluster = cluster.DeepCopy()
@@ -77,6 +77,7 @@ type StatefulSetResource struct {
 	pandaproxyAPINodeCertSecretKey types.NamespacedName
 	serviceAccountName             string
 	configuratorSettings           ConfiguratorSettings
+	adminAPIClientFactory          AdminAPIClientFactory
 	logger                         logr.Logger
 
 	LastObservedState *appsv1.StatefulSet
@@ -98,6 +99,7 @@ func NewStatefulSet(
 	pandaproxyAPINodeCertSecretKey types.NamespacedName,
 	serviceAccountName string,
 	configuratorSettings ConfiguratorSettings,
+	adminAPIClientFactory AdminAPIClientFactory,
 	logger logr.Logger,
 ) *StatefulSetResource {
 	return &StatefulSetResource{
@@ -116,6 +118,7 @@ func NewStatefulSet(
 		pandaproxyAPINodeCertSecretKey,
 		serviceAccountName,
 		configuratorSettings,
+		adminAPIClientFactory,
 		logger.WithValues("Kind", statefulSetKind()),
 		nil,
 	}
@@ -157,6 +160,10 @@ func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 	}
 	r.LastObservedState = &sts
 
+	if err := r.handleScaleDown(ctx, &sts); err != nil {
+		return err
+	}
+
 	partitioned, err := r.shouldUsePartitionedUpdate(&sts)
 	if err != nil {
 		return err
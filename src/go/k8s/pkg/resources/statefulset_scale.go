@@ -0,0 +1,142 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/api/admin"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// AdminAPIClientFactory returns a client for the redpanda Admin API reachable
+// at the given urls. It matches admin.NewAdminAPI, and can be replaced in
+// tests.
+type AdminAPIClientFactory func(
+	urls []string, tlsConfig *tls.Config,
+) (admin.AdminAPI, error)
+
+// handleScaleDown makes sure the brokers removed by a scale down are
+// decommissioned before the StatefulSet replicas are reduced. Otherwise
+// redpanda would keep expecting the removed brokers to come back.
+//
+// Brokers are removed one at a time, starting from the highest ordinal, which
+// is the one the StatefulSet removes first. The node ID of the broker being
+// decommissioned is tracked in the DecommissioningNode status, so the process
+// can resume after a requeue or an operator restart. The steps are as
+// follows: 1) if the StatefulSet has more replicas than the cluster CR, ask
+// the pod with the highest ordinal for its node ID, and set the
+// DecommissioningNode status to it; 2) ask the Admin API to decommission the
+// broker and requeue until it's no longer in the cluster's broker list; 3)
+// reduce the StatefulSet replicas by one and clear the status.
+//
+// A RequeueAfterError is returned while a scale down is in progress, so that
+// the StatefulSet isn't updated to the cluster CR replicas in one step.
+func (r *StatefulSetResource) handleScaleDown(
+	ctx context.Context, sts *appsv1.StatefulSet,
+) error {
+	if r.pandaCluster.Status.DecommissioningNode == nil {
+		if sts.Spec.Replicas == nil || r.pandaCluster.Spec.Replicas == nil ||
+			*sts.Spec.Replicas <= *r.pandaCluster.Spec.Replicas {
+			return nil
+		}
+		ordinal := *sts.Spec.Replicas - 1
+		nodeID, err := r.getNodeID(ctx, ordinal)
+		if err != nil {
+			return &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("unable to get the node ID of the pod (ordinal: %d): %s", ordinal, err)}
+		}
+		r.logger.Info("Scaling down, decommissioning broker", "ordinal", ordinal, "node id", nodeID)
+		r.pandaCluster.Status.DecommissioningNode = &nodeID
+		if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+			return err
+		}
+	}
+
+	nodeID := *r.pandaCluster.Status.DecommissioningNode
+	adminAPI, err := r.getAdminAPIClient(ctx, r.adminAPIURLs(*sts.Spec.Replicas)...)
+	if err != nil {
+		return err
+	}
+	brokers, err := adminAPI.Brokers()
+	if err != nil {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to list the brokers: %s", err)}
+	}
+	for _, b := range brokers {
+		if int32(b.NodeID) != nodeID {
+			continue
+		}
+		if b.MembershipStatus != "draining" {
+			r.logger.Info("Decommissioning broker", "node id", b.NodeID)
+			if err := adminAPI.DecommissionBroker(b.NodeID); err != nil {
+				return &RequeueAfterError{RequeueAfter: requeueDuration,
+					Msg: fmt.Sprintf("unable to decommission broker %d: %s", b.NodeID, err)}
+			}
+		}
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("wait for broker %d to be decommissioned", b.NodeID)}
+	}
+
+	// The broker is no longer a member of the cluster, so its pod, which is
+	// the one with the highest ordinal, can be removed.
+	ordinal := *sts.Spec.Replicas - 1
+	r.logger.Info("Broker decommissioned, reducing StatefulSet replicas", "node id", nodeID, "ordinal", ordinal)
+	sts.Spec.Replicas = &ordinal
+	if err := r.Update(ctx, sts); err != nil {
+		return fmt.Errorf("failed to scale down StatefulSet (ordinal %d): %w", ordinal, err)
+	}
+	r.pandaCluster.Status.DecommissioningNode = nil
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return err
+	}
+	return &RequeueAfterError{RequeueAfter: requeueDuration,
+		Msg: fmt.Sprintf("broker %d decommissioned", nodeID)}
+}
+
+// getNodeID asks the pod with the given ordinal for its node ID, which is
+// the one the pod's broker has in the cluster's broker list.
+func (r *StatefulSetResource) getNodeID(
+	ctx context.Context, ordinal int32,
+) (int32, error) {
+	adminAPI, err := r.getAdminAPIClient(ctx, r.adminAPIURL(ordinal))
+	if err != nil {
+		return 0, err
+	}
+	conf, err := adminAPI.NodeConfig()
+	if err != nil {
+		return 0, err
+	}
+	return int32(conf.NodeID), nil
+}
+
+func (r *StatefulSetResource) adminAPIURL(ordinal int32) string {
+	return fmt.Sprintf("%s-%d.%s:%d", r.pandaCluster.Name, ordinal, r.serviceFQDN, r.pandaCluster.AdminAPIInternal().Port)
+}
+
+func (r *StatefulSetResource) adminAPIURLs(replicas int32) []string {
+	var urls []string
+	for i := int32(0); i < replicas; i++ {
+		urls = append(urls, r.adminAPIURL(i))
+	}
+	return urls
+}
+
+func (r *StatefulSetResource) getAdminAPIClient(
+	ctx context.Context, urls ...string,
+) (admin.AdminAPI, error) {
+	tlsConfig, err := r.adminAPITLSConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.adminAPIClientFactory(urls, tlsConfig)
+}
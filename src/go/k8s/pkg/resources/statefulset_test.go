@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/api/admin"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -93,6 +94,7 @@ func TestEnsure(t *testing.T) {
 				ConfiguratorTag:       "latest",
 				ImagePullPolicy:       "Always",
 			},
+			admin.NewAdminAPI,
 			ctrl.Log.WithName("test"))
 
 		err = sts.Ensure(context.Background())
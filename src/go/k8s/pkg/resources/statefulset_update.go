@@ -185,19 +185,13 @@ func (r *StatefulSetResource) queryRedpandaStatus(
 ) error {
 	client := &http.Client{Timeout: adminAPITimeout}
 
-	// TODO right now we support TLS only on one listener so if external
-	// connectivity is enabled, TLS is enabled only on external listener. This
-	// will be fixed by https://github.com/vectorizedio/redpanda/issues/1084
-	if r.pandaCluster.AdminAPITLS() != nil &&
-		r.pandaCluster.AdminAPIExternal() == nil {
-		tlsConfig := tls.Config{MinVersion: tls.VersionTLS12} // TLS12 is min version allowed by gosec.
-
-		if err := r.populateTLSConfigCert(ctx, &tlsConfig); err != nil {
-			return err
-		}
-
+	tlsConfig, err := r.adminAPITLSConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
 		client.Transport = &http.Transport{
-			TLSClientConfig: &tlsConfig,
+			TLSClientConfig: tlsConfig,
 		}
 		adminURL.Scheme = "https"
 	}
@@ -219,6 +213,25 @@ func (r *StatefulSetResource) queryRedpandaStatus(
 	return nil
 }
 
+// adminAPITLSConfig returns the TLS config used to reach the internal Admin
+// API listener, or nil if it doesn't use TLS.
+func (r *StatefulSetResource) adminAPITLSConfig(
+	ctx context.Context,
+) (*tls.Config, error) {
+	// TODO right now we support TLS only on one listener so if external
+	// connectivity is enabled, TLS is enabled only on external listener. This
+	// will be fixed by https://github.com/vectorizedio/redpanda/issues/1084
+	if r.pandaCluster.AdminAPITLS() == nil ||
+		r.pandaCluster.AdminAPIExternal() != nil {
+		return nil, nil
+	}
+	tlsConfig := tls.Config{MinVersion: tls.VersionTLS12} // TLS12 is min version allowed by gosec.
+	if err := r.populateTLSConfigCert(ctx, &tlsConfig); err != nil {
+		return nil, err
+	}
+	return &tlsConfig, nil
+}
+
 // Populates crypto/TLS configuration for certificate used by the operator
 // during its client authentication.
 func (r *StatefulSetResource) populateTLSConfigCert(
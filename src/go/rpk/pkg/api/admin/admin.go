@@ -28,15 +28,53 @@ import (
 )
 
 const (
-	usersEndpoint = "/v1/security/users"
-	httpPrefix    = "http://"
-	httpsPrefix   = "https://"
+	usersEndpoint   = "/v1/security/users"
+	brokersEndpoint = "/v1/brokers"
+	configEndpoint  = "/v1/config"
+	// Node-local: it reports the status of the node the request is sent to.
+	maintenanceEndpoint = "/v1/maintenance"
+	nodeConfigEndpoint  = "/v1/node_config"
+	httpPrefix          = "http://"
+	httpsPrefix         = "https://"
 )
 
 type AdminAPI interface {
 	CreateUser(username, password string) error
 	DeleteUser(username string) error
 	ListUsers() ([]string, error)
+	Brokers() ([]Broker, error)
+	DecommissionBroker(id int) error
+	Config() (map[string]interface{}, error)
+	EnableMaintenanceMode(nodeID int) error
+	DisableMaintenanceMode(nodeID int) error
+	MaintenanceStatus() (MaintenanceStatus, error)
+	NodeConfig() (NodeConfig, error)
+}
+
+// Broker is a member of the cluster, as reported by the admin API.
+type Broker struct {
+	NodeID           int    `json:"node_id"`
+	NumCores         int    `json:"num_cores"`
+	MembershipStatus string `json:"membership_status"`
+}
+
+// NodeConfig is the node-local configuration of the node the admin API
+// belongs to.
+type NodeConfig struct {
+	NodeID int `json:"node_id"`
+}
+
+// MaintenanceStatus is the drain status of a node, as reported by the admin
+// API. Once a node is put in maintenance mode, it transfers the leadership
+// of its partitions away, and Finished is set when it's done.
+type MaintenanceStatus struct {
+	Draining     bool `json:"draining"`
+	Finished     bool `json:"finished"`
+	Errors       bool `json:"errors"`
+	Partitions   int  `json:"partitions"`
+	Eligible     int  `json:"eligible"`
+	Transferring int  `json:"transferring"`
+	Failed       int  `json:"failed"`
 }
 
 type adminAPI struct {
@@ -130,6 +168,136 @@ func (a *adminAPI) ListUsers() ([]string, error) {
 	return usernames, err
 }
 
+// Brokers returns the brokers that are currently members of the cluster.
+func (a *adminAPI) Brokers() ([]Broker, error) {
+	urls := make([]string, len(a.urls))
+	for i := 0; i < len(a.urls); i++ {
+		urls[i] = fmt.Sprintf("%s%s", a.urls[i], brokersEndpoint)
+	}
+	res, err := sendToMultiple(urls, http.MethodGet, nil, a.client)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	brokers := []Broker{}
+	err = json.Unmarshal(bs, &brokers)
+	return brokers, err
+}
+
+// DecommissionBroker starts the decommission of the broker with the given
+// ID. Decommissioning is asynchronous: the broker is removed from the list
+// returned by Brokers once its partitions have been moved elsewhere.
+func (a *adminAPI) DecommissionBroker(id int) error {
+	urls := make([]string, len(a.urls))
+	for i := 0; i < len(a.urls); i++ {
+		urls[i] = fmt.Sprintf(
+			"%s%s/%d/decommission",
+			a.urls[i],
+			brokersEndpoint,
+			id,
+		)
+	}
+	_, err := sendToMultiple(urls, http.MethodPut, nil, a.client)
+	return err
+}
+
+// Config returns the effective configuration of the node, keyed by the
+// property name (e.g. log_segment_size).
+func (a *adminAPI) Config() (map[string]interface{}, error) {
+	urls := make([]string, len(a.urls))
+	for i := 0; i < len(a.urls); i++ {
+		urls[i] = fmt.Sprintf("%s%s", a.urls[i], configEndpoint)
+	}
+	res, err := sendToMultiple(urls, http.MethodGet, nil, a.client)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	conf := map[string]interface{}{}
+	err = json.Unmarshal(bs, &conf)
+	return conf, err
+}
+
+// EnableMaintenanceMode puts the broker with the given ID in maintenance
+// mode, so that it stops accepting the leadership of partitions and starts
+// transferring the leadership it holds to other brokers.
+func (a *adminAPI) EnableMaintenanceMode(nodeID int) error {
+	urls := make([]string, len(a.urls))
+	for i := 0; i < len(a.urls); i++ {
+		urls[i] = fmt.Sprintf(
+			"%s%s/%d/maintenance",
+			a.urls[i],
+			brokersEndpoint,
+			nodeID,
+		)
+	}
+	_, err := sendToMultiple(urls, http.MethodPut, nil, a.client)
+	return err
+}
+
+// DisableMaintenanceMode takes the broker with the given ID out of
+// maintenance mode, so that it accepts the leadership of partitions again.
+func (a *adminAPI) DisableMaintenanceMode(nodeID int) error {
+	urls := make([]string, len(a.urls))
+	for i := 0; i < len(a.urls); i++ {
+		urls[i] = fmt.Sprintf(
+			"%s%s/%d/maintenance",
+			a.urls[i],
+			brokersEndpoint,
+			nodeID,
+		)
+	}
+	_, err := sendToMultiple(urls, http.MethodDelete, nil, a.client)
+	return err
+}
+
+// MaintenanceStatus returns the drain status of the node the admin API
+// belongs to. It should only be used with a single URL, since the status is
+// node-local.
+func (a *adminAPI) MaintenanceStatus() (MaintenanceStatus, error) {
+	urls := make([]string, len(a.urls))
+	for i := 0; i < len(a.urls); i++ {
+		urls[i] = fmt.Sprintf("%s%s", a.urls[i], maintenanceEndpoint)
+	}
+	status := MaintenanceStatus{}
+	res, err := sendToMultiple(urls, http.MethodGet, nil, a.client)
+	if err != nil {
+		return status, err
+	}
+	bs, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return status, err
+	}
+	err = json.Unmarshal(bs, &status)
+	return status, err
+}
+
+// NodeConfig returns the configuration of the node the admin API belongs to.
+// Like MaintenanceStatus, it should only be used with a single URL.
+func (a *adminAPI) NodeConfig() (NodeConfig, error) {
+	urls := make([]string, len(a.urls))
+	for i := 0; i < len(a.urls); i++ {
+		urls[i] = fmt.Sprintf("%s%s", a.urls[i], nodeConfigEndpoint)
+	}
+	conf := NodeConfig{}
+	res, err := sendToMultiple(urls, http.MethodGet, nil, a.client)
+	if err != nil {
+		return conf, err
+	}
+	bs, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return conf, err
+	}
+	err = json.Unmarshal(bs, &conf)
+	return conf, err
+}
+
 // As of v21.4.15, the Redpanda admin API doesn't do request forwarding, which
 // means that some requests (such as the ones made to /users) will fail unless
 // the reached node is the leader. Therefore, a request needs to be made to
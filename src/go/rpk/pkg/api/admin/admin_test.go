@@ -128,3 +128,166 @@ func TestListUsers(t *testing.T) {
 	require.NoError(t, err)
 	require.Exactly(t, []string{"Joss", "lola", "jeff", "tobias"}, users)
 }
+
+func TestBrokers(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Exactly(t, http.MethodGet, r.Method)
+			require.Exactly(t, "/v1/brokers", r.URL.Path)
+			w.Write([]byte(
+				`[{"node_id":0,"num_cores":2,"membership_status":"active"},` +
+					`{"node_id":1,"num_cores":2,"membership_status":"draining"}]`,
+			))
+		}),
+	)
+	defer ts.Close()
+
+	adminClient, err := NewAdminAPI([]string{ts.URL}, nil)
+	require.NoError(t, err)
+	brokers, err := adminClient.Brokers()
+	require.NoError(t, err)
+	require.Exactly(
+		t,
+		[]Broker{
+			{NodeID: 0, NumCores: 2, MembershipStatus: "active"},
+			{NodeID: 1, NumCores: 2, MembershipStatus: "draining"},
+		},
+		brokers,
+	)
+}
+
+func TestDecommissionBroker(t *testing.T) {
+	rand.Seed(time.Now().Unix())
+	nNodes := rand.Int31n(6) + 1
+	urls := []string{}
+
+	for i := 0; i < int(nNodes); i++ {
+		n := i
+		ts := httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Exactly(t, http.MethodPut, r.Method)
+				require.Exactly(t, "/v1/brokers/2/decommission", r.URL.Path)
+
+				if n == 0 {
+					w.WriteHeader(http.StatusOK)
+				} else {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+		)
+		defer ts.Close()
+
+		urls = append(urls, ts.URL)
+	}
+
+	adminClient, err := NewAdminAPI(urls, nil)
+	require.NoError(t, err)
+	err = adminClient.DecommissionBroker(2)
+	require.NoError(t, err)
+}
+
+func TestConfig(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Exactly(t, http.MethodGet, r.Method)
+			require.Exactly(t, "/v1/config", r.URL.Path)
+			w.Write([]byte(
+				`{"node_id":1,"log_segment_size":1073741824,` +
+					`"enable_idempotence":true}`,
+			))
+		}),
+	)
+	defer ts.Close()
+
+	adminClient, err := NewAdminAPI([]string{ts.URL}, nil)
+	require.NoError(t, err)
+	conf, err := adminClient.Config()
+	require.NoError(t, err)
+	require.Exactly(
+		t,
+		map[string]interface{}{
+			"node_id":            float64(1),
+			"log_segment_size":   float64(1073741824),
+			"enable_idempotence": true,
+		},
+		conf,
+	)
+}
+
+func TestEnableMaintenanceMode(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Exactly(t, http.MethodPut, r.Method)
+			require.Exactly(t, "/v1/brokers/3/maintenance", r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	adminClient, err := NewAdminAPI([]string{ts.URL}, nil)
+	require.NoError(t, err)
+	err = adminClient.EnableMaintenanceMode(3)
+	require.NoError(t, err)
+}
+
+func TestDisableMaintenanceMode(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Exactly(t, http.MethodDelete, r.Method)
+			require.Exactly(t, "/v1/brokers/3/maintenance", r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	adminClient, err := NewAdminAPI([]string{ts.URL}, nil)
+	require.NoError(t, err)
+	err = adminClient.DisableMaintenanceMode(3)
+	require.NoError(t, err)
+}
+
+func TestMaintenanceStatus(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Exactly(t, http.MethodGet, r.Method)
+			require.Exactly(t, "/v1/maintenance", r.URL.Path)
+			w.Write([]byte(
+				`{"draining":true,"finished":false,"errors":false,` +
+					`"partitions":12,"eligible":10,"transferring":4,"failed":0}`,
+			))
+		}),
+	)
+	defer ts.Close()
+
+	adminClient, err := NewAdminAPI([]string{ts.URL}, nil)
+	require.NoError(t, err)
+	status, err := adminClient.MaintenanceStatus()
+	require.NoError(t, err)
+	require.Exactly(
+		t,
+		MaintenanceStatus{
+			Draining:     true,
+			Partitions:   12,
+			Eligible:     10,
+			Transferring: 4,
+		},
+		status,
+	)
+}
+
+func TestNodeConfig(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Exactly(t, http.MethodGet, r.Method)
+			require.Exactly(t, "/v1/node_config", r.URL.Path)
+			w.Write([]byte(`{"node_id":3}`))
+		}),
+	)
+	defer ts.Close()
+
+	adminClient, err := NewAdminAPI([]string{ts.URL}, nil)
+	require.NoError(t, err)
+	conf, err := adminClient.NodeConfig()
+	require.NoError(t, err)
+	require.Exactly(t, NodeConfig{NodeID: 3}, conf)
+}
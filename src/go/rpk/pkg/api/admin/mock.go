@@ -13,6 +13,17 @@ type MockAdminAPI struct {
 	MockCreateUser func(username, password string) error
 	MockDeleteUser func(username string) error
 	MockListUsers  func() ([]string, error)
+
+	MockBrokers            func() ([]Broker, error)
+	MockDecommissionBroker func(id int) error
+
+	MockConfig func() (map[string]interface{}, error)
+
+	MockEnableMaintenanceMode  func(nodeID int) error
+	MockDisableMaintenanceMode func(nodeID int) error
+	MockMaintenanceStatus      func() (MaintenanceStatus, error)
+
+	MockNodeConfig func() (NodeConfig, error)
 }
 
 func (m *MockAdminAPI) CreateUser(username, password string) error {
@@ -35,3 +46,52 @@ func (m *MockAdminAPI) ListUsers() ([]string, error) {
 	}
 	return []string{}, nil
 }
+
+func (m *MockAdminAPI) Brokers() ([]Broker, error) {
+	if m.MockBrokers != nil {
+		return m.MockBrokers()
+	}
+	return []Broker{}, nil
+}
+
+func (m *MockAdminAPI) DecommissionBroker(id int) error {
+	if m.MockDecommissionBroker != nil {
+		return m.MockDecommissionBroker(id)
+	}
+	return nil
+}
+
+func (m *MockAdminAPI) Config() (map[string]interface{}, error) {
+	if m.MockConfig != nil {
+		return m.MockConfig()
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (m *MockAdminAPI) EnableMaintenanceMode(nodeID int) error {
+	if m.MockEnableMaintenanceMode != nil {
+		return m.MockEnableMaintenanceMode(nodeID)
+	}
+	return nil
+}
+
+func (m *MockAdminAPI) DisableMaintenanceMode(nodeID int) error {
+	if m.MockDisableMaintenanceMode != nil {
+		return m.MockDisableMaintenanceMode(nodeID)
+	}
+	return nil
+}
+
+func (m *MockAdminAPI) MaintenanceStatus() (MaintenanceStatus, error) {
+	if m.MockMaintenanceStatus != nil {
+		return m.MockMaintenanceStatus()
+	}
+	return MaintenanceStatus{}, nil
+}
+
+func (m *MockAdminAPI) NodeConfig() (NodeConfig, error) {
+	if m.MockNodeConfig != nil {
+		return m.MockNodeConfig()
+	}
+	return NodeConfig{}, nil
+}
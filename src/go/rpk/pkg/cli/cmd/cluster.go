@@ -79,6 +79,7 @@ func NewClusterCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 		return sarama.NewClusterAdminFromClient(client)
 	}
 	command.AddCommand(cluster.NewOffsetsCommand(clientClosure, adminWrapperClosure))
+	command.AddCommand(cluster.NewCheckCommand(fs, configClosure))
 
 	return command
 }
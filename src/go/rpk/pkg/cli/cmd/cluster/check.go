@@ -0,0 +1,318 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	rpkos "github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+// The name the local node's results are reported under.
+const localNode = "local"
+
+// CheckTransport collects the check results of a remote node.
+type CheckTransport interface {
+	Check(node string, timeout time.Duration) ([]tuners.CheckReport, error)
+}
+
+// Runs 'rpk redpanda check' on the node over SSH. The remote command's exit
+// code is ignored, since it's non-zero when a fatal check fails, and the
+// results are reported either way.
+type sshTransport struct {
+	proc rpkos.Proc
+}
+
+func NewSSHTransport() CheckTransport {
+	return &sshTransport{proc: rpkos.NewProc()}
+}
+
+func (t *sshTransport) Check(
+	node string, timeout time.Duration,
+) ([]tuners.CheckReport, error) {
+	remote := fmt.Sprintf(
+		"rpk redpanda check --format json --timeout %s || true",
+		timeout,
+	)
+	// Leave some time for the connection to be established on top of
+	// the checks' timeout.
+	lines, err := t.proc.RunWithSystemLdPath(
+		timeout+10*time.Second,
+		"ssh",
+		"-o", "BatchMode=yes",
+		node,
+		remote,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return parseCheckReports(lines)
+}
+
+// Parses the output of 'rpk redpanda check --format json'. rpk logs to
+// stdout, so the lines around the results' JSON array are skipped.
+func parseCheckReports(lines []string) ([]tuners.CheckReport, error) {
+	start, end := -1, -1
+	for i, l := range lines {
+		if start == -1 && strings.HasPrefix(l, "[") {
+			start = i
+		}
+		if start != -1 && strings.HasSuffix(l, "]") {
+			end = i
+			break
+		}
+	}
+	if start == -1 || end == -1 {
+		return nil, errors.New("no check results found in the output")
+	}
+	reports := []tuners.CheckReport{}
+	err := json.Unmarshal([]byte(strings.Join(lines[start:end+1], "\n")), &reports)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the check results: %v", err)
+	}
+	return reports, nil
+}
+
+// A check whose current value on a node differs from the rest of the
+// cluster's.
+type divergence struct {
+	node    string
+	check   string
+	current string
+	cluster string
+}
+
+func NewCheckCommand(
+	fs afero.Fs, getConfig func() (*config.Config, error),
+) *cobra.Command {
+	return newCheckCommand(fs, getConfig, NewSSHTransport())
+}
+
+func newCheckCommand(
+	fs afero.Fs,
+	getConfig func() (*config.Config, error),
+	transport CheckTransport,
+) *cobra.Command {
+	var (
+		nodes   []string
+		timeout time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "check",
+		Short: "Compare the system checks' results across the cluster's nodes",
+		Long: `Compare the system checks' results across the cluster's nodes.
+
+The checks are run locally and, over SSH, on each node passed with --nodes
+(which must have rpk installed), and the results which differ from the rest of
+the cluster's are reported, e.g. a node whose data directory is on ext4 while
+the others' are on xfs. Numeric values (e.g. the free memory) are only reported
+if the check passes on some nodes but not on others. The command exits with
+code 3 if any divergence is found. Unreachable nodes are reported, and left out
+of the comparison.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			conf, err := getConfig()
+			if err != nil {
+				return err
+			}
+			local := func() ([]tuners.CheckReport, error) {
+				results, err := tuners.Check(fs, conf, timeout)
+				if err != nil {
+					return nil, err
+				}
+				reports := []tuners.CheckReport{}
+				for _, res := range results {
+					reports = append(reports, tuners.NewCheckReport(res))
+				}
+				return reports, nil
+			}
+			return executeClusterCheck(
+				os.Stdout,
+				local,
+				transport,
+				nodes,
+				timeout,
+			)
+		},
+	}
+	command.Flags().StringSliceVar(
+		&nodes,
+		"nodes",
+		[]string{},
+		"Comma-separated list of the hosts to collect the results from,"+
+			" e.g. host1,host2",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		2000*time.Millisecond,
+		"The maximum amount of time to wait for the checks to complete on"+
+			" each node",
+	)
+	return command
+}
+
+func executeClusterCheck(
+	out io.Writer,
+	local func() ([]tuners.CheckReport, error),
+	transport CheckTransport,
+	nodes []string,
+	timeout time.Duration,
+) error {
+	if len(nodes) == 0 {
+		return fmt.Errorf("no nodes to compare with, pass them with --nodes")
+	}
+	reports, err := local()
+	if err != nil {
+		return err
+	}
+	results := map[string][]tuners.CheckReport{localNode: reports}
+	order := []string{localNode}
+	unreachable := []string{}
+	for _, node := range nodes {
+		reports, err := transport.Check(node, timeout)
+		if err != nil {
+			log.Warnf("Couldn't collect the check results from %s: %v", node, err)
+			unreachable = append(unreachable, node)
+			continue
+		}
+		results[node] = reports
+		order = append(order, node)
+	}
+	divs := compareCheckResults(results, order)
+	if len(unreachable) > 0 {
+		log.Warnf(
+			"%d node(s) were left out of the comparison: %s",
+			len(unreachable),
+			strings.Join(unreachable, ", "),
+		)
+	}
+	if len(divs) == 0 {
+		log.Infof(
+			"The check results are consistent across %d node(s)",
+			len(order),
+		)
+		return nil
+	}
+	t := ui.NewRpkTable(out)
+	t.SetHeader([]string{"Node", "Condition", "Current", "Cluster"})
+	for _, d := range divs {
+		t.Append([]string{d.node, d.check, d.current, d.cluster})
+	}
+	t.Render()
+	return cli.NewExitError(
+		cli.ExitCheckFailed,
+		fmt.Errorf("found %d divergence(s) across the cluster", len(divs)),
+	)
+}
+
+// Compares each check's current value on every node with the cluster's,
+// which is the most common one (ties are broken by the order of the nodes).
+// Results which couldn't be read on a node, and checks which a node doesn't
+// report (e.g. the ones for NICs it doesn't have) are skipped. Numeric values
+// (e.g. the free memory) vary from node to node, so for those it's whether
+// the check passes that's compared.
+func compareCheckResults(
+	results map[string][]tuners.CheckReport, nodes []string,
+) []divergence {
+	byCheck := map[string]map[string]tuners.CheckReport{}
+	for node, reports := range results {
+		for _, r := range reports {
+			if r.Error != "" {
+				continue
+			}
+			if byCheck[r.Description] == nil {
+				byCheck[r.Description] = map[string]tuners.CheckReport{}
+			}
+			byCheck[r.Description][node] = r
+		}
+	}
+	checks := make([]string, 0, len(byCheck))
+	for c := range byCheck {
+		checks = append(checks, c)
+	}
+	sort.Strings(checks)
+
+	divs := []divergence{}
+	for _, check := range checks {
+		reports := byCheck[check]
+		if len(reports) < 2 {
+			continue
+		}
+		numeric := true
+		for _, r := range reports {
+			if _, err := strconv.ParseFloat(r.Current, 64); err != nil {
+				numeric = false
+			}
+		}
+		if numeric {
+			// Compare whether the check passes, and show what's
+			// required as the cluster's value.
+			passed := clusterValue(reports, nodes, func(r tuners.CheckReport) string {
+				return strconv.FormatBool(r.Ok)
+			})
+			for _, node := range nodes {
+				r, ok := reports[node]
+				if !ok || strconv.FormatBool(r.Ok) == passed {
+					continue
+				}
+				divs = append(divs, divergence{node, check, r.Current, r.Required})
+			}
+			continue
+		}
+		cluster := clusterValue(reports, nodes, func(r tuners.CheckReport) string {
+			return r.Current
+		})
+		for _, node := range nodes {
+			r, ok := reports[node]
+			if !ok || r.Current == cluster {
+				continue
+			}
+			divs = append(divs, divergence{node, check, r.Current, cluster})
+		}
+	}
+	return divs
+}
+
+// Returns the most common value among the nodes' reports.
+func clusterValue(
+	reports map[string]tuners.CheckReport,
+	nodes []string,
+	value func(tuners.CheckReport) string,
+) string {
+	counts := map[string]int{}
+	for _, r := range reports {
+		counts[value(r)]++
+	}
+	common, max := "", 0
+	for _, node := range nodes {
+		r, ok := reports[node]
+		if ok && counts[value(r)] > max {
+			common, max = value(r), counts[value(r)]
+		}
+	}
+	return common
+}
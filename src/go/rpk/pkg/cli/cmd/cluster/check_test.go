@@ -0,0 +1,184 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+type fakeTransport map[string][]tuners.CheckReport
+
+func (t fakeTransport) Check(
+	node string, _ time.Duration,
+) ([]tuners.CheckReport, error) {
+	reports, ok := t[node]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return reports, nil
+}
+
+func fsReport(fsType string) tuners.CheckReport {
+	return tuners.CheckReport{
+		Description: "Data directory filesystem type",
+		Required:    "xfs",
+		Current:     fsType,
+		Severity:    "Warning",
+		Ok:          fsType == "xfs",
+	}
+}
+
+func memReport(mb string, passed bool) tuners.CheckReport {
+	return tuners.CheckReport{
+		Description: "Free memory per CPU [MB]",
+		Required:    "2048 per CPU",
+		Current:     mb,
+		Severity:    "Warning",
+		Ok:          passed,
+	}
+}
+
+func TestCompareCheckResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  map[string][]tuners.CheckReport
+		nodes    []string
+		expected []divergence
+	}{
+		{
+			name: "it should report the node which differs from the rest",
+			results: map[string][]tuners.CheckReport{
+				"local": {fsReport("xfs")},
+				"node1": {fsReport("xfs")},
+				"node2": {fsReport("ext4")},
+			},
+			nodes: []string{"local", "node1", "node2"},
+			expected: []divergence{
+				{"node2", "Data directory filesystem type", "ext4", "xfs"},
+			},
+		},
+		{
+			name: "it should use the majority's value even if the local node differs",
+			results: map[string][]tuners.CheckReport{
+				"local": {fsReport("ext4")},
+				"node1": {fsReport("xfs")},
+				"node2": {fsReport("xfs")},
+			},
+			nodes: []string{"local", "node1", "node2"},
+			expected: []divergence{
+				{"local", "Data directory filesystem type", "ext4", "xfs"},
+			},
+		},
+		{
+			name: "it shouldn't report numeric values which pass everywhere",
+			results: map[string][]tuners.CheckReport{
+				"local": {memReport("3000", true)},
+				"node1": {memReport("4096", true)},
+			},
+			nodes:    []string{"local", "node1"},
+			expected: []divergence{},
+		},
+		{
+			name: "it should report numeric values which fail on some nodes",
+			results: map[string][]tuners.CheckReport{
+				"local": {memReport("3000", true)},
+				"node1": {memReport("4096", true)},
+				"node2": {memReport("1024", false)},
+			},
+			nodes: []string{"local", "node1", "node2"},
+			expected: []divergence{
+				{"node2", "Free memory per CPU [MB]", "1024", "2048 per CPU"},
+			},
+		},
+		{
+			name: "it should skip the results which couldn't be read, and the missing ones",
+			results: map[string][]tuners.CheckReport{
+				"local": {fsReport("xfs"), memReport("3000", true)},
+				"node1": {func() tuners.CheckReport {
+					r := fsReport("")
+					r.Error = "no such file or directory"
+					return r
+				}()},
+			},
+			nodes:    []string{"local", "node1"},
+			expected: []divergence{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			divs := compareCheckResults(tt.results, tt.nodes)
+			require.Exactly(st, tt.expected, divs)
+		})
+	}
+}
+
+func TestExecuteClusterCheck(t *testing.T) {
+	local := func() ([]tuners.CheckReport, error) {
+		return []tuners.CheckReport{fsReport("xfs")}, nil
+	}
+	transport := fakeTransport{
+		"node1": {fsReport("xfs")},
+		"node2": {fsReport("ext4")},
+	}
+
+	var out bytes.Buffer
+	err := executeClusterCheck(
+		&out,
+		local,
+		transport,
+		[]string{"node1", "node2", "node3"},
+		time.Second,
+	)
+	require.EqualError(t, err, "found 1 divergence(s) across the cluster")
+	require.Equal(t, cli.ExitCheckFailed, cli.ExitCode(err))
+	require.Contains(t, out.String(), "node2")
+	require.Contains(t, out.String(), "ext4")
+	// Unreachable nodes are left out.
+	require.NotContains(t, out.String(), "node3")
+
+	out.Reset()
+	err = executeClusterCheck(
+		&out,
+		local,
+		transport,
+		[]string{"node1"},
+		time.Second,
+	)
+	require.NoError(t, err)
+}
+
+func TestParseCheckReports(t *testing.T) {
+	lines := []string{
+		"System check 'NTP Synced' failed with non-fatal error 'timeout'",
+		"[",
+		"  {",
+		`    "description": "Data directory filesystem type",`,
+		`    "required": "xfs",`,
+		`    "current": "ext4",`,
+		`    "ok": false,`,
+		`    "severity": "Warning"`,
+		"  }",
+		"]",
+		"",
+	}
+	reports, err := parseCheckReports(lines)
+	require.NoError(t, err)
+	require.Exactly(t, []tuners.CheckReport{fsReport("ext4")}, reports)
+
+	_, err = parseCheckReports([]string{"bash: rpk: command not found"})
+	require.EqualError(t, err, "no check results found in the output")
+}
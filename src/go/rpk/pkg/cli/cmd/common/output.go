@@ -0,0 +1,54 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const outputFlag = "output"
+
+// AddOutputFlag adds the --output flag to commands which print a payload,
+// such as a config or a manifest, so that it can be written to a file
+// instead of being mixed with the logs.
+func AddOutputFlag(command *cobra.Command, path *string) {
+	command.Flags().StringVar(
+		path,
+		outputFlag,
+		"",
+		"Write the output to the given file instead of stdout",
+	)
+}
+
+// WriteOutput writes the payload to the file at path, as passed with
+// --output, or to the command's output (stdout by default) if it's empty.
+// The file is created along with its parent directories if it doesn't exist,
+// and overwritten if it does.
+func WriteOutput(
+	fs afero.Fs, command *cobra.Command, path string, payload []byte,
+) error {
+	if path == "" {
+		_, err := command.OutOrStdout().Write(payload)
+		return err
+	}
+	err := fs.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("couldn't create the directory for %s: %v", path, err)
+	}
+	err = afero.WriteFile(fs, path, payload, 0644)
+	if err != nil {
+		return fmt.Errorf("couldn't write the output to %s: %v", path, err)
+	}
+	return nil
+}
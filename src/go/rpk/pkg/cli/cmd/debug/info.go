@@ -146,7 +146,7 @@ func executeInfo(
 		return getCPUInfo(fs, cpuInfoRowsCh)
 	})
 	grp.Go(func() error {
-		return getConf(mgr, conf.ConfigFile, confRowsCh)
+		return getConf(fs, mgr, conf.ConfigFile, confRowsCh)
 	})
 	results := [][][]string{
 		metricsRes.rows,
@@ -241,9 +241,18 @@ func getCPUInfo(fs afero.Fs, out chan<- [][]string) error {
 }
 
 func getConf(
-	mgr config.Manager, configFile string, out chan<- [][]string,
+	fs afero.Fs,
+	mgr config.Manager,
+	configFile string,
+	out chan<- [][]string,
 ) error {
 	rows := [][]string{}
+	sum, err := config.ConfigChecksum(fs, configFile)
+	if err != nil {
+		log.Debugf("Couldn't compute the config checksum: %v", err)
+	} else {
+		rows = append(rows, []string{"Config Checksum (SHA256)", sum})
+	}
 	props, err := mgr.ReadFlat(configFile)
 	if err != nil {
 		err = errors.Wrap(err, "Error reading or parsing configuration")
@@ -101,6 +101,11 @@ power management:
 			expectedOut: `\n\s\sCPU\sModel[\s]+`,
 			before:      defaultSetup,
 		},
+		{
+			name:        "it should contain a config checksum row",
+			expectedOut: `\n\s\sConfig\sChecksum\s\(SHA256\)\s+[0-9a-f]{64}`,
+			before:      defaultSetup,
+		},
 		{
 			name:        "doesn't print the CPU% if no pid file is found",
 			expectedOut: "Omitting runtime metrics: the local redpanda process isn't running.",
@@ -10,18 +10,21 @@
 package cmd
 
 import (
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/generate"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 )
 
-func NewGenerateCommand(mgr config.Manager) *cobra.Command {
+func NewGenerateCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "generate [template]",
 		Short: "Generate a configuration template for related services.",
 	}
-	command.AddCommand(generate.NewGrafanaDashboardCmd())
+	command.AddCommand(generate.NewGrafanaDashboardCmd(fs))
+	command.AddCommand(generate.NewGrafanaBundleCmd(fs))
 	command.AddCommand(generate.NewPrometheusConfigCmd(mgr))
+	command.AddCommand(generate.NewConfigSchemaCmd(fs))
 	command.AddCommand(generate.NewShellCompletionCommand())
 	return command
 }
@@ -0,0 +1,184 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package generate
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// The fields config.Check requires, keyed by the path of the object they're
+// in. The items of a list share its path.
+var requiredConfigFields = map[string][]string{
+	"":                           {"redpanda"},
+	"redpanda":                   {"data_directory", "rpc_server", "kafka_api"},
+	"redpanda.rpc_server":        {"address", "port"},
+	"redpanda.kafka_api":         {"address", "port"},
+	"redpanda.seed_servers":      {"host"},
+	"redpanda.seed_servers.host": {"address", "port"},
+}
+
+func NewConfigSchemaCmd(fs afero.Fs) *cobra.Command {
+	var output string
+	command := &cobra.Command{
+		Use:   "config-schema",
+		Short: "Generate a JSON Schema for the redpanda config file.",
+		Long: `Generate a JSON Schema (draft-07) for the redpanda config file.
+
+The schema describes every field rpk knows about, its type, and the fields
+which are required for the config to be valid. It can be used to validate
+generated config files, or by editors to offer autocompletion. The redpanda
+block, as well as the config's top level, may have other fields which rpk
+passes through to redpanda as they are.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			jsonSchema, err := json.MarshalIndent(configSchema(), "", "  ")
+			if err != nil {
+				return err
+			}
+			if output != "" {
+				return common.WriteOutput(
+					fs,
+					cmd,
+					output,
+					append(jsonSchema, '\n'),
+				)
+			}
+			log.SetFormatter(cli.NewNoopFormatter())
+			// The logger's default stream is stderr, which prevents piping to files
+			// from working without redirecting them with '2>&1'.
+			if log.StandardLogger().Out == os.Stderr {
+				log.SetOutput(os.Stdout)
+			}
+			log.Info(string(jsonSchema))
+			return nil
+		},
+	}
+	common.AddOutputFlag(command, &output)
+	return command
+}
+
+func configSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(config.Config{}), "")
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "redpanda.yaml"
+	return schema
+}
+
+// Returns the schema for the given type, where path is the dot-separated
+// YAML path to the field it belongs to.
+func typeSchema(t reflect.Type, path string) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		// Optional fields may be set to null explicitly.
+		schema := typeSchema(t.Elem(), path)
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typ, "null"}
+		}
+		return schema
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), path),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), path),
+		}
+	case reflect.Struct:
+		return structSchema(t, path)
+	}
+	// Any value, e.g. for interface{}.
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, path string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	additional := false
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			// Unexported fields aren't part of the config file.
+			if field.PkgPath != "" {
+				continue
+			}
+			name, inline := yamlName(field)
+			if name == "-" {
+				continue
+			}
+			if inline {
+				if field.Type.Kind() == reflect.Map {
+					// The fields rpk doesn't know about, which are kept
+					// as they are.
+					additional = true
+				} else {
+					addFields(field.Type)
+				}
+				continue
+			}
+			properties[name] = typeSchema(field.Type, joinPath(path, name))
+		}
+	}
+	addFields(t)
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": additional,
+	}
+	if required, ok := requiredConfigFields[path]; ok {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Returns the field's name in the YAML file, and whether it's inlined into
+// its parent.
+func yamlName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			return "", true
+		}
+	}
+	if parts[0] == "" {
+		return strings.ToLower(field.Name), false
+	}
+	return parts[0], false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
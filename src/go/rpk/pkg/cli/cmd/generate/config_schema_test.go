@@ -0,0 +1,122 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package generate_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/generate"
+)
+
+func TestConfigSchemaCmd(t *testing.T) {
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	cmd := generate.NewConfigSchemaCmd(afero.NewMemMapFs())
+	cmd.SetArgs([]string{})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	schema := map[string]interface{}{}
+	err = json.Unmarshal(out.Bytes(), &schema)
+	require.NoError(t, err)
+	require.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+	require.Equal(t, "object", schema["type"])
+	require.Equal(t, []interface{}{"redpanda"}, schema["required"])
+	// The top level may have fields rpk doesn't know about.
+	require.Equal(t, true, schema["additionalProperties"])
+
+	props := schema["properties"].(map[string]interface{})
+	redpanda := props["redpanda"].(map[string]interface{})
+	require.Equal(
+		t,
+		[]interface{}{"data_directory", "rpc_server", "kafka_api"},
+		redpanda["required"],
+	)
+	rpProps := redpanda["properties"].(map[string]interface{})
+	require.Equal(
+		t,
+		map[string]interface{}{"type": "integer"},
+		rpProps["node_id"],
+	)
+	// Optional fields may be null.
+	require.Equal(
+		t,
+		map[string]interface{}{"type": []interface{}{"integer", "null"}},
+		rpProps["log_segment_size"],
+	)
+
+	// The inlined socket address fields are part of the listeners.
+	kafkaAPI := rpProps["kafka_api"].(map[string]interface{})
+	require.Equal(t, "array", kafkaAPI["type"])
+	listener := kafkaAPI["items"].(map[string]interface{})
+	require.Equal(t, []interface{}{"address", "port"}, listener["required"])
+	listenerProps := listener["properties"].(map[string]interface{})
+	for _, field := range []string{"address", "port", "name", "authentication_method"} {
+		require.Contains(t, listenerProps, field)
+	}
+
+	seeds := rpProps["seed_servers"].(map[string]interface{})
+	seed := seeds["items"].(map[string]interface{})
+	require.Equal(t, []interface{}{"host"}, seed["required"])
+	host := seed["properties"].(map[string]interface{})["host"].(map[string]interface{})
+	require.Equal(t, []interface{}{"address", "port"}, host["required"])
+
+	rpk := props["rpk"].(map[string]interface{})
+	require.Equal(t, false, rpk["additionalProperties"])
+	rpkProps := rpk["properties"].(map[string]interface{})
+	require.Equal(
+		t,
+		map[string]interface{}{"type": "boolean"},
+		rpkProps["tune_network"],
+	)
+	require.Equal(
+		t,
+		map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		},
+		rpkProps["node_labels"],
+	)
+	require.Equal(
+		t,
+		map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		},
+		rpkProps["additional_start_flags"],
+	)
+}
+
+func TestConfigSchemaCmdOutput(t *testing.T) {
+	const path = "/tmp/redpanda-schema.json"
+	fs := afero.NewMemMapFs()
+	var out, logs bytes.Buffer
+	logrus.SetOutput(&logs)
+	cmd := generate.NewConfigSchemaCmd(fs)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--output", path})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	bs, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	schema := map[string]interface{}{}
+	err = json.Unmarshal(bs, &schema)
+	require.NoError(t, err)
+	require.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+	// Nothing else is printed.
+	require.Empty(t, out.String())
+	require.Empty(t, logs.String())
+}
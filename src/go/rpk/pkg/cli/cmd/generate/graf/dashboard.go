@@ -9,7 +9,10 @@
 
 package graf
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 const panelHeight = "8"
 
@@ -20,6 +23,12 @@ func nextID() uint {
 	return id
 }
 
+// ResetIDs makes the panels created afterwards start from ID 1 again, so
+// that each dashboard built in the same process gets the same IDs.
+func ResetIDs() {
+	id = 0
+}
+
 type Dashboard struct {
 	UID           string      `json:"uid,omitempty"`
 	Title         string      `json:"title,omitempty"`
@@ -40,6 +49,60 @@ type Panel interface {
 	Type() string
 	GetGridPos() *GridPos
 }
+
+func (d *Dashboard) UnmarshalJSON(data []byte) error {
+	type DashboardAlias Dashboard
+	typedDashboard := struct {
+		Panels []json.RawMessage `json:"panels"`
+		*DashboardAlias
+	}{
+		DashboardAlias: (*DashboardAlias)(d),
+	}
+	err := json.Unmarshal(data, &typedDashboard)
+	if err != nil {
+		return err
+	}
+	d.Panels, err = unmarshalPanels(typedDashboard.Panels)
+	return err
+}
+
+// Decodes each panel into the type named by its "type" field, as they are
+// written by their MarshalJSON.
+func unmarshalPanels(raws []json.RawMessage) ([]Panel, error) {
+	if raws == nil {
+		return nil, nil
+	}
+	panels := make([]Panel, 0, len(raws))
+	for _, raw := range raws {
+		typed := struct {
+			Type string `json:"type"`
+		}{}
+		err := json.Unmarshal(raw, &typed)
+		if err != nil {
+			return nil, err
+		}
+		var panel Panel
+		switch typed.Type {
+		case (*RowPanel)(nil).Type():
+			panel = &RowPanel{}
+		case (*GraphPanel)(nil).Type():
+			panel = &GraphPanel{}
+		case (*SingleStatPanel)(nil).Type():
+			panel = &SingleStatPanel{}
+		case (*TextPanel)(nil).Type():
+			panel = &TextPanel{}
+		default:
+			return nil, fmt.Errorf("unsupported panel type '%s'", typed.Type)
+		}
+		err = json.Unmarshal(raw, panel)
+		if err != nil {
+			return nil, err
+		}
+		panels = append(panels, panel)
+	}
+	return panels, nil
+}
+
 type Templating struct {
 	List []TemplateVar `json:"list"`
 }
@@ -100,3 +100,58 @@ func TestDashboardMarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestDashboardUnmarshalJSON(t *testing.T) {
+	// The panels' IDs are sequential, so the dashboard is built only once.
+	dashboard := defaultDashboard()
+	text := graf.NewTextPanel("<h1>Redpanda</h1>", "html")
+	text.Title = "text 1"
+	singlestat := graf.NewSingleStatPanel("single stat 2")
+	singlestat.Datasource = "prometheus"
+	singlestat.Targets = []graf.Target{{
+		Expr:    `sum(up{job=~"redpanda"})`,
+		Instant: true,
+	}}
+	dashboard.Panels = append(dashboard.Panels, text, singlestat)
+	dashboardJSON, err := json.Marshal(dashboard)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    graf.Dashboard
+		expectedErr string
+	}{
+		{
+			name:     "it should decode every panel type, including nested ones",
+			input:    string(dashboardJSON),
+			expected: dashboard,
+		},
+		{
+			name:        "it should fail if a panel's type isn't supported",
+			input:       `{"panels":[{"type":"heatmap","id":1}]}`,
+			expectedErr: "unsupported panel type 'heatmap'",
+		},
+		{
+			name:        "it should fail if a nested panel's type isn't supported",
+			input:       `{"panels":[{"type":"row","panels":[{"id":1}]}]}`,
+			expectedErr: "unsupported panel type ''",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var d graf.Dashboard
+			err := json.Unmarshal([]byte(tt.input), &d)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, d)
+			// It should be marshalled back into the same JSON.
+			roundTripped, err := json.Marshal(d)
+			require.NoError(st, err)
+			require.Equal(st, tt.input, string(roundTripped))
+		})
+	}
+}
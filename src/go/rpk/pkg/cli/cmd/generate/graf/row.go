@@ -38,6 +38,22 @@ func (p *RowPanel) MarshalJSON() ([]byte, error) {
 	return json.Marshal(typedPanel)
 }
 
+func (p *RowPanel) UnmarshalJSON(data []byte) error {
+	type PanelAlias RowPanel
+	typedPanel := struct {
+		Panels []json.RawMessage `json:"panels"`
+		*PanelAlias
+	}{
+		PanelAlias: (*PanelAlias)(p),
+	}
+	err := json.Unmarshal(data, &typedPanel)
+	if err != nil {
+		return err
+	}
+	p.Panels, err = unmarshalPanels(typedPanel.Panels)
+	return err
+}
+
 func NewRowPanel(title string, panels ...Panel) *RowPanel {
 	return &RowPanel{
 		BasePanel: &BasePanel{
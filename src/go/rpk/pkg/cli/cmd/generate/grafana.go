@@ -14,23 +14,41 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/generate/graf"
 )
 
-var datasource string
-var jobName string
+const (
+	panelHeight    = 6
+	datasourceFlag = "datasource"
+	// The labels most panels aggregate the metrics by, as chosen in the
+	// dashboard.
+	aggrCriteria  = "[[aggr_criteria]]"
+	perShardTitle = "per shard"
+	// The group of the metrics which don't belong to any of metricGroups.
+	othersGroup = "others"
+	// The label of a summary's quantiles.
+	quantileLabel = "quantile"
+)
 
-const panelHeight = 6
+// The percentiles rendered if --percentiles isn't set.
+var (
+	defaultSummaryPercentiles   = []float64{0.95, 0.99}
+	defaultHistogramPercentiles = []float64{0.95}
+)
 
 var metricGroups = []string{
 	"errors",
@@ -45,33 +63,117 @@ var metricGroups = []string{
 	"raft",
 }
 
+// The options the dashboard is generated with.
+type dashboardOptions struct {
+	datasource      string
+	jobName         string
+	perShardMetrics []string
+	// The metric groups whose rows are rendered, or all of them if empty.
+	groups        []string
+	percentiles   []float64
+	unitOverrides []unitOverride
+}
+
 type RowSet struct {
 	rowTitles   []string
 	groupPanels map[string]*graf.RowPanel
+	opts        dashboardOptions
 }
 
-func newRowSet() *RowSet {
+func newRowSet(opts dashboardOptions) *RowSet {
 	return &RowSet{
 		rowTitles:   []string{},
 		groupPanels: map[string]*graf.RowPanel{},
+		opts:        opts,
+	}
+}
+
+// Where the metrics the dashboard is generated from are read from: either a
+// node's metrics endpoint, or a file with a scrape of it.
+type metricsSource struct {
+	endpoint string
+	file     string
+}
+
+func (s metricsSource) String() string {
+	if s.file != "" {
+		return s.file
 	}
+	return s.endpoint
 }
 
-func NewGrafanaDashboardCmd() *cobra.Command {
-	var metricsEndpoint string
+func (s metricsSource) fetch() (map[string]*dto.MetricFamily, error) {
+	if s.file != "" {
+		return readMetricsFile(s.file)
+	}
+	return fetchMetrics(s.endpoint)
+}
+
+func NewGrafanaDashboardCmd(fs afero.Fs) *cobra.Command {
+	var (
+		metricsEndpoint string
+		metricsFile     string
+		lintFile        string
+		listGroups      bool
+		unitOverrideArg []string
+		output          string
+		pretty          bool
+		validate        bool
+		opts            dashboardOptions
+	)
+	metricsEndpointFlag := "metrics-endpoint"
+	deprecatedPrometheusURLFlag := "prometheus-url"
+	metricsFileFlag := "metrics-file"
 	command := &cobra.Command{
 		Use:   "grafana-dashboard",
 		Short: "Generate a Grafana dashboard for redpanda metrics.",
 		RunE: func(ccmd *cobra.Command, args []string) error {
+			if listGroups {
+				for _, group := range availableGroups() {
+					fmt.Fprintln(ccmd.OutOrStdout(), group)
+				}
+				return nil
+			}
+			if err := checkGroups(opts.groups); err != nil {
+				return err
+			}
+			for _, p := range opts.percentiles {
+				if p <= 0 || p >= 1 {
+					return fmt.Errorf(
+						"the percentiles must be between 0 and 1 (exclusive), but got %s",
+						formatFloat(p),
+					)
+				}
+			}
+			var err error
+			opts.unitOverrides, err = parseUnitOverrides(unitOverrideArg)
+			if err != nil {
+				return err
+			}
+			endpointSet := ccmd.Flags().Changed(metricsEndpointFlag) ||
+				ccmd.Flags().Changed(deprecatedPrometheusURLFlag)
+			if endpointSet && metricsFile != "" {
+				return fmt.Errorf(
+					"--%s and --%s can't be used together",
+					metricsEndpointFlag,
+					metricsFileFlag,
+				)
+			}
 			if !(strings.HasPrefix(metricsEndpoint, "http://") ||
 				strings.HasPrefix(metricsEndpoint, "https://")) {
 				metricsEndpoint = fmt.Sprintf("http://%s", metricsEndpoint)
 			}
-			return executeGrafanaDashboard(metricsEndpoint)
+			src := metricsSource{endpoint: metricsEndpoint, file: metricsFile}
+			if lintFile != "" {
+				return executeLintDashboard(src, lintFile)
+			}
+			// --datasource is only required when generating a dashboard.
+			if opts.datasource == "" {
+				return fmt.Errorf(`required flag(s) "%s" not set`, datasourceFlag)
+			}
+			return executeGrafanaDashboard(fs, ccmd, src, opts, output, pretty, validate)
 		},
 	}
-	metricsEndpointFlag := "metrics-endpoint"
-	deprecatedPrometheusURLFlag := "prometheus-url"
 
 	for _, flag := range []string{metricsEndpointFlag, deprecatedPrometheusURLFlag} {
 		command.Flags().StringVar(
@@ -83,31 +185,130 @@ func NewGrafanaDashboardCmd() *cobra.Command {
 
 	command.Flags().MarkDeprecated(deprecatedPrometheusURLFlag, fmt.Sprintf("Deprecated flag. Use --%v instead", metricsEndpointFlag))
 
-	datasourceFlag := "datasource"
 	command.Flags().StringVar(
-		&datasource,
+		&metricsFile,
+		metricsFileFlag,
+		"",
+		"Read the metrics from the given file, in the Prometheus text format,"+
+			" instead of from --"+metricsEndpointFlag+", e.g. to generate the"+
+			" dashboard offline")
+
+	command.Flags().StringVar(
+		&opts.datasource,
 		datasourceFlag,
 		"",
 		"The name of the Prometheus datasource as configured in your grafana instance.")
 	command.Flags().StringVar(
-		&jobName,
+		&opts.jobName,
 		"job-name",
 		"redpanda",
-		"The prometheus job name by which to identify the redpanda nodes")
-	command.MarkFlagRequired(datasourceFlag)
+		"The prometheus job name by which to identify the redpanda nodes."+
+			" It's the default value of the dashboard's job variable, which"+
+			" can be changed to view other clusters")
+	command.Flags().StringVar(
+		&lintFile,
+		"lint",
+		"",
+		"Instead of generating a dashboard, report the metrics referenced by"+
+			" the given dashboard JSON file which the node doesn't export")
+	command.Flags().StringSliceVar(
+		&opts.perShardMetrics,
+		"per-shard-metrics",
+		[]string{},
+		"The metric families to also render broken out by shard, in a"+
+			" separate row, e.g. for debugging shard imbalances")
+	command.Flags().StringSliceVar(
+		&opts.groups,
+		"groups",
+		[]string{},
+		"Only render the rows of the given metric groups, e.g."+
+			" errors,raft,storage. The summary is always rendered")
+	command.Flags().Float64SliceVar(
+		&opts.percentiles,
+		"percentiles",
+		[]float64{},
+		"The percentiles to render the latency panels of the summary and the"+
+			" histograms' panels for, e.g. 0.5,0.95,0.99,0.999. If not set,"+
+			" the summary renders 0.95 and 0.99, and the histograms 0.95")
+	command.Flags().StringArrayVar(
+		&unitOverrideArg,
+		"unit-override",
+		[]string{},
+		"Set the Y axis unit of the panels of the metrics matching a pattern,"+
+			" as 'pattern=unit', where the pattern is a regular expression"+
+			" and the unit a Grafana unit, e.g. 'batches_read$=ops'. Can be"+
+			" passed multiple times, and the first matching one is used")
+	command.Flags().BoolVar(
+		&listGroups,
+		"list-groups",
+		false,
+		"Print the metric groups which can be passed to --groups and exit")
+	command.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"",
+		"Write the dashboard to the given file instead of stdout, creating"+
+			" its parent directories if they don't exist")
+	command.Flags().BoolVar(
+		&pretty,
+		"pretty",
+		true,
+		"Indent the dashboard's JSON. --pretty=false prints it compacted")
+	command.Flags().BoolVar(
+		&validate,
+		"validate",
+		false,
+		"Check that the dashboard's JSON is the same after reading it back"+
+			" into the dashboard types, and fail if any field is lost")
 	return command
 }
 
-func executeGrafanaDashboard(metricsEndpoint string) error {
-	metricFamilies, err := fetchMetrics(metricsEndpoint)
+func executeGrafanaDashboard(
+	fs afero.Fs,
+	cmd *cobra.Command,
+	src metricsSource,
+	opts dashboardOptions,
+	output string,
+	pretty, validate bool,
+) error {
+	metricFamilies, err := src.fetch()
 	if err != nil {
 		return err
 	}
-	dashboard := buildGrafanaDashboard(metricFamilies)
-	jsonSpec, err := json.MarshalIndent(dashboard, "", " ")
+	for _, name := range opts.perShardMetrics {
+		if _, ok := metricFamilies[name]; !ok {
+			return fmt.Errorf(
+				"can't render %s per shard: %s doesn't export it",
+				name,
+				src,
+			)
+		}
+	}
+	dashboard := buildGrafanaDashboard(metricFamilies, opts)
+	if validate {
+		err = validateDashboard(dashboard)
+		if err != nil {
+			return err
+		}
+	}
+	var jsonSpec []byte
+	if pretty {
+		jsonSpec, err = json.MarshalIndent(dashboard, "", " ")
+	} else {
+		jsonSpec, err = json.Marshal(dashboard)
+	}
 	if err != nil {
 		return err
 	}
+	if output != "" {
+		err = common.WriteOutput(fs, cmd, output, append(jsonSpec, '\n'))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote the dashboard to %s\n", output)
+		return nil
+	}
 	log.SetFormatter(cli.NewNoopFormatter())
 	// The logger's default stream is stderr, which prevents piping to files
 	// from working without redirecting them with '2>&1'.
@@ -118,20 +319,69 @@ func executeGrafanaDashboard(metricsEndpoint string) error {
 	return nil
 }
 
+// Checks that the dashboard is marshalled into the same JSON after it's
+// marshalled and unmarshalled, i.e. that every field the panels are
+// rendered with can be read back.
+func validateDashboard(dashboard graf.Dashboard) error {
+	original, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+	var decoded graf.Dashboard
+	err = json.Unmarshal(original, &decoded)
+	if err != nil {
+		return fmt.Errorf("couldn't read the dashboard's JSON back: %v", err)
+	}
+	roundTripped, err := json.Marshal(decoded)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(original, roundTripped) {
+		return nil
+	}
+	i := 0
+	for i < len(original) && i < len(roundTripped) && original[i] == roundTripped[i] {
+		i++
+	}
+	return fmt.Errorf(
+		"the dashboard's JSON changed after reading it back, at offset %d:"+
+			" expected '%s' but got '%s'",
+		i,
+		jsonExcerpt(original, i),
+		jsonExcerpt(roundTripped, i),
+	)
+}
+
+// Returns up to 40 bytes of the given JSON around the given offset.
+func jsonExcerpt(bs []byte, offset int) string {
+	const radius = 20
+	start := offset - radius
+	if start < 0 {
+		start = 0
+	}
+	end := offset + radius
+	if end > len(bs) {
+		end = len(bs)
+	}
+	return string(bs[start:end])
+}
+
 func buildGrafanaDashboard(
-	metricFamilies map[string]*dto.MetricFamily,
+	metricFamilies map[string]*dto.MetricFamily, opts dashboardOptions,
 ) graf.Dashboard {
+	graf.ResetIDs()
 	intervals := []string{"5s", "10s", "30s", "1m", "5m", "15m", "30m", "1h", "2h", "1d"}
 	timeOptions := []string{"5m", "15m", "1h", "6h", "12h", "24h", "2d", "7d", "30d"}
-	summaryPanels := buildSummary(metricFamilies)
+	summaryPanels := opts.buildSummary(metricFamilies)
 	lastY := summaryPanels[len(summaryPanels)-1].GetGridPos().Y + panelHeight
-	rowSet := newRowSet()
+	rowSet := newRowSet(opts)
 	rowSet.processRows(metricFamilies)
 	rowSet.addCachePerformancePanels(metricFamilies)
+	rowSet.addPerShardPanels(metricFamilies, opts.perShardMetrics)
 	rows := rowSet.finalize(lastY)
 	return graf.Dashboard{
 		Title:      "Redpanda",
-		Templating: buildTemplating(),
+		Templating: opts.buildTemplating(),
 		Panels: append(
 			summaryPanels,
 			rows...,
@@ -176,28 +426,50 @@ func (rowSet *RowSet) processRows(metricFamilies map[string]*dto.MetricFamily) {
 	}
 	sort.Strings(names)
 	for _, name := range names {
-		family := metricFamilies[name]
-		var panel graf.Panel
-		if family.GetType() == dto.MetricType_COUNTER {
-			panel = newCounterPanel(family)
-		} else if subtype(family) == "histogram" {
-			panel = newPercentilePanel(family, 0.95)
-		} else {
-			panel = newGaugePanel(family)
+		group := metricGroup(name)
+		if !rowSet.opts.isGroupSelected(group) {
+			continue
 		}
+		for _, panel := range rowSet.opts.newMetricPanels(metricFamilies[name], aggrCriteria) {
+			rowSet.addPanel(group, panel)
+		}
+	}
+}
+
+func (rowSet *RowSet) addPanel(group string, panel graf.Panel) {
+	row, ok := rowSet.groupPanels[group]
+	if ok {
+		row.Panels = append(row.Panels, panel)
+		rowSet.groupPanels[group] = row
+	} else {
+		rowSet.rowTitles = append(rowSet.rowTitles, group)
+		rowSet.groupPanels[group] = graf.NewRowPanel(group, panel)
+	}
+}
 
-		if panel == nil {
+// Adds a row with a panel for each of the given metric families, where the
+// values aren't aggregated across shards, so that imbalances between them
+// show up.
+func (rowSet *RowSet) addPerShardPanels(
+	metricFamilies map[string]*dto.MetricFamily, names []string,
+) {
+	for _, name := range names {
+		family, ok := metricFamilies[name]
+		if !ok {
 			continue
 		}
-
-		group := metricGroup(name)
-		row, ok := rowSet.groupPanels[group]
-		if ok {
-			row.Panels = append(row.Panels, panel)
-			rowSet.groupPanels[group] = row
-		} else {
-			rowSet.rowTitles = append(rowSet.rowTitles, group)
-			rowSet.groupPanels[group] = graf.NewRowPanel(group, panel)
+		// Shard IDs repeat across nodes, so the series are kept apart
+		// by instance too.
+		for _, panel := range rowSet.opts.newMetricPanels(family, "instance, shard") {
+			panel.Title += " - " + perShardTitle
+			for i := range panel.Targets {
+				legend := "{{instance}} shard {{shard}}"
+				if isSummary(family) {
+					legend += fmt.Sprintf(", %s: {{%s}}", quantileLabel, quantileLabel)
+				}
+				panel.Targets[i].LegendFormat = legend
+			}
+			rowSet.addPanel(perShardTitle, panel)
 		}
 	}
 }
@@ -208,7 +480,7 @@ func (rowSet *RowSet) addRatioPanel(
 	a := metricFamilies[m0]
 	b := metricFamilies[m1]
 	row, _ := rowSet.groupPanels[group]
-	panel := makeRatioPanel(a, b, help)
+	panel := rowSet.opts.makeRatioPanel(a, b, help)
 	row.Panels = append(row.Panels, panel)
 	rowSet.groupPanels[group] = row
 }
@@ -217,6 +489,9 @@ func (rowSet *RowSet) addCachePerformancePanels(
 	metricFamilies map[string]*dto.MetricFamily,
 ) {
 
+	if !rowSet.opts.isGroupSelected("storage") {
+		return
+	}
 	// are we generating for a broker that has these stats?
 	if _, ok := metricFamilies["vectorized_storage_log_cached_batches_read"]; !ok {
 		return
@@ -237,17 +512,24 @@ func (rowSet *RowSet) addCachePerformancePanels(
 		"Batch cache hit ratio - bytes")
 }
 
-func buildTemplating() graf.Templating {
-	node := newDefaultTemplateVar("node", "Node", true)
+func (o dashboardOptions) buildTemplating() graf.Templating {
+	// The job the nodes are scraped under, which defaults to --job-name, so
+	// that the same dashboard can be used for clusters scraped under
+	// different jobs.
+	job := o.newDefaultTemplateVar("job", "Job", false)
+	job.Type = "query"
+	job.Query = "label_values(vectorized_application_uptime, job)"
+	job.Current = graf.Current{Text: o.jobName, Value: o.jobName}
+	node := o.newDefaultTemplateVar("node", "Node", true)
 	node.IncludeAll = true
 	node.AllValue = ".*"
 	node.Type = "query"
-	node.Query = "label_values(instance)"
-	shard := newDefaultTemplateVar("node_shard", "Shard", true)
+	node.Query = `label_values({job=~"[[job]]"}, instance)`
+	shard := o.newDefaultTemplateVar("node_shard", "Shard", true)
 	shard.IncludeAll = true
 	shard.AllValue = ".*"
 	shard.Type = "query"
-	shard.Query = "label_values(shard)"
+	shard.Query = `label_values({job=~"[[job]]"}, shard)`
 	clusterOpt := graf.Option{
 		Text:     "Cluster",
 		Value:    "",
@@ -266,7 +548,7 @@ func buildTemplating() graf.Templating {
 			Selected: false,
 		},
 	}
-	aggregate := newDefaultTemplateVar(
+	aggregate := o.newDefaultTemplateVar(
 		"aggr_criteria",
 		"Aggregate by",
 		false,
@@ -279,14 +561,16 @@ func buildTemplating() graf.Templating {
 		Value: clusterOpt.Value,
 	}
 	return graf.Templating{
-		List: []graf.TemplateVar{node, shard, aggregate},
+		List: []graf.TemplateVar{job, node, shard, aggregate},
 	}
 }
 
-func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
+func (o dashboardOptions) buildSummary(
+	metricFamilies map[string]*dto.MetricFamily,
+) []graf.Panel {
 	maxWidth := 24
 	singleStatW := 2
-	percentiles := []float32{0.95, 0.99}
+	percentiles := o.summaryPercentiles()
 	percentilesNo := len(percentiles)
 	panels := []graf.Panel{}
 	y := 0
@@ -299,10 +583,10 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 	y += summaryTitle.GridPos.H
 
 	nodesUp := graf.NewSingleStatPanel("Nodes Up")
-	nodesUp.Datasource = datasource
+	nodesUp.Datasource = o.datasource
 	nodesUp.GridPos = graf.GridPos{H: 6, W: singleStatW, X: 0, Y: y}
 	nodesUp.Targets = []graf.Target{{
-		Expr:           `count by (app) (vectorized_application_uptime)`,
+		Expr:           `count by (app) (vectorized_application_uptime{job=~"[[job]]"})`,
 		Step:           40,
 		IntervalFactor: 1,
 		LegendFormat:   "Nodes Up",
@@ -312,7 +596,7 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 	y += nodesUp.GridPos.H
 
 	partitionCount := graf.NewSingleStatPanel("Partitions")
-	partitionCount.Datasource = datasource
+	partitionCount.Datasource = o.datasource
 	partitionCount.GridPos = graf.GridPos{
 		H: 6,
 		W: singleStatW,
@@ -320,7 +604,7 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 		Y: y,
 	}
 	partitionCount.Targets = []graf.Target{{
-		Expr:         `count(count by (topic,partition) (vectorized_storage_log_partition_size{namespace="kafka"}))`,
+		Expr:         `count(count by (topic,partition) (vectorized_storage_log_partition_size{job=~"[[job]]",namespace="kafka"}))`,
 		LegendFormat: "Partition count",
 	}}
 	partitionCount.Transparent = true
@@ -331,7 +615,7 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 	if kafkaExists {
 		width := (maxWidth - (singleStatW * 2)) / percentilesNo
 		for i, p := range percentiles {
-			panel := newPercentilePanel(kafkaFamily, p)
+			panel := o.newPercentilePanel(kafkaFamily, p, aggrCriteria)
 			panel.GridPos = graf.GridPos{
 				H: panelHeight,
 				W: width,
@@ -343,6 +627,8 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 		y += panelHeight
 	}
 	width := maxWidth / 4
+	// The RPC latency panels share the left half with each other.
+	rpcWidth := maxWidth / 2 / percentilesNo
 	rpcLatencyText := htmlHeader("Internal RPC Latency")
 	rpcLatencyTitle := graf.NewTextPanel(rpcLatencyText, "html")
 	rpcLatencyTitle.GridPos = graf.GridPos{H: 2, W: maxWidth / 2, X: 0, Y: y}
@@ -352,11 +638,11 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 		y += rpcLatencyTitle.GridPos.H
 		panels = append(panels, rpcLatencyTitle)
 		for i, p := range percentiles {
-			panel := newPercentilePanel(rpcFamily, p)
+			panel := o.newPercentilePanel(rpcFamily, p, aggrCriteria)
 			panel.GridPos = graf.GridPos{
 				H: panelHeight,
-				W: width,
-				X: i * width,
+				W: rpcWidth,
+				X: i * rpcWidth,
 				Y: y,
 			}
 			panels = append(panels, panel)
@@ -377,7 +663,7 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 	readBytesFamily, readBytesExist := metricFamilies["vectorized_storage_log_read_bytes"]
 	writtenBytesFamily, writtenBytesExist := metricFamilies["vectorized_storage_log_written_bytes"]
 	if readBytesExist && writtenBytesExist {
-		readPanel := newCounterPanel(readBytesFamily)
+		readPanel := o.newCounterPanel(readBytesFamily, aggrCriteria)
 		readPanel.GridPos = graf.GridPos{
 			H: panelHeight,
 			W: width,
@@ -386,7 +672,7 @@ func buildSummary(metricFamilies map[string]*dto.MetricFamily) []graf.Panel {
 		}
 		panels = append(panels, readPanel)
 
-		writtenPanel := newCounterPanel(writtenBytesFamily)
+		writtenPanel := o.newCounterPanel(writtenBytesFamily, aggrCriteria)
 		writtenPanel.GridPos = graf.GridPos{
 			H: panelHeight,
 			W: width,
@@ -405,7 +691,49 @@ func metricGroup(metric string) string {
 			return group
 		}
 	}
-	return "others"
+	return othersGroup
+}
+
+// Returns the metric groups the dashboard's rows can be filtered by, sorted.
+func availableGroups() []string {
+	groups := append([]string{othersGroup}, metricGroups...)
+	sort.Strings(groups)
+	return groups
+}
+
+func checkGroups(groups []string) error {
+	available := availableGroups()
+	for _, group := range groups {
+		found := false
+		for _, a := range available {
+			if group == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf(
+				"'%s' isn't a metric group. Available groups: %s",
+				group,
+				strings.Join(available, ", "),
+			)
+		}
+	}
+	return nil
+}
+
+// Whether the group's row should be rendered, according to --groups. All of
+// them are rendered if it's not set.
+func (o dashboardOptions) isGroupSelected(group string) bool {
+	if len(o.groups) == 0 {
+		return true
+	}
+	for _, g := range o.groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
 }
 
 func fetchMetrics(
@@ -432,13 +760,85 @@ func fetchMetrics(
 	return parser.TextToMetricFamilies(bytes.NewBuffer(bs))
 }
 
-func newPercentilePanel(
-	m *dto.MetricFamily, percentile float32,
+// Reads the metrics from a file in the Prometheus text exposition format, as
+// scraped from a node's metrics endpoint.
+func readMetricsFile(path string) (map[string]*dto.MetricFamily, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parser := &expfmt.TextParser{}
+	metricFamilies, err := parser.TextToMetricFamilies(bytes.NewBuffer(bs))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %v", path, err)
+	}
+	return metricFamilies, nil
+}
+
+// Returns the panels for the metric family according to its type, with the
+// values aggregated by the given labels: one for counters and gauges, and one
+// per percentile for histograms.
+func (o dashboardOptions) newMetricPanels(
+	m *dto.MetricFamily, by string,
+) []*graf.GraphPanel {
+	if m.GetType() == dto.MetricType_COUNTER {
+		return []*graf.GraphPanel{o.newCounterPanel(m, by)}
+	} else if subtype(m) == "histogram" {
+		panels := []*graf.GraphPanel{}
+		for _, p := range o.histogramPercentiles() {
+			panels = append(panels, o.newPercentilePanel(m, p, by))
+		}
+		return panels
+	} else if isSummary(m) {
+		return []*graf.GraphPanel{o.newQuantilePanel(m, by)}
+	}
+	return []*graf.GraphPanel{o.newGaugePanel(m, by)}
+}
+
+// Whether the family is a summary: either typed as one, or with the quantiles
+// in a label, as the families exported as gauges sometimes are.
+func isSummary(m *dto.MetricFamily) bool {
+	if m.GetType() == dto.MetricType_SUMMARY {
+		return true
+	}
+	for _, metric := range m.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == quantileLabel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o dashboardOptions) summaryPercentiles() []float64 {
+	if len(o.percentiles) == 0 {
+		return defaultSummaryPercentiles
+	}
+	return o.percentiles
+}
+
+func (o dashboardOptions) histogramPercentiles() []float64 {
+	if len(o.percentiles) == 0 {
+		return defaultHistogramPercentiles
+	}
+	return o.percentiles
+}
+
+// Formats the float with as few digits as needed, e.g. 0.999 instead of
+// 0.999000.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (o dashboardOptions) newPercentilePanel(
+	m *dto.MetricFamily, percentile float64, by string,
 ) *graf.GraphPanel {
 	expr := fmt.Sprintf(
-		`histogram_quantile(%.2f, sum(rate(%s_bucket{instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by (le, [[aggr_criteria]]))`,
-		percentile,
+		`histogram_quantile(%s, sum(rate(%s_bucket{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by (le, %s))`,
+		formatFloat(percentile),
 		m.GetName(),
+		by,
 	)
 	target := graf.Target{
 		Expr:           expr,
@@ -448,8 +848,14 @@ func newPercentilePanel(
 		IntervalFactor: 2,
 		RefID:          "A",
 	}
-	title := fmt.Sprintf("%s (p%.0f)", m.GetHelp(), percentile*100)
-	panel := newGraphPanel(title, target, "µs")
+	// The percentage is rounded so that floating point errors don't show up
+	// in the title, e.g. p99.9 for 0.999.
+	title := fmt.Sprintf(
+		"%s (p%s)",
+		m.GetHelp(),
+		formatFloat(math.Round(percentile*1e6)/1e4),
+	)
+	panel := o.newGraphPanel(title, m.GetName(), target, "µs")
 	panel.Lines = true
 	panel.SteppedLine = true
 	panel.NullPointMode = "null as zero"
@@ -458,10 +864,45 @@ func newPercentilePanel(
 	return panel
 }
 
-func newCounterPanel(m *dto.MetricFamily) *graf.GraphPanel {
+// Returns a panel which plots each of the summary's quantiles as a series.
+// They can't be summed, so the max across the aggregated series is shown.
+func (o dashboardOptions) newQuantilePanel(
+	m *dto.MetricFamily, by string,
+) *graf.GraphPanel {
+	expr := fmt.Sprintf(
+		`max(%s{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]",%s!=""}) by (%s, %s)`,
+		m.GetName(),
+		quantileLabel,
+		quantileLabel,
+		by,
+	)
+	legend := legendFormat(m)
+	// The quantiles of the families typed as summaries aren't labels in the
+	// parsed family, so they're missing from its legend.
+	if !strings.Contains(legend, "{{"+quantileLabel+"}}") {
+		legend += fmt.Sprintf(", %s: {{%s}}", quantileLabel, quantileLabel)
+	}
+	target := graf.Target{
+		Expr:           expr,
+		LegendFormat:   legend,
+		Format:         "time_series",
+		Step:           10,
+		IntervalFactor: 2,
+	}
+	panel := o.newGraphPanel(m.GetHelp()+" (quantiles)", m.GetName(), target, "short")
+	panel.Lines = true
+	panel.SteppedLine = true
+	panel.Tooltip.ValueType = "individual"
+	return panel
+}
+
+func (o dashboardOptions) newCounterPanel(
+	m *dto.MetricFamily, by string,
+) *graf.GraphPanel {
 	expr := fmt.Sprintf(
-		`sum(irate(%s{instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by ([[aggr_criteria]])`,
+		`sum(irate(%s{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by (%s)`,
 		m.GetName(),
+		by,
 	)
 	target := graf.Target{
 		Expr:           expr,
@@ -474,15 +915,18 @@ func newCounterPanel(m *dto.MetricFamily) *graf.GraphPanel {
 	if strings.Contains(m.GetName(), "bytes") {
 		format = "Bps"
 	}
-	panel := newGraphPanel("Rate - "+m.GetHelp(), target, format)
+	panel := o.newGraphPanel("Rate - "+m.GetHelp(), m.GetName(), target, format)
 	panel.Lines = true
 	return panel
 }
 
-func newGaugePanel(m *dto.MetricFamily) *graf.GraphPanel {
+func (o dashboardOptions) newGaugePanel(
+	m *dto.MetricFamily, by string,
+) *graf.GraphPanel {
 	expr := fmt.Sprintf(
-		`sum(%s{instance=~"[[node]]",shard=~"[[node_shard]]"}) by ([[aggr_criteria]])`,
+		`sum(%s{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}) by (%s)`,
 		m.GetName(),
+		by,
 	)
 	target := graf.Target{
 		Expr:           expr,
@@ -495,15 +939,17 @@ func newGaugePanel(m *dto.MetricFamily) *graf.GraphPanel {
 	if strings.Contains(subtype(m), "bytes") {
 		format = "bytes"
 	}
-	panel := newGraphPanel(m.GetHelp(), target, format)
+	panel := o.newGraphPanel(m.GetHelp(), m.GetName(), target, format)
 	panel.Lines = true
 	panel.SteppedLine = true
 	return panel
 }
 
-func makeRatioPanel(m0, m1 *dto.MetricFamily, help string) *graf.GraphPanel {
+func (o dashboardOptions) makeRatioPanel(
+	m0, m1 *dto.MetricFamily, help string,
+) *graf.GraphPanel {
 	expr := fmt.Sprintf(
-		`sum(irate(%s{instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by ([[aggr_criteria]]) / sum(irate(%s{instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by ([[aggr_criteria]])`,
+		`sum(irate(%s{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by ([[aggr_criteria]]) / sum(irate(%s{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by ([[aggr_criteria]])`,
 		m0.GetName(), m1.GetName())
 	target := graf.Target{
 		Expr:           expr,
@@ -516,18 +962,21 @@ func makeRatioPanel(m0, m1 *dto.MetricFamily, help string) *graf.GraphPanel {
 	if strings.Contains(subtype(m0), "bytes") {
 		format = "bytes"
 	}
-	panel := newGraphPanel(help, target, format)
+	panel := o.newGraphPanel(help, m0.GetName(), target, format)
 	panel.Lines = true
 	panel.SteppedLine = true
 	return panel
 }
 
-func newGraphPanel(
-	title string, target graf.Target, yAxisFormat string,
+// Returns a panel plotting the given metric's target. Its Y axis has the unit
+// of the first --unit-override matching the metric, or yAxisFormat if none
+// does.
+func (o dashboardOptions) newGraphPanel(
+	title, metric string, target graf.Target, yAxisFormat string,
 ) *graf.GraphPanel {
 	// yAxisMin := 0.0
-	p := graf.NewGraphPanel(title, yAxisFormat)
-	p.Datasource = datasource
+	p := graf.NewGraphPanel(title, o.unitFor(metric, yAxisFormat))
+	p.Datasource = o.datasource
 	p.Targets = []graf.Target{target}
 	p.Tooltip = graf.Tooltip{
 		MsResolution: true,
@@ -537,12 +986,12 @@ func newGraphPanel(
 	return p
 }
 
-func newDefaultTemplateVar(
+func (o dashboardOptions) newDefaultTemplateVar(
 	name, label string, multi bool, opts ...graf.Option,
 ) graf.TemplateVar {
 	return graf.TemplateVar{
 		Name:       name,
-		Datasource: datasource,
+		Datasource: o.datasource,
 		Label:      label,
 		Multi:      multi,
 		Refresh:    1,
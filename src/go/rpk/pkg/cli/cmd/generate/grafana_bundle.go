@@ -0,0 +1,191 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const bundleFileName = "redpanda"
+
+type grafanaDatasources struct {
+	APIVersion  int                 `yaml:"apiVersion"`
+	Datasources []grafanaDatasource `yaml:"datasources"`
+}
+
+type grafanaDatasource struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type"`
+	Access string `yaml:"access"`
+	URL    string `yaml:"url"`
+}
+
+type grafanaDashboardProviders struct {
+	APIVersion int                        `yaml:"apiVersion"`
+	Providers  []grafanaDashboardProvider `yaml:"providers"`
+}
+
+type grafanaDashboardProvider struct {
+	Name    string                          `yaml:"name"`
+	Type    string                          `yaml:"type"`
+	Options grafanaDashboardProviderOptions `yaml:"options"`
+}
+
+type grafanaDashboardProviderOptions struct {
+	Path string `yaml:"path"`
+}
+
+func NewGrafanaBundleCmd(fs afero.Fs) *cobra.Command {
+	var (
+		metricsEndpoint string
+		prometheusURL   string
+		dashboardsPath  string
+		outputDir       string
+		datasourceName  string
+		job             string
+	)
+	command := &cobra.Command{
+		Use:   "grafana-bundle --prometheus-url <url> [--output-dir <dir>]",
+		Short: "Generate a Grafana provisioning bundle with a datasource and the redpanda dashboard.",
+		Long: `Generate a Grafana provisioning bundle with a datasource and the redpanda dashboard.
+
+The bundle is laid out for Grafana's file provisioning:
+
+  <output-dir>/provisioning/datasources/redpanda.yaml
+  <output-dir>/provisioning/dashboards/redpanda.yaml
+  <output-dir>/dashboards/redpanda.json
+
+The datasource points at the Prometheus server in --prometheus-url, and the
+dashboard queries it by its name. The provisioning directory's contents go in
+Grafana's provisioning directory (e.g. /etc/grafana/provisioning), and the
+dashboards directory must be available to Grafana at --dashboards-path.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if !(strings.HasPrefix(metricsEndpoint, "http://") ||
+				strings.HasPrefix(metricsEndpoint, "https://")) {
+				metricsEndpoint = fmt.Sprintf("http://%s", metricsEndpoint)
+			}
+			metricFamilies, err := fetchMetrics(metricsEndpoint)
+			if err != nil {
+				return err
+			}
+			dashboard, err := json.MarshalIndent(
+				buildGrafanaDashboard(metricFamilies, dashboardOptions{
+					datasource: datasourceName,
+					jobName:    job,
+				}),
+				"",
+				" ",
+			)
+			if err != nil {
+				return err
+			}
+			datasources, err := yaml.Marshal(grafanaDatasources{
+				APIVersion: 1,
+				Datasources: []grafanaDatasource{{
+					Name:   datasourceName,
+					Type:   "prometheus",
+					Access: "proxy",
+					URL:    prometheusURL,
+				}},
+			})
+			if err != nil {
+				return err
+			}
+			providers, err := yaml.Marshal(grafanaDashboardProviders{
+				APIVersion: 1,
+				Providers: []grafanaDashboardProvider{{
+					Name:    bundleFileName,
+					Type:    "file",
+					Options: grafanaDashboardProviderOptions{Path: dashboardsPath},
+				}},
+			})
+			if err != nil {
+				return err
+			}
+			files := []struct {
+				path    string
+				content []byte
+			}{
+				{
+					filepath.Join(outputDir, "provisioning", "datasources", bundleFileName+".yaml"),
+					datasources,
+				},
+				{
+					filepath.Join(outputDir, "provisioning", "dashboards", bundleFileName+".yaml"),
+					providers,
+				},
+				{
+					filepath.Join(outputDir, "dashboards", bundleFileName+".json"),
+					dashboard,
+				},
+			}
+			for _, f := range files {
+				err = fs.MkdirAll(filepath.Dir(f.path), 0755)
+				if err != nil {
+					return err
+				}
+				err = afero.WriteFile(fs, f.path, f.content, 0644)
+				if err != nil {
+					return err
+				}
+				log.Infof("Wrote %s", f.path)
+			}
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&metricsEndpoint,
+		"metrics-endpoint",
+		"http://localhost:9644/metrics",
+		"The redpanda metrics endpoint where to get the metrics metadata. i.e. redpanda_host:9644/metrics",
+	)
+	command.Flags().StringVar(
+		&prometheusURL,
+		"prometheus-url",
+		"",
+		"The URL of the Prometheus server the datasource points at",
+	)
+	command.Flags().StringVar(
+		&datasourceName,
+		datasourceFlag,
+		"redpanda-prometheus",
+		"The name of the Prometheus datasource",
+	)
+	command.Flags().StringVar(
+		&job,
+		"job-name",
+		"redpanda",
+		"The prometheus job name by which to identify the redpanda nodes",
+	)
+	command.Flags().StringVar(
+		&dashboardsPath,
+		"dashboards-path",
+		"/var/lib/grafana/dashboards",
+		"The path where Grafana will find the generated dashboards directory",
+	)
+	command.Flags().StringVar(
+		&outputDir,
+		"output-dir",
+		"grafana-bundle",
+		"The directory to write the bundle to",
+	)
+	cobra.MarkFlagRequired(command.Flags(), "prometheus-url")
+	return command
+}
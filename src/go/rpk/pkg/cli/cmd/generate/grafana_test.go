@@ -11,19 +11,27 @@ package generate_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/generate"
+	"gopkg.in/yaml.v2"
 )
 
 func TestPrometheusURLFlagDeprecation(t *testing.T) {
 	var out bytes.Buffer
 	logrus.SetOutput(&out)
-	cmd := generate.NewGrafanaDashboardCmd()
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
 	cmd.SetArgs([]string{
 		"--prometheus-url", "localhost:8888/metrics",
 		"--datasource", "prometheus",
@@ -34,7 +42,7 @@ func TestPrometheusURLFlagDeprecation(t *testing.T) {
 func TestGrafanaHostNoServer(t *testing.T) {
 	var out bytes.Buffer
 	logrus.SetOutput(&out)
-	cmd := generate.NewGrafanaDashboardCmd()
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
 	cmd.SetArgs([]string{
 		"--metrics-endpoint", "localhost:8888/metrics",
 		"--datasource", "prometheus",
@@ -66,7 +74,7 @@ vectorized_vectorized_internal_rpc_dispatch_handler_latency_bucket{le="20.000000
 vectorized_memory_allocated_memory_bytes{shard="0",type="bytes"} 40837120
 vectorized_memory_allocated_memory_bytes{shard="1",type="bytes"} 36986880
 `
-	expected := `{"title":"Redpanda","templating":{"list":[{"name":"node","datasource":"prometheus","label":"Node","type":"query","refresh":1,"options":null,"includeAll":true,"allFormat":"","allValue":".*","multi":true,"multiFormat":"","query":"label_values(instance)","current":{"text":"","value":null},"hide":0,"sort":1},{"name":"node_shard","datasource":"prometheus","label":"Shard","type":"query","refresh":1,"options":null,"includeAll":true,"allFormat":"","allValue":".*","multi":true,"multiFormat":"","query":"label_values(shard)","current":{"text":"","value":null},"hide":0,"sort":1},{"name":"aggr_criteria","datasource":"prometheus","label":"Aggregate by","type":"custom","refresh":1,"options":[{"text":"Cluster","value":"","selected":false},{"text":"Instance","value":"instance,","selected":false},{"text":"Instance, Shard","value":"instance,shard,","selected":false}],"includeAll":false,"allFormat":"","allValue":"","multi":false,"multiFormat":"","query":"Cluster : cluster,Instance : instance,Instance\\,Shard : instance\\,shard","current":{"text":"Cluster","value":""},"hide":0,"sort":1}]},"panels":[{"type":"text","id":1,"title":"","editable":true,"gridPos":{"h":2,"w":24,"x":0,"y":0},"transparent":true,"links":null,"span":1,"error":false,"content":"<h1 style=\"color:#87CEEB; border-bottom: 3px solid #87CEEB;\">Redpanda Summary</h1>","mode":"html"},{"type":"singlestat","id":2,"title":"Nodes Up","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":2,"x":0,"y":2},"transparent":true,"span":1,"error":false,"targets":[{"refId":"","expr":"count by (app) (vectorized_application_uptime)","intervalFactor":1,"step":40,"legendFormat":"Nodes Up"}],"format":"none","prefix":"","postfix":"","maxDataPoints":100,"valueMaps":[{"value":"null","op":"=","text":"N/A"}],"mappingTypes":[{"name":"value to text","value":1},{"name":"range to text","value":2}],"rangeMaps":[{"from":"null","to":"null","text":"N/A"}],"mappingType":1,"nullPointMode":"connected","valueName":"current","valueFontSize":"200%","prefixFontSize":"50%","postfixFontSize":"50%","colorBackground":false,"colorValue":true,"colors":["#299c46","rgba(237, 129, 40, 0.89)","#d44a3a"],"thresholds":"","sparkline":{"show":false,"full":false,"ymin":null,"ymax":null,"lineColor":"rgb(31, 120, 193)","fillColor":"rgba(31, 118, 189, 0.18)"},"gauge":{"show":false,"minValue":0,"maxValue":100,"thresholdMarkers":true,"thresholdLabels":false},"links":[],"interval":null,"timeFrom":null,"timeShift":null,"nullText":null,"cacheTimeout":null,"tableColumn":""},{"type":"singlestat","id":3,"title":"Partitions","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":2,"x":2,"y":8},"transparent":true,"span":1,"error":false,"targets":[{"refId":"","expr":"count(count by (topic,partition) (vectorized_storage_log_partition_size{namespace=\"kafka\"}))","legendFormat":"Partition count"}],"format":"none","prefix":"","postfix":"","maxDataPoints":100,"valueMaps":[{"value":"null","op":"=","text":"N/A"}],"mappingTypes":[{"name":"value to text","value":1},{"name":"range to text","value":2}],"rangeMaps":[{"from":"null","to":"null","text":"N/A"}],"mappingType":1,"nullPointMode":"connected","valueName":"current","valueFontSize":"200%","prefixFontSize":"50%","postfixFontSize":"50%","colorBackground":false,"colorValue":true,"colors":["#299c46","rgba(237, 129, 40, 0.89)","#d44a3a"],"thresholds":"","sparkline":{"show":false,"full":false,"ymin":null,"ymax":null,"lineColor":"rgb(31, 120, 193)","fillColor":"rgba(31, 118, 189, 0.18)"},"gauge":{"show":false,"minValue":0,"maxValue":100,"thresholdMarkers":true,"thresholdLabels":false},"links":[],"interval":null,"timeFrom":null,"timeShift":null,"nullText":null,"cacheTimeout":null,"tableColumn":""},{"type":"text","id":5,"title":"","editable":true,"gridPos":{"h":2,"w":12,"x":12,"y":14},"transparent":true,"links":null,"span":1,"error":false,"content":"<h1 style=\"color:#87CEEB; border-bottom: 3px solid #87CEEB;\">Throughput</h1>","mode":"html"},{"type":"row","collapsed":true,"id":7,"title":"memory","editable":true,"gridPos":{"h":6,"w":24,"x":0,"y":20},"transparent":false,"links":null,"span":0,"error":false,"panels":[{"type":"graph","id":6,"title":"Rate - Allocated memory size in bytes","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":0,"y":20},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"","expr":"sum(irate(vectorized_memory_allocated_memory_bytes{instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}[1m])) by ([[aggr_criteria]])","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"Bps"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"cumulative","msResolution":true},"aliasColors":{},"steppedLine":false}]},{"type":"row","collapsed":true,"id":9,"title":"vectorized_internal_rpc","editable":true,"gridPos":{"h":6,"w":24,"x":0,"y":21},"transparent":false,"links":null,"span":0,"error":false,"panels":[{"type":"graph","id":8,"title":"Amount of memory consumed for requests processing","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":0,"y":21},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"","expr":"sum(vectorized_vectorized_internal_rpc_consumed_mem{instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}) by ([[aggr_criteria]])","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"short"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"cumulative","msResolution":true},"aliasColors":{},"steppedLine":true},{"type":"graph","id":10,"title":"Rate - Number of requests with corrupted headers","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":8,"y":21},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"","expr":"sum(irate(vectorized_vectorized_internal_rpc_corrupted_headers{instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}[1m])) by ([[aggr_criteria]])","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"ops"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"cumulative","msResolution":true},"aliasColors":{},"steppedLine":false},{"type":"graph","id":11,"title":"Latency of service handler dispatch (p95)","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":16,"y":21},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"A","expr":"histogram_quantile(0.95, sum(rate(vectorized_vectorized_internal_rpc_dispatch_handler_latency_bucket{instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}[1m])) by (le, [[aggr_criteria]]))","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"µs"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null as zero","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"individual","msResolution":true},"aliasColors":{},"steppedLine":true}]}],"editable":true,"timezone":"utc","refresh":"10s","time":{"from":"now-1h","to":"now"},"timepicker":{"refresh_intervals":["5s","10s","30s","1m","5m","15m","30m","1h","2h","1d"],"time_options":["5m","15m","1h","6h","12h","24h","2d","7d","30d"]},"annotations":{"list":null},"links":null,"schemaVersion":12}`
+	expected := `{"title":"Redpanda","templating":{"list":[{"name":"job","datasource":"prometheus","label":"Job","type":"query","refresh":1,"options":null,"includeAll":false,"allFormat":"","allValue":"","multi":false,"multiFormat":"","query":"label_values(vectorized_application_uptime, job)","current":{"text":"redpanda","value":"redpanda"},"hide":0,"sort":1},{"name":"node","datasource":"prometheus","label":"Node","type":"query","refresh":1,"options":null,"includeAll":true,"allFormat":"","allValue":".*","multi":true,"multiFormat":"","query":"label_values({job=~\"[[job]]\"}, instance)","current":{"text":"","value":null},"hide":0,"sort":1},{"name":"node_shard","datasource":"prometheus","label":"Shard","type":"query","refresh":1,"options":null,"includeAll":true,"allFormat":"","allValue":".*","multi":true,"multiFormat":"","query":"label_values({job=~\"[[job]]\"}, shard)","current":{"text":"","value":null},"hide":0,"sort":1},{"name":"aggr_criteria","datasource":"prometheus","label":"Aggregate by","type":"custom","refresh":1,"options":[{"text":"Cluster","value":"","selected":false},{"text":"Instance","value":"instance,","selected":false},{"text":"Instance, Shard","value":"instance,shard,","selected":false}],"includeAll":false,"allFormat":"","allValue":"","multi":false,"multiFormat":"","query":"Cluster : cluster,Instance : instance,Instance\\,Shard : instance\\,shard","current":{"text":"Cluster","value":""},"hide":0,"sort":1}]},"panels":[{"type":"text","id":1,"title":"","editable":true,"gridPos":{"h":2,"w":24,"x":0,"y":0},"transparent":true,"links":null,"span":1,"error":false,"content":"<h1 style=\"color:#87CEEB; border-bottom: 3px solid #87CEEB;\">Redpanda Summary</h1>","mode":"html"},{"type":"singlestat","id":2,"title":"Nodes Up","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":2,"x":0,"y":2},"transparent":true,"span":1,"error":false,"targets":[{"refId":"","expr":"count by (app) (vectorized_application_uptime{job=~\"[[job]]\"})","intervalFactor":1,"step":40,"legendFormat":"Nodes Up"}],"format":"none","prefix":"","postfix":"","maxDataPoints":100,"valueMaps":[{"value":"null","op":"=","text":"N/A"}],"mappingTypes":[{"name":"value to text","value":1},{"name":"range to text","value":2}],"rangeMaps":[{"from":"null","to":"null","text":"N/A"}],"mappingType":1,"nullPointMode":"connected","valueName":"current","valueFontSize":"200%","prefixFontSize":"50%","postfixFontSize":"50%","colorBackground":false,"colorValue":true,"colors":["#299c46","rgba(237, 129, 40, 0.89)","#d44a3a"],"thresholds":"","sparkline":{"show":false,"full":false,"ymin":null,"ymax":null,"lineColor":"rgb(31, 120, 193)","fillColor":"rgba(31, 118, 189, 0.18)"},"gauge":{"show":false,"minValue":0,"maxValue":100,"thresholdMarkers":true,"thresholdLabels":false},"links":[],"interval":null,"timeFrom":null,"timeShift":null,"nullText":null,"cacheTimeout":null,"tableColumn":""},{"type":"singlestat","id":3,"title":"Partitions","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":2,"x":2,"y":8},"transparent":true,"span":1,"error":false,"targets":[{"refId":"","expr":"count(count by (topic,partition) (vectorized_storage_log_partition_size{job=~\"[[job]]\",namespace=\"kafka\"}))","legendFormat":"Partition count"}],"format":"none","prefix":"","postfix":"","maxDataPoints":100,"valueMaps":[{"value":"null","op":"=","text":"N/A"}],"mappingTypes":[{"name":"value to text","value":1},{"name":"range to text","value":2}],"rangeMaps":[{"from":"null","to":"null","text":"N/A"}],"mappingType":1,"nullPointMode":"connected","valueName":"current","valueFontSize":"200%","prefixFontSize":"50%","postfixFontSize":"50%","colorBackground":false,"colorValue":true,"colors":["#299c46","rgba(237, 129, 40, 0.89)","#d44a3a"],"thresholds":"","sparkline":{"show":false,"full":false,"ymin":null,"ymax":null,"lineColor":"rgb(31, 120, 193)","fillColor":"rgba(31, 118, 189, 0.18)"},"gauge":{"show":false,"minValue":0,"maxValue":100,"thresholdMarkers":true,"thresholdLabels":false},"links":[],"interval":null,"timeFrom":null,"timeShift":null,"nullText":null,"cacheTimeout":null,"tableColumn":""},{"type":"text","id":5,"title":"","editable":true,"gridPos":{"h":2,"w":12,"x":12,"y":14},"transparent":true,"links":null,"span":1,"error":false,"content":"<h1 style=\"color:#87CEEB; border-bottom: 3px solid #87CEEB;\">Throughput</h1>","mode":"html"},{"type":"row","collapsed":true,"id":7,"title":"memory","editable":true,"gridPos":{"h":6,"w":24,"x":0,"y":20},"transparent":false,"links":null,"span":0,"error":false,"panels":[{"type":"graph","id":6,"title":"Rate - Allocated memory size in bytes","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":0,"y":20},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"","expr":"sum(irate(vectorized_memory_allocated_memory_bytes{job=~\"[[job]]\",instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}[1m])) by ([[aggr_criteria]])","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"Bps"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"cumulative","msResolution":true},"aliasColors":{},"steppedLine":false}]},{"type":"row","collapsed":true,"id":9,"title":"vectorized_internal_rpc","editable":true,"gridPos":{"h":6,"w":24,"x":0,"y":21},"transparent":false,"links":null,"span":0,"error":false,"panels":[{"type":"graph","id":8,"title":"Amount of memory consumed for requests processing","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":0,"y":21},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"","expr":"sum(vectorized_vectorized_internal_rpc_consumed_mem{job=~\"[[job]]\",instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}) by ([[aggr_criteria]])","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"short"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"cumulative","msResolution":true},"aliasColors":{},"steppedLine":true},{"type":"graph","id":10,"title":"Rate - Number of requests with corrupted headers","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":8,"y":21},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"","expr":"sum(irate(vectorized_vectorized_internal_rpc_corrupted_headers{job=~\"[[job]]\",instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}[1m])) by ([[aggr_criteria]])","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"ops"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"cumulative","msResolution":true},"aliasColors":{},"steppedLine":false},{"type":"graph","id":11,"title":"Latency of service handler dispatch (p95)","datasource":"prometheus","editable":true,"gridPos":{"h":6,"w":8,"x":16,"y":21},"transparent":false,"links":null,"renderer":"flot","span":4,"error":false,"targets":[{"refId":"A","expr":"histogram_quantile(0.95, sum(rate(vectorized_vectorized_internal_rpc_dispatch_handler_latency_bucket{job=~\"[[job]]\",instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}[1m])) by (le, [[aggr_criteria]]))","intervalFactor":2,"step":10,"legendFormat":"node: {{instance}}, shard: {{shard}}","format":"time_series"}],"xaxis":{"format":"","logBase":0,"show":true,"mode":"time"},"yaxes":[{"label":null,"show":true,"logBase":1,"min":0,"format":"µs"},{"label":null,"show":true,"logBase":1,"min":0,"format":"short"}],"legend":{"show":true,"max":false,"min":false,"values":false,"avg":false,"current":false,"total":false},"fill":1,"linewidth":2,"nullPointMode":"null as zero","thresholds":null,"lines":true,"bars":false,"tooltip":{"shared":true,"value_type":"individual","msResolution":true},"aliasColors":{},"steppedLine":true}]}],"editable":true,"timezone":"utc","refresh":"10s","time":{"from":"now-1h","to":"now"},"timepicker":{"refresh_intervals":["5s","10s","30s","1m","5m","15m","30m","1h","2h","1d"],"time_options":["5m","15m","1h","6h","12h","24h","2d","7d","30d"]},"annotations":{"list":null},"links":null,"schemaVersion":12}`
 	ts := httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -75,7 +83,7 @@ vectorized_memory_allocated_memory_bytes{shard="1",type="bytes"} 36986880
 	)
 	var out bytes.Buffer
 	logrus.SetOutput(&out)
-	cmd := generate.NewGrafanaDashboardCmd()
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
 	cmd.SetOutput(&out)
 	cmd.SetArgs([]string{
 		"--metrics-endpoint", ts.URL,
@@ -86,6 +94,104 @@ vectorized_memory_allocated_memory_bytes{shard="1",type="bytes"} 36986880
 	require.JSONEq(t, expected, out.String())
 }
 
+func TestGrafanaPerShardMetrics(t *testing.T) {
+	res := `# HELP vectorized_vectorized_internal_rpc_consumed_mem Amount of memory consumed for requests processing
+# TYPE vectorized_vectorized_internal_rpc_consumed_mem gauge
+vectorized_vectorized_internal_rpc_consumed_mem{shard="0",type="gauge"} 0.000000
+vectorized_vectorized_internal_rpc_consumed_mem{shard="1",type="gauge"} 0.000000
+# HELP vectorized_vectorized_internal_rpc_corrupted_headers Number of requests with corrupted headers
+# TYPE vectorized_vectorized_internal_rpc_corrupted_headers counter
+vectorized_vectorized_internal_rpc_corrupted_headers{shard="0",type="derive"} 0
+vectorized_vectorized_internal_rpc_corrupted_headers{shard="1",type="derive"} 0
+# HELP vectorized_vectorized_internal_rpc_dispatch_handler_latency Latency of service handler dispatch
+# TYPE vectorized_vectorized_internal_rpc_dispatch_handler_latency histogram
+vectorized_vectorized_internal_rpc_dispatch_handler_latency_sum{shard="0",type="histogram"} 0
+vectorized_vectorized_internal_rpc_dispatch_handler_latency_count{shard="0",type="histogram"} 0
+vectorized_vectorized_internal_rpc_dispatch_handler_latency_bucket{le="10.000000",shard="0",type="histogram"} 0
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(res))
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("it should render the families per shard", func(st *testing.T) {
+		var out bytes.Buffer
+		logrus.SetOutput(&out)
+		cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+		cmd.SetOutput(&out)
+		cmd.SetArgs([]string{
+			"--metrics-endpoint", ts.URL,
+			"--datasource", "prometheus",
+			"--per-shard-metrics",
+			"vectorized_vectorized_internal_rpc_consumed_mem," +
+				"vectorized_vectorized_internal_rpc_corrupted_headers," +
+				"vectorized_vectorized_internal_rpc_dispatch_handler_latency",
+		})
+		err := cmd.Execute()
+		require.NoError(st, err)
+
+		var dashboard struct {
+			Panels []struct {
+				Title  string `json:"title"`
+				Panels []struct {
+					Title   string `json:"title"`
+					Targets []struct {
+						Expr         string `json:"expr"`
+						LegendFormat string `json:"legendFormat"`
+					} `json:"targets"`
+				} `json:"panels"`
+			} `json:"panels"`
+		}
+		err = json.Unmarshal(out.Bytes(), &dashboard)
+		require.NoError(st, err)
+
+		exprs := map[string]string{}
+		for _, row := range dashboard.Panels {
+			for _, p := range row.Panels {
+				require.Len(st, p.Targets, 1)
+				if row.Title != "per shard" {
+					// The rest of the panels are still aggregated.
+					require.Contains(st, p.Targets[0].Expr, "[[aggr_criteria]]")
+					continue
+				}
+				require.Equal(st, "{{instance}} shard {{shard}}", p.Targets[0].LegendFormat)
+				exprs[p.Title] = p.Targets[0].Expr
+			}
+		}
+		require.Equal(
+			st,
+			map[string]string{
+				"Amount of memory consumed for requests processing - per shard": `sum(vectorized_vectorized_internal_rpc_consumed_mem{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}) by (instance, shard)`,
+				"Rate - Number of requests with corrupted headers - per shard":  `sum(irate(vectorized_vectorized_internal_rpc_corrupted_headers{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by (instance, shard)`,
+				"Latency of service handler dispatch (p95) - per shard":         `histogram_quantile(0.95, sum(rate(vectorized_vectorized_internal_rpc_dispatch_handler_latency_bucket{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]"}[1m])) by (le, instance, shard))`,
+			},
+			exprs,
+		)
+	})
+
+	t.Run("it should fail if a family isn't exported", func(st *testing.T) {
+		var out bytes.Buffer
+		logrus.SetOutput(&out)
+		cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+		cmd.SetOutput(&out)
+		cmd.SetArgs([]string{
+			"--metrics-endpoint", ts.URL,
+			"--datasource", "prometheus",
+			"--per-shard-metrics", "vectorized_storage_log_read_bytes",
+		})
+		err := cmd.Execute()
+		require.EqualError(
+			st,
+			err,
+			"can't render vectorized_storage_log_read_bytes per shard: "+
+				ts.URL+" doesn't export it",
+		)
+	})
+}
+
 func TestGrafanaInvalidResponse(t *testing.T) {
 	res := `# HELP vectorized_vectorized_internal_rpc_consumed_mem Amount of memory consumed for requests processing
 # TYPE vectorized_vectorized_internal_rpc_consumed_mem gauge
@@ -99,7 +205,7 @@ vectorized_vectorized_in
 	)
 	var out bytes.Buffer
 	logrus.SetOutput(&out)
-	cmd := generate.NewGrafanaDashboardCmd()
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
 	cmd.SetOutput(&out)
 	cmd.SetArgs([]string{
 		"--metrics-endpoint", ts.URL,
@@ -108,3 +214,928 @@ vectorized_vectorized_in
 	err := cmd.Execute()
 	require.EqualError(t, err, "text format parsing error in line 3: expected float as value, got \"\"")
 }
+
+func TestGrafanaLint(t *testing.T) {
+	metrics := `# HELP vectorized_application_uptime Redpanda uptime in milliseconds
+# TYPE vectorized_application_uptime gauge
+vectorized_application_uptime{shard="0",type="gauge"} 1000
+# HELP vectorized_kafka_rpc_dispatch_handler_latency Latency of service handler dispatch
+# TYPE vectorized_kafka_rpc_dispatch_handler_latency histogram
+vectorized_kafka_rpc_dispatch_handler_latency_sum{shard="0",type="histogram"} 0
+vectorized_kafka_rpc_dispatch_handler_latency_count{shard="0",type="histogram"} 0
+vectorized_kafka_rpc_dispatch_handler_latency_bucket{le="10.000000",shard="0",type="histogram"} 0
+`
+	dashboard := `{
+  "title": "Redpanda",
+  "panels": [
+    {
+      "type": "singlestat",
+      "targets": [
+        {"expr": "count by (app) (vectorized_application_uptime)"}
+      ]
+    },
+    {
+      "type": "row",
+      "panels": [
+        {
+          "type": "graph",
+          "targets": [
+            {"expr": "histogram_quantile(0.95, sum(rate(vectorized_kafka_rpc_dispatch_handler_latency_bucket{instance=~\"[[node]]\",shard=~\"[[node_shard]]\"}[1m])) by (le, [[aggr_criteria]]))"},
+            {"expr": "sum(irate(vectorized_storage_log_written_bytes{instance=~\"$node\"}[1m])) by ([[aggr_criteria]])"}
+          ]
+        }
+      ]
+    }
+  ]
+}`
+	tests := []struct {
+		name           string
+		dashboard      string
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name:      "it should report the metrics which aren't exported",
+			dashboard: dashboard,
+			expectedOutput: []string{
+				"'vectorized_storage_log_written_bytes' isn't exported by",
+			},
+			expectedErrMsg: "found 1 metric(s) referenced in",
+		},
+		{
+			name: "it should pass if all the metrics are exported",
+			dashboard: `{"panels": [{"targets": [
+				{"expr": "count by (app) (vectorized_application_uptime)"},
+				{"expr": "rate(vectorized_kafka_rpc_dispatch_handler_latency_count[1m])"}
+			]}]}`,
+			expectedOutput: []string{": OK"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(metrics))
+				}),
+			)
+			defer ts.Close()
+			dir, err := ioutil.TempDir("", "rpk-grafana-lint")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "dashboard.json")
+			err = ioutil.WriteFile(path, []byte(tt.dashboard), 0644)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+			cmd.SetOutput(&out)
+			cmd.SetArgs([]string{
+				"--metrics-endpoint", ts.URL,
+				"--lint", path,
+			})
+			err = cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+			for _, o := range tt.expectedOutput {
+				require.Contains(t, out.String(), o)
+			}
+			require.NotContains(t, out.String(), "'vectorized_application_uptime'")
+			require.NotContains(t, out.String(), "'vectorized_kafka_rpc_dispatch_handler_latency")
+		})
+	}
+}
+
+func TestGrafanaBundle(t *testing.T) {
+	metrics := `# HELP vectorized_memory_allocated_memory_bytes Allocated memory size in bytes
+# TYPE vectorized_memory_allocated_memory_bytes counter
+vectorized_memory_allocated_memory_bytes{shard="0",type="bytes"} 40837120
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(metrics))
+		}),
+	)
+	defer ts.Close()
+
+	fs := afero.NewMemMapFs()
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	cmd := generate.NewGrafanaBundleCmd(fs)
+	cmd.SetOutput(&out)
+	cmd.SetArgs([]string{
+		"--metrics-endpoint", ts.URL,
+		"--prometheus-url", "http://prometheus:9090",
+		"--datasource", "my-prometheus",
+		"--output-dir", "/tmp/bundle",
+	})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	bs, err := afero.ReadFile(fs, "/tmp/bundle/provisioning/datasources/redpanda.yaml")
+	require.NoError(t, err)
+	var datasources struct {
+		Datasources []struct {
+			Name string `yaml:"name"`
+			Type string `yaml:"type"`
+			URL  string `yaml:"url"`
+		} `yaml:"datasources"`
+	}
+	err = yaml.Unmarshal(bs, &datasources)
+	require.NoError(t, err)
+	require.Len(t, datasources.Datasources, 1)
+	require.Equal(t, "my-prometheus", datasources.Datasources[0].Name)
+	require.Equal(t, "prometheus", datasources.Datasources[0].Type)
+	require.Equal(t, "http://prometheus:9090", datasources.Datasources[0].URL)
+
+	bs, err = afero.ReadFile(fs, "/tmp/bundle/provisioning/dashboards/redpanda.yaml")
+	require.NoError(t, err)
+	require.Contains(t, string(bs), "path: /var/lib/grafana/dashboards")
+
+	// Every datasource referenced by the dashboard is the provisioned one.
+	bs, err = afero.ReadFile(fs, "/tmp/bundle/dashboards/redpanda.json")
+	require.NoError(t, err)
+	var dashboard interface{}
+	err = json.Unmarshal(bs, &dashboard)
+	require.NoError(t, err)
+	refs := []interface{}{}
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, val := range v {
+				if k == "datasource" {
+					refs = append(refs, val)
+					continue
+				}
+				collect(val)
+			}
+		case []interface{}:
+			for _, val := range v {
+				collect(val)
+			}
+		}
+	}
+	collect(dashboard)
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		require.Equal(t, "my-prometheus", ref)
+	}
+}
+
+func TestGrafanaMetricsFile(t *testing.T) {
+	metrics := `# HELP vectorized_memory_allocated_memory_bytes Allocated memory size in bytes
+# TYPE vectorized_memory_allocated_memory_bytes counter
+vectorized_memory_allocated_memory_bytes{shard="0",type="bytes"} 40837120
+vectorized_memory_allocated_memory_bytes{shard="1",type="bytes"} 36986880
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(metrics))
+		}),
+	)
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "rpk-grafana-metrics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "metrics.txt")
+	err = ioutil.WriteFile(path, []byte(metrics), 0644)
+	require.NoError(t, err)
+
+	generateDashboard := func(args ...string) string {
+		var out bytes.Buffer
+		logrus.SetOutput(&out)
+		cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+		cmd.SetOutput(&out)
+		cmd.SetArgs(append(args, "--datasource", "prometheus"))
+		err := cmd.Execute()
+		require.NoError(t, err)
+		return out.String()
+	}
+	fromEndpoint := generateDashboard("--metrics-endpoint", ts.URL)
+	fromFile := generateDashboard("--metrics-file", path)
+	require.JSONEq(t, fromEndpoint, fromFile)
+}
+
+func TestGrafanaMetricsFileErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpk-grafana-metrics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	invalid := filepath.Join(dir, "invalid.txt")
+	err = ioutil.WriteFile(
+		invalid,
+		[]byte("# TYPE vectorized_application_uptime gauge\nvectorized_application_uptime\n"),
+		0644,
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedErrMsg string
+	}{
+		{
+			name: "it should fail if both the endpoint and the file are given",
+			args: []string{
+				"--metrics-endpoint", "localhost:9644/metrics",
+				"--metrics-file", invalid,
+			},
+			expectedErrMsg: "--metrics-endpoint and --metrics-file can't be used together",
+		},
+		{
+			name: "it should fail if the deprecated endpoint flag and the file are given",
+			args: []string{
+				"--prometheus-url", "localhost:9644/metrics",
+				"--metrics-file", invalid,
+			},
+			expectedErrMsg: "--metrics-endpoint and --metrics-file can't be used together",
+		},
+		{
+			name:           "it should fail if the file can't be parsed",
+			args:           []string{"--metrics-file", invalid},
+			expectedErrMsg: "couldn't parse " + invalid + ": text format parsing error in line 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+			cmd.SetOutput(&out)
+			cmd.SetArgs(append(tt.args, "--datasource", "prometheus"))
+			err := cmd.Execute()
+			require.Error(st, err)
+			require.Contains(st, err.Error(), tt.expectedErrMsg)
+		})
+	}
+}
+
+func TestGrafanaGroups(t *testing.T) {
+	res := `# HELP vectorized_vectorized_internal_rpc_consumed_mem Amount of memory consumed for requests processing
+# TYPE vectorized_vectorized_internal_rpc_consumed_mem gauge
+vectorized_vectorized_internal_rpc_consumed_mem{shard="0",type="gauge"} 0.000000
+# HELP vectorized_memory_allocated_memory_bytes Allocated memory size in bytes
+# TYPE vectorized_memory_allocated_memory_bytes counter
+vectorized_memory_allocated_memory_bytes{shard="0",type="bytes"} 40837120
+# HELP vectorized_storage_log_read_bytes Total number of bytes read
+# TYPE vectorized_storage_log_read_bytes counter
+vectorized_storage_log_read_bytes{shard="0",type="derive"} 0
+# HELP vectorized_storage_log_batches_read Total number of batches read
+# TYPE vectorized_storage_log_batches_read counter
+vectorized_storage_log_batches_read{shard="0",type="derive"} 0
+# HELP vectorized_storage_log_cached_batches_read Total number of cached batches read
+# TYPE vectorized_storage_log_cached_batches_read counter
+vectorized_storage_log_cached_batches_read{shard="0",type="derive"} 0
+# HELP vectorized_storage_log_cached_read_bytes Total number of cached bytes read
+# TYPE vectorized_storage_log_cached_read_bytes counter
+vectorized_storage_log_cached_read_bytes{shard="0",type="derive"} 0
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(res))
+		}),
+	)
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedRows   []string
+		expectedErrMsg string
+	}{
+		{
+			name:         "it should render every group by default",
+			expectedRows: []string{"memory", "storage", "vectorized_internal_rpc"},
+		},
+		{
+			name:         "it should only render the given groups",
+			args:         []string{"--groups", "memory,vectorized_internal_rpc"},
+			expectedRows: []string{"memory", "vectorized_internal_rpc"},
+		},
+		{
+			name:         "it should render the cache panels with the storage group",
+			args:         []string{"--groups", "storage"},
+			expectedRows: []string{"storage"},
+		},
+		{
+			name:         "it should render no rows if the groups have no metrics",
+			args:         []string{"--groups", "raft"},
+			expectedRows: []string{},
+		},
+		{
+			name:           "it should fail if a group doesn't exist",
+			args:           []string{"--groups", "memory,disk"},
+			expectedErrMsg: "'disk' isn't a metric group. Available groups: errors, io_queue, kafka_rpc, memory, others, raft, reactor, rpc_client, scheduler, storage, vectorized_internal_rpc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+			cmd.SetOutput(&out)
+			cmd.SetArgs(append(
+				[]string{"--metrics-endpoint", ts.URL, "--datasource", "prometheus"},
+				tt.args...,
+			))
+			err := cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+
+			var dashboard struct {
+				Panels []struct {
+					Type   string `json:"type"`
+					Title  string `json:"title"`
+					Panels []struct {
+						Title string `json:"title"`
+					} `json:"panels"`
+				} `json:"panels"`
+			}
+			err = json.Unmarshal(out.Bytes(), &dashboard)
+			require.NoError(st, err)
+			rows := []string{}
+			summaryPanels := 0
+			for _, p := range dashboard.Panels {
+				if p.Type != "row" {
+					summaryPanels++
+					continue
+				}
+				rows = append(rows, p.Title)
+				if p.Title == "storage" {
+					titles := []string{}
+					for _, sp := range p.Panels {
+						titles = append(titles, sp.Title)
+					}
+					require.Contains(st, titles, "Batch cache hit ratio - batches")
+				}
+			}
+			// The summary is always rendered.
+			require.NotZero(st, summaryPanels)
+			require.Equal(st, tt.expectedRows, rows)
+		})
+	}
+}
+
+func TestGrafanaListGroups(t *testing.T) {
+	var out bytes.Buffer
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+	cmd.SetOutput(&out)
+	cmd.SetArgs([]string{"--list-groups"})
+	err := cmd.Execute()
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"errors\nio_queue\nkafka_rpc\nmemory\nothers\nraft\nreactor\n"+
+			"rpc_client\nscheduler\nstorage\nvectorized_internal_rpc\n",
+		out.String(),
+	)
+}
+
+func TestGrafanaPercentiles(t *testing.T) {
+	res := `# HELP vectorized_internal_rpc_dispatch_handler_latency Latency of service handler dispatch
+# TYPE vectorized_internal_rpc_dispatch_handler_latency histogram
+vectorized_internal_rpc_dispatch_handler_latency_sum{shard="0",type="histogram"} 0
+vectorized_internal_rpc_dispatch_handler_latency_count{shard="0",type="histogram"} 0
+vectorized_internal_rpc_dispatch_handler_latency_bucket{le="10.000000",shard="0",type="histogram"} 0
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(res))
+		}),
+	)
+	defer ts.Close()
+
+	type panel struct {
+		Type    string `json:"type"`
+		Title   string `json:"title"`
+		GridPos struct {
+			W int `json:"w"`
+			X int `json:"x"`
+		} `json:"gridPos"`
+		Targets []struct {
+			Expr string `json:"expr"`
+		} `json:"targets"`
+		Panels []panel `json:"panels"`
+	}
+	tests := []struct {
+		name            string
+		args            []string
+		expectedSummary []string
+		expectedRow     []string
+		expectedErrMsg  string
+	}{
+		{
+			name: "it should render p95 and p99 in the summary and p95 in the rows by default",
+			expectedSummary: []string{
+				"Latency of service handler dispatch (p95)",
+				"Latency of service handler dispatch (p99)",
+			},
+			expectedRow: []string{
+				"Latency of service handler dispatch (p95)",
+			},
+		},
+		{
+			name: "it should render the given percentiles",
+			args: []string{"--percentiles", "0.5,0.95,0.99,0.999"},
+			expectedSummary: []string{
+				"Latency of service handler dispatch (p50)",
+				"Latency of service handler dispatch (p95)",
+				"Latency of service handler dispatch (p99)",
+				"Latency of service handler dispatch (p99.9)",
+			},
+			expectedRow: []string{
+				"Latency of service handler dispatch (p50)",
+				"Latency of service handler dispatch (p95)",
+				"Latency of service handler dispatch (p99)",
+				"Latency of service handler dispatch (p99.9)",
+			},
+		},
+		{
+			name:           "it should fail if a percentile is 1 or more",
+			args:           []string{"--percentiles", "0.5,1"},
+			expectedErrMsg: "the percentiles must be between 0 and 1 (exclusive), but got 1",
+		},
+		{
+			name:           "it should fail if a percentile is 0 or less",
+			args:           []string{"--percentiles", "-0.5"},
+			expectedErrMsg: "the percentiles must be between 0 and 1 (exclusive), but got -0.5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+			cmd.SetOutput(&out)
+			cmd.SetArgs(append(
+				[]string{"--metrics-endpoint", ts.URL, "--datasource", "prometheus"},
+				tt.args...,
+			))
+			err := cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+
+			var dashboard struct {
+				Panels []panel `json:"panels"`
+			}
+			err = json.Unmarshal(out.Bytes(), &dashboard)
+			require.NoError(st, err)
+			summary := []string{}
+			row := []string{}
+			for _, p := range dashboard.Panels {
+				if p.Type == "graph" {
+					summary = append(summary, p.Title)
+					// The RPC latency panels fit in the summary's left half.
+					require.LessOrEqual(st, p.GridPos.X+p.GridPos.W, 12)
+					continue
+				}
+				for _, rp := range p.Panels {
+					row = append(row, rp.Title)
+					require.Len(st, rp.Targets, 1)
+				}
+			}
+			require.Equal(st, tt.expectedSummary, summary)
+			require.Equal(st, tt.expectedRow, row)
+		})
+	}
+}
+
+func TestGrafanaJobVariable(t *testing.T) {
+	res := `# HELP vectorized_memory_allocated_memory_bytes Allocated memory size in bytes
+# TYPE vectorized_memory_allocated_memory_bytes counter
+vectorized_memory_allocated_memory_bytes{shard="0",type="bytes"} 40837120
+# HELP vectorized_vectorized_internal_rpc_consumed_mem Amount of memory consumed for requests processing
+# TYPE vectorized_vectorized_internal_rpc_consumed_mem gauge
+vectorized_vectorized_internal_rpc_consumed_mem{shard="0",type="gauge"} 0.000000
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(res))
+		}),
+	)
+	defer ts.Close()
+
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+	cmd.SetOutput(&out)
+	cmd.SetArgs([]string{
+		"--metrics-endpoint", ts.URL,
+		"--datasource", "prometheus",
+		"--job-name", "cluster-a",
+	})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	type panel struct {
+		Targets []struct {
+			Expr string `json:"expr"`
+		} `json:"targets"`
+		Panels []panel `json:"panels"`
+	}
+	var dashboard struct {
+		Templating struct {
+			List []struct {
+				Name    string `json:"name"`
+				Query   string `json:"query"`
+				Current struct {
+					Text  string `json:"text"`
+					Value string `json:"value"`
+				} `json:"current"`
+			} `json:"list"`
+		} `json:"templating"`
+		Panels []panel `json:"panels"`
+	}
+	err = json.Unmarshal(out.Bytes(), &dashboard)
+	require.NoError(t, err)
+
+	// --job-name is only the variable's default.
+	job := dashboard.Templating.List[0]
+	require.Equal(t, "job", job.Name)
+	require.Equal(t, "cluster-a", job.Current.Text)
+	require.Equal(t, "cluster-a", job.Current.Value)
+	for _, v := range dashboard.Templating.List[1:] {
+		if v.Query != "" && v.Name != "aggr_criteria" {
+			require.Contains(t, v.Query, `job=~"[[job]]"`, v.Name)
+		}
+	}
+
+	exprs := []string{}
+	var collect func([]panel)
+	collect = func(panels []panel) {
+		for _, p := range panels {
+			for _, target := range p.Targets {
+				exprs = append(exprs, target.Expr)
+			}
+			collect(p.Panels)
+		}
+	}
+	collect(dashboard.Panels)
+	require.NotEmpty(t, exprs)
+	for _, expr := range exprs {
+		require.Contains(t, expr, `job=~"[[job]]"`)
+		require.NotContains(t, expr, "cluster-a")
+	}
+}
+
+func TestGrafanaOutputFile(t *testing.T) {
+	const path = "/tmp/grafana/dashboards/redpanda.json"
+	res := `# HELP vectorized_memory_allocated_memory_bytes Allocated memory size in bytes
+# TYPE vectorized_memory_allocated_memory_bytes counter
+vectorized_memory_allocated_memory_bytes{shard="0",type="bytes"} 40837120
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(res))
+		}),
+	)
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		args   []string
+		pretty bool
+	}{
+		{
+			name:   "it should write the indented dashboard to the file",
+			pretty: true,
+		},
+		{
+			name: "it should write the compacted dashboard with --pretty=false",
+			args: []string{"--pretty=false"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			var out, errOut bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := generate.NewGrafanaDashboardCmd(fs)
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs(append([]string{
+				"--metrics-endpoint", ts.URL,
+				"--datasource", "prometheus",
+				"-o", path,
+			}, tt.args...))
+			err := cmd.Execute()
+			require.NoError(st, err)
+			require.Empty(st, out.String())
+			require.Equal(st, "Wrote the dashboard to "+path+"\n", errOut.String())
+
+			bs, err := afero.ReadFile(fs, path)
+			require.NoError(st, err)
+			var dashboard map[string]interface{}
+			require.NoError(st, json.Unmarshal(bs, &dashboard))
+			require.Equal(st, "Redpanda", dashboard["title"])
+			// The compacted JSON is a single line.
+			lines := bytes.Count(bs, []byte("\n"))
+			if tt.pretty {
+				require.Greater(st, lines, 1)
+			} else {
+				require.Equal(st, 1, lines)
+			}
+		})
+	}
+}
+
+func TestGrafanaSummaries(t *testing.T) {
+	str := func(s string) *string { return &s }
+	float := func(f float64) *float64 { return &f }
+	label := func(name, value string) *dto.LabelPair {
+		return &dto.LabelPair{Name: str(name), Value: str(value)}
+	}
+	families := []*dto.MetricFamily{
+		{
+			// A summary exported as a gauge, with the quantiles in a label.
+			Name: str("vectorized_raft_append_latency"),
+			Help: str("Append latency"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{
+						label("quantile", "0.5"),
+						label("shard", "0"),
+					},
+					Gauge: &dto.Gauge{Value: float(10)},
+				},
+				{
+					Label: []*dto.LabelPair{
+						label("quantile", "0.99"),
+						label("shard", "0"),
+					},
+					Gauge: &dto.Gauge{Value: float(100)},
+				},
+			},
+		},
+		{
+			Name: str("vectorized_storage_flush_latency"),
+			Help: str("Flush latency"),
+			Type: dto.MetricType_SUMMARY.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{label("shard", "0")},
+				Summary: &dto.Summary{
+					SampleCount: func(n uint64) *uint64 { return &n }(2),
+					SampleSum:   float(110),
+					Quantile: []*dto.Quantile{
+						{Quantile: float(0.5), Value: float(10)},
+						{Quantile: float(0.99), Value: float(100)},
+					},
+				},
+			}},
+		},
+	}
+	var res bytes.Buffer
+	for _, f := range families {
+		_, err := expfmt.MetricFamilyToText(&res, f)
+		require.NoError(t, err)
+	}
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(res.Bytes())
+		}),
+	)
+	defer ts.Close()
+
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+	cmd.SetOutput(&out)
+	cmd.SetArgs([]string{
+		"--metrics-endpoint", ts.URL,
+		"--datasource", "prometheus",
+		"--per-shard-metrics", "vectorized_raft_append_latency",
+	})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	type target struct {
+		Expr         string `json:"expr"`
+		LegendFormat string `json:"legendFormat"`
+	}
+	var dashboard struct {
+		Panels []struct {
+			Panels []struct {
+				Title   string   `json:"title"`
+				Targets []target `json:"targets"`
+			} `json:"panels"`
+		} `json:"panels"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dashboard))
+	targets := map[string]target{}
+	for _, row := range dashboard.Panels {
+		for _, p := range row.Panels {
+			require.Len(t, p.Targets, 1)
+			targets[p.Title] = p.Targets[0]
+		}
+	}
+	require.Equal(
+		t,
+		target{
+			Expr:         `max(vectorized_raft_append_latency{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]",quantile!=""}) by (quantile, [[aggr_criteria]])`,
+			LegendFormat: "node: {{instance}}, quantile: {{quantile}}, shard: {{shard}}",
+		},
+		targets["Append latency (quantiles)"],
+	)
+	require.Equal(
+		t,
+		target{
+			Expr:         `max(vectorized_storage_flush_latency{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]",quantile!=""}) by (quantile, [[aggr_criteria]])`,
+			LegendFormat: "node: {{instance}}, shard: {{shard}}, quantile: {{quantile}}",
+		},
+		targets["Flush latency (quantiles)"],
+	)
+	require.Equal(
+		t,
+		target{
+			Expr:         `max(vectorized_raft_append_latency{job=~"[[job]]",instance=~"[[node]]",shard=~"[[node_shard]]",quantile!=""}) by (quantile, shard)`,
+			LegendFormat: "shard: {{shard}}, quantile: {{quantile}}",
+		},
+		targets["Append latency (quantiles) - per shard"],
+	)
+}
+
+func TestGrafanaUnitOverrides(t *testing.T) {
+	res := `# HELP vectorized_storage_log_read_bytes Total number of bytes read
+# TYPE vectorized_storage_log_read_bytes counter
+vectorized_storage_log_read_bytes{shard="0",type="derive"} 10
+# HELP vectorized_storage_log_written_bytes Total number of bytes written
+# TYPE vectorized_storage_log_written_bytes counter
+vectorized_storage_log_written_bytes{shard="0",type="derive"} 10
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(res))
+		}),
+	)
+	defer ts.Close()
+
+	tests := []struct {
+		name          string
+		overrides     []string
+		expectedUnits map[string]string
+		expectedErr   string
+	}{
+		{
+			name: "it should keep the default units without overrides",
+			expectedUnits: map[string]string{
+				"Rate - Total number of bytes read":    "Bps",
+				"Rate - Total number of bytes written": "Bps",
+			},
+		},
+		{
+			name:      "it should set the unit of the matching metrics",
+			overrides: []string{"read_bytes$=ops"},
+			expectedUnits: map[string]string{
+				"Rate - Total number of bytes read":    "ops",
+				"Rate - Total number of bytes written": "Bps",
+			},
+		},
+		{
+			name:      "it should use the first matching override",
+			overrides: []string{"storage_log_(read|written)_bytes=iops", "_bytes$=ops"},
+			expectedUnits: map[string]string{
+				"Rate - Total number of bytes read":    "iops",
+				"Rate - Total number of bytes written": "iops",
+			},
+		},
+		{
+			name:        "it should fail if the override isn't 'pattern=unit'",
+			overrides:   []string{"read_bytes"},
+			expectedErr: "'read_bytes' isn't a valid unit override, it must be 'pattern=unit'",
+		},
+		{
+			name:        "it should fail if the pattern is invalid",
+			overrides:   []string{"read_(bytes=ops"},
+			expectedErr: "'read_(bytes' isn't a valid pattern in the unit override 'read_(bytes=ops'",
+		},
+		{
+			name:        "it should fail if the unit isn't a Grafana unit",
+			overrides:   []string{"read_bytes$=batches"},
+			expectedErr: "'batches' isn't a known Grafana unit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+			cmd.SetOutput(&out)
+			args := []string{
+				"--metrics-endpoint", ts.URL,
+				"--datasource", "prometheus",
+			}
+			for _, o := range tt.overrides {
+				args = append(args, "--unit-override", o)
+			}
+			cmd.SetArgs(args)
+			err := cmd.Execute()
+			if tt.expectedErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+
+			var dashboard struct {
+				Panels []struct {
+					Panels []struct {
+						Title string `json:"title"`
+						YAxes []struct {
+							Format string `json:"format"`
+						} `json:"yaxes"`
+					} `json:"panels"`
+				} `json:"panels"`
+			}
+			require.NoError(st, json.Unmarshal(out.Bytes(), &dashboard))
+			units := map[string]string{}
+			for _, row := range dashboard.Panels {
+				for _, p := range row.Panels {
+					units[p.Title] = p.YAxes[0].Format
+				}
+			}
+			for title, unit := range tt.expectedUnits {
+				require.Equal(st, unit, units[title], title)
+			}
+		})
+	}
+}
+
+func TestGrafanaValidate(t *testing.T) {
+	res := `# HELP vectorized_memory_allocated_memory_bytes Allocated memory size in bytes
+# TYPE vectorized_memory_allocated_memory_bytes counter
+vectorized_memory_allocated_memory_bytes{shard="0",type="bytes"} 40837120
+# HELP vectorized_vectorized_internal_rpc_dispatch_handler_latency Latency of service handler dispatch
+# TYPE vectorized_vectorized_internal_rpc_dispatch_handler_latency histogram
+vectorized_vectorized_internal_rpc_dispatch_handler_latency_sum{shard="0",type="histogram"} 0
+vectorized_vectorized_internal_rpc_dispatch_handler_latency_count{shard="0",type="histogram"} 0
+vectorized_vectorized_internal_rpc_dispatch_handler_latency_bucket{le="10.000000",shard="0",type="histogram"} 0
+vectorized_vectorized_internal_rpc_dispatch_handler_latency_bucket{le="+Inf",shard="0",type="histogram"} 0
+# HELP vectorized_storage_flush_latency Flush latency
+# TYPE vectorized_storage_flush_latency summary
+vectorized_storage_flush_latency{shard="0",quantile="0.5"} 10
+vectorized_storage_flush_latency{shard="0",quantile="0.99"} 100
+vectorized_storage_flush_latency_sum{shard="0"} 110
+vectorized_storage_flush_latency_count{shard="0"} 2
+`
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(res))
+		}),
+	)
+	defer ts.Close()
+
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	cmd := generate.NewGrafanaDashboardCmd(afero.NewMemMapFs())
+	cmd.SetOutput(&out)
+	cmd.SetArgs([]string{
+		"--metrics-endpoint", ts.URL,
+		"--datasource", "prometheus",
+		"--per-shard-metrics", "vectorized_memory_allocated_memory_bytes",
+		"--validate",
+	})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	// The dashboard is still printed, and has every type of panel.
+	var dashboard map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dashboard))
+	types := map[string]bool{}
+	var collectTypes func(panels []interface{})
+	collectTypes = func(panels []interface{}) {
+		for _, p := range panels {
+			panel := p.(map[string]interface{})
+			types[panel["type"].(string)] = true
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				collectTypes(nested)
+			}
+		}
+	}
+	collectTypes(dashboard["panels"].([]interface{}))
+	require.Equal(
+		t,
+		map[string]bool{"text": true, "singlestat": true, "row": true, "graph": true},
+		types,
+	)
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package generate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The Grafana unit identifiers which can be set with --unit-override, as
+// listed in Grafana's unit picker.
+var grafanaUnits = map[string]bool{
+	// Misc
+	"none": true, "short": true, "percent": true, "percentunit": true,
+	"humidity": true, "dB": true, "hex0x": true, "hex": true, "sci": true,
+	"locale": true, "pixel": true,
+	// Computation
+	"flops": true, "mflops": true, "gflops": true, "tflops": true,
+	"pflops": true, "eflops": true, "zflops": true, "yflops": true,
+	// Data
+	"bits": true, "bytes": true, "kbytes": true, "mbytes": true,
+	"gbytes": true, "tbytes": true, "pbytes": true, "decbits": true,
+	"decbytes": true, "deckbytes": true, "decmbytes": true,
+	"decgbytes": true, "dectbytes": true, "decpbytes": true,
+	// Data rate
+	"pps": true, "binBps": true, "Bps": true, "binbps": true, "bps": true,
+	"KiBs": true, "Kibits": true, "KBs": true, "Kbits": true,
+	"MiBs": true, "Mibits": true, "MBs": true, "Mbits": true,
+	"GiBs": true, "Gibits": true, "GBs": true, "Gbits": true,
+	"TiBs": true, "Tibits": true, "TBs": true, "Tbits": true,
+	"PiBs": true, "Pibits": true, "PBs": true, "Pbits": true,
+	// Throughput
+	"cps": true, "ops": true, "reqps": true, "rps": true, "wps": true,
+	"iops": true, "cpm": true, "opm": true, "rpm": true, "wpm": true,
+	// Time
+	"hertz": true, "ns": true, "µs": true, "ms": true, "s": true, "m": true,
+	"h": true, "d": true, "dtdurationms": true, "dtdurations": true,
+	"dthms": true, "dtdhms": true, "timeticks": true, "clockms": true,
+	"clocks": true,
+}
+
+// Sets the unit of the panels of the metrics whose name matches pattern.
+type unitOverride struct {
+	pattern *regexp.Regexp
+	unit    string
+}
+
+// Parses the --unit-override values, in the form 'pattern=unit', where the
+// pattern is a regular expression matched against the metric names, and the
+// unit is one of grafanaUnits.
+func parseUnitOverrides(values []string) ([]unitOverride, error) {
+	overrides := []unitOverride{}
+	for _, val := range values {
+		// The unit can't have a '=', but the pattern could.
+		i := strings.LastIndex(val, "=")
+		if i <= 0 || i == len(val)-1 {
+			return nil, fmt.Errorf(
+				"'%s' isn't a valid unit override, it must be 'pattern=unit'",
+				val,
+			)
+		}
+		pattern, unit := val[:i], val[i+1:]
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"'%s' isn't a valid pattern in the unit override '%s': %v",
+				pattern,
+				val,
+				err,
+			)
+		}
+		if !grafanaUnits[unit] {
+			units := []string{}
+			for u := range grafanaUnits {
+				units = append(units, u)
+			}
+			sort.Strings(units)
+			return nil, fmt.Errorf(
+				"'%s' isn't a known Grafana unit. Known units: %s",
+				unit,
+				strings.Join(units, ", "),
+			)
+		}
+		overrides = append(overrides, unitOverride{pattern: re, unit: unit})
+	}
+	return overrides, nil
+}
+
+// Returns the unit of the first override matching the metric name, or the
+// given default if none does.
+func (o dashboardOptions) unitFor(metric, defaultUnit string) string {
+	for _, u := range o.unitOverrides {
+		if u.pattern.MatchString(metric) {
+			return u.unit
+		}
+	}
+	return defaultUnit
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Grafana template variables, i.e. [[var]], ${var} and $var.
+var grafanaVarRegexp = regexp.MustCompile(`\[\[[^\]]*\]\]|\$\{[^}]*\}|\$\w+`)
+
+// PromQL keywords which are followed by a list of label names in parentheses,
+// e.g. 'sum by (instance) (metric)'.
+var labelListKeywords = map[string]bool{
+	"by":          true,
+	"without":     true,
+	"on":          true,
+	"ignoring":    true,
+	"group_left":  true,
+	"group_right": true,
+}
+
+// PromQL keywords which may appear without being immediately followed by
+// parentheses, and so could be mistaken for metric names.
+var promQLKeywords = map[string]bool{
+	"and":          true,
+	"or":           true,
+	"unless":       true,
+	"bool":         true,
+	"offset":       true,
+	"inf":          true,
+	"nan":          true,
+	"sum":          true,
+	"min":          true,
+	"max":          true,
+	"avg":          true,
+	"group":        true,
+	"stddev":       true,
+	"stdvar":       true,
+	"count":        true,
+	"count_values": true,
+	"bottomk":      true,
+	"topk":         true,
+	"quantile":     true,
+}
+
+// The suffixes of the series exported for histograms and summaries, which
+// are grouped under a single metric family without them.
+var familySuffixes = []string{"_bucket", "_sum", "_count"}
+
+func executeLintDashboard(src metricsSource, dashboardFile string) error {
+	bs, err := ioutil.ReadFile(dashboardFile)
+	if err != nil {
+		return err
+	}
+	referenced, err := referencedMetrics(bs)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %v", dashboardFile, err)
+	}
+	metricFamilies, err := src.fetch()
+	if err != nil {
+		return err
+	}
+	absent := absentMetrics(referenced, metricFamilies)
+	if len(absent) == 0 {
+		log.Infof("%s: OK", dashboardFile)
+		return nil
+	}
+	for _, m := range absent {
+		log.Errorf(
+			"%s: '%s' isn't exported by %s",
+			dashboardFile,
+			m,
+			src,
+		)
+	}
+	return fmt.Errorf(
+		"found %d metric(s) referenced in %s which aren't exported by %s",
+		len(absent),
+		dashboardFile,
+		src,
+	)
+}
+
+// Returns the sorted names of the metrics referenced by the expressions in
+// the dashboard's panel targets, including those in the panels nested in
+// rows.
+func referencedMetrics(dashboard []byte) ([]string, error) {
+	var doc interface{}
+	err := json.Unmarshal(dashboard, &doc)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	var walk func(interface{})
+	walk = func(val interface{}) {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			if targets, ok := v["targets"].([]interface{}); ok {
+				for _, t := range targets {
+					target, ok := t.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					if expr, ok := target["expr"].(string); ok {
+						for _, n := range metricNames(expr) {
+							names[n] = true
+						}
+					}
+				}
+			}
+			for _, e := range v {
+				walk(e)
+			}
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(doc)
+	referenced := make([]string, 0, len(names))
+	for n := range names {
+		referenced = append(referenced, n)
+	}
+	sort.Strings(referenced)
+	return referenced, nil
+}
+
+// Returns the metric names in the given PromQL expression. Function names,
+// keywords, label names and matchers, strings, durations and Grafana
+// template variables are skipped.
+func metricNames(expr string) []string {
+	expr = grafanaVarRegexp.ReplaceAllString(expr, "")
+	names := []string{}
+	skipLabels := false
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(expr, i)
+		case c == '{':
+			i = skipUntil(expr, i, '}')
+		case c == '[':
+			i = skipUntil(expr, i, ']')
+		case c == '(' && skipLabels:
+			skipLabels = false
+			i = skipUntil(expr, i, ')')
+		case isDigit(c) || c == '.':
+			// Numbers and durations, e.g. 0.95, 1e3 or 5m.
+			for i < len(expr) && (isIdentChar(expr[i]) || expr[i] == '.') {
+				i++
+			}
+		case isIdentStart(c):
+			start := i
+			for i < len(expr) && isIdentChar(expr[i]) {
+				i++
+			}
+			ident := expr[start:i]
+			next := strings.TrimLeft(expr[i:], " \t\n")
+			switch {
+			case labelListKeywords[ident]:
+				skipLabels = true
+			case strings.HasPrefix(next, "("):
+				// A function call.
+			case promQLKeywords[strings.ToLower(ident)]:
+			default:
+				names = append(names, ident)
+			}
+		default:
+			i++
+		}
+	}
+	return names
+}
+
+// Returns the index after the end of the string starting at i, taking
+// escaped quotes into account.
+func skipString(expr string, i int) int {
+	quote := expr[i]
+	for i++; i < len(expr); i++ {
+		if expr[i] == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if expr[i] == quote {
+			return i + 1
+		}
+	}
+	return i
+}
+
+// Returns the index after the first occurrence of end after i, skipping any
+// strings in between.
+func skipUntil(expr string, i int, end byte) int {
+	for i++; i < len(expr); {
+		switch expr[i] {
+		case '"', '\'', '`':
+			i = skipString(expr, i)
+		case end:
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// Returns the referenced metrics which aren't among the given metric
+// families.
+func absentMetrics(
+	referenced []string, metricFamilies map[string]*dto.MetricFamily,
+) []string {
+	absent := []string{}
+	for _, m := range referenced {
+		if !isExported(m, metricFamilies) {
+			absent = append(absent, m)
+		}
+	}
+	return absent
+}
+
+func isExported(
+	metric string, metricFamilies map[string]*dto.MetricFamily,
+) bool {
+	if _, ok := metricFamilies[metric]; ok {
+		return true
+	}
+	for _, suffix := range familySuffixes {
+		if !strings.HasSuffix(metric, suffix) {
+			continue
+		}
+		family, ok := metricFamilies[strings.TrimSuffix(metric, suffix)]
+		if !ok {
+			continue
+		}
+		switch family.GetType() {
+		case dto.MetricType_HISTOGRAM, dto.MetricType_SUMMARY:
+			return true
+		}
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -10,6 +10,7 @@
 package cmd
 
 import (
+	"fmt"
 	"path/filepath"
 	"time"
 
@@ -17,7 +18,9 @@ import (
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	rp "github.com/vectorizedio/redpanda/src/go/rpk/pkg/redpanda"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/iotune"
 )
 
 func NewIoTuneCmd(fs afero.Fs, mgr config.Manager) *cobra.Command {
@@ -82,9 +85,51 @@ func NewIoTuneCmd(fs afero.Fs, mgr config.Manager) *cobra.Command {
 			"fraction and a unit suffix, such as '300ms', '1.5s' or '2h45m'. "+
 			"Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'",
 	)
+	command.AddCommand(newIoTuneValidateCmd(fs))
 	return command
 }
 
+func newIoTuneValidateCmd(fs afero.Fs) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validate the IO configuration file",
+		Long: `Validate the IO configuration file and exit.
+
+If path is omitted, the file next to the redpanda config file is validated,
+which is the one redpanda start uses by default. It checks that every disk has
+a mountpoint, and positive read and write IOPS and bandwidth values. It exits
+with a non-zero code if the file is invalid.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return executeIoTuneValidate(fs, path)
+		},
+	}
+}
+
+func executeIoTuneValidate(fs afero.Fs, path string) error {
+	if path == "" {
+		configFile, err := config.FindConfigFile(fs)
+		if err != nil {
+			configFile = config.Default().ConfigFile
+		}
+		path = rp.GetIOConfigPath(filepath.Dir(configFile))
+	}
+	errs := iotune.ValidateFile(fs, path)
+	if len(errs) == 0 {
+		log.Infof("%s: OK", path)
+		return nil
+	}
+	for _, e := range errs {
+		log.Errorf("%s: %v", path, e)
+	}
+	return fmt.Errorf("found %d error(s) in %s", len(errs), path)
+}
+
 func execIoTune(
 	fs afero.Fs,
 	directories []string,
@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd"
@@ -107,3 +108,76 @@ func TestTimeoutDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestIoTuneValidate(t *testing.T) {
+	const validIoConfig = `disks:
+- mountpoint: /var/lib/redpanda/data
+  read_iops: 411200
+  read_bandwidth: 2015342735
+  write_iops: 181500
+  write_bandwidth: 808775652
+`
+	tests := []struct {
+		name           string
+		file           string
+		content        string
+		args           []string
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name:           "it should validate the file next to the config by default",
+			file:           "/etc/redpanda/io-config.yaml",
+			content:        validIoConfig,
+			expectedOutput: []string{"/etc/redpanda/io-config.yaml: OK"},
+		},
+		{
+			name:           "it should validate the given file",
+			file:           "/tmp/io.yaml",
+			content:        validIoConfig,
+			args:           []string{"/tmp/io.yaml"},
+			expectedOutput: []string{"/tmp/io.yaml: OK"},
+		},
+		{
+			name: "it should report every problem found",
+			file: "/tmp/io.yaml",
+			content: `disks:
+- mountpoint: /var/lib/redpanda/data
+  read_iops: 411200
+  write_iops: -1
+  write_bandwidth: 808775652
+`,
+			args: []string{"/tmp/io.yaml"},
+			expectedOutput: []string{
+				"/tmp/io.yaml: disks.0.read_bandwidth is missing",
+				"/tmp/io.yaml: disks.0.write_iops must be positive, but got -1",
+			},
+			expectedErrMsg: "found 2 error(s) in /tmp/io.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			err := afero.WriteFile(fs, "/etc/redpanda/redpanda.yaml", []byte(validConfig), 0644)
+			require.NoError(t, err)
+			err = afero.WriteFile(fs, tt.file, []byte(tt.content), 0644)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := cmd.NewIoTuneCmd(fs, mgr)
+			c.SetArgs(append([]string{"validate"}, tt.args...))
+			c.SetOut(&out)
+			err = c.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+			for _, o := range tt.expectedOutput {
+				require.Contains(t, out.String(), o)
+			}
+		})
+	}
+}
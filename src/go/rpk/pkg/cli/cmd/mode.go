@@ -10,15 +10,16 @@
 package cmd
 
 import (
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/redpanda"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 )
 
-func NewModeCommand(mgr config.Manager) *cobra.Command {
+func NewModeCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	return common.Deprecated(
-		redpanda.NewModeCommand(mgr),
+		redpanda.NewModeCommand(fs, mgr),
 		"rpk redpanda mode",
 	)
 }
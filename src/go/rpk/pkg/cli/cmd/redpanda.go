@@ -27,10 +27,20 @@ func NewRedpandaCommand(
 
 	command.AddCommand(redpanda.NewStartCommand(fs, mgr, launcher))
 	command.AddCommand(redpanda.NewStopCommand(fs, mgr))
+	command.AddCommand(redpanda.NewUndrainCommand(fs, mgr))
 	command.AddCommand(redpanda.NewCheckCommand(fs, mgr))
 	command.AddCommand(redpanda.NewTuneCommand(fs, mgr))
-	command.AddCommand(redpanda.NewModeCommand(mgr))
+	command.AddCommand(redpanda.NewModeCommand(fs, mgr))
 	command.AddCommand(redpanda.NewConfigCommand(fs, mgr))
+	command.AddCommand(redpanda.NewValidateConfigCommand(fs, mgr))
+	command.AddCommand(redpanda.NewResourcesCommand(fs, mgr))
+	command.AddCommand(redpanda.NewNtpWatchCommand(fs))
+	command.AddCommand(redpanda.NewMetricsPushCommand(fs, mgr))
+	command.AddCommand(redpanda.NewCheckPartitionsCommand(fs, mgr))
+	command.AddCommand(redpanda.NewCheckFallocateCommand(fs, mgr))
+	command.AddCommand(redpanda.NewInitDevCommand(fs, mgr))
+	command.AddCommand(redpanda.NewCrashSummaryCommand(fs))
+	command.AddCommand(redpanda.NewSeastarFlagsCommand(fs, mgr))
 
 	return command
 }
@@ -10,14 +10,17 @@
 package redpanda
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
@@ -26,6 +29,8 @@ import (
 func NewCheckCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	var (
 		configFile string
+		installDir string
+		format     string
 		timeout    time.Duration
 	)
 	command := &cobra.Command{
@@ -33,7 +38,19 @@ func NewCheckCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 		Short:        "Check if system meets redpanda requirements",
 		SilenceUsage: true,
 		RunE: func(ccmd *cobra.Command, args []string) error {
-			return executeCheck(fs, mgr, configFile, timeout)
+			if format != "text" && format != "json" {
+				return fmt.Errorf(
+					"unsupported format '%s', it must be 'text' or 'json'",
+					format,
+				)
+			}
+			// redpanda must be installed for the checks to be
+			// meaningful, and it's found the same way start does.
+			_, err := cli.GetOrFindInstallDir(fs, installDir)
+			if err != nil {
+				return err
+			}
+			return executeCheck(fs, mgr, configFile, format, timeout)
 		},
 	}
 	command.Flags().StringVar(
@@ -43,6 +60,19 @@ func NewCheckCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 		"Redpanda config file, if not set the file will be searched for"+
 			" in the default locations",
 	)
+	command.Flags().StringVar(
+		&installDir,
+		"install-dir",
+		"",
+		"Directory where redpanda has been installed. Can also be set"+
+			" with the REDPANDA_INSTALL_DIR environment variable",
+	)
+	command.Flags().StringVar(
+		&format,
+		"format",
+		"text",
+		"The output format. Can be 'text' or 'json'",
+	)
 	command.Flags().DurationVar(
 		&timeout,
 		"timeout",
@@ -66,7 +96,11 @@ func appendToTable(t *tablewriter.Table, r tuners.CheckResult) {
 }
 
 func executeCheck(
-	fs afero.Fs, mgr config.Manager, configFile string, timeout time.Duration,
+	fs afero.Fs,
+	mgr config.Manager,
+	configFile string,
+	format string,
+	timeout time.Duration,
 ) error {
 	conf, err := mgr.FindOrGenerate(configFile)
 	if err != nil {
@@ -76,6 +110,18 @@ func executeCheck(
 	if err != nil {
 		return err
 	}
+	if format == "json" {
+		reports := []tuners.CheckReport{}
+		for _, res := range results {
+			reports = append(reports, tuners.NewCheckReport(res))
+		}
+		out, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return failedChecksError(results)
+	}
 	table := ui.NewRpkTable(os.Stdout)
 	table.SetHeader([]string{
 		"Condition",
@@ -90,7 +136,28 @@ func executeCheck(
 	}
 	fmt.Printf("\nSystem check results\n")
 	table.Render()
-	return nil
+	return failedChecksError(results)
+}
+
+// Returns an error listing the fatal checks which failed, if any.
+func failedChecksError(results []tuners.CheckResult) error {
+	failed := []string{}
+	for _, res := range results {
+		if !res.IsOk && res.Severity == tuners.Fatal {
+			failed = append(failed, res.Desc)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return cli.NewExitError(
+		cli.ExitCheckFailed,
+		fmt.Errorf(
+			"%d fatal system check(s) failed: %s",
+			len(failed),
+			strings.Join(failed, ", "),
+		),
+	)
 }
 
 func printResult(sev tuners.Severity, isOk bool) string {
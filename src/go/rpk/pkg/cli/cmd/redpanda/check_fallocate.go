@@ -0,0 +1,193 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// The name of the test file created in the data directory.
+const fallocateTestFile = ".rpk-fallocate-check"
+
+type fallocateResult struct {
+	path      string
+	bytes     int64
+	allocTime time.Duration
+	rmTime    time.Duration
+	// Whether the filesystem doesn't support fallocate, in which case
+	// nothing was allocated.
+	unsupported bool
+}
+
+func NewCheckFallocateCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	return newCheckFallocateCommand(fs, mgr, os.NewProc(), time.Now)
+}
+
+func newCheckFallocateCommand(
+	fs afero.Fs, mgr config.Manager, proc os.Proc, now func() time.Time,
+) *cobra.Command {
+	var (
+		configFile string
+		size       string
+		timeout    time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "check-fallocate",
+		Short: "Time the allocation of a file in the data directory",
+		Long: `Time the allocation of a file in the data directory.
+
+A test file of --size is allocated in the data directory with fallocate, the
+same way the swap file is, and then removed, and the time each step takes is
+reported. It gives an idea of how long allocating a ballast file would take
+on the node. A warning is shown if the data directory's filesystem doesn't
+support fallocate.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			bytes, err := units.RAMInBytes(size)
+			if err != nil {
+				return fmt.Errorf("couldn't parse the size '%s': %v", size, err)
+			}
+			if bytes <= 0 {
+				return fmt.Errorf("--size must be positive, but got '%s'", size)
+			}
+			conf, err := mgr.ReadOrFind(configFile)
+			if err != nil {
+				return err
+			}
+			res, err := timeFallocate(
+				fs,
+				executors.NewDirectExecutor(),
+				proc,
+				now,
+				filepath.Join(conf.Redpanda.Directory, fallocateTestFile),
+				bytes,
+				timeout,
+			)
+			if err != nil {
+				return err
+			}
+			printFallocateResult(cmd.OutOrStdout(), res)
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		configFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	command.Flags().StringVar(
+		&size,
+		"size",
+		"1G",
+		"The size of the test file, e.g. 512M or 10G",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		30*time.Second,
+		"The maximum amount of time to wait for the file to be allocated",
+	)
+	return command
+}
+
+// Allocates a file of the given size at path with fallocate, and removes it,
+// timing both steps.
+func timeFallocate(
+	fs afero.Fs,
+	executor executors.Executor,
+	proc os.Proc,
+	now func() time.Time,
+	path string,
+	bytes int64,
+	timeout time.Duration,
+) (fallocateResult, error) {
+	res := fallocateResult{path: path, bytes: bytes}
+	exists, err := afero.Exists(fs, path)
+	if err != nil {
+		return res, err
+	}
+	if exists {
+		return res, fmt.Errorf(
+			"%s already exists. Please remove it and try again",
+			path,
+		)
+	}
+	start := now()
+	err = executor.Execute(
+		commands.NewLaunchCmd(
+			proc,
+			timeout,
+			"fallocate",
+			"-l",
+			fmt.Sprint(bytes),
+			path,
+		),
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "Operation not supported") {
+			res.unsupported = true
+			return res, nil
+		}
+		return res, fmt.Errorf("couldn't allocate %s: %v", path, err)
+	}
+	res.allocTime = now().Sub(start)
+
+	start = now()
+	err = fs.Remove(path)
+	if err != nil {
+		return res, fmt.Errorf("couldn't remove %s: %v", path, err)
+	}
+	res.rmTime = now().Sub(start)
+	return res, nil
+}
+
+func printFallocateResult(out io.Writer, res fallocateResult) {
+	if res.unsupported {
+		log.Warnf(
+			"The filesystem of %s doesn't support fallocate, so files"+
+				" such as a ballast file would have to be written"+
+				" out in full to allocate them",
+			filepath.Dir(res.path),
+		)
+		return
+	}
+	rate := "-"
+	if res.allocTime > 0 {
+		rate = units.BytesSize(
+			float64(res.bytes)/res.allocTime.Seconds(),
+		) + "/s"
+	}
+	t := ui.NewRpkTable(out)
+	t.AppendBulk([][]string{
+		{"File", res.path},
+		{"Size", units.BytesSize(float64(res.bytes))},
+		{"Allocation time", res.allocTime.String()},
+		{"Removal time", res.rmTime.String()},
+		{"Allocation rate", rate},
+	})
+	t.Render()
+}
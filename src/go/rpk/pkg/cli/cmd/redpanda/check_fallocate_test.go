@@ -0,0 +1,142 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+// Fakes fallocate by creating the file in fs, or fails with err if it's set.
+type fallocateProc struct {
+	fs    afero.Fs
+	err   error
+	calls []string
+}
+
+func (p *fallocateProc) RunWithSystemLdPath(
+	_ time.Duration, cmd string, args ...string,
+) ([]string, error) {
+	p.calls = append(p.calls, strings.Join(append([]string{cmd}, args...), " "))
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []string{}, afero.WriteFile(p.fs, args[len(args)-1], []byte{}, 0644)
+}
+
+func (*fallocateProc) IsRunning(_ time.Duration, _ string) bool {
+	return false
+}
+
+// Returns a clock which advances by the given steps each time it's read.
+func steppingClock(steps ...time.Duration) func() time.Time {
+	t := time.Unix(0, 0)
+	i := 0
+	return func() time.Time {
+		if i < len(steps) {
+			t = t.Add(steps[i])
+			i++
+		}
+		return t
+	}
+}
+
+func TestCheckFallocateCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		procErr        error
+		before         func(afero.Fs)
+		expectedCalls  []string
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name: "it should report the allocation and removal times",
+			args: []string{"--size", "2G"},
+			expectedCalls: []string{
+				"fallocate -l 2147483648 /var/lib/redpanda/data/.rpk-fallocate-check",
+			},
+			expectedOutput: []string{
+				`Size\s+2GiB`,
+				`Allocation time\s+2s`,
+				`Removal time\s+500ms`,
+				`Allocation rate\s+1GiB/s`,
+			},
+		},
+		{
+			name:    "it should warn if fallocate isn't supported",
+			procErr: errors.New("err=exit status 1, stderr=fallocate: fallocate failed: Operation not supported"),
+			expectedCalls: []string{
+				"fallocate -l 1073741824 /var/lib/redpanda/data/.rpk-fallocate-check",
+			},
+			expectedOutput: []string{
+				"The filesystem of /var/lib/redpanda/data doesn't support fallocate",
+			},
+		},
+		{
+			name:           "it should fail if fallocate fails for other reasons",
+			procErr:        errors.New("err=exit status 1, stderr=fallocate: fallocate failed: No space left on device"),
+			expectedErrMsg: "couldn't allocate /var/lib/redpanda/data/.rpk-fallocate-check: err=exit status 1, stderr=fallocate: fallocate failed: No space left on device",
+		},
+		{
+			name: "it should fail if the test file already exists",
+			before: func(fs afero.Fs) {
+				afero.WriteFile(fs, "/var/lib/redpanda/data/.rpk-fallocate-check", []byte{}, 0644)
+			},
+			expectedErrMsg: "/var/lib/redpanda/data/.rpk-fallocate-check already exists. Please remove it and try again",
+		},
+		{
+			name:           "it should fail if the size is invalid",
+			args:           []string{"--size", "lots"},
+			expectedErrMsg: "couldn't parse the size 'lots': invalid size: 'lots'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			require.NoError(st, mgr.Write(conf))
+			if tt.before != nil {
+				tt.before(fs)
+			}
+			proc := &fallocateProc{fs: fs, err: tt.procErr}
+			clock := steppingClock(0, 2*time.Second, 0, 500*time.Millisecond)
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := newCheckFallocateCommand(fs, mgr, proc, clock)
+			cmd.SetOut(&out)
+			cmd.SetArgs(append(tt.args, "--config", conf.ConfigFile))
+			err := cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+			require.Exactly(st, tt.expectedCalls, proc.calls)
+			for _, o := range tt.expectedOutput {
+				require.Regexp(st, o, out.String())
+			}
+			// The test file is always removed.
+			exists, err := afero.Exists(fs, "/var/lib/redpanda/data/.rpk-fallocate-check")
+			require.NoError(st, err)
+			require.False(st, exists)
+		})
+	}
+}
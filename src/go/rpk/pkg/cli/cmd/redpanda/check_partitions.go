@@ -0,0 +1,132 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+)
+
+// A rough estimate of the memory each partition replica needs for its raft
+// state, its readers' caches and its appender's buffers. It's derived from
+// the rule of thumb of up to 1000 partitions and 2GiB of memory per core.
+const partitionMemoryEstimateMB = 2
+
+func NewCheckPartitionsCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	return newCheckPartitionsCommand(mgr, func() (int, error) {
+		return system.GetMemTotalMB(fs)
+	})
+}
+
+func newCheckPartitionsCommand(
+	mgr config.Manager, memTotalMB func() (int, error),
+) *cobra.Command {
+	var (
+		configFile string
+		memory     string
+		partitions int
+	)
+	command := &cobra.Command{
+		Use:   "check-partitions --partitions <count>",
+		Short: "Check whether the node has enough memory for a partition count",
+		Long: fmt.Sprintf(`Check whether the node has enough memory for a partition count.
+
+The memory needed is estimated at %dMiB per partition replica hosted by the
+node, and compared to the memory redpanda will use, which is resolved the same
+way 'rpk redpanda resources' does. Overcommitting memory may make redpanda
+crash when it fails to allocate it.`, partitionMemoryEstimateMB),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if partitions <= 0 {
+				return fmt.Errorf(
+					"--partitions must be positive, but got %d",
+					partitions,
+				)
+			}
+			if !cmd.Flags().Changed(memoryFlag) {
+				conf, err := mgr.ReadOrFind(configFile)
+				if err != nil {
+					log.Warnf(
+						"Couldn't read the config, ignoring"+
+							" rpk.additional_start_flags: %v",
+						err,
+					)
+				} else {
+					memory = parseFlags(conf.Rpk.AdditionalStartFlags)[memoryFlag]
+				}
+			}
+			mem, _, err := resolveMemory(memory, memTotalMB)
+			if err != nil {
+				return err
+			}
+			memBytes, err := units.RAMInBytes(mem)
+			if err != nil {
+				return fmt.Errorf("couldn't parse the memory '%s': %v", mem, err)
+			}
+			availableMB := int(memBytes / units.MiB)
+			if availableMB <= 0 {
+				return errors.New("the memory must be at least 1MiB")
+			}
+			neededMB := partitions * partitionMemoryEstimateMB
+			maxPartitions := availableMB / partitionMemoryEstimateMB
+			t := ui.NewRpkTable(cmd.OutOrStdout())
+			t.AppendBulk([][]string{
+				{"Partitions", fmt.Sprint(partitions)},
+				{"Estimated memory needed", fmt.Sprintf("%dM", neededMB)},
+				{"Available memory", fmt.Sprintf("%dM", availableMB)},
+				{"Max partitions", fmt.Sprint(maxPartitions)},
+			})
+			t.Render()
+			if partitions > maxPartitions {
+				log.Warnf(
+					"%d partitions would overcommit the memory: they need"+
+						" an estimated %dM, but only %dM are available."+
+						" Reduce the partition count or add memory",
+					partitions,
+					neededMB,
+					availableMB,
+				)
+				return nil
+			}
+			log.Infof("%d partitions fit in the available memory", partitions)
+			return nil
+		},
+	}
+	command.Flags().IntVar(
+		&partitions,
+		"partitions",
+		0,
+		"The number of partition replicas the node would host",
+	)
+	command.Flags().StringVar(
+		&configFile,
+		configFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	command.Flags().StringVar(
+		&memory,
+		memoryFlag,
+		"",
+		"The amount of memory that would be passed to 'rpk redpanda start'",
+	)
+	cobra.MarkFlagRequired(command.Flags(), "partitions")
+	return command
+}
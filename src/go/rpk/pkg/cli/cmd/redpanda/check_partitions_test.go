@@ -0,0 +1,101 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func TestCheckPartitionsCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		startFlags     []string
+		memTotalMB     func() (int, error)
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name: "it should pass if the partitions fit in the memory",
+			args: []string{"--partitions", "1000"},
+			memTotalMB: func() (int, error) {
+				return 4096, nil
+			},
+			expectedOutput: []string{
+				`Estimated memory needed\s+2000M`,
+				`Available memory\s+4096M`,
+				`Max partitions\s+2048`,
+				"1000 partitions fit in the available memory",
+			},
+		},
+		{
+			name: "it should warn if the partitions overcommit the memory",
+			args: []string{"--partitions", "3000", "--memory", "2G"},
+			memTotalMB: func() (int, error) {
+				return 0, errors.New("detection shouldn't have been run")
+			},
+			expectedOutput: []string{
+				`Available memory\s+2048M`,
+				`Max partitions\s+1024`,
+				"3000 partitions would overcommit the memory: they need an" +
+					" estimated 6000M, but only 2048M are available",
+			},
+		},
+		{
+			name:       "it should use the memory in rpk.additional_start_flags",
+			args:       []string{"--partitions", "100"},
+			startFlags: []string{"--memory=512M"},
+			memTotalMB: func() (int, error) {
+				return 0, errors.New("detection shouldn't have been run")
+			},
+			expectedOutput: []string{
+				`Available memory\s+512M`,
+				`Max partitions\s+256`,
+			},
+		},
+		{
+			name:           "it should fail if the partition count isn't positive",
+			args:           []string{"--partitions", "0"},
+			expectedErrMsg: "--partitions must be positive, but got 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			conf.Rpk.AdditionalStartFlags = tt.startFlags
+			err := mgr.Write(conf)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := newCheckPartitionsCommand(mgr, tt.memTotalMB)
+			cmd.SetArgs(append([]string{"--config", conf.ConfigFile}, tt.args...))
+			cmd.SetOut(&out)
+			err = cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+			for _, o := range tt.expectedOutput {
+				require.Regexp(st, o, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+func TestFailedChecksError(t *testing.T) {
+	tests := []struct {
+		name           string
+		results        []tuners.CheckResult
+		expectedErrMsg string
+	}{
+		{
+			name: "it shouldn't fail if only warnings failed",
+			results: []tuners.CheckResult{
+				{Desc: "Swappiness", IsOk: true, Severity: tuners.Warning},
+				{Desc: "NTP Synced", IsOk: false, Severity: tuners.Warning},
+			},
+		},
+		{
+			name: "it should list the fatal checks which failed",
+			results: []tuners.CheckResult{
+				{Desc: "Config file valid", IsOk: false, Severity: tuners.Fatal},
+				{Desc: "NTP Synced", IsOk: false, Severity: tuners.Warning},
+				{Desc: "Data directory is writable", IsOk: false, Severity: tuners.Fatal},
+				{Desc: "Free memory per CPU", IsOk: true, Severity: tuners.Fatal},
+			},
+			expectedErrMsg: "2 fatal system check(s) failed: Config file" +
+				" valid, Data directory is writable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			err := failedChecksError(tt.results)
+			if tt.expectedErrMsg == "" {
+				require.NoError(st, err)
+				return
+			}
+			require.EqualError(st, err, tt.expectedErrMsg)
+			require.Equal(st, cli.ExitCheckFailed, cli.ExitCode(err))
+		})
+	}
+}
+
+func TestCheckInvalidInstallDir(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/opt/redpanda/lib", 0755))
+	cmd := NewCheckCommand(fs, config.NewManager(fs))
+	cmd.SetArgs([]string{"--install-dir", "/opt/redpanda"})
+	err := cmd.Execute()
+	require.EqualError(
+		t,
+		err,
+		"'/opt/redpanda' isn't a valid redpanda install directory,"+
+			" it's missing: bin/redpanda",
+	)
+}
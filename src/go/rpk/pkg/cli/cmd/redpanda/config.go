@@ -10,25 +10,96 @@
 package redpanda
 
 import (
+	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/Shopify/sarama"
+	"github.com/mitchellh/mapstructure"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/iotune"
+	"gopkg.in/yaml.v2"
 )
 
 const configFileFlag = "config"
 
+// The key under which the config is embedded in the ConfigMap built by
+// 'to-configmap'.
+const configMapDataKey = "redpanda.yaml"
+
+type configMap struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   configMapMetadata `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+}
+
+type configMapMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// The properties which are specific to each node, and so are never pulled
+// from other nodes: their IDs, addresses, local paths and credentials.
+var nodeSpecificProperties = map[string]bool{
+	"node_id":                    true,
+	"rack":                       true,
+	"data_directory":             true,
+	"developer_mode":             true,
+	"rpc_server":                 true,
+	"rpc_server_tls":             true,
+	"advertised_rpc_api":         true,
+	"kafka_api":                  true,
+	"kafka_api_tls":              true,
+	"advertised_kafka_api":       true,
+	"admin":                      true,
+	"admin_api_tls":              true,
+	"admin_api_doc_dir":          true,
+	"dashboard_dir":              true,
+	"seed_servers":               true,
+	"coproc_supervisor_server":   true,
+	"cloud_storage_access_key":   true,
+	"cloud_storage_secret_key":   true,
+	"cloud_storage_trust_file":   true,
+	"cloud_storage_api_endpoint": true,
+}
+
 func NewConfigCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	root := &cobra.Command{
 		Use:   "config <command>",
 		Short: "Edit configuration",
 	}
 	root.AddCommand(set(fs, mgr))
+	root.AddCommand(get(fs, mgr))
 	root.AddCommand(bootstrap(mgr))
 	root.AddCommand(initNode(mgr))
+	root.AddCommand(resetUUID(fs, mgr))
+	root.AddCommand(fromFlat(fs))
+	root.AddCommand(pull(fs, mgr))
+	root.AddCommand(toConfigMap(fs, mgr))
+	root.AddCommand(toHelmValues(fs, mgr))
+	root.AddCommand(apply(fs, mgr))
+	root.AddCommand(audit(mgr))
+	root.AddCommand(exportTuners(fs, mgr))
+	root.AddCommand(importTuners(fs, mgr))
+	root.AddCommand(which(fs))
+	root.AddCommand(checkTLS(fs, mgr))
+	root.AddCommand(checkCluster(fs))
+	root.AddCommand(generateCluster(fs))
+	root.AddCommand(repair(fs, mgr))
+	root.AddCommand(printConfig(fs, mgr))
 
 	return root
 }
@@ -37,11 +108,13 @@ func set(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	var (
 		format     string
 		configPath string
+		noBackup   bool
 	)
 	c := &cobra.Command{
-		Use:   "set <key> <value>",
-		Short: "Set configuration values, such as the node IDs or the list of seed servers",
-		Args:  cobra.ExactArgs(2),
+		Use:               "set <key> <value>",
+		Short:             "Set configuration values, such as the node IDs or the list of seed servers",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSetArgs,
 		RunE: func(_ *cobra.Command, args []string) error {
 			var err error
 			key := args[0]
@@ -52,6 +125,13 @@ func set(fs afero.Fs, mgr config.Manager) *cobra.Command {
 					return err
 				}
 			}
+			// The config is locked until it's written, so that it isn't
+			// changed by another process in the meantime.
+			unlock, err := mgr.Lock(configPath)
+			if err != nil {
+				return err
+			}
+			defer unlock()
 			_, err = mgr.Read(configPath)
 			if err != nil {
 				return err
@@ -60,14 +140,16 @@ func set(fs afero.Fs, mgr config.Manager) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			mgr.SetBackup(!noBackup)
 			return mgr.WriteLoaded()
 		},
 	}
+	addNoBackupFlag(c, &noBackup)
 	c.Flags().StringVar(&format,
 		"format",
 		"single",
 		"The value format. Can be 'single', for single values such as"+
-			" '/etc/redpanda' or 100; and 'json' and 'yaml' when"+
+			" '/etc/redpanda' or 100; and 'json', 'yaml' and 'toml' when"+
 			" partially or completely setting config objects",
 	)
 	c.Flags().StringVar(
@@ -80,12 +162,133 @@ func set(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	return c
 }
 
+func get(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		format     string
+		configPath string
+		output     string
+	)
+	c := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a configuration value, or a whole object or list",
+		Long: `Print a configuration value, or a whole object or list.
+
+Objects and lists, such as 'rpk' or 'redpanda.kafka_api', are printed as a
+whole in the given format, where 'text' prints them as YAML. List elements are
+indexed the same way they are for 'set', e.g. redpanda.kafka_api.0.port.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" && format != "yaml" {
+				return fmt.Errorf(
+					"unsupported format '%s', it must be 'text', 'json' or 'yaml'",
+					format,
+				)
+			}
+			var err error
+			if configPath == "" {
+				configPath, err = config.FindConfigFile(fs)
+				if err != nil {
+					return err
+				}
+			}
+			_, err = mgr.Read(configPath)
+			if err != nil {
+				return err
+			}
+			val, err := mgr.GetKey(args[0])
+			if err != nil {
+				return err
+			}
+			out, err := formatValue(val, format)
+			if err != nil {
+				return err
+			}
+			return common.WriteOutput(fs, cmd, output, []byte(out+"\n"))
+		},
+	}
+	c.Flags().StringVar(
+		&format,
+		"format",
+		"text",
+		"The output format. Can be 'text', 'json' or 'yaml'",
+	)
+	common.AddOutputFlag(c, &output)
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	return c
+}
+
+// Returns the value in the given format, without a trailing newline.
+func formatValue(val interface{}, format string) (string, error) {
+	switch val.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		if format == "text" {
+			return fmt.Sprint(val), nil
+		}
+	}
+	if format == "json" {
+		out, err := json.MarshalIndent(val, "", "  ")
+		return string(out), err
+	}
+	out, err := yaml.Marshal(val)
+	return strings.TrimSuffix(string(out), "\n"), err
+}
+
+// completeSetArgs suggests the known config keys for the first argument, and
+// the accepted values for enum-like keys for the second one.
+func completeSetArgs(
+	_ *cobra.Command, args []string, toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	var candidates []string
+	switch len(args) {
+	case 0:
+		candidates = config.KnownKeys()
+	case 1:
+		candidates = knownValues(args[0])
+	}
+	completions := []string{}
+	for _, c := range candidates {
+		if strings.HasPrefix(c, toComplete) {
+			completions = append(completions, c)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func knownValues(key string) []string {
+	switch key {
+	case "rpk.well_known_io":
+		return iotune.WellKnownIos()
+	case "rpk.sasl.type", "rpk.kafka_api.sasl.type":
+		return []string{
+			sarama.SASLTypeSCRAMSHA256,
+			sarama.SASLTypeSCRAMSHA512,
+		}
+	case "redpanda.developer_mode":
+		return []string{"true", "false"}
+	}
+	if strings.HasPrefix(key, "rpk.tune_") ||
+		strings.HasPrefix(key, "rpk.enable_") ||
+		key == "rpk.overprovisioned" {
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
 func bootstrap(mgr config.Manager) *cobra.Command {
 	var (
 		ips        []string
 		self       string
 		id         int
 		configPath string
+		noBackup   bool
 	)
 	c := &cobra.Command{
 		Use:   "bootstrap --id <id> [--self <ip>] [--ips <ip1,ip2,...>]",
@@ -151,9 +354,11 @@ func bootstrap(mgr config.Manager) *cobra.Command {
 				seeds = append(seeds, seed)
 			}
 			conf.Redpanda.SeedServers = seeds
+			mgr.SetBackup(!noBackup)
 			return mgr.Write(conf)
 		},
 	}
+	addNoBackupFlag(c, &noBackup)
 	c.Flags().StringSliceVar(
 		&ips,
 		"ips",
@@ -213,6 +418,803 @@ func initNode(mgr config.Manager) *cobra.Command {
 	return c
 }
 
+func resetUUID(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath string
+		confirm    bool
+		force      bool
+	)
+	c := &cobra.Command{
+		Use:   "reset-uuid --confirm",
+		Short: "Regenerate the node's UUID",
+		Long: `Regenerate the node's UUID.
+
+Nodes created from the same VM image end up with the same node_uuid, which
+confuses redpanda, so the cloned nodes' UUIDs must be reset. Since the UUID
+identifies a node which already joined a cluster, resetting it is destructive,
+and --confirm is required. The command also refuses to reset the UUID if the
+data directory has data, unless --force is passed.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if !confirm {
+				return errors.New(
+					"resetting node_uuid is destructive for a node" +
+						" which already joined a cluster. Pass" +
+						" --confirm to reset it",
+				)
+			}
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			if !force {
+				hasData, err := dataDirHasData(fs, conf)
+				if err != nil {
+					return err
+				}
+				if hasData {
+					return fmt.Errorf(
+						"%s has data, so the node has likely joined"+
+							" a cluster already. Pass --force to"+
+							" reset node_uuid anyway",
+						conf.Redpanda.Directory,
+					)
+				}
+			}
+			previous := conf.NodeUuid
+			err = mgr.WriteNodeUUID(conf)
+			if err != nil {
+				return err
+			}
+			log.Infof(
+				"Reset node_uuid from '%s' to '%s'",
+				previous,
+				conf.NodeUuid,
+			)
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	c.Flags().BoolVar(
+		&confirm,
+		"confirm",
+		false,
+		"Confirm that the node's UUID should be reset",
+	)
+	c.Flags().BoolVar(
+		&force,
+		"force",
+		false,
+		"Reset the UUID even if the data directory has data",
+	)
+	return c
+}
+
+// Whether the data directory has anything besides the PID file and hidden
+// files, such as the ones rpk's checks leave behind.
+func dataDirHasData(fs afero.Fs, conf *config.Config) (bool, error) {
+	dir := conf.Redpanda.Directory
+	exists, err := afero.DirExists(fs, dir)
+	if err != nil || !exists {
+		return false, err
+	}
+	infos, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return false, err
+	}
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+		if path == conf.PIDFile() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func fromFlat(fs afero.Fs) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "from-flat [file]",
+		Short: "Build a config file from flattened key=value pairs",
+		Long: `Build a config file from flattened key=value pairs.
+
+The input is the flattened config, with one key=value pair per line, e.g.
+
+  redpanda.data_directory=/var/lib/redpanda/data
+  redpanda.seed_servers.0=192.168.0.1:33145
+  redpanda.kafka_api.0=internal://0.0.0.0:9092
+  redpanda.kafka_api_tls.0.enabled=true
+
+It's read from the given file, or from stdin if it's omitted or '-'. The
+resulting config is validated and printed to stdout.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = cmd.InOrStdin()
+			if len(args) > 0 && args[0] != "-" {
+				f, err := fs.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			flat, err := readFlat(in)
+			if err != nil {
+				return err
+			}
+			conf, err := config.FromFlat(flat)
+			if err != nil {
+				return err
+			}
+			ok, errs := config.Check(conf)
+			if !ok {
+				reasons := []string{}
+				for _, err := range errs {
+					reasons = append(reasons, err.Error())
+				}
+				return errors.New(strings.Join(reasons, ", "))
+			}
+			out, err := yaml.Marshal(conf)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+	return c
+}
+
+func pull(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		from                   string
+		configPath             string
+		adminAPIEnableTLS      bool
+		adminAPICertFile       string
+		adminAPIKeyFile        string
+		adminAPITruststoreFile string
+	)
+	c := &cobra.Command{
+		Use:   "pull --from <admin-api-url>",
+		Short: "Merge the cluster properties of an existing node into the local config",
+		Long: `Merge the cluster properties of an existing node into the local config.
+
+The effective config is fetched from the admin API of the node in --from, and
+its cluster properties (e.g. log_segment_size) are merged into the local
+config. Node-specific properties, such as node_id, data_directory, the API
+addresses, seed_servers and the cloud storage credentials, are never pulled.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if from == "" {
+				return errors.New("required flag(s) \"from\" not set")
+			}
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			tlsConfig, err := common.BuildAdminApiTLSConfig(
+				fs,
+				&adminAPIEnableTLS,
+				&adminAPICertFile,
+				&adminAPIKeyFile,
+				&adminAPITruststoreFile,
+				func() (*config.Config, error) { return conf, nil },
+			)()
+			if err != nil {
+				return err
+			}
+			api, err := admin.NewAdminAPI([]string{from}, tlsConfig)
+			if err != nil {
+				return err
+			}
+			remote, err := api.Config()
+			if err != nil {
+				return fmt.Errorf(
+					"couldn't fetch the config from %s: %v",
+					from,
+					err,
+				)
+			}
+			pulled, err := mergeClusterProperties(conf, remote)
+			if err != nil {
+				return err
+			}
+			if len(pulled) == 0 {
+				log.Infof("No cluster properties to pull from %s", from)
+				return nil
+			}
+			err = mgr.Merge(conf)
+			if err != nil {
+				return err
+			}
+			err = mgr.WriteLoaded()
+			if err != nil {
+				return err
+			}
+			log.Infof(
+				"Pulled %s from %s",
+				strings.Join(pulled, ", "),
+				from,
+			)
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&from,
+		"from",
+		"",
+		"The admin API address of the node to pull the config from",
+	)
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	common.AddAdminAPITLSFlags(
+		c,
+		&adminAPIEnableTLS,
+		&adminAPICertFile,
+		&adminAPIKeyFile,
+		&adminAPITruststoreFile,
+	)
+	return c
+}
+
+func toConfigMap(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		name       string
+		namespace  string
+		configPath string
+		output     string
+	)
+	c := &cobra.Command{
+		Use:   "to-configmap --name <name> [--namespace <namespace>]",
+		Short: "Print the config as a Kubernetes ConfigMap manifest",
+		Long: `Print the config as a Kubernetes ConfigMap manifest.
+
+The config is embedded in the ConfigMap under the redpanda.yaml key, and the
+manifest is printed to stdout, so that it can be piped to 'kubectl apply -f -'.
+
+Secrets are never embedded: the values read from the secrets file are printed
+as their ${secret:<key>} placeholders, and the plain-text passwords and keys
+in the config are replaced with placeholders too. The secrets must then be
+provided in a secrets.yaml file next to redpanda.yaml, e.g. by mounting a
+Kubernetes Secret.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			redacted, err := mgr.Redacted(conf)
+			if err != nil {
+				return err
+			}
+			for _, key := range replaceSensitiveValues(redacted) {
+				log.Warnf(
+					"Replaced the value of %s with ${secret:%s}. Add it to"+
+						" the %s file next to redpanda.yaml",
+					key,
+					secretKey(key),
+					config.SecretsFileName,
+				)
+			}
+			confBytes, err := yaml.Marshal(redacted)
+			if err != nil {
+				return err
+			}
+			out, err := yaml.Marshal(configMap{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Metadata: configMapMetadata{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Data: map[string]string{configMapDataKey: string(confBytes)},
+			})
+			if err != nil {
+				return err
+			}
+			return common.WriteOutput(fs, cmd, output, out)
+		},
+	}
+	common.AddOutputFlag(c, &output)
+	c.Flags().StringVar(&name, "name", "", "The name of the ConfigMap")
+	c.Flags().StringVar(
+		&namespace,
+		"namespace",
+		"",
+		"The namespace of the ConfigMap. If empty, it's left to kubectl",
+	)
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	cobra.MarkFlagRequired(c.Flags(), "name")
+	return c
+}
+
+func apply(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath string
+		yes        bool
+		noBackup   bool
+		detectOnly bool
+	)
+	c := &cobra.Command{
+		Use:   "apply <file>",
+		Short: "Replace the config with the one in the given file",
+		Long: `Replace the config with the one in the given file.
+
+The config in the file is validated, and the fields that would change are
+shown before asking for confirmation, which can be skipped with --yes. The
+current config is backed up before being replaced, unless --no-backup is
+passed.
+
+With --detect-only, the changes are only shown, and the command exits with
+code 6 if there are any, so that it can be used to detect drift.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if configPath == "" {
+				configPath, err = config.FindConfigFile(fs)
+				if err != nil {
+					return err
+				}
+			}
+			if !detectOnly {
+				// The config is locked until the changes are applied, so
+				// that the ones shown are the ones written.
+				unlock, err := mgr.Lock(configPath)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+			}
+			currentMgr := config.NewManager(fs)
+			current, err := currentMgr.Read(configPath)
+			if err != nil {
+				return err
+			}
+			// The changes are shown with the secrets' placeholders.
+			redactedCurrent, err := currentMgr.Redacted(current)
+			if err != nil {
+				return err
+			}
+			desired, err := mgr.Read(args[0])
+			if err != nil {
+				return fmt.Errorf("couldn't read %s: %v", args[0], err)
+			}
+			desired.ConfigFile = current.ConfigFile
+			ok, errs := config.Check(desired)
+			if !ok {
+				return fmt.Errorf(
+					"refusing to apply %s, which is invalid: %w",
+					args[0],
+					&config.InvalidConfigError{Errs: errs},
+				)
+			}
+			redactedDesired, err := mgr.Redacted(desired)
+			if err != nil {
+				return err
+			}
+			changes, err := config.Diff(redactedCurrent, redactedDesired)
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				log.Infof("%s is up to date", current.ConfigFile)
+				return nil
+			}
+			printChanges(cmd.OutOrStdout(), changes)
+			if detectOnly {
+				return cli.NewExitError(
+					cli.ExitConfigDrift,
+					fmt.Errorf(
+						"%s differs from %s in %d field(s)",
+						current.ConfigFile,
+						args[0],
+						len(changes),
+					),
+				)
+			}
+			if !yes {
+				confirmed, err := promptConfirmation(
+					fmt.Sprintf("Apply the changes to %s?", current.ConfigFile),
+					cmd.InOrStdin(),
+				)
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					log.Info("No changes were applied")
+					return nil
+				}
+			}
+			mgr.SetBackup(!noBackup)
+			err = mgr.Write(desired)
+			if err != nil {
+				return err
+			}
+			log.Infof("Applied %d change(s) to %s", len(changes), current.ConfigFile)
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	c.Flags().BoolVar(
+		&yes,
+		"yes",
+		false,
+		"Apply the changes without asking for confirmation",
+	)
+	c.Flags().BoolVar(
+		&detectOnly,
+		"detect-only",
+		false,
+		"Only show the changes, exiting with a non-zero code if there are any",
+	)
+	addNoBackupFlag(c, &noBackup)
+	return c
+}
+
+func addNoBackupFlag(c *cobra.Command, noBackup *bool) {
+	c.Flags().BoolVar(
+		noBackup,
+		"no-backup",
+		false,
+		"Overwrite the config file without backing it up first",
+	)
+}
+
+func audit(mgr config.Manager) *cobra.Command {
+	var configPath string
+	c := &cobra.Command{
+		Use:   "audit",
+		Short: "List the config fields which rpk doesn't recognize",
+		Long: `List the config fields which rpk doesn't recognize.
+
+rpk keeps the fields it doesn't recognize as they are when it writes the
+config, so they're still passed on to redpanda. This lists them, so that it's
+possible to tell the fields rpk doesn't model yet, or typos, apart from the
+ones it understands.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			keys := config.UnrecognizedKeys(conf)
+			if len(keys) == 0 {
+				log.Infof(
+					"All the fields in %s are recognized by rpk",
+					conf.ConfigFile,
+				)
+				return nil
+			}
+			log.Infof(
+				"Found %d field(s) in %s not recognized by rpk:",
+				len(keys),
+				conf.ConfigFile,
+			)
+			for _, k := range keys {
+				fmt.Fprintln(cmd.OutOrStdout(), k)
+			}
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	return c
+}
+
+// The document written by 'export-tuners' and read by 'import-tuners'.
+type tunerProfile struct {
+	Rpk map[string]interface{} `yaml:"rpk"`
+}
+
+func exportTuners(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath string
+		output     string
+	)
+	c := &cobra.Command{
+		Use:   "export-tuners",
+		Short: "Print the tuners config as standalone YAML",
+		Long: `Print the tuners config as standalone YAML.
+
+Only the rpk fields which configure the tuners are printed, under an 'rpk'
+block, so that they can be shared across nodes with 'import-tuners'. The
+connection settings and credentials in the rpk block are left out.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			profile, err := config.TunerProfile(conf)
+			if err != nil {
+				return err
+			}
+			out, err := yaml.Marshal(tunerProfile{Rpk: profile})
+			if err != nil {
+				return err
+			}
+			return common.WriteOutput(fs, cmd, output, out)
+		},
+	}
+	common.AddOutputFlag(c, &output)
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	return c
+}
+
+func importTuners(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath       string
+		keepNodeSpecific bool
+		noBackup         bool
+	)
+	c := &cobra.Command{
+		Use:   "import-tuners <file>",
+		Short: "Merge the tuners config in the given file into the local config",
+		Long: `Merge the tuners config in the given file into the local config.
+
+The file must contain an 'rpk' block with only the fields which configure the
+tuners, such as the one printed by 'export-tuners'. The tuner fields in the
+local config are replaced by the ones in the file. The fields which depend on
+the node's disks (coredump_dir, swapfile_path and ballast_file_path) are kept
+as they are if they're set locally, unless --keep-node-specific=false is
+passed.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			bs, err := afero.ReadFile(fs, args[0])
+			if err != nil {
+				return err
+			}
+			profile := tunerProfile{}
+			err = yaml.Unmarshal(bs, &profile)
+			if err != nil {
+				return fmt.Errorf("couldn't parse %s: %v", args[0], err)
+			}
+			if len(profile.Rpk) == 0 {
+				return fmt.Errorf("%s doesn't contain an 'rpk' block", args[0])
+			}
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			err = config.ApplyTunerProfile(conf, profile.Rpk, keepNodeSpecific)
+			if err != nil {
+				return fmt.Errorf("couldn't import %s: %v", args[0], err)
+			}
+			mgr.SetBackup(!noBackup)
+			err = mgr.Write(conf)
+			if err != nil {
+				return err
+			}
+			log.Infof("Imported the tuners config in %s into %s", args[0], conf.ConfigFile)
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	c.Flags().BoolVar(
+		&keepNodeSpecific,
+		"keep-node-specific",
+		true,
+		"Keep the local coredump_dir, swapfile_path and ballast_file_path",
+	)
+	addNoBackupFlag(c, &noBackup)
+	return c
+}
+
+func which(fs afero.Fs) *cobra.Command {
+	var configPath string
+	c := &cobra.Command{
+		Use:   "which",
+		Short: "Print the path of the config file rpk would use",
+		Long: `Print the path of the config file rpk would use.
+
+The config file is looked for in the same locations, and in the same order, as
+the rest of the commands do when --config isn't passed. The search order is
+printed along with the resolved path.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if configPath != "" {
+				log.Infof("Using the config file passed with --%s", configFileFlag)
+				fmt.Fprintln(cmd.OutOrStdout(), configPath)
+				return nil
+			}
+			candidates := config.SearchPaths()
+			found, err := config.FindConfigFile(fs)
+			log.Info("Search order:")
+			for i, candidate := range candidates {
+				status := "not found"
+				if exists, _ := afero.Exists(fs, candidate); exists {
+					status = "found"
+				}
+				if candidate == found {
+					status = "used"
+				}
+				log.Infof("  %d. %s (%s)", i+1, candidate, status)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), found)
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if set it's the one which will be used",
+	)
+	return c
+}
+
+// Prints each change as '+ key: value' for added fields, '- key: value' for
+// removed ones and '~ key: before -> after' for changed ones.
+func printChanges(out io.Writer, changes []config.Change) {
+	for _, c := range changes {
+		switch {
+		case c.Before == nil:
+			fmt.Fprintf(out, "+ %s: %v\n", c.Key, c.After)
+		case c.After == nil:
+			fmt.Fprintf(out, "- %s: %v\n", c.Key, c.Before)
+		default:
+			fmt.Fprintf(out, "~ %s: %v -> %v\n", c.Key, c.Before, c.After)
+		}
+	}
+}
+
+// Replaces the plain-text passwords and keys in conf with ${secret:<key>}
+// placeholders, and returns the sorted paths of the replaced values. Values
+// which already have placeholders are left as they are.
+func replaceSensitiveValues(conf *config.Config) []string {
+	values := map[string]*string{
+		"redpanda.cloud_storage_access_key": conf.Redpanda.CloudStorageAccessKey,
+		"redpanda.cloud_storage_secret_key": conf.Redpanda.CloudStorageSecretKey,
+	}
+	if conf.Rpk.SASL != nil {
+		values["rpk.sasl.password"] = &conf.Rpk.SASL.Password
+	}
+	if conf.Rpk.KafkaApi.SASL != nil {
+		values["rpk.kafka_api.sasl.password"] = &conf.Rpk.KafkaApi.SASL.Password
+	}
+	if conf.PandaproxyClient != nil {
+		values["pandaproxy_client.scram_password"] = conf.PandaproxyClient.SCRAMPassword
+	}
+	if conf.SchemaRegistryClient != nil {
+		values["schema_registry_client.scram_password"] = conf.SchemaRegistryClient.SCRAMPassword
+	}
+	replaced := []string{}
+	for path, v := range values {
+		if v == nil || *v == "" || strings.Contains(*v, "${secret:") {
+			continue
+		}
+		*v = fmt.Sprintf("${secret:%s}", secretKey(path))
+		replaced = append(replaced, path)
+	}
+	sort.Strings(replaced)
+	return replaced
+}
+
+// Returns the key in the secrets file for the value at the given path.
+func secretKey(path string) string {
+	return strings.ReplaceAll(path, ".", "_")
+}
+
+// Sets the cluster properties in the given node config, as returned by the
+// admin API, in conf.Redpanda. Node-specific properties and null values are
+// skipped. Returns the sorted names of the properties which were set.
+func mergeClusterProperties(
+	conf *config.Config, remote map[string]interface{},
+) ([]string, error) {
+	props := map[string]interface{}{}
+	for k, v := range remote {
+		if nodeSpecificProperties[k] || v == nil {
+			continue
+		}
+		props[k] = v
+	}
+	other := conf.Redpanda.Other
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		// The admin API returns JSON, so integers are decoded as floats.
+		WeaklyTypedInput: true,
+		Result:           &conf.Redpanda,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(props)
+	if err != nil {
+		return nil, err
+	}
+	// mapstructure replaces the ",remain" map with the unknown properties
+	// which were decoded, so the ones which weren't pulled are restored.
+	for k, v := range other {
+		if _, ok := props[k]; ok {
+			continue
+		}
+		if conf.Redpanda.Other == nil {
+			conf.Redpanda.Other = map[string]interface{}{}
+		}
+		conf.Redpanda.Other[k] = v
+	}
+	pulled := make([]string, 0, len(props))
+	for k := range props {
+		pulled = append(pulled, k)
+	}
+	sort.Strings(pulled)
+	return pulled, nil
+}
+
+// readFlat reads key=value lines into a map, skipping empty lines.
+func readFlat(r io.Reader) (map[string]string, error) {
+	flat := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		kv := strings.SplitN(text, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf(
+				"line %d: expected key=value, got '%s'",
+				line,
+				text,
+			)
+		}
+		flat[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return flat, scanner.Err()
+}
+
 func parseIPs(ips []string) ([]net.IP, error) {
 	parsed := []net.IP{}
 	for _, i := range ips {
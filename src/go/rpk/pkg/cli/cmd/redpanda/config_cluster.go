@@ -0,0 +1,106 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func checkCluster(fs afero.Fs) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "check-cluster <file or directory>...",
+		Short: "Check that the given node configs can form a cluster",
+		Long: `Check that the given node configs can form a cluster.
+
+Each argument is either a node's config file, or a directory whose .yaml files
+are all node configs. Besides checking each config on its own, the command
+checks that:
+
+- The node IDs are unique.
+- The seed servers are the RPC addresses of the given nodes.
+- The nodes on the same host (i.e. with the same RPC address) don't listen on
+  the same ports.
+- At least one node has no seed servers, so that it can bootstrap the cluster.
+
+The command exits with code 2 if any of the checks fails.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			paths, err := nodeConfigPaths(fs, args)
+			if err != nil {
+				return err
+			}
+			confs := []*config.Config{}
+			for _, path := range paths {
+				conf, err := config.NewManager(fs).Read(path)
+				if err != nil {
+					return fmt.Errorf("couldn't read %s: %v", path, err)
+				}
+				confs = append(confs, conf)
+			}
+			errs := config.CheckCluster(confs)
+			for _, e := range errs {
+				log.Error(e)
+			}
+			if len(errs) > 0 {
+				return cli.NewExitError(
+					cli.ExitConfigInvalid,
+					fmt.Errorf(
+						"found %d issue(s) in the %d node config(s)",
+						len(errs),
+						len(confs),
+					),
+				)
+			}
+			log.Infof("The %d node config(s) can form a cluster", len(confs))
+			return nil
+		},
+	}
+	return c
+}
+
+// Returns the config files in the given paths, replacing each directory with
+// the .yaml files in it.
+func nodeConfigPaths(fs afero.Fs, args []string) ([]string, error) {
+	paths := []string{}
+	for _, arg := range args {
+		isDir, err := afero.IsDir(fs, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !isDir {
+			paths = append(paths, arg)
+			continue
+		}
+		infos, err := afero.ReadDir(fs, arg)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, info := range infos {
+			if info.IsDir() || filepath.Ext(info.Name()) != ".yaml" {
+				continue
+			}
+			paths = append(paths, filepath.Join(arg, info.Name()))
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no .yaml files", arg)
+		}
+	}
+	return paths, nil
+}
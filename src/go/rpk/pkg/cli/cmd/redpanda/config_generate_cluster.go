@@ -0,0 +1,115 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func generateCluster(fs afero.Fs) *cobra.Command {
+	var (
+		templatePath string
+		nodeArgs     []string
+		outputDir    string
+	)
+	c := &cobra.Command{
+		Use:   "generate-cluster",
+		Short: "Generate the node configs of a cluster from a template",
+		Long: `Generate the node configs of a cluster from a template.
+
+A config is generated for each of the nodes passed to --nodes, as 'id@host',
+based on the --template config, and written to
+<output-dir>/redpanda-<id>.yaml. Each node's config has:
+
+- Its ID as redpanda.node_id.
+- Its host as the address of the RPC server, the Kafka API and admin API
+  listeners, and of the advertised ones if the template sets them. The
+  template's ports are kept.
+- No seed servers for the first node, which bootstraps the cluster, and the
+  RPC addresses of all the nodes for the rest.
+
+The generated configs are checked as 'check-cluster' does, and none of them is
+written if any of the checks fails, in which case the command exits with
+code 2.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			nodes := []config.ClusterNode{}
+			for _, arg := range nodeArgs {
+				node, err := config.ParseClusterNode(arg)
+				if err != nil {
+					return err
+				}
+				nodes = append(nodes, node)
+			}
+			// The configs are written through the manager the template is
+			// read with, so that they keep its secrets' placeholders.
+			mgr := config.NewManager(fs)
+			template, err := mgr.Read(templatePath)
+			if err != nil {
+				return fmt.Errorf("couldn't read %s: %v", templatePath, err)
+			}
+			confs, err := config.GenerateCluster(template, nodes, outputDir)
+			if err != nil {
+				return err
+			}
+			errs := config.CheckCluster(confs)
+			for _, e := range errs {
+				log.Error(e)
+			}
+			if len(errs) > 0 {
+				return cli.NewExitError(
+					cli.ExitConfigInvalid,
+					fmt.Errorf(
+						"found %d issue(s) in the %d generated node config(s)",
+						len(errs),
+						len(confs),
+					),
+				)
+			}
+			for _, conf := range confs {
+				err = mgr.Write(conf)
+				if err != nil {
+					return fmt.Errorf("couldn't write %s: %v", conf.ConfigFile, err)
+				}
+			}
+			log.Infof("Wrote %d node config(s) to %s", len(confs), outputDir)
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&templatePath,
+		"template",
+		"",
+		"The config the nodes' configs are based on",
+	)
+	c.MarkFlagRequired("template")
+	c.Flags().StringSliceVar(
+		&nodeArgs,
+		"nodes",
+		[]string{},
+		"The nodes to generate a config for, as 'id@host', e.g."+
+			" 0@10.0.0.1,1@10.0.0.2. The first one bootstraps the cluster",
+	)
+	c.MarkFlagRequired("nodes")
+	c.Flags().StringVar(
+		&outputDir,
+		"output-dir",
+		".",
+		"The directory to write the nodes' configs to",
+	)
+	return c
+}
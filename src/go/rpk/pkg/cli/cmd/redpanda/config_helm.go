@@ -0,0 +1,284 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// The subset of the redpanda Helm chart's values which can be derived from a
+// node's config.
+type helmValues struct {
+	CommonLabels map[string]string `yaml:"commonLabels,omitempty"`
+	TLS          helmTLS           `yaml:"tls"`
+	Auth         helmAuth          `yaml:"auth"`
+	Listeners    helmListeners     `yaml:"listeners"`
+	Storage      helmStorage       `yaml:"storage,omitempty"`
+	Resources    *helmResources    `yaml:"resources,omitempty"`
+	Tuning       map[string]bool   `yaml:"tuning,omitempty"`
+	Config       helmConfig        `yaml:"config,omitempty"`
+}
+
+type helmTLS struct {
+	Enabled           bool `yaml:"enabled"`
+	RequireClientAuth bool `yaml:"requireClientAuth,omitempty"`
+}
+
+type helmAuth struct {
+	SASL helmSASL `yaml:"sasl"`
+}
+
+type helmSASL struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type helmListeners struct {
+	Admin          *helmListener `yaml:"admin,omitempty"`
+	Kafka          *helmListener `yaml:"kafka,omitempty"`
+	RPC            *helmListener `yaml:"rpc,omitempty"`
+	HTTP           *helmListener `yaml:"http,omitempty"`
+	SchemaRegistry *helmListener `yaml:"schemaRegistry,omitempty"`
+}
+
+// The first listener of an API is the chart's internal one, and the rest are
+// its external ones, keyed by their name.
+type helmListener struct {
+	Port     int                     `yaml:"port"`
+	TLS      *helmTLS                `yaml:"tls,omitempty"`
+	External map[string]helmExternal `yaml:"external,omitempty"`
+}
+
+type helmExternal struct {
+	Port int      `yaml:"port"`
+	TLS  *helmTLS `yaml:"tls,omitempty"`
+}
+
+type helmStorage struct {
+	HostPath string `yaml:"hostPath,omitempty"`
+}
+
+type helmResources struct {
+	CPU helmCPU `yaml:"cpu"`
+}
+
+type helmCPU struct {
+	Cores int `yaml:"cores"`
+}
+
+type helmConfig struct {
+	Cluster map[string]interface{} `yaml:"cluster,omitempty"`
+}
+
+func toHelmValues(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath string
+		output     string
+	)
+	c := &cobra.Command{
+		Use:   "to-helm-values",
+		Short: "Print the config as values for the redpanda Helm chart",
+		Long: `Print the config as values for the redpanda Helm chart.
+
+The listeners (the first one of each API is the internal one, and the rest are
+external), their TLS settings, SASL, the node labels (as commonLabels), the
+data directory (as storage.hostPath), rpk.smp (as resources.cpu.cores), the
+enabled tuners and the cluster properties set in the redpanda section are
+translated into the chart's values, and printed as YAML.
+
+The fields which have no equivalent in the chart's values are listed as
+warnings and left out: the node ID, the seed servers and the advertised
+addresses (the chart derives them from the StatefulSet), the listeners'
+addresses, the TLS certificates' paths (the chart manages the certificates),
+the superusers and the rest of the rpk section.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			// Don't leak the values read from the secrets file, e.g.
+			// in the cluster properties.
+			redacted, err := mgr.Redacted(conf)
+			if err != nil {
+				return err
+			}
+			values, unmapped, err := helmValuesFor(redacted)
+			if err != nil {
+				return err
+			}
+			for _, key := range unmapped {
+				log.Warnf(
+					"%s has no equivalent in the chart's values, and was"+
+						" left out",
+					key,
+				)
+			}
+			out, err := yaml.Marshal(values)
+			if err != nil {
+				return err
+			}
+			return common.WriteOutput(fs, cmd, output, out)
+		},
+	}
+	common.AddOutputFlag(c, &output)
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	return c
+}
+
+// Returns the chart's values for the config, along with the set fields which
+// couldn't be mapped.
+func helmValuesFor(conf *config.Config) (helmValues, []string, error) {
+	rp := conf.Redpanda
+	values := helmValues{
+		CommonLabels: conf.Rpk.NodeLabels,
+		Storage:      helmStorage{HostPath: rp.Directory},
+		Config:       helmConfig{Cluster: rp.Other},
+	}
+	values.Listeners.Kafka = helmListenerFor(rp.KafkaApi, rp.KafkaApiTLS)
+	values.Listeners.Admin = helmListenerFor(rp.AdminApi, rp.AdminApiTLS)
+	if rp.RPCServer.Port != 0 {
+		values.Listeners.RPC = &helmListener{Port: rp.RPCServer.Port}
+	}
+	if pp := conf.Pandaproxy; pp != nil {
+		values.Listeners.HTTP = helmListenerFor(pp.PandaproxyAPI, pp.PandaproxyAPITLS)
+	}
+	if sr := conf.SchemaRegistry; sr != nil {
+		values.Listeners.SchemaRegistry = helmListenerFor(
+			sr.SchemaRegistryAPI,
+			sr.SchemaRegistryAPITLS,
+		)
+	}
+	for _, l := range []*helmListener{
+		values.Listeners.Kafka,
+		values.Listeners.Admin,
+		values.Listeners.HTTP,
+		values.Listeners.SchemaRegistry,
+	} {
+		if l == nil {
+			continue
+		}
+		if l.TLS != nil && l.TLS.Enabled {
+			values.TLS.Enabled = true
+		}
+		for _, e := range l.External {
+			if e.TLS != nil && e.TLS.Enabled {
+				values.TLS.Enabled = true
+			}
+		}
+	}
+	if rp.EnableSASL != nil {
+		values.Auth.SASL.Enabled = *rp.EnableSASL
+	}
+	if conf.Rpk.SMP != nil {
+		values.Resources = &helmResources{helmCPU{*conf.Rpk.SMP}}
+	}
+	profile, err := config.TunerProfile(conf)
+	if err != nil {
+		return values, nil, err
+	}
+	for k, v := range profile {
+		if enabled, ok := v.(bool); ok && enabled && strings.HasPrefix(k, "tune_") {
+			if values.Tuning == nil {
+				values.Tuning = map[string]bool{}
+			}
+			values.Tuning[k] = true
+		}
+	}
+	return values, unmappedHelmFields(conf), nil
+}
+
+func helmListenerFor(
+	addrs []config.NamedSocketAddress, tls []config.ServerTLS,
+) *helmListener {
+	if len(addrs) == 0 {
+		return nil
+	}
+	tlsFor := func(name string) *helmTLS {
+		for _, t := range tls {
+			if t.Name == name && t.Enabled {
+				return &helmTLS{
+					Enabled:           true,
+					RequireClientAuth: t.RequireClientAuth,
+				}
+			}
+		}
+		return nil
+	}
+	l := &helmListener{
+		Port: addrs[0].Port,
+		TLS:  tlsFor(addrs[0].Name),
+	}
+	for i, a := range addrs[1:] {
+		name := a.Name
+		if name == "" {
+			name = fmt.Sprintf("external-%d", i)
+		}
+		if l.External == nil {
+			l.External = map[string]helmExternal{}
+		}
+		l.External[name] = helmExternal{Port: a.Port, TLS: tlsFor(a.Name)}
+	}
+	return l
+}
+
+// Returns the set fields which have no equivalent in the chart's values.
+func unmappedHelmFields(conf *config.Config) []string {
+	rp := conf.Redpanda
+	unmapped := []string{}
+	add := func(key string, set bool) {
+		if set {
+			unmapped = append(unmapped, key)
+		}
+	}
+	add("redpanda.node_id", rp.Id != 0)
+	add("redpanda.seed_servers", len(rp.SeedServers) > 0)
+	add("redpanda.advertised_kafka_api", len(rp.AdvertisedKafkaApi) > 0)
+	add("redpanda.advertised_rpc_api", rp.AdvertisedRPCAPI != nil)
+	add("redpanda.superusers", len(rp.Superusers) > 0)
+	add("rpk.rack_label", conf.Rpk.RackLabel != "")
+	add("rpk.additional_start_flags", len(conf.Rpk.AdditionalStartFlags) > 0)
+	if pp := conf.Pandaproxy; pp != nil {
+		add("pandaproxy.advertised_pandaproxy_api", len(pp.AdvertisedPandaproxyAPI) > 0)
+	}
+	tlsSets := map[string][]config.ServerTLS{
+		"redpanda.kafka_api_tls": rp.KafkaApiTLS,
+		"redpanda.admin_api_tls": rp.AdminApiTLS,
+	}
+	if pp := conf.Pandaproxy; pp != nil {
+		tlsSets["pandaproxy.pandaproxy_api_tls"] = pp.PandaproxyAPITLS
+	}
+	if sr := conf.SchemaRegistry; sr != nil {
+		tlsSets["schema_registry.schema_registry_api_tls"] = sr.SchemaRegistryAPITLS
+	}
+	for key, tls := range tlsSets {
+		for i, t := range tls {
+			set := t.CertFile != "" || t.KeyFile != "" || t.TruststoreFile != ""
+			add(fmt.Sprintf("%s.%d (the certificate paths)", key, i), set)
+		}
+	}
+	sort.Strings(unmapped)
+	return unmapped
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func printConfig(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath string
+		output     string
+		sets       []string
+		sources    bool
+	)
+	c := &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective config",
+		Long: `Print the effective config.
+
+The config is printed as YAML with the values overridden through the
+environment (e.g. REDPANDA_NODE_ID) and the ones passed with --set, which
+isn't written to the file, applied. The values read from the secrets file are
+printed as their placeholders.
+
+With --sources, each value is annotated with where it comes from: 'env' if
+it's overridden through the environment, 'set' if it's passed with --set,
+'file' if it's in the config file, or 'default' otherwise. The environment
+takes precedence over --set, which takes precedence over the file.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var err error
+			if configPath == "" {
+				configPath, err = config.FindConfigFile(fs)
+				if err != nil {
+					return err
+				}
+			}
+			conf, err := mgr.Read(configPath)
+			if err != nil {
+				return err
+			}
+			setKeys := []string{}
+			for _, kv := range sets {
+				parts := strings.SplitN(kv, "=", 2)
+				if len(parts) < 2 {
+					return fmt.Errorf(
+						"key-value pair '%s' is not formatted as expected (k=v)",
+						kv,
+					)
+				}
+				key := strings.ToLower(strings.TrimSpace(parts[0]))
+				err = mgr.Set(key, parts[1], "")
+				if err != nil {
+					return err
+				}
+				setKeys = append(setKeys, key)
+			}
+			if len(setKeys) > 0 {
+				// The environment is applied over the values set, rather
+				// than reading the file again, which would drop them.
+				conf, err = mgr.Get()
+				if err != nil {
+					return err
+				}
+				conf, err = mgr.ApplyEnvOverrides(conf)
+				if err != nil {
+					return err
+				}
+			}
+			var annotations map[string]string
+			if sources {
+				annotations, err = mgr.Sources(conf, setKeys)
+				if err != nil {
+					return err
+				}
+			}
+			out, err := mgr.EffectiveYAML(conf, annotations)
+			if err != nil {
+				return err
+			}
+			return common.WriteOutput(fs, cmd, output, out)
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	c.Flags().StringArrayVar(
+		&sets,
+		"set",
+		[]string{},
+		"Set a config value (key=value) before printing it, without writing"+
+			" it to the file. Can be passed multiple times",
+	)
+	c.Flags().BoolVar(
+		&sources,
+		"sources",
+		false,
+		"Annotate each value with where it comes from: env, set, file or"+
+			" default",
+	)
+	common.AddOutputFlag(c, &output)
+	return c
+}
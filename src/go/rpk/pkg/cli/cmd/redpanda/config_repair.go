@@ -0,0 +1,114 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func repair(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath string
+		yes        bool
+		noBackup   bool
+	)
+	c := &cobra.Command{
+		Use:   "repair",
+		Short: "Fix the config issues which can be fixed with a default value",
+		Long: `Fix the config issues which can be fixed with a default value.
+
+The config is checked, and for each issue which can be fixed by setting the
+field to its default value (an empty redpanda.data_directory, or a port set to
+0), the command asks whether to apply the fix. --yes applies all of them
+without asking. The config is then checked again and written, backing up the
+current one first, unless --no-backup is passed.
+
+The issues which can't be fixed automatically are listed, and the command exits
+with code 2 without writing the config.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var err error
+			if configPath == "" {
+				configPath, err = config.FindConfigFile(fs)
+				if err != nil {
+					return err
+				}
+			}
+			conf, err := mgr.Read(configPath)
+			if err != nil {
+				return err
+			}
+			if ok, _ := config.Check(conf); ok {
+				log.Infof("%s is valid, there's nothing to repair", conf.ConfigFile)
+				return nil
+			}
+			applied := 0
+			for _, r := range config.Repairs(conf) {
+				msg := fmt.Sprintf("%s. Set %s to %v?", r.Problem, r.Key, r.Value)
+				if yes {
+					log.Info(msg)
+				} else {
+					confirmed, err := promptConfirmation(msg, cmd.InOrStdin())
+					if err != nil {
+						return err
+					}
+					if !confirmed {
+						continue
+					}
+				}
+				r.Apply(conf)
+				applied++
+			}
+			ok, errs := config.Check(conf)
+			if !ok {
+				for _, e := range errs {
+					log.Error(e)
+				}
+				return cli.NewExitError(
+					cli.ExitConfigInvalid,
+					fmt.Errorf(
+						"%d issue(s) in %s can't be repaired automatically",
+						len(errs),
+						conf.ConfigFile,
+					),
+				)
+			}
+			mgr.SetBackup(!noBackup)
+			err = mgr.Write(conf)
+			if err != nil {
+				return err
+			}
+			log.Infof("Repaired %d field(s) in %s", applied, conf.ConfigFile)
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	c.Flags().BoolVar(
+		&yes,
+		"yes",
+		false,
+		"Apply the fixes without asking for confirmation",
+	)
+	addNoBackupFlag(c, &noBackup)
+	return c
+}
@@ -10,15 +10,33 @@
 package redpanda_test
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/redpanda"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"gopkg.in/yaml.v2"
 )
 
 func TestSetCmd(t *testing.T) {
@@ -75,6 +93,14 @@ func TestSetCmd(t *testing.T) {
 				"enable_memory_locking":      false,
 				"tune_fstrim":                false,
 				"tune_coredump":              false,
+				"tune_swapfile":              false,
+				"tune_dirty_pages":           false,
+				"tune_disk_nr_requests":      false,
+				"tune_nic_queues":            false,
+				"tune_cstates":               false,
+				"tune_max_map_count":         false,
+				"tune_fd_limit":              false,
+				"tune_mount_options":         false,
 				"coredump_dir":               "/var/lib/redpanda/coredump",
 			},
 		},
@@ -119,8 +145,8 @@ func TestSetCmd(t *testing.T) {
 		{
 			name:      "it should fail if the format isn't supported",
 			key:       "redpanda",
-			value:     `node_id=1`,
-			args:      []string{"--format", "toml"},
+			value:     "<node_id>1</node_id>",
+			args:      []string{"--format", "xml"},
 			expectErr: true,
 		},
 		{
@@ -289,3 +315,1532 @@ func TestInitNode(t *testing.T) {
 	val := v.Get("node_uuid")
 	require.NotEmpty(t, val)
 }
+
+func TestResetUUID(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		before      func(fs afero.Fs, conf *config.Config)
+		expectedErr string
+	}{
+		{
+			name: "it should regenerate and write the UUID",
+			args: []string{"--confirm"},
+		},
+		{
+			name: "it should ignore the PID file and hidden files in the data directory",
+			args: []string{"--confirm"},
+			before: func(fs afero.Fs, conf *config.Config) {
+				afero.WriteFile(fs, conf.PIDFile(), []byte("1"), 0644)
+				afero.WriteFile(fs, filepath.Join(conf.Redpanda.Directory, ".rpk-check"), []byte{}, 0644)
+			},
+		},
+		{
+			name:        "it should fail if --confirm isn't passed",
+			expectedErr: "resetting node_uuid is destructive for a node which already joined a cluster. Pass --confirm to reset it",
+		},
+		{
+			name: "it should fail if the data directory has data",
+			args: []string{"--confirm"},
+			before: func(fs afero.Fs, conf *config.Config) {
+				fs.MkdirAll(filepath.Join(conf.Redpanda.Directory, "redpanda"), 0755)
+			},
+			expectedErr: "/var/lib/redpanda/data has data, so the node has likely joined a cluster already. Pass --force to reset node_uuid anyway",
+		},
+		{
+			name: "it should reset the UUID if the data directory has data with --force",
+			args: []string{"--confirm", "--force"},
+			before: func(fs afero.Fs, conf *config.Config) {
+				fs.MkdirAll(filepath.Join(conf.Redpanda.Directory, "redpanda"), 0755)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			conf.NodeUuid = "cloned-uuid"
+			err := mgr.Write(conf)
+			require.NoError(st, err)
+			if tt.before != nil {
+				tt.before(fs, conf)
+			}
+
+			c := redpanda.NewConfigCommand(fs, mgr)
+			c.SetArgs(append([]string{"reset-uuid", "--config", conf.ConfigFile}, tt.args...))
+			err = c.Execute()
+
+			written, rErr := config.NewManager(fs).Read(conf.ConfigFile)
+			require.NoError(st, rErr)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				require.Equal(st, "cloned-uuid", written.NodeUuid)
+				return
+			}
+			require.NoError(st, err)
+			require.NotEmpty(st, written.NodeUuid)
+			require.NotEqual(st, "cloned-uuid", written.NodeUuid)
+		})
+	}
+}
+
+func TestSetCmdExitCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		readOnly     bool
+		args         []string
+		expectedCode int
+	}{
+		{
+			name:         "it should exit with 0 if the value was set",
+			args:         []string{"redpanda.node_id", "2"},
+			expectedCode: cli.ExitOK,
+		},
+		{
+			name:         "it should exit with the config-invalid code if the result is invalid",
+			args:         []string{"redpanda.rpc_server.port", "0"},
+			expectedCode: cli.ExitConfigInvalid,
+		},
+		{
+			name:         "it should exit with the permission code if the config can't be written",
+			readOnly:     true,
+			args:         []string{"redpanda.node_id", "2"},
+			expectedCode: cli.ExitPermission,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var fs afero.Fs = afero.NewMemMapFs()
+			conf := config.Default()
+			err := config.NewManager(fs).Write(conf)
+			require.NoError(st, err)
+			if tt.readOnly {
+				fs = afero.NewReadOnlyFs(fs)
+			}
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			args := append([]string{"set"}, tt.args...)
+			c.SetArgs(append(args, "--config", conf.ConfigFile))
+			err = c.Execute()
+			require.Equal(st, tt.expectedCode, cli.ExitCode(err))
+		})
+	}
+}
+
+func TestSetCmdCompletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:     "it should suggest the known config keys",
+			args:     []string{"redpanda.data"},
+			expected: []string{"redpanda.data_directory"},
+		},
+		{
+			name: "it should suggest nested keys",
+			args: []string{"rpk.kafka_api.tls."},
+			expected: []string{
+				"rpk.kafka_api.tls.cert_file",
+				"rpk.kafka_api.tls.key_file",
+				"rpk.kafka_api.tls.truststore_file",
+			},
+			unexpected: []string{"rpk.admin_api.tls.cert_file"},
+		},
+		{
+			name: "it should suggest the SASL mechanisms",
+			args: []string{"rpk.kafka_api.sasl.type", ""},
+			expected: []string{
+				"SCRAM-SHA-256",
+				"SCRAM-SHA-512",
+			},
+		},
+		{
+			name:     "it should suggest the well-known IO settings",
+			args:     []string{"rpk.well_known_io", "aws:i3.l"},
+			expected: []string{"aws:i3.large:default"},
+		},
+		{
+			name:     "it should suggest boolean values for tuner flags",
+			args:     []string{"rpk.tune_cpu", ""},
+			expected: []string{"true", "false"},
+		},
+		{
+			name:       "it shouldn't suggest values for free-form keys",
+			args:       []string{"redpanda.data_directory", ""},
+			unexpected: []string{"true", "false"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			c := redpanda.NewConfigCommand(fs, mgr)
+			var out bytes.Buffer
+			c.SetOut(&out)
+			c.SetArgs(append([]string{cobra.ShellCompRequestCmd, "set"}, tt.args...))
+			err := c.Execute()
+			require.NoError(t, err)
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			// The last line holds the completion directive.
+			completions := lines[:len(lines)-1]
+			for _, e := range tt.expected {
+				require.Contains(t, completions, e)
+			}
+			for _, u := range tt.unexpected {
+				require.NotContains(t, completions, u)
+			}
+		})
+	}
+}
+
+func TestGetCmd(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedOutput string
+		expectedErrMsg string
+	}{
+		{
+			name:           "it should print single values as text by default",
+			args:           []string{"redpanda.kafka_api.0.port"},
+			expectedOutput: "9092\n",
+		},
+		{
+			name:           "it should print single values as JSON",
+			args:           []string{"redpanda.data_directory", "--format", "json"},
+			expectedOutput: "\"/var/lib/redpanda/data\"\n",
+		},
+		{
+			name:           "it should print objects as YAML in the text format",
+			args:           []string{"redpanda.rpc_server"},
+			expectedOutput: "address: 0.0.0.0\nport: 33145\n",
+		},
+		{
+			name: "it should print lists as JSON",
+			args: []string{"redpanda.kafka_api", "--format", "json"},
+			expectedOutput: `[
+  {
+    "address": "0.0.0.0",
+    "port": 9092
+  }
+]
+`,
+		},
+		{
+			name:           "it should print objects as YAML",
+			args:           []string{"redpanda.rpc_server", "--format", "yaml"},
+			expectedOutput: "address: 0.0.0.0\nport: 33145\n",
+		},
+		{
+			name:           "it should fail if the key is missing",
+			args:           []string{"redpanda.missing"},
+			expectedErrMsg: "key 'redpanda.missing' not found in /etc/redpanda/redpanda.yaml",
+		},
+		{
+			name:           "it should fail if the format is unsupported",
+			args:           []string{"rpk", "--format", "toml"},
+			expectedErrMsg: "unsupported format 'toml', it must be 'text', 'json' or 'yaml'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			conf := config.Default()
+			err := config.NewManager(fs).Write(conf)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetOut(&out)
+			args := append([]string{"get"}, tt.args...)
+			c.SetArgs(append(args, "--config", conf.ConfigFile))
+			err = c.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestFromFlatCmd(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		check  func(*testing.T, *config.Config)
+		expErr string
+	}{
+		{
+			name: "it should build the config from the flattened keys",
+			input: `config_file=/etc/redpanda/redpanda.yaml
+redpanda.data_directory=/var/lib/redpanda/data
+redpanda.node_id=2
+redpanda.rpc_server=0.0.0.0:33145
+
+redpanda.seed_servers.1=192.168.0.2:33145
+redpanda.seed_servers.0=192.168.0.1:33145
+redpanda.kafka_api.0=internal://0.0.0.0:9092
+redpanda.kafka_api_tls.0.name=internal
+redpanda.kafka_api_tls.0.enabled=true
+rpk.tune_cpu=true
+`,
+			check: func(t *testing.T, c *config.Config) {
+				require.Equal(t, 2, c.Redpanda.Id)
+				require.Equal(t, []config.SeedServer{
+					{Host: config.SocketAddress{Address: "192.168.0.1", Port: 33145}},
+					{Host: config.SocketAddress{Address: "192.168.0.2", Port: 33145}},
+				}, c.Redpanda.SeedServers)
+				require.Equal(t, []config.NamedSocketAddress{{
+					SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 9092},
+					Name:          "internal",
+				}}, c.Redpanda.KafkaApi)
+				require.Equal(t, []config.ServerTLS{{
+					Name:    "internal",
+					Enabled: true,
+				}}, c.Redpanda.KafkaApiTLS)
+				require.True(t, c.Rpk.TuneCpu)
+			},
+		},
+		{
+			name:   "it should fail if a line isn't a key=value pair",
+			input:  "redpanda.node_id=1\nredpanda.data_directory\n",
+			expErr: "line 2: expected key=value, got 'redpanda.data_directory'",
+		},
+		{
+			name: "it should fail if the resulting config is invalid",
+			input: `redpanda.node_id=1
+redpanda.rpc_server=0.0.0.0:33145
+`,
+			expErr: "redpanda.data_directory can't be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			var out bytes.Buffer
+			c.SetIn(strings.NewReader(tt.input))
+			c.SetOut(&out)
+			c.SetArgs([]string{"from-flat"})
+			err := c.Execute()
+			if tt.expErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+
+			path := "/etc/redpanda/redpanda.yaml"
+			err = afero.WriteFile(fs, path, out.Bytes(), 0644)
+			require.NoError(t, err)
+			conf, err := config.NewManager(fs).Read(path)
+			require.NoError(t, err)
+			tt.check(t, conf)
+		})
+	}
+}
+
+func TestPullCmd(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		response  string
+		expectErr string
+		check     func(*testing.T, *config.Config)
+	}{
+		{
+			name:   "it should merge the cluster properties",
+			status: http.StatusOK,
+			response: `{"log_segment_size":536870912,` +
+				`"group_topic_partitions":16,` +
+				`"enable_idempotence":true,` +
+				`"superusers":["admin"]}`,
+			check: func(st *testing.T, conf *config.Config) {
+				require.Equal(st, 536870912, *conf.Redpanda.LogSegmentSize)
+				require.Equal(st, 16, *conf.Redpanda.GroupTopicPartitions)
+				require.Equal(st, []string{"admin"}, conf.Redpanda.Superusers)
+				require.Equal(st, true, conf.Redpanda.Other["enable_idempotence"])
+				// Local properties which weren't pulled are kept.
+				require.Equal(st, "keep", conf.Redpanda.Other["local_property"])
+			},
+		},
+		{
+			name:   "it shouldn't overwrite node-specific properties",
+			status: http.StatusOK,
+			response: `{"node_id":7,` +
+				`"data_directory":"/mnt/other",` +
+				`"rpc_server":{"address":"10.0.0.7","port":33145},` +
+				`"seed_servers":[],` +
+				`"cloud_storage_secret_key":"s3cr3t",` +
+				`"log_segment_size":536870912}`,
+			check: func(st *testing.T, conf *config.Config) {
+				require.Equal(st, 1, conf.Redpanda.Id)
+				require.Equal(st, "/var/lib/redpanda/data", conf.Redpanda.Directory)
+				require.Equal(st, "0.0.0.0", conf.Redpanda.RPCServer.Address)
+				require.Nil(st, conf.Redpanda.CloudStorageSecretKey)
+				require.Equal(st, 536870912, *conf.Redpanda.LogSegmentSize)
+			},
+		},
+		{
+			name:      "it should fail if the config can't be fetched",
+			status:    http.StatusInternalServerError,
+			response:  "oops",
+			expectErr: "couldn't fetch the config from",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			ts := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(st, "/v1/config", r.URL.Path)
+					w.WriteHeader(tt.status)
+					w.Write([]byte(tt.response))
+				}),
+			)
+			defer ts.Close()
+
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			conf.Redpanda.Id = 1
+			conf.Redpanda.Other = map[string]interface{}{
+				"local_property": "keep",
+			}
+			err := mgr.Write(conf)
+			require.NoError(st, err)
+
+			c := redpanda.NewConfigCommand(fs, mgr)
+			c.SetArgs([]string{
+				"pull",
+				"--from", ts.URL,
+				"--config", conf.ConfigFile,
+			})
+			err = c.Execute()
+			if tt.expectErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(st, err)
+
+			pulled, err := config.NewManager(fs).Read(conf.ConfigFile)
+			require.NoError(st, err)
+			tt.check(st, pulled)
+		})
+	}
+}
+
+func TestPullCmdRequiresFrom(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	c := redpanda.NewConfigCommand(fs, mgr)
+	c.SetArgs([]string{"pull"})
+	err := c.Execute()
+	require.EqualError(t, err, `required flag(s) "from" not set`)
+}
+
+func TestToConfigMapCmd(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	conf := config.Default()
+	conf.Redpanda.Id = 3
+	conf.Rpk.KafkaApi.SASL = &config.SASL{
+		User:      "admin",
+		Password:  "s3cr3t",
+		Mechanism: "SCRAM-SHA-256",
+	}
+	err := mgr.Write(conf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	c := redpanda.NewConfigCommand(fs, mgr)
+	c.SetOut(&out)
+	c.SetArgs([]string{
+		"to-configmap",
+		"--name", "redpanda-config",
+		"--namespace", "streaming",
+		"--config", conf.ConfigFile,
+	})
+	err = c.Execute()
+	require.NoError(t, err)
+
+	manifest := struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+		Metadata   struct {
+			Name      string `yaml:"name"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"metadata"`
+		Data map[string]string `yaml:"data"`
+	}{}
+	err = yaml.Unmarshal(out.Bytes(), &manifest)
+	require.NoError(t, err)
+	require.Equal(t, "v1", manifest.APIVersion)
+	require.Equal(t, "ConfigMap", manifest.Kind)
+	require.Equal(t, "redpanda-config", manifest.Metadata.Name)
+	require.Equal(t, "streaming", manifest.Metadata.Namespace)
+
+	embedded, ok := manifest.Data["redpanda.yaml"]
+	require.True(t, ok)
+	require.NotContains(t, embedded, "s3cr3t")
+	embeddedConf := config.Config{}
+	err = yaml.Unmarshal([]byte(embedded), &embeddedConf)
+	require.NoError(t, err)
+	require.Equal(t, 3, embeddedConf.Redpanda.Id)
+	require.Equal(t, conf.Redpanda.KafkaApi, embeddedConf.Redpanda.KafkaApi)
+	require.Equal(
+		t,
+		"${secret:rpk_kafka_api_sasl_password}",
+		embeddedConf.Rpk.KafkaApi.SASL.Password,
+	)
+}
+
+func TestToConfigMapCmdRequiresName(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	c := redpanda.NewConfigCommand(fs, mgr)
+	c.SetArgs([]string{"to-configmap"})
+	err := c.Execute()
+	require.EqualError(t, err, `required flag(s) "name" not set`)
+}
+
+func TestToHelmValuesCmd(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := config.Default()
+	conf.Redpanda.Directory = "/mnt/redpanda/data"
+	conf.Redpanda.KafkaApi = []config.NamedSocketAddress{
+		{Name: "internal", SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 9092}},
+		{Name: "external", SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 19092}},
+	}
+	conf.Redpanda.KafkaApiTLS = []config.ServerTLS{{
+		Name:              "external",
+		Enabled:           true,
+		CertFile:          "/etc/redpanda/certs/node.crt",
+		KeyFile:           "/etc/redpanda/certs/node.key",
+		RequireClientAuth: true,
+	}}
+	sasl := true
+	conf.Redpanda.EnableSASL = &sasl
+	conf.Redpanda.Other = map[string]interface{}{"auto_create_topics_enabled": true}
+	conf.Rpk.NodeLabels = map[string]string{"team": "storage"}
+	conf.Rpk.TuneNetwork = true
+	bs, err := yaml.Marshal(conf)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, conf.ConfigFile, bs, 0644)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+	c.SetOut(&out)
+	c.SetArgs([]string{"to-helm-values", "--config", conf.ConfigFile})
+	err = c.Execute()
+	require.NoError(t, err)
+
+	values := map[string]interface{}{}
+	err = yaml.Unmarshal(out.Bytes(), &values)
+	require.NoError(t, err)
+	// Returns the value at the given dot-separated path.
+	get := func(path string) interface{} {
+		var v interface{} = values
+		for _, k := range strings.Split(path, ".") {
+			m, ok := v.(map[interface{}]interface{})
+			if !ok {
+				m2, ok := v.(map[string]interface{})
+				require.True(t, ok, "%s isn't a map", path)
+				v = m2[k]
+				continue
+			}
+			v = m[k]
+		}
+		return v
+	}
+	require.Equal(t, 9092, get("listeners.kafka.port"))
+	require.Nil(t, get("listeners.kafka.tls"))
+	require.Equal(t, 19092, get("listeners.kafka.external.external.port"))
+	require.Equal(t, true, get("listeners.kafka.external.external.tls.enabled"))
+	require.Equal(t, true, get("listeners.kafka.external.external.tls.requireClientAuth"))
+	require.Equal(t, 9644, get("listeners.admin.port"))
+	require.Equal(t, 33145, get("listeners.rpc.port"))
+	require.Equal(t, true, get("tls.enabled"))
+	require.Equal(t, true, get("auth.sasl.enabled"))
+	require.Equal(t, "/mnt/redpanda/data", get("storage.hostPath"))
+	require.Equal(t, "storage", get("commonLabels.team"))
+	require.Equal(t, true, get("tuning.tune_network"))
+	require.Nil(t, get("tuning.tune_cpu"))
+	require.Equal(t, true, get("config.cluster.auto_create_topics_enabled"))
+	// The certificates' paths have no equivalent in the values.
+	require.NotContains(t, out.String(), "node.crt")
+}
+
+func TestApplyCmd(t *testing.T) {
+	const desiredPath = "/tmp/desired.yaml"
+	tests := []struct {
+		name           string
+		args           []string
+		input          string
+		modify         func(*config.Config)
+		expectedOutput []string
+		expectedErr    string
+		expectApplied  bool
+	}{
+		{
+			name: "it should apply the changes without asking with --yes",
+			args: []string{"--yes"},
+			modify: func(c *config.Config) {
+				c.Redpanda.Id = 2
+				c.Redpanda.KafkaApi[0].Port = 9093
+			},
+			expectedOutput: []string{
+				"~ redpanda.kafka_api.0.port: 9092 -> 9093",
+				"~ redpanda.node_id: 1 -> 2",
+			},
+			expectApplied: true,
+		},
+		{
+			name:  "it should apply the changes if they're confirmed",
+			input: "y\n",
+			modify: func(c *config.Config) {
+				c.Redpanda.Id = 2
+				c.Redpanda.Other = map[string]interface{}{"enable_idempotence": true}
+			},
+			expectedOutput: []string{
+				"~ redpanda.node_id: 1 -> 2",
+				"+ redpanda.enable_idempotence: true",
+			},
+			expectApplied: true,
+		},
+		{
+			name:  "it shouldn't apply the changes if they're rejected",
+			input: "n\n",
+			modify: func(c *config.Config) {
+				c.Redpanda.Id = 2
+			},
+			expectedOutput: []string{"~ redpanda.node_id: 1 -> 2"},
+		},
+		{
+			name: "it should refuse to apply an invalid config",
+			args: []string{"--yes"},
+			modify: func(c *config.Config) {
+				c.Redpanda.Id = -1
+			},
+			expectedErr: "refusing to apply /tmp/desired.yaml, which is invalid:" +
+				" redpanda.node_id can't be a negative integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			current := config.Default()
+			current.Redpanda.Id = 1
+			err := mgr.Write(current)
+			require.NoError(st, err)
+			before, err := afero.ReadFile(fs, current.ConfigFile)
+			require.NoError(st, err)
+
+			desired := config.Default()
+			desired.Redpanda.Id = 1
+			tt.modify(desired)
+			bs, err := yaml.Marshal(desired)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, desiredPath, bs, 0644)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetOut(&out)
+			c.SetIn(strings.NewReader(tt.input))
+			c.SetArgs(append(
+				[]string{"apply", desiredPath, "--config", current.ConfigFile},
+				tt.args...,
+			))
+			err = c.Execute()
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+			} else {
+				require.NoError(st, err)
+			}
+			for _, o := range tt.expectedOutput {
+				require.Contains(st, out.String(), o)
+			}
+
+			after, err := afero.ReadFile(fs, current.ConfigFile)
+			require.NoError(st, err)
+			if !tt.expectApplied {
+				require.Equal(st, string(before), string(after))
+				return
+			}
+			conf, err := config.NewManager(fs).Read(current.ConfigFile)
+			require.NoError(st, err)
+			require.Equal(st, desired.Redpanda, conf.Redpanda)
+			// The previous config is backed up.
+			files, err := afero.ReadDir(fs, filepath.Dir(current.ConfigFile))
+			require.NoError(st, err)
+			backups := 0
+			for _, f := range files {
+				if strings.HasSuffix(f.Name(), ".bk") {
+					backups++
+				}
+			}
+			require.Equal(st, 1, backups)
+		})
+	}
+}
+
+func TestApplyCmdDetectOnly(t *testing.T) {
+	const desiredPath = "/tmp/desired.yaml"
+	tests := []struct {
+		name             string
+		desiredID        int
+		expectedOutput   string
+		expectedErr      string
+		expectedExitCode int
+	}{
+		{
+			name:             "it should exit with 0 if there's no drift",
+			desiredID:        1,
+			expectedOutput:   "/etc/redpanda/redpanda.yaml is up to date",
+			expectedExitCode: cli.ExitOK,
+		},
+		{
+			name:           "it should exit with a non-zero code if there's drift",
+			desiredID:      2,
+			expectedOutput: "~ redpanda.node_id: 1 -> 2",
+			expectedErr: "/etc/redpanda/redpanda.yaml differs from" +
+				" /tmp/desired.yaml in 1 field(s)",
+			expectedExitCode: cli.ExitConfigDrift,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			current := config.Default()
+			current.Redpanda.Id = 1
+			err := mgr.Write(current)
+			require.NoError(st, err)
+			before, err := afero.ReadFile(fs, current.ConfigFile)
+			require.NoError(st, err)
+
+			desired := config.Default()
+			desired.Redpanda.Id = tt.desiredID
+			bs, err := yaml.Marshal(desired)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, desiredPath, bs, 0644)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetOut(&out)
+			c.SetArgs([]string{
+				"apply", desiredPath,
+				"--config", current.ConfigFile,
+				"--detect-only",
+			})
+			err = c.Execute()
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+			} else {
+				require.NoError(st, err)
+			}
+			require.Equal(st, tt.expectedExitCode, cli.ExitCode(err))
+			require.Contains(st, out.String(), tt.expectedOutput)
+
+			// Nothing should be written.
+			after, err := afero.ReadFile(fs, current.ConfigFile)
+			require.NoError(st, err)
+			require.Equal(st, string(before), string(after))
+		})
+	}
+}
+
+func TestAuditCmd(t *testing.T) {
+	tests := []struct {
+		name           string
+		modify         func(*config.Config)
+		expectedOutput string
+	}{
+		{
+			name:           "it shouldn't list anything if all fields are recognized",
+			modify:         func(*config.Config) {},
+			expectedOutput: "",
+		},
+		{
+			name: "it should list the unrecognized fields",
+			modify: func(c *config.Config) {
+				c.Other = map[string]interface{}{"unknown_section": 1}
+				c.Redpanda.Other = map[string]interface{}{
+					"enable_idempotence":         true,
+					"auto_create_topics_enabled": false,
+				}
+			},
+			expectedOutput: "redpanda.auto_create_topics_enabled\n" +
+				"redpanda.enable_idempotence\n" +
+				"unknown_section\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			conf := config.Default()
+			tt.modify(conf)
+			bs, err := yaml.Marshal(conf)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, conf.ConfigFile, bs, 0644)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetOut(&out)
+			c.SetArgs([]string{"audit", "--config", conf.ConfigFile})
+			err = c.Execute()
+			require.NoError(st, err)
+			require.Equal(st, tt.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestExportTuners(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := config.Default()
+	conf.Rpk.TuneNetwork = true
+	conf.Rpk.CoredumpDir = "/var/lib/redpanda/coredump"
+	conf.Rpk.KafkaApi.Brokers = []string{"127.0.0.1:9092"}
+	bs, err := yaml.Marshal(conf)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, conf.ConfigFile, bs, 0644)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+	c.SetOut(&out)
+	c.SetArgs([]string{"export-tuners", "--config", conf.ConfigFile})
+	err = c.Execute()
+	require.NoError(t, err)
+
+	exported := map[string]map[string]interface{}{}
+	err = yaml.Unmarshal(out.Bytes(), &exported)
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	rpk, ok := exported["rpk"]
+	require.True(t, ok, "the output should contain only the rpk block")
+	require.Equal(t, true, rpk["tune_network"])
+	require.Equal(t, false, rpk["tune_cpu"])
+	require.Equal(t, conf.Rpk.CoredumpDir, rpk["coredump_dir"])
+	for k := range rpk {
+		require.NotContains(
+			t,
+			[]string{"kafka_api", "admin_api", "tls", "sasl", "enable_usage_stats"},
+			k,
+		)
+	}
+}
+
+func TestOutputFlag(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "get",
+			args: []string{"get", "redpanda.rpc_server"},
+		},
+		{
+			name: "to-configmap",
+			args: []string{"to-configmap", "--name", "redpanda"},
+		},
+		{
+			name: "to-helm-values",
+			args: []string{"to-helm-values"},
+		},
+		{
+			name: "export-tuners",
+			args: []string{"export-tuners"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			const outputPath = "/tmp/rpk-output"
+			fs := afero.NewMemMapFs()
+			conf := config.Default()
+			err := config.NewManager(fs).Write(conf)
+			require.NoError(st, err)
+			args := append(tt.args, "--config", conf.ConfigFile)
+
+			var stdout bytes.Buffer
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetOut(&stdout)
+			c.SetArgs(args)
+			err = c.Execute()
+			require.NoError(st, err)
+			require.NotEmpty(st, stdout.String())
+
+			var out bytes.Buffer
+			c = redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetOut(&out)
+			c.SetArgs(append(args, "--output", outputPath))
+			err = c.Execute()
+			require.NoError(st, err)
+			require.Empty(st, out.String())
+			written, err := afero.ReadFile(fs, outputPath)
+			require.NoError(st, err)
+			// The file has the same payload as stdout would, and nothing
+			// else.
+			require.Equal(st, stdout.String(), string(written))
+		})
+	}
+}
+
+func TestImportTuners(t *testing.T) {
+	const profilePath = "/tmp/tuners.yaml"
+	const profile = `rpk:
+  tune_network: true
+  tune_cpu: false
+  coredump_dir: /mnt/coredump
+  smp: 2
+`
+	tests := []struct {
+		name           string
+		profile        string
+		args           []string
+		expectedErrMsg string
+		check          func(st *testing.T, before, after *config.Config)
+	}{
+		{
+			name:    "it should replace the tuner fields and keep the node-specific ones",
+			profile: profile,
+			check: func(st *testing.T, before, after *config.Config) {
+				require.True(st, after.Rpk.TuneNetwork)
+				require.False(st, after.Rpk.TuneCpu)
+				require.False(st, after.Rpk.TuneAioEvents)
+				require.NotNil(st, after.Rpk.SMP)
+				require.Equal(st, 2, *after.Rpk.SMP)
+				require.Equal(st, before.Rpk.CoredumpDir, after.Rpk.CoredumpDir)
+				require.Equal(st, before.Rpk.KafkaApi, after.Rpk.KafkaApi)
+				require.Equal(st, before.Redpanda, after.Redpanda)
+			},
+		},
+		{
+			name:    "it should replace the node-specific fields with --keep-node-specific=false",
+			profile: profile,
+			args:    []string{"--keep-node-specific=false"},
+			check: func(st *testing.T, _, after *config.Config) {
+				require.True(st, after.Rpk.TuneNetwork)
+				require.Equal(st, "/mnt/coredump", after.Rpk.CoredumpDir)
+			},
+		},
+		{
+			name: "it should fail if the profile has fields which aren't tuner ones",
+			profile: `rpk:
+  tune_network: true
+  kafka_api:
+    brokers:
+    - 10.0.0.1:9092
+`,
+			expectedErrMsg: "couldn't import /tmp/tuners.yaml: the profile" +
+				" contains fields which don't configure the tuners: kafka_api",
+		},
+		{
+			name:           "it should fail if there's no rpk block",
+			profile:        "redpanda:\n  node_id: 2\n",
+			expectedErrMsg: "/tmp/tuners.yaml doesn't contain an 'rpk' block",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			conf := config.Default()
+			conf.Rpk.TuneCpu = true
+			conf.Rpk.TuneAioEvents = true
+			conf.Rpk.CoredumpDir = "/var/lib/redpanda/coredump"
+			conf.Rpk.KafkaApi.Brokers = []string{"127.0.0.1:9092"}
+			bs, err := yaml.Marshal(conf)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, conf.ConfigFile, bs, 0644)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, profilePath, []byte(tt.profile), 0644)
+			require.NoError(st, err)
+
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			args := []string{
+				"import-tuners", profilePath, "--config", conf.ConfigFile,
+			}
+			c.SetArgs(append(args, tt.args...))
+			err = c.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+			after, err := config.NewManager(fs).Read(conf.ConfigFile)
+			require.NoError(st, err)
+			tt.check(st, conf, after)
+		})
+	}
+}
+
+func TestWhichCmd(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	cwdConfig := filepath.Join(cwd, "redpanda.yaml")
+	etcConfig := "/etc/redpanda/redpanda.yaml"
+	homeConfig := filepath.Join(home, "redpanda.yaml")
+
+	tests := []struct {
+		name           string
+		existing       []string
+		args           []string
+		expectedOutput string
+		expectedLogs   []string
+		expectErr      bool
+	}{
+		{
+			name:           "the config in /etc/redpanda should win over the one in the home dir",
+			existing:       []string{homeConfig, etcConfig},
+			expectedOutput: etcConfig + "\n",
+			expectedLogs: []string{
+				"1. " + cwdConfig + " (not found)",
+				"2. " + etcConfig + " (used)",
+				"3. " + homeConfig + " (found)",
+			},
+		},
+		{
+			name:           "the config in the current dir should win over the one in /etc/redpanda",
+			existing:       []string{etcConfig, cwdConfig},
+			expectedOutput: cwdConfig + "\n",
+			expectedLogs: []string{
+				"1. " + cwdConfig + " (used)",
+				"2. " + etcConfig + " (found)",
+			},
+		},
+		{
+			name:           "it should print the path passed with --config",
+			existing:       []string{etcConfig},
+			args:           []string{"--config", "/tmp/redpanda.yaml"},
+			expectedOutput: "/tmp/redpanda.yaml\n",
+		},
+		{
+			name:      "it should fail if no config is found",
+			expectErr: true,
+			expectedLogs: []string{
+				"1. " + cwdConfig + " (not found)",
+				"2. " + etcConfig + " (not found)",
+				"3. " + homeConfig + " (not found)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			for _, path := range tt.existing {
+				err := afero.WriteFile(fs, path, []byte{}, 0644)
+				require.NoError(st, err)
+			}
+			var out, logs bytes.Buffer
+			logrus.SetOutput(&logs)
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetOut(&out)
+			c.SetArgs(append([]string{"which"}, tt.args...))
+			err := c.Execute()
+			for _, l := range tt.expectedLogs {
+				require.Contains(st, logs.String(), l)
+			}
+			if tt.expectErr {
+				require.Error(st, err)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestNoBackupFlag(t *testing.T) {
+	const desiredPath = "/tmp/desired.yaml"
+	tests := []struct {
+		name            string
+		args            []string
+		expectedBackups int
+	}{
+		{
+			name:            "set should back up the config by default",
+			args:            []string{"set", "redpanda.node_id", "2"},
+			expectedBackups: 1,
+		},
+		{
+			name: "set shouldn't back up the config with --no-backup",
+			args: []string{"set", "redpanda.node_id", "2", "--no-backup"},
+		},
+		{
+			name:            "apply should back up the config by default",
+			args:            []string{"apply", desiredPath, "--yes"},
+			expectedBackups: 1,
+		},
+		{
+			name: "apply shouldn't back up the config with --no-backup",
+			args: []string{"apply", desiredPath, "--yes", "--no-backup"},
+		},
+		{
+			name:            "bootstrap should back up the config by default",
+			args:            []string{"bootstrap", "--id", "2", "--self", "10.0.0.1"},
+			expectedBackups: 1,
+		},
+		{
+			name: "bootstrap shouldn't back up the config with --no-backup",
+			args: []string{"bootstrap", "--id", "2", "--self", "10.0.0.1", "--no-backup"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			current := config.Default()
+			current.Redpanda.Id = 1
+			err := mgr.Write(current)
+			require.NoError(st, err)
+
+			desired := config.Default()
+			desired.Redpanda.Id = 2
+			bs, err := yaml.Marshal(desired)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, desiredPath, bs, 0644)
+			require.NoError(st, err)
+
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetArgs(append(tt.args, "--config", current.ConfigFile))
+			err = c.Execute()
+			require.NoError(st, err)
+
+			conf, err := config.NewManager(fs).Read(current.ConfigFile)
+			require.NoError(st, err)
+			require.Equal(st, 2, conf.Redpanda.Id)
+			files, err := afero.ReadDir(fs, filepath.Dir(current.ConfigFile))
+			require.NoError(st, err)
+			backups := 0
+			for _, f := range files {
+				if strings.HasSuffix(f.Name(), ".bk") {
+					backups++
+				}
+			}
+			require.Equal(st, tt.expectedBackups, backups)
+		})
+	}
+}
+
+// Returns a self-signed, PEM-encoded cert for the given DNS names, which
+// expires after the given duration.
+func generateCert(t *testing.T, dnsNames []string, validFor time.Duration) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Vectorized"}},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCheckTLSCmd(t *testing.T) {
+	const certPath = "/etc/redpanda/certs/node.crt"
+	tests := []struct {
+		name             string
+		dnsNames         []string
+		validFor         time.Duration
+		expectedOutput   []string
+		expectedErr      string
+		expectedExitCode int
+	}{
+		{
+			name:             "it should pass if the cert covers the listeners",
+			dnsNames:         []string{"internal.redpanda.local", "*.redpanda.example.com"},
+			validFor:         365 * 24 * time.Hour,
+			expectedOutput:   []string{"The TLS certificates cover all the listeners"},
+			expectedExitCode: cli.ExitOK,
+		},
+		{
+			name:     "it should fail if the cert doesn't cover the advertised hostname",
+			dnsNames: []string{"internal.redpanda.local"},
+			validFor: 365 * 24 * time.Hour,
+			expectedOutput: []string{
+				"redpanda.kafka_api_tls.0: " + certPath + " doesn't cover" +
+					" 'node-0.redpanda.example.com', the address of listener 'external'",
+			},
+			expectedErr:      "found 1 issue(s) with the TLS certificates",
+			expectedExitCode: cli.ExitCheckFailed,
+		},
+		{
+			name:             "it should warn if the cert is about to expire",
+			dnsNames:         []string{"internal.redpanda.local", "*.redpanda.example.com"},
+			validFor:         24 * time.Hour,
+			expectedOutput:   []string{"redpanda.kafka_api_tls.0: " + certPath + " expires on"},
+			expectedExitCode: cli.ExitOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			conf.Redpanda.KafkaApi = []config.NamedSocketAddress{{
+				SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 9092},
+				Name:          "external",
+			}, {
+				SocketAddress: config.SocketAddress{Address: "internal.redpanda.local", Port: 9093},
+				Name:          "internal",
+			}}
+			conf.Redpanda.AdvertisedKafkaApi = []config.NamedSocketAddress{{
+				SocketAddress: config.SocketAddress{Address: "node-0.redpanda.example.com", Port: 9092},
+				Name:          "external",
+			}}
+			conf.Redpanda.KafkaApiTLS = []config.ServerTLS{{
+				Name:     "external",
+				Enabled:  true,
+				CertFile: certPath,
+				KeyFile:  "/etc/redpanda/certs/node.key",
+			}, {
+				Name:     "internal",
+				Enabled:  true,
+				CertFile: certPath,
+				KeyFile:  "/etc/redpanda/certs/node.key",
+			}}
+			err := mgr.Write(conf)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, certPath, generateCert(st, tt.dnsNames, tt.validFor), 0644)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := redpanda.NewConfigCommand(fs, mgr)
+			c.SetArgs([]string{"check-tls", "--config", conf.ConfigFile})
+			err = c.Execute()
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+			} else {
+				require.NoError(st, err)
+			}
+			require.Equal(st, tt.expectedExitCode, cli.ExitCode(err))
+			for _, o := range tt.expectedOutput {
+				require.Contains(st, out.String(), o)
+			}
+		})
+	}
+}
+
+func TestCheckClusterCmd(t *testing.T) {
+	const dir = "/etc/redpanda/cluster"
+	tests := []struct {
+		name             string
+		modify           func([]*config.Config)
+		expectedOutput   string
+		expectedErr      string
+		expectedExitCode int
+	}{
+		{
+			name:             "it should pass if the nodes can form a cluster",
+			modify:           func([]*config.Config) {},
+			expectedOutput:   "The 3 node config(s) can form a cluster",
+			expectedExitCode: cli.ExitOK,
+		},
+		{
+			name: "it should fail if the node IDs aren't unique",
+			modify: func(confs []*config.Config) {
+				confs[2].Redpanda.Id = 1
+			},
+			expectedOutput: "redpanda.node_id 1 is used by both " + dir +
+				"/node-1.yaml and " + dir + "/node-2.yaml",
+			expectedErr:      "found 1 issue(s) in the 3 node config(s)",
+			expectedExitCode: cli.ExitConfigInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			confs := []*config.Config{}
+			for i := 0; i < 3; i++ {
+				conf := config.Default()
+				conf.ConfigFile = filepath.Join(dir, fmt.Sprintf("node-%d.yaml", i))
+				conf.Redpanda.Id = i
+				conf.Redpanda.RPCServer = config.SocketAddress{
+					Address: fmt.Sprintf("10.0.0.%d", i+1),
+					Port:    33145,
+				}
+				if i > 0 {
+					conf.Redpanda.SeedServers = []config.SeedServer{{
+						Host: config.SocketAddress{Address: "10.0.0.1", Port: 33145},
+					}}
+				}
+				confs = append(confs, conf)
+			}
+			tt.modify(confs)
+			for _, conf := range confs {
+				err := config.NewManager(fs).Write(conf)
+				require.NoError(st, err)
+			}
+			// Only the .yaml files in the directory are read.
+			err := afero.WriteFile(fs, filepath.Join(dir, "README"), []byte("nodes"), 0644)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := redpanda.NewConfigCommand(fs, mgr)
+			c.SetArgs([]string{"check-cluster", dir})
+			err = c.Execute()
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+			} else {
+				require.NoError(st, err)
+			}
+			require.Equal(st, tt.expectedExitCode, cli.ExitCode(err))
+			require.Contains(st, out.String(), tt.expectedOutput)
+		})
+	}
+}
+
+func TestGenerateClusterCmd(t *testing.T) {
+	const (
+		templatePath = "/etc/redpanda/base.yaml"
+		dir          = "/etc/redpanda/cluster"
+	)
+	tests := []struct {
+		name             string
+		nodes            string
+		expectedOutput   string
+		expectedErr      string
+		expectedExitCode int
+	}{
+		{
+			name:             "it should write a config for each node",
+			nodes:            "0@10.0.0.1,1@10.0.0.2,2@10.0.0.3",
+			expectedOutput:   "Wrote 3 node config(s) to " + dir,
+			expectedExitCode: cli.ExitOK,
+		},
+		{
+			name:  "it should fail if the nodes on the same host use the same ports",
+			nodes: "0@10.0.0.1,1@10.0.0.1",
+			expectedOutput: "port 33145 on 10.0.0.1 is used by both" +
+				" redpanda.rpc_server (" + dir + "/redpanda-0.yaml) and" +
+				" redpanda.rpc_server (" + dir + "/redpanda-1.yaml)",
+			// The RPC, Kafka and admin ports clash, and the second
+			// node's seeds repeat the shared RPC address.
+			expectedErr:      "found 4 issue(s) in the 2 generated node config(s)",
+			expectedExitCode: cli.ExitConfigInvalid,
+		},
+		{
+			name:             "it should fail if a node ID is given more than once",
+			nodes:            "0@10.0.0.1,1@10.0.0.2,1@10.0.0.3",
+			expectedErr:      "node ID 1 is given more than once",
+			expectedExitCode: cli.ExitGeneric,
+		},
+		{
+			name:             "it should fail if a node isn't valid",
+			nodes:            "0@10.0.0.1,10.0.0.2",
+			expectedErr:      "'10.0.0.2' isn't a valid node, it must be 'id@host'",
+			expectedExitCode: cli.ExitGeneric,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			template := config.Default()
+			template.ConfigFile = templatePath
+			template.Redpanda.Directory = "/data/redpanda"
+			err := config.NewManager(fs).Write(template)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := redpanda.NewConfigCommand(fs, mgr)
+			c.SetArgs([]string{
+				"generate-cluster",
+				"--template", templatePath,
+				"--nodes", tt.nodes,
+				"--output-dir", dir,
+			})
+			err = c.Execute()
+			require.Equal(st, tt.expectedExitCode, cli.ExitCode(err))
+			require.Contains(st, out.String(), tt.expectedOutput)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				// No config is written.
+				exists, err := afero.DirExists(fs, dir)
+				require.NoError(st, err)
+				require.False(st, exists)
+				return
+			}
+			require.NoError(st, err)
+
+			var seeds []config.SeedServer
+			for i := 0; i < 3; i++ {
+				path := filepath.Join(dir, fmt.Sprintf("redpanda-%d.yaml", i))
+				conf, err := config.NewManager(fs).Read(path)
+				require.NoError(st, err)
+				require.Equal(st, i, conf.Redpanda.Id)
+				require.Equal(
+					st,
+					fmt.Sprintf("10.0.0.%d", i+1),
+					conf.Redpanda.RPCServer.Address,
+				)
+				require.Equal(st, "/data/redpanda", conf.Redpanda.Directory)
+				if i == 0 {
+					require.Empty(st, conf.Redpanda.SeedServers)
+					continue
+				}
+				require.Len(st, conf.Redpanda.SeedServers, 3)
+				if seeds == nil {
+					seeds = conf.Redpanda.SeedServers
+				}
+				require.Equal(st, seeds, conf.Redpanda.SeedServers)
+			}
+			// The generated configs can form a cluster.
+			c = redpanda.NewConfigCommand(fs, mgr)
+			c.SetArgs([]string{"check-cluster", dir})
+			require.NoError(st, c.Execute())
+		})
+	}
+}
+
+func TestRepairCmd(t *testing.T) {
+	tests := []struct {
+		name             string
+		args             []string
+		input            string
+		modify           func(*config.Config)
+		expectRepaired   bool
+		expectedOutput   []string
+		expectedErr      string
+		expectedExitCode int
+	}{
+		{
+			name: "it should set an empty data_directory to the default",
+			args: []string{"--yes"},
+			modify: func(c *config.Config) {
+				c.Redpanda.Directory = ""
+			},
+			expectRepaired: true,
+			expectedOutput: []string{
+				"redpanda.data_directory can't be empty. Set" +
+					" redpanda.data_directory to /var/lib/redpanda/data?",
+				"Repaired 1 field(s) in /etc/redpanda/redpanda.yaml",
+			},
+		},
+		{
+			name:  "it should set a port set to 0 to the default if confirmed",
+			input: "y\n",
+			modify: func(c *config.Config) {
+				c.Redpanda.KafkaApi[0].Port = 0
+			},
+			expectRepaired: true,
+			expectedOutput: []string{
+				"redpanda.kafka_api.0.port can't be 0. Set" +
+					" redpanda.kafka_api.0.port to 9092? (y/n/q)",
+				"Repaired 1 field(s) in /etc/redpanda/redpanda.yaml",
+			},
+		},
+		{
+			name:  "it shouldn't write the config if a fix is rejected",
+			input: "n\n",
+			modify: func(c *config.Config) {
+				c.Redpanda.RPCServer.Port = 0
+			},
+			expectedOutput:   []string{"redpanda.rpc_server.port can't be 0"},
+			expectedErr:      "1 issue(s) in /etc/redpanda/redpanda.yaml can't be repaired automatically",
+			expectedExitCode: cli.ExitConfigInvalid,
+		},
+		{
+			name: "it should report the issues which can't be fixed",
+			args: []string{"--yes"},
+			modify: func(c *config.Config) {
+				c.Redpanda.Directory = ""
+				c.Redpanda.Id = -1
+			},
+			expectedOutput:   []string{"redpanda.node_id can't be a negative integer"},
+			expectedErr:      "1 issue(s) in /etc/redpanda/redpanda.yaml can't be repaired automatically",
+			expectedExitCode: cli.ExitConfigInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			conf := config.Default()
+			tt.modify(conf)
+			// The invalid config can't be written through the manager.
+			bs, err := yaml.Marshal(conf)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, conf.ConfigFile, bs, 0644)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+			c.SetIn(strings.NewReader(tt.input))
+			c.SetArgs(append(
+				[]string{"repair", "--config", conf.ConfigFile},
+				tt.args...,
+			))
+			err = c.Execute()
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+			} else {
+				require.NoError(st, err)
+			}
+			require.Equal(st, tt.expectedExitCode, cli.ExitCode(err))
+			for _, o := range tt.expectedOutput {
+				require.Contains(st, out.String(), o)
+			}
+
+			after, err := afero.ReadFile(fs, conf.ConfigFile)
+			require.NoError(st, err)
+			if !tt.expectRepaired {
+				require.Equal(st, string(bs), string(after))
+				return
+			}
+			repaired, err := config.NewManager(fs).Read(conf.ConfigFile)
+			require.NoError(st, err)
+			require.Equal(st, config.Default().Redpanda, repaired.Redpanda)
+		})
+	}
+}
+
+func TestPrintCmd(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := config.Default()
+	conf.Redpanda.Id = 1
+	err := config.NewManager(fs).Write(conf)
+	require.NoError(t, err)
+	before, err := afero.ReadFile(fs, conf.ConfigFile)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	c := redpanda.NewConfigCommand(fs, config.NewManager(fs))
+	c.SetOut(&out)
+	c.SetArgs([]string{
+		"print",
+		"--config", conf.ConfigFile,
+		"--sources",
+		"--set", "redpanda.node_id=3",
+	})
+	err = c.Execute()
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "node_id: 3 # set\n")
+	require.Contains(t, out.String(), "data_directory: /var/lib/redpanda/data # file\n")
+
+	// The values passed with --set aren't written.
+	after, err := afero.ReadFile(fs, conf.ConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+}
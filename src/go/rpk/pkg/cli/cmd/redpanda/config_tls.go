@@ -0,0 +1,224 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+// The listeners of an API, along with their TLS config.
+type tlsListeners struct {
+	configPath string
+	listeners  []config.NamedSocketAddress
+	advertised []config.NamedSocketAddress
+	tls        []config.ServerTLS
+}
+
+type tlsCheckResult struct {
+	// Issues which break the clients, e.g. a cert which doesn't cover a
+	// listener's hostname.
+	errs []string
+	// Issues which will break the clients if left alone, e.g. a cert
+	// which is about to expire.
+	warnings []string
+}
+
+func checkTLS(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configPath      string
+		expiryThreshold time.Duration
+	)
+	c := &cobra.Command{
+		Use:   "check-tls",
+		Short: "Check the TLS certificates of the listeners",
+		Long: `Check the TLS certificates of the listeners.
+
+The certificate of each TLS-enabled listener is loaded, and its subject
+alternative names are checked to cover the listener's bind and advertised
+hostnames (the wildcard addresses, such as 0.0.0.0, are skipped). The command
+exits with code 3 if a certificate can't be read, doesn't cover a hostname or
+has expired, and warns about the ones which expire within --expiry-threshold.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			conf, err := mgr.ReadOrFind(configPath)
+			if err != nil {
+				return err
+			}
+			res := checkTLSCerts(fs, conf, time.Now(), expiryThreshold)
+			for _, w := range res.warnings {
+				log.Warn(w)
+			}
+			for _, e := range res.errs {
+				log.Error(e)
+			}
+			if len(res.errs) > 0 {
+				return cli.NewExitError(
+					cli.ExitCheckFailed,
+					fmt.Errorf(
+						"found %d issue(s) with the TLS certificates",
+						len(res.errs),
+					),
+				)
+			}
+			if len(res.warnings) == 0 {
+				log.Info("The TLS certificates cover all the listeners")
+			}
+			return nil
+		},
+	}
+	c.Flags().StringVar(
+		&configPath,
+		configFileFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched"+
+			" for in the default location",
+	)
+	c.Flags().DurationVar(
+		&expiryThreshold,
+		"expiry-threshold",
+		30*24*time.Hour,
+		"Warn about the certificates which expire within this time",
+	)
+	return c
+}
+
+func checkTLSCerts(
+	fs afero.Fs, conf *config.Config, now time.Time, expiryThreshold time.Duration,
+) tlsCheckResult {
+	apis := []tlsListeners{
+		{
+			"redpanda.kafka_api_tls",
+			conf.Redpanda.KafkaApi,
+			conf.Redpanda.AdvertisedKafkaApi,
+			conf.Redpanda.KafkaApiTLS,
+		},
+		{
+			"redpanda.admin_api_tls",
+			conf.Redpanda.AdminApi,
+			nil,
+			conf.Redpanda.AdminApiTLS,
+		},
+	}
+	if conf.Pandaproxy != nil {
+		apis = append(apis, tlsListeners{
+			"pandaproxy.pandaproxy_api_tls",
+			conf.Pandaproxy.PandaproxyAPI,
+			conf.Pandaproxy.AdvertisedPandaproxyAPI,
+			conf.Pandaproxy.PandaproxyAPITLS,
+		})
+	}
+	if conf.SchemaRegistry != nil {
+		apis = append(apis, tlsListeners{
+			"schema_registry.schema_registry_api_tls",
+			conf.SchemaRegistry.SchemaRegistryAPI,
+			nil,
+			conf.SchemaRegistry.SchemaRegistryAPITLS,
+		})
+	}
+	res := tlsCheckResult{}
+	for _, api := range apis {
+		for i, tls := range api.tls {
+			if !tls.Enabled || tls.CertFile == "" {
+				continue
+			}
+			path := fmt.Sprintf("%s.%d", api.configPath, i)
+			cert, err := readCert(fs, tls.CertFile)
+			if err != nil {
+				res.errs = append(res.errs, fmt.Sprintf(
+					"%s: couldn't read %s: %v",
+					path,
+					tls.CertFile,
+					err,
+				))
+				continue
+			}
+			for _, host := range listenerHosts(tls.Name, api) {
+				if cert.VerifyHostname(host) != nil {
+					res.errs = append(res.errs, fmt.Sprintf(
+						"%s: %s doesn't cover '%s', the address of listener '%s'",
+						path,
+						tls.CertFile,
+						host,
+						tls.Name,
+					))
+				}
+			}
+			expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+			if now.After(cert.NotAfter) {
+				res.errs = append(res.errs, fmt.Sprintf(
+					"%s: %s expired on %s",
+					path,
+					tls.CertFile,
+					expiry,
+				))
+			} else if cert.NotAfter.Sub(now) < expiryThreshold {
+				res.warnings = append(res.warnings, fmt.Sprintf(
+					"%s: %s expires on %s",
+					path,
+					tls.CertFile,
+					expiry,
+				))
+			}
+		}
+	}
+	return res
+}
+
+// Returns the bind and advertised addresses of the listeners with the given
+// name, skipping the wildcard ones, which can't be checked.
+func listenerHosts(name string, api tlsListeners) []string {
+	hosts := []string{}
+	seen := map[string]bool{}
+	add := func(addrs []config.NamedSocketAddress) {
+		for _, a := range addrs {
+			if a.Name != name || seen[a.Address] {
+				continue
+			}
+			switch a.Address {
+			case "", "0.0.0.0", "::", "[::]":
+				continue
+			}
+			seen[a.Address] = true
+			hosts = append(hosts, a.Address)
+		}
+	}
+	add(api.listeners)
+	add(api.advertised)
+	return hosts
+}
+
+// Reads the first certificate in the PEM file at path.
+func readCert(fs afero.Fs, path string) (*x509.Certificate, error) {
+	bs, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, bs = pem.Decode(bs)
+		if block == nil {
+			return nil, errors.New("no PEM-encoded certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
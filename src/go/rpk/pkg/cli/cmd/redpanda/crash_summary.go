@@ -0,0 +1,283 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+)
+
+const (
+	crashKindAssert    = "assert"
+	crashKindBadAlloc  = "bad_alloc"
+	crashKindBacktrace = "backtrace"
+)
+
+var (
+	// Seastar's log line format, e.g.
+	// ERROR 2021-07-20 10:00:00,123 [shard 0] assert - Assert failure: ...
+	// It's matched anywhere in the line, since journald may prefix it.
+	logLineRegexp = regexp.MustCompile(
+		`(TRACE|DEBUG|INFO|WARN|ERROR)\s+` +
+			`(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2},\d{3}) ` +
+			`\[shard \d+\] \S+ - (.*)$`,
+	)
+	// The message logged by vassert, e.g.
+	// Assert failure: (../src/v/raft/consensus.cc:1234) 'offset >= 0' msg
+	assertRegexp = regexp.MustCompile(`Assert failure: \(([^)]+)\) (.*)$`)
+)
+
+// A crash, or the group of identical crashes, found in the log.
+type crashSummary struct {
+	Kind      string   `json:"kind"`
+	Location  string   `json:"location,omitempty"`
+	Message   string   `json:"message"`
+	Count     int      `json:"count"`
+	FirstSeen string   `json:"first_seen,omitempty"`
+	LastSeen  string   `json:"last_seen,omitempty"`
+	Backtrace []string `json:"backtrace,omitempty"`
+}
+
+func NewCrashSummaryCommand(fs afero.Fs) *cobra.Command {
+	proc := os.NewProc()
+	return newCrashSummaryCommand(fs, func(timeout time.Duration) ([]string, error) {
+		return proc.RunWithSystemLdPath(
+			timeout,
+			"journalctl",
+			"--unit",
+			"redpanda",
+			"--no-pager",
+			"--output",
+			"cat",
+		)
+	})
+}
+
+func newCrashSummaryCommand(
+	fs afero.Fs, readJournal func(time.Duration) ([]string, error),
+) *cobra.Command {
+	var (
+		format  string
+		timeout time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "crash-summary [logfile]",
+		Short: "Summarize the asserts and crashes in the redpanda log",
+		Long: `Summarize the asserts and crashes in the redpanda log.
+
+The log is scanned for assert failures, bad_alloc errors and backtraces, and
+the identical ones are grouped: the asserts by their location in the source
+code, and the rest by their message. Each group is listed with how many times
+it was found and when it was first and last seen, which is meant to be
+attached to bug reports. The JSON output also includes the last backtrace
+found for each group.
+
+If logfile is omitted, the log is read from journald's redpanda unit.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf(
+					"unsupported format '%s', it must be 'text' or 'json'",
+					format,
+				)
+			}
+			var (
+				summaries []crashSummary
+				err       error
+			)
+			if len(args) > 0 {
+				summaries, err = summarizeLogFile(fs, args[0])
+			} else {
+				var lines []string
+				lines, err = readJournal(timeout)
+				if err != nil {
+					return fmt.Errorf(
+						"couldn't read the redpanda log from journald: %v",
+						err,
+					)
+				}
+				summaries, err = summarizeCrashes(
+					strings.NewReader(strings.Join(lines, "\n")),
+				)
+			}
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				out, err := json.MarshalIndent(summaries, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return nil
+			}
+			printCrashSummaries(cmd.OutOrStdout(), summaries)
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&format,
+		"format",
+		"text",
+		"The output format. Can be 'text' or 'json'",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		10*time.Second,
+		"The maximum time to wait for journald's log to be read",
+	)
+	return command
+}
+
+func summarizeLogFile(fs afero.Fs, path string) ([]crashSummary, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open the log file %s: %v", path, err)
+	}
+	defer f.Close()
+	return summarizeCrashes(f)
+}
+
+// Returns the crashes found in the log, grouped and sorted by when they were
+// first seen.
+func summarizeCrashes(r io.Reader) ([]crashSummary, error) {
+	summaries := []crashSummary{}
+	index := map[string]int{}
+	var (
+		// The timestamp of the last log line, which the lines without one
+		// (e.g. the backtraces' frames) are assumed to be from.
+		lastTimestamp string
+		// The index of the crash the backtrace being read belongs to, or -1.
+		current = -1
+		// Whether the lines being read are a backtrace's frames.
+		inBacktrace bool
+		// The last line, which describes the crash a bare backtrace is for
+		// (e.g. 'Segmentation fault on shard 0.').
+		previous string
+	)
+	record := func(kind, location, message string) int {
+		key := kind + "|" + location
+		if location == "" {
+			key = kind + "|" + message
+		}
+		i, ok := index[key]
+		if !ok {
+			summaries = append(summaries, crashSummary{
+				Kind:      kind,
+				Location:  location,
+				Message:   message,
+				FirstSeen: lastTimestamp,
+			})
+			i = len(summaries) - 1
+			index[key] = i
+		}
+		summaries[i].Count++
+		summaries[i].LastSeen = lastTimestamp
+		return i
+	}
+
+	scanner := bufio.NewScanner(r)
+	// Backtraces and assert messages may make for long lines.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		message := strings.TrimSpace(line)
+		if m := logLineRegexp.FindStringSubmatch(line); m != nil {
+			lastTimestamp = m[2]
+			message = m[3]
+			inBacktrace = false
+		} else if inBacktrace {
+			if message == "" {
+				inBacktrace = false
+			} else if current >= 0 {
+				summaries[current].Backtrace = append(
+					summaries[current].Backtrace,
+					message,
+				)
+			}
+			continue
+		}
+
+		switch {
+		case assertRegexp.MatchString(message):
+			m := assertRegexp.FindStringSubmatch(message)
+			current = record(crashKindAssert, m[1], m[2])
+		case strings.Contains(message, "bad_alloc"):
+			current = record(crashKindBadAlloc, "", message)
+		case strings.HasPrefix(message, "Backtrace"):
+			// vassert logs the backtrace right after the assert failure,
+			// otherwise it's a crash of its own.
+			if current < 0 {
+				desc := previous
+				if desc == "" {
+					desc = "Backtrace"
+				}
+				current = record(crashKindBacktrace, "", desc)
+			}
+			// Only the last backtrace of each group is kept.
+			summaries[current].Backtrace = nil
+			inBacktrace = true
+			// The frames may start on the same line.
+			if i := strings.Index(message, ":"); i >= 0 {
+				if frames := strings.TrimSpace(message[i+1:]); frames != "" {
+					summaries[current].Backtrace = []string{frames}
+				}
+			}
+			continue
+		default:
+			current = -1
+		}
+		previous = message
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read the log: %v", err)
+	}
+	return summaries, nil
+}
+
+func printCrashSummaries(out io.Writer, summaries []crashSummary) {
+	if len(summaries) == 0 {
+		fmt.Fprintln(out, "No asserts or crashes found.")
+		return
+	}
+	t := ui.NewRpkTable(out)
+	t.SetAutoWrapText(false)
+	t.SetHeader([]string{
+		"Kind",
+		"Location",
+		"Count",
+		"First seen",
+		"Last seen",
+		"Message",
+	})
+	for _, s := range summaries {
+		t.Append([]string{
+			s.Kind,
+			s.Location,
+			fmt.Sprint(s.Count),
+			s.FirstSeen,
+			s.LastSeen,
+			s.Message,
+		})
+	}
+	t.Render()
+}
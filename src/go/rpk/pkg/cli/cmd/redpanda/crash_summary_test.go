@@ -0,0 +1,191 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleCrashLog = `INFO  2021-07-20 10:00:00,001 [shard 0] redpanda::main - application.cc:123 - Starting Redpanda
+ERROR 2021-07-20 10:05:00,100 [shard 1] assert - Assert failure: (../../../src/v/raft/consensus.cc:1234) 'offset >= 0' Invalid offset -1
+ERROR 2021-07-20 10:05:00,101 [shard 1] assert - Backtrace below:
+0x3b2a1c5
+0x3b2a1c6
+INFO  2021-07-20 10:06:00,000 [shard 0] redpanda::main - application.cc:123 - Starting Redpanda
+ERROR 2021-07-20 10:07:00,200 [shard 0] assert - Assert failure: (../../../src/v/storage/segment.cc:88) '!_closed' segment already closed
+ERROR 2021-07-20 10:07:00,201 [shard 0] assert - Backtrace below:
+0x4c1d2e0
+INFO  2021-07-20 10:08:00,000 [shard 0] redpanda::main - application.cc:123 - Starting Redpanda
+ERROR 2021-07-20 10:09:30,300 [shard 2] assert - Assert failure: (../../../src/v/raft/consensus.cc:1234) 'offset >= 0' Invalid offset -7
+ERROR 2021-07-20 10:09:30,301 [shard 2] assert - Backtrace below:
+0x3b2a1d0
+0x3b2a1d1
+WARN  2021-07-20 10:10:00,000 [shard 0] seastar - Exceptional future ignored: std::bad_alloc (std::bad_alloc)
+Segmentation fault on shard 3.
+Backtrace:
+  0x5a1b2c3
+  /lib64/libc.so.6+0x3a5b0
+
+INFO  2021-07-20 10:11:00,000 [shard 0] redpanda::main - application.cc:123 - Starting Redpanda
+`
+
+func TestSummarizeCrashes(t *testing.T) {
+	summaries, err := summarizeCrashes(strings.NewReader(sampleCrashLog))
+	require.NoError(t, err)
+	require.Equal(t, []crashSummary{
+		{
+			Kind:      crashKindAssert,
+			Location:  "../../../src/v/raft/consensus.cc:1234",
+			Message:   "'offset >= 0' Invalid offset -1",
+			Count:     2,
+			FirstSeen: "2021-07-20 10:05:00,100",
+			LastSeen:  "2021-07-20 10:09:30,300",
+			Backtrace: []string{"0x3b2a1d0", "0x3b2a1d1"},
+		},
+		{
+			Kind:      crashKindAssert,
+			Location:  "../../../src/v/storage/segment.cc:88",
+			Message:   "'!_closed' segment already closed",
+			Count:     1,
+			FirstSeen: "2021-07-20 10:07:00,200",
+			LastSeen:  "2021-07-20 10:07:00,200",
+			Backtrace: []string{"0x4c1d2e0"},
+		},
+		{
+			Kind:      crashKindBadAlloc,
+			Message:   "Exceptional future ignored: std::bad_alloc (std::bad_alloc)",
+			Count:     1,
+			FirstSeen: "2021-07-20 10:10:00,000",
+			LastSeen:  "2021-07-20 10:10:00,000",
+		},
+		{
+			Kind:      crashKindBacktrace,
+			Message:   "Segmentation fault on shard 3.",
+			Count:     1,
+			FirstSeen: "2021-07-20 10:10:00,000",
+			LastSeen:  "2021-07-20 10:10:00,000",
+			Backtrace: []string{"0x5a1b2c3", "/lib64/libc.so.6+0x3a5b0"},
+		},
+	}, summaries)
+}
+
+func TestCrashSummaryCommand(t *testing.T) {
+	const logPath = "/var/log/redpanda.log"
+	noJournal := func(time.Duration) ([]string, error) {
+		return nil, errors.New("journald shouldn't have been read")
+	}
+	tests := []struct {
+		name           string
+		args           []string
+		readJournal    func(time.Duration) ([]string, error)
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name:        "it should print the grouped crashes in the log file",
+			args:        []string{logPath},
+			readJournal: noJournal,
+			expectedOutput: []string{
+				`assert\s+../../../src/v/raft/consensus.cc:1234\s+2\s+2021-07-20 10:05:00,100\s+2021-07-20 10:09:30,300\s+'offset >= 0' Invalid offset -1`,
+				`assert\s+../../../src/v/storage/segment.cc:88\s+1\s+`,
+				`bad_alloc\s+1\s+`,
+				`backtrace\s+1\s+.*Segmentation fault on shard 3.`,
+			},
+		},
+		{
+			name: "it should read the log from journald if no file is passed",
+			readJournal: func(time.Duration) ([]string, error) {
+				return strings.Split(sampleCrashLog, "\n"), nil
+			},
+			expectedOutput: []string{
+				`assert\s+../../../src/v/raft/consensus.cc:1234\s+2\s+`,
+			},
+		},
+		{
+			name: "it should say if there are no crashes",
+			readJournal: func(time.Duration) ([]string, error) {
+				return []string{"INFO  2021-07-20 10:00:00,001 [shard 0] redpanda::main - Starting Redpanda"}, nil
+			},
+			expectedOutput: []string{"No asserts or crashes found."},
+		},
+		{
+			name: "it should fail if journald can't be read",
+			readJournal: func(time.Duration) ([]string, error) {
+				return nil, errors.New("journalctl: command not found")
+			},
+			expectedErrMsg: "couldn't read the redpanda log from journald: journalctl: command not found",
+		},
+		{
+			name:           "it should fail if the log file doesn't exist",
+			args:           []string{"/var/log/missing.log"},
+			readJournal:    noJournal,
+			expectedErrMsg: "couldn't open the log file /var/log/missing.log",
+		},
+		{
+			name:           "it should fail if the format isn't supported",
+			args:           []string{logPath, "--format", "yaml"},
+			readJournal:    noJournal,
+			expectedErrMsg: "unsupported format 'yaml', it must be 'text' or 'json'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, logPath, []byte(sampleCrashLog), 0644)
+			require.NoError(st, err)
+			var out bytes.Buffer
+			cmd := newCrashSummaryCommand(fs, tt.readJournal)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(&out)
+			err = cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+			for _, exp := range tt.expectedOutput {
+				require.Regexp(st, regexp.MustCompile(exp), out.String())
+			}
+		})
+	}
+}
+
+func TestCrashSummaryCommandJSON(t *testing.T) {
+	const logPath = "/var/log/redpanda.log"
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, logPath, []byte(sampleCrashLog), 0644)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	cmd := newCrashSummaryCommand(fs, nil)
+	cmd.SetArgs([]string{logPath, "--format", "json"})
+	cmd.SetOut(&out)
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	summaries := []crashSummary{}
+	err = json.Unmarshal(out.Bytes(), &summaries)
+	require.NoError(t, err)
+	require.Len(t, summaries, 4)
+	require.Equal(t, "../../../src/v/raft/consensus.cc:1234", summaries[0].Location)
+	require.Equal(t, 2, summaries[0].Count)
+	require.Equal(t, []string{"0x3b2a1d0", "0x3b2a1d1"}, summaries[0].Backtrace)
+	require.Equal(t, "../../../src/v/storage/segment.cc:88", summaries[1].Location)
+	require.Equal(t, 1, summaries[1].Count)
+}
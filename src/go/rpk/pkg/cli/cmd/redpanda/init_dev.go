@@ -0,0 +1,99 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+const loopbackAddress = "127.0.0.1"
+
+func NewInitDevCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configFile string
+		force      bool
+	)
+	command := &cobra.Command{
+		Use:   "init-dev",
+		Short: "Generate a config for a single-node development cluster",
+		Long: `Generate a config for a single-node development cluster.
+
+The node listens on the loopback interface only, has no seed servers, its ID is
+0 and it runs in development mode, so all the tuners are disabled. The command
+prints the one to start the node with once the config is written.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return executeInitDev(fs, mgr, configFile, force)
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		configFileFlag,
+		config.Default().ConfigFile,
+		"The path where the config will be written",
+	)
+	command.Flags().BoolVar(
+		&force,
+		"force",
+		false,
+		"Overwrite the config file if it already exists",
+	)
+	return command
+}
+
+func executeInitDev(
+	fs afero.Fs, mgr config.Manager, configFile string, force bool,
+) error {
+	exists, err := afero.Exists(fs, configFile)
+	if err != nil {
+		return err
+	}
+	if exists && !force {
+		return fmt.Errorf(
+			"%s already exists. Pass --force to overwrite it",
+			configFile,
+		)
+	}
+	conf, err := devConfig(configFile)
+	if err != nil {
+		return err
+	}
+	err = mgr.Write(conf)
+	if err != nil {
+		return err
+	}
+	log.Infof("Wrote a single-node development config to '%s'", configFile)
+	log.Info("Start the node with:")
+	log.Infof("  rpk redpanda start --%s %s", configFileFlag, configFile)
+	return nil
+}
+
+// Returns the default config, bound to the loopback interface and set to
+// development mode.
+func devConfig(configFile string) (*config.Config, error) {
+	conf := config.Default()
+	conf.ConfigFile = configFile
+	conf.Redpanda.Id = 0
+	conf.Redpanda.SeedServers = []config.SeedServer{}
+	conf.Redpanda.RPCServer.Address = loopbackAddress
+	for i := range conf.Redpanda.KafkaApi {
+		conf.Redpanda.KafkaApi[i].Address = loopbackAddress
+	}
+	for i := range conf.Redpanda.AdminApi {
+		conf.Redpanda.AdminApi[i].Address = loopbackAddress
+	}
+	return config.SetMode(config.ModeDev, conf)
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func TestInitDevCommand(t *testing.T) {
+	path := "/tmp/redpanda/redpanda.yaml"
+	tests := []struct {
+		name           string
+		args           []string
+		before         func(afero.Fs) error
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name: "it should write a single-node dev config",
+			args: []string{"--config", path},
+			expectedOutput: []string{
+				"Wrote a single-node development config to '" + path + "'",
+				"rpk redpanda start --config " + path,
+			},
+		},
+		{
+			name: "it should fail if the config already exists",
+			args: []string{"--config", path},
+			before: func(fs afero.Fs) error {
+				return afero.WriteFile(fs, path, []byte("redpanda: {}"), 0644)
+			},
+			expectedErrMsg: path + " already exists. Pass --force to overwrite it",
+		},
+		{
+			name: "it should overwrite the config if --force is passed",
+			args: []string{"--config", path, "--force"},
+			before: func(fs afero.Fs) error {
+				conf := config.Default()
+				conf.ConfigFile = path
+				conf.Redpanda.Id = 3
+				return config.NewManager(fs).Write(conf)
+			},
+			expectedOutput: []string{
+				"rpk redpanda start --config " + path,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.before != nil {
+				require.NoError(st, tt.before(fs))
+			}
+			mgr := config.NewManager(fs)
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := NewInitDevCommand(fs, mgr)
+			c.SetArgs(tt.args)
+			err := c.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+			for _, o := range tt.expectedOutput {
+				require.Contains(st, out.String(), o)
+			}
+
+			conf, err := config.NewManager(fs).Read(path)
+			require.NoError(st, err)
+			ok, errs := config.Check(conf)
+			require.True(st, ok, "%v", errs)
+			require.True(st, conf.Redpanda.DeveloperMode)
+			require.True(st, conf.Rpk.Overprovisioned)
+			require.False(st, conf.Rpk.TuneNetwork)
+			require.False(st, conf.Rpk.TuneCpu)
+			require.Exactly(st, 0, conf.Redpanda.Id)
+			require.Empty(st, conf.Redpanda.SeedServers)
+			require.Equal(st, "127.0.0.1", conf.Redpanda.RPCServer.Address)
+			for _, l := range conf.Redpanda.KafkaApi {
+				require.Equal(st, "127.0.0.1", l.Address)
+			}
+			for _, l := range conf.Redpanda.AdminApi {
+				require.Equal(st, "127.0.0.1", l.Address)
+			}
+		})
+	}
+}
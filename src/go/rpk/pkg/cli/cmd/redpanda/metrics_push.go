@@ -0,0 +1,200 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+)
+
+// The content type of the Prometheus text exposition format.
+const prometheusTextContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// The maximum time to wait for the pushgateway to reply to each push.
+const pushTimeout = 10 * time.Second
+
+func NewMetricsPushCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	return newMetricsPushCommand(
+		mgr,
+		func(timeout time.Duration, conf config.Config) (*system.Metrics, error) {
+			return system.GatherMetrics(fs, timeout, conf)
+		},
+		0,
+	)
+}
+
+// The command stops after the given number of pushes, or never if it's 0.
+func newMetricsPushCommand(
+	mgr config.Manager,
+	gatherMetrics func(time.Duration, config.Config) (*system.Metrics, error),
+	pushes int,
+) *cobra.Command {
+	var (
+		configFile string
+		gateway    string
+		interval   time.Duration
+		job        string
+		timeout    time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "metrics-push --gateway <url>",
+		Short: "Continuously push the system metrics to a Prometheus pushgateway",
+		Long: `Continuously push the system metrics to a Prometheus pushgateway.
+
+The metrics reported by 'rpk debug info' (redpanda's CPU usage, and the free
+memory and disk space) are gathered every --interval and pushed as gauges to
+the pushgateway at --gateway, under the --job grouping key. Failed pushes, or
+metrics which can't be gathered (e.g. because redpanda isn't running), are
+logged, but don't stop the command.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive, but got %s", interval)
+			}
+			if job == "" {
+				return errors.New("--job can't be empty")
+			}
+			pushURL, err := pushgatewayURL(gateway, job)
+			if err != nil {
+				return err
+			}
+			conf, err := mgr.ReadOrFind(configFile)
+			if err != nil {
+				return err
+			}
+			client := &http.Client{Timeout: pushTimeout}
+			pushed := 0
+			for {
+				m, err := gatherMetrics(timeout, *conf)
+				switch {
+				case system.IsErrRedpandaDown(err):
+					log.Warnf("Not pushing the metrics: %v", err)
+				case err != nil:
+					log.Warnf("Couldn't gather the metrics: %v", err)
+				default:
+					err = pushMetrics(client, pushURL, m)
+					if err != nil {
+						log.Warnf("Couldn't push the metrics: %v", err)
+					} else {
+						log.Infof("Pushed the metrics to %s", pushURL)
+					}
+				}
+				pushed++
+				if pushes > 0 && pushed >= pushes {
+					return nil
+				}
+				time.Sleep(interval)
+			}
+		},
+	}
+	command.Flags().StringVar(
+		&gateway,
+		"gateway",
+		"",
+		"The URL of the Prometheus pushgateway, e.g. http://localhost:9091",
+	)
+	command.Flags().DurationVar(
+		&interval,
+		"interval",
+		time.Minute,
+		"The time to wait between pushes",
+	)
+	command.Flags().StringVar(
+		&job,
+		"job",
+		"redpanda",
+		"The job the metrics are pushed under",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		2*time.Second,
+		"The time to sample redpanda's CPU usage for in each push",
+	)
+	command.Flags().StringVar(
+		&configFile,
+		configFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	cobra.MarkFlagRequired(command.Flags(), "gateway")
+	return command
+}
+
+// Returns the URL the metrics for the given job are pushed to.
+func pushgatewayURL(gateway, job string) (string, error) {
+	u, err := url.Parse(gateway)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse the gateway URL '%s': %v", gateway, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf(
+			"the gateway URL '%s' must start with http:// or https://",
+			gateway,
+		)
+	}
+	return strings.TrimSuffix(u.String(), "/") +
+		"/metrics/job/" + url.PathEscape(job), nil
+}
+
+// Renders the metrics in the Prometheus text exposition format.
+func renderMetrics(m *system.Metrics) []byte {
+	var buf bytes.Buffer
+	gauges := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"redpanda_cpu_percentage", "The CPU usage of the redpanda process", m.CpuPercentage},
+		{"redpanda_free_memory_mb", "The free memory, in MB", m.FreeMemoryMB},
+		{"redpanda_free_space_mb", "The free space in the data directory, in MB", m.FreeSpaceMB},
+	}
+	for _, g := range gauges {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", g.name, g.help)
+		fmt.Fprintf(&buf, "# TYPE %s gauge\n", g.name)
+		fmt.Fprintf(&buf, "%s %g\n", g.name, g.value)
+	}
+	return buf.Bytes()
+}
+
+// Replaces the job's metrics in the pushgateway with the given ones.
+func pushMetrics(client *http.Client, pushURL string, m *system.Metrics) error {
+	req, err := http.NewRequest(
+		http.MethodPut,
+		pushURL,
+		bytes.NewReader(renderMetrics(m)),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", prometheusTextContentType)
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("the pushgateway replied with status %d", res.StatusCode)
+	}
+	return nil
+}
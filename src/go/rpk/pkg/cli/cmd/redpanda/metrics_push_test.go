@@ -0,0 +1,160 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+)
+
+type pushRequest struct {
+	method      string
+	path        string
+	contentType string
+	body        string
+}
+
+func TestMetricsPushCommand(t *testing.T) {
+	tests := []struct {
+		name             string
+		args             []string
+		pushes           int
+		status           int
+		gatherErr        error
+		expectedPath     string
+		expectedRequests int
+		expectedOutput   []string
+		expectedErrMsg   string
+	}{
+		{
+			name:             "it should push the metrics on every interval",
+			pushes:           2,
+			status:           http.StatusOK,
+			expectedPath:     "/metrics/job/redpanda",
+			expectedRequests: 2,
+			expectedOutput:   []string{"Pushed the metrics to"},
+		},
+		{
+			name:             "it should push the metrics under the given job",
+			args:             []string{"--job", "node-1"},
+			pushes:           1,
+			status:           http.StatusAccepted,
+			expectedPath:     "/metrics/job/node-1",
+			expectedRequests: 1,
+		},
+		{
+			name:             "it should keep going if the push fails",
+			pushes:           2,
+			status:           http.StatusInternalServerError,
+			expectedPath:     "/metrics/job/redpanda",
+			expectedRequests: 2,
+			expectedOutput: []string{
+				"Couldn't push the metrics: the pushgateway replied with status 500",
+			},
+		},
+		{
+			name:           "it should skip the push if the metrics can't be gathered",
+			pushes:         2,
+			gatherErr:      errors.New("no such process"),
+			expectedOutput: []string{"Couldn't gather the metrics: no such process"},
+		},
+		{
+			name:           "it should fail if the interval isn't positive",
+			args:           []string{"--interval", "0s"},
+			expectedErrMsg: "--interval must be positive, but got 0s",
+		},
+		{
+			name:           "it should fail if the gateway URL isn't HTTP",
+			args:           []string{"--gateway", "localhost:9091"},
+			expectedErrMsg: "the gateway URL 'localhost:9091' must start with http:// or https://",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			reqs := make(chan pushRequest, 10)
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					body, err := ioutil.ReadAll(r.Body)
+					require.NoError(st, err)
+					reqs <- pushRequest{
+						method:      r.Method,
+						path:        r.URL.Path,
+						contentType: r.Header.Get("Content-Type"),
+						body:        string(body),
+					}
+					w.WriteHeader(tt.status)
+				},
+			))
+			defer server.Close()
+
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			err := mgr.Write(conf)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			gatherMetrics := func(time.Duration, config.Config) (*system.Metrics, error) {
+				if tt.gatherErr != nil {
+					return nil, tt.gatherErr
+				}
+				return &system.Metrics{
+					CpuPercentage: 12.5,
+					FreeMemoryMB:  1024,
+					FreeSpaceMB:   2048,
+				}, nil
+			}
+			cmd := newMetricsPushCommand(mgr, gatherMetrics, tt.pushes)
+			cmd.SetArgs(append(
+				[]string{
+					"--config", conf.ConfigFile,
+					"--gateway", server.URL,
+					"--interval", "1ms",
+				},
+				tt.args...,
+			))
+			err = cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, err)
+			close(reqs)
+			received := []pushRequest{}
+			for r := range reqs {
+				received = append(received, r)
+			}
+			require.Len(st, received, tt.expectedRequests)
+			for _, r := range received {
+				require.Equal(st, http.MethodPut, r.method)
+				require.Equal(st, tt.expectedPath, r.path)
+				require.Equal(st, prometheusTextContentType, r.contentType)
+				require.Contains(st, r.body, "# TYPE redpanda_cpu_percentage gauge\nredpanda_cpu_percentage 12.5\n")
+				require.Contains(st, r.body, "# TYPE redpanda_free_memory_mb gauge\nredpanda_free_memory_mb 1024\n")
+				require.Contains(st, r.body, "# TYPE redpanda_free_space_mb gauge\nredpanda_free_space_mb 2048\n")
+			}
+			for _, o := range tt.expectedOutput {
+				require.Contains(st, out.String(), o)
+			}
+		})
+	}
+}
@@ -10,28 +10,46 @@
 package redpanda
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
 )
 
-func NewModeCommand(mgr config.Manager) *cobra.Command {
-	var configFile string
+func NewModeCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configFile string
+		dryRun     bool
+	)
 	command := &cobra.Command{
 		Use:   "mode <mode>",
 		Short: "Enable a default configuration mode",
-		Long:  "",
+		Long: `Enable a default configuration mode.
+
+The mode may be followed by a comma-separated list of tuners to enable, or to
+disable if they're prefixed with "no-", on top of the mode's defaults. For
+example, 'prod:no-transparent_hugepages,coredump' enables the production
+tuners except for transparent_hugepages, and also enables coredump.`,
+		ValidArgs: config.AvailableModes(),
 		Args: func(_ *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return fmt.Errorf("requires a mode [%s]", strings.Join(config.AvailableModes(), ", "))
 			}
-			return nil
+			return checkModeTuners(args[0])
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
 			// Safe to access args[0] because it was validated in Args
+			if dryRun {
+				return executeModePreview(mgr, configFile, args[0])
+			}
 			return executeMode(mgr, configFile, args[0])
 		},
 	}
@@ -42,9 +60,142 @@ func NewModeCommand(mgr config.Manager) *cobra.Command {
 		"Redpanda config file, if not set the file will be searched for"+
 			" in the default locations",
 	)
+	command.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Print the flags the mode would change, without writing the config",
+	)
+	command.AddCommand(newModeDiffCommand(fs, mgr))
+	return command
+}
+
+// A flag which at least one of the modes would change, with its value in the
+// current config and in each mode.
+type modeFlagDiff struct {
+	key     string
+	current bool
+	dev     bool
+	prod    bool
+}
+
+func newModeDiffCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configFile string
+		output     string
+	)
+	command := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the flags set by the dev and prod modes",
+		Long: `Compare the flags set by the dev and prod modes.
+
+Each flag which either mode would change is listed along with its value in the
+current config and the one it would have in each mode. The flags which are
+already set to both modes' values are left out.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			conf, err := common.FindConfigFile(mgr, &configFile)()
+			if err != nil {
+				return err
+			}
+			diffs, err := diffModes(conf)
+			if err != nil {
+				return err
+			}
+			var out bytes.Buffer
+			if len(diffs) == 0 {
+				fmt.Fprintf(
+					&out,
+					"Neither mode would change '%s'\n",
+					conf.ConfigFile,
+				)
+				return common.WriteOutput(fs, cmd, output, out.Bytes())
+			}
+			t := ui.NewRpkTable(&out)
+			t.SetHeader([]string{
+				"Flag",
+				"Current",
+				config.ModeDev,
+				config.ModeProd,
+			})
+			for _, d := range diffs {
+				t.Append([]string{
+					d.key,
+					fmt.Sprint(d.current),
+					fmt.Sprint(d.dev),
+					fmt.Sprint(d.prod),
+				})
+			}
+			t.Render()
+			return common.WriteOutput(fs, cmd, output, out.Bytes())
+		},
+	}
+	common.AddOutputFlag(command, &output)
+	command.Flags().StringVar(
+		&configFile,
+		"config",
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
 	return command
 }
 
+// Returns the flags which the dev or the prod mode would change in conf,
+// sorted by their key.
+func diffModes(conf *config.Config) ([]modeFlagDiff, error) {
+	devChanges, err := config.SetModePreview(config.ModeDev, conf)
+	if err != nil {
+		return nil, err
+	}
+	prodChanges, err := config.SetModePreview(config.ModeProd, conf)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]*modeFlagDiff{}
+	// The flags a mode doesn't change keep their current value in it.
+	for k, c := range devChanges {
+		byKey[k] = &modeFlagDiff{key: k, current: c[0], dev: c[1], prod: c[0]}
+	}
+	for k, c := range prodChanges {
+		d, ok := byKey[k]
+		if !ok {
+			d = &modeFlagDiff{key: k, current: c[0], dev: c[0]}
+			byKey[k] = d
+		}
+		d.prod = c[1]
+	}
+	diffs := make([]modeFlagDiff, 0, len(byKey))
+	for _, d := range byKey {
+		diffs = append(diffs, *d)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].key < diffs[j].key
+	})
+	return diffs, nil
+}
+
+// Checks that the tuners given along with the mode, if any, are available.
+func checkModeTuners(mode string) error {
+	_, tuners, err := config.ParseMode(mode)
+	if err != nil {
+		return err
+	}
+	for name := range tuners {
+		if !factory.IsTunerAvailable(name) {
+			available := factory.AvailableTuners()
+			sort.Strings(available)
+			return fmt.Errorf(
+				"'%s' is not a supported tuner. Available tuners: %s",
+				name,
+				strings.Join(available, ", "),
+			)
+		}
+	}
+	return nil
+}
+
 func executeMode(mgr config.Manager, configFile string, mode string) error {
 	conf, err := mgr.FindOrGenerate(configFile)
 	if err != nil {
@@ -57,3 +208,30 @@ func executeMode(mgr config.Manager, configFile string, mode string) error {
 	log.Infof("Writing '%s' mode defaults to '%s'", mode, conf.ConfigFile)
 	return mgr.Write(conf)
 }
+
+func executeModePreview(mgr config.Manager, configFile string, mode string) error {
+	// The preview mustn't write anything, so it's done against the
+	// default config if there's none yet.
+	conf, err := common.FindConfigFile(mgr, &configFile)()
+	if err != nil {
+		return err
+	}
+	changes, err := config.SetModePreview(mode, conf)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		log.Infof("'%s' mode wouldn't change '%s'", mode, conf.ConfigFile)
+		return nil
+	}
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	log.Infof("'%s' mode would change '%s':", mode, conf.ConfigFile)
+	for _, k := range keys {
+		log.Infof("  %s: %t -> %t", k, changes[k][0], changes[k][1])
+	}
+	return nil
+}
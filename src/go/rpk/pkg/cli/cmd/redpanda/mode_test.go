@@ -13,6 +13,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
 	"gopkg.in/yaml.v2"
 )
 
@@ -44,6 +46,12 @@ func fillRpkConfig(path, mode string) *config.Config {
 	return conf
 }
 
+func sortedTuners() []string {
+	tuners := factory.AvailableTuners()
+	sort.Strings(tuners)
+	return tuners
+}
+
 func TestModeCommand(t *testing.T) {
 	configPath := "/etc/redpanda/redpanda.yaml"
 	dir, err := os.Getwd()
@@ -142,6 +150,39 @@ func TestModeCommand(t *testing.T) {
 			expectedOutput: "",
 			expectedErrMsg: "'invalidmode' is not a supported mode. Available modes: dev, development, prod, production",
 		},
+		{
+			name: "mode should enable and disable the tuners passed along with it",
+			args: []string{"prod:no-cpu,coredump", "--config", configPath},
+			before: func(fs afero.Fs) (string, error) {
+				bs, err := yaml.Marshal(fillRpkConfig(configPath, config.ModeDev))
+				if err != nil {
+					return "", err
+				}
+				return configPath, afero.WriteFile(fs, configPath, bs, 0644)
+			},
+			expectedConfig: func() *config.Config {
+				conf := fillRpkConfig(configPath, config.ModeProd)
+				conf.Rpk.TuneCpu = false
+				conf.Rpk.TuneCoredump = true
+				return conf
+			}(),
+			expectedOutput: fmt.Sprintf("Writing 'prod:no-cpu,coredump' mode defaults to '%s'", configPath),
+			expectedErrMsg: "",
+		},
+		{
+			name: "mode lists the available tuners if one passed along with it is not valid",
+			args: []string{"prod:no-thp", "--config", configPath},
+			before: func(fs afero.Fs) (string, error) {
+				bs, err := yaml.Marshal(fillRpkConfig(configPath, config.ModeDev))
+				if err != nil {
+					return "", err
+				}
+				return configPath, afero.WriteFile(fs, configPath, bs, 0644)
+			},
+			expectedOutput: "",
+			expectedErrMsg: "'thp' is not a supported tuner. Available tuners: " +
+				strings.Join(sortedTuners(), ", "),
+		},
 	}
 
 	for _, tt := range tests {
@@ -151,7 +192,7 @@ func TestModeCommand(t *testing.T) {
 			path, err := tt.before(fs)
 			require.NoError(t, err)
 			var out bytes.Buffer
-			cmd := NewModeCommand(mgr)
+			cmd := NewModeCommand(fs, mgr)
 			cmd.SetArgs(tt.args)
 			logrus.SetOutput(&out)
 			err = cmd.Execute()
@@ -168,3 +209,160 @@ func TestModeCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestModeCommandDevDisablesProdTuners(t *testing.T) {
+	configPath := "/etc/redpanda/redpanda.yaml"
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	bs, err := yaml.Marshal(fillRpkConfig(configPath, config.ModeDev))
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, configPath, bs, 0644))
+
+	tuners := "swapfile,dirty_pages,disk_nr_requests,nic_queues,cstates," +
+		"max_map_count,fd_limit,mount_options"
+	for _, mode := range []string{"prod:" + tuners, "dev"} {
+		cmd := NewModeCommand(fs, mgr)
+		cmd.SetArgs([]string{mode, "--config", configPath})
+		logrus.SetOutput(&bytes.Buffer{})
+		require.NoError(t, cmd.Execute())
+	}
+
+	conf, err := mgr.Read(configPath)
+	require.NoError(t, err)
+	require.Exactly(t, fillRpkConfig(configPath, config.ModeDev), conf)
+	// The tuners disabled by the dev mode are written to the file too.
+	bs, err = afero.ReadFile(fs, configPath)
+	require.NoError(t, err)
+	for _, flag := range strings.Split(tuners, ",") {
+		require.Contains(t, string(bs), fmt.Sprintf("tune_%s: false", flag))
+	}
+}
+
+func TestModeCommandDryRun(t *testing.T) {
+	configPath := "/etc/redpanda/redpanda.yaml"
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	bs, err := yaml.Marshal(fillRpkConfig(configPath, config.ModeDev))
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, configPath, bs, 0644))
+
+	var out bytes.Buffer
+	cmd := NewModeCommand(fs, mgr)
+	cmd.SetArgs([]string{"prod", "--dry-run", "--config", configPath})
+	logrus.SetOutput(&out)
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	output := out.String()
+	require.Contains(t, output, "redpanda.developer_mode: true -> false")
+	require.Contains(t, output, "rpk.tune_cpu: false -> true")
+	require.Contains(t, output, "rpk.overprovisioned: true -> false")
+	// The config is left as it is.
+	after, err := afero.ReadFile(fs, configPath)
+	require.NoError(t, err)
+	require.Equal(t, string(bs), string(after))
+}
+
+func TestModeCommandDryRunWithoutConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+
+	var out bytes.Buffer
+	cmd := NewModeCommand(fs, mgr)
+	cmd.SetArgs([]string{"prod", "--dry-run"})
+	logrus.SetOutput(&out)
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	output := out.String()
+	require.Contains(t, output, "redpanda.developer_mode: true -> false")
+	require.Contains(t, output, "rpk.tune_cpu: false -> true")
+	// No config is generated.
+	exists, err := afero.Exists(fs, config.Default().ConfigFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestDiffModes(t *testing.T) {
+	conf := fillRpkConfig("/etc/redpanda/redpanda.yaml", config.ModeDev)
+	// Only the dev mode resets it, since prod leaves it as it is.
+	conf.Rpk.TuneCoredump = true
+
+	diffs, err := diffModes(conf)
+	require.NoError(t, err)
+	differing := map[string][2]bool{}
+	for _, d := range diffs {
+		require.NotEqual(t, d.dev, d.prod, d.key)
+		differing[d.key] = [2]bool{d.dev, d.prod}
+	}
+	expected := map[string][2]bool{
+		"redpanda.developer_mode":   {true, false},
+		"rpk.overprovisioned":       {true, false},
+		"rpk.tune_network":          {false, true},
+		"rpk.tune_disk_scheduler":   {false, true},
+		"rpk.tune_disk_nomerges":    {false, true},
+		"rpk.tune_disk_write_cache": {false, true},
+		"rpk.tune_disk_irq":         {false, true},
+		"rpk.tune_fstrim":           {false, true},
+		"rpk.tune_cpu":              {false, true},
+		"rpk.tune_aio_events":       {false, true},
+		"rpk.tune_clocksource":      {false, true},
+		"rpk.tune_swappiness":       {false, true},
+		"rpk.tune_coredump":         {false, true},
+	}
+	require.Equal(t, expected, differing)
+}
+
+func TestModeDiffCommand(t *testing.T) {
+	configPath := "/etc/redpanda/redpanda.yaml"
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	bs, err := yaml.Marshal(fillRpkConfig(configPath, config.ModeProd))
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, configPath, bs, 0644))
+
+	var out bytes.Buffer
+	cmd := NewModeCommand(fs, mgr)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"diff", "--config", configPath})
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 13)
+	require.Regexp(t, `^FLAG\s+CURRENT\s+DEV\s+PROD$`, strings.TrimSpace(lines[0]))
+	require.Regexp(
+		t,
+		`^redpanda\.developer_mode\s+false\s+true\s+false$`,
+		strings.TrimSpace(lines[1]),
+	)
+	require.Regexp(
+		t,
+		`^rpk\.tune_cpu\s+true\s+false\s+true$`,
+		strings.TrimSpace(lines[5]),
+	)
+}
+
+func TestModeDiffCommandOutput(t *testing.T) {
+	const outputPath = "/tmp/mode-diff.txt"
+	configPath := "/etc/redpanda/redpanda.yaml"
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	bs, err := yaml.Marshal(fillRpkConfig(configPath, config.ModeDev))
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, configPath, bs, 0644))
+
+	var out bytes.Buffer
+	cmd := NewModeCommand(fs, mgr)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"diff", "--config", configPath, "--output", outputPath})
+	err = cmd.Execute()
+	require.NoError(t, err)
+	require.Empty(t, out.String())
+
+	written, err := afero.ReadFile(fs, outputPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(written)), "\n")
+	require.Regexp(t, `^FLAG\s+CURRENT\s+DEV\s+PROD$`, strings.TrimSpace(lines[0]))
+	require.Len(t, lines, 13)
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+)
+
+func NewNtpWatchCommand(fs afero.Fs) *cobra.Command {
+	return newNtpWatchCommand(
+		func(timeout time.Duration) (time.Duration, error) {
+			return system.NewNtpQuery(timeout, fs).NtpOffset()
+		},
+	)
+}
+
+func newNtpWatchCommand(
+	ntpOffset func(time.Duration) (time.Duration, error),
+) *cobra.Command {
+	var (
+		interval  time.Duration
+		maxOffset time.Duration
+		timeout   time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "ntp-watch",
+		Short: "Continuously check the NTP offset of the local clock",
+		Long: `Continuously check the NTP offset of the local clock.
+
+The offset is queried every --interval through chronyc, or ntpq if chronyc
+isn't available. If it exceeds --max-offset (in either direction), the command
+logs an error and exits with a non-zero status, so it can be run as a sidecar
+or a service which alerts on clock drift. Failed queries are logged, but
+don't stop the command.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive, but got %s", interval)
+			}
+			return watchNtp(
+				func() (time.Duration, error) { return ntpOffset(timeout) },
+				interval,
+				maxOffset,
+			)
+		},
+	}
+	command.Flags().DurationVar(
+		&interval,
+		"interval",
+		time.Minute,
+		"The time to wait between NTP offset queries",
+	)
+	command.Flags().DurationVar(
+		&maxOffset,
+		"max-offset",
+		100*time.Millisecond,
+		"The maximum offset allowed between the local clock and the NTP time",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		2*time.Second,
+		"The maximum time to wait for each NTP offset query to complete",
+	)
+	return command
+}
+
+// Queries the NTP offset every interval, until it exceeds maxOffset.
+func watchNtp(
+	ntpOffset func() (time.Duration, error), interval, maxOffset time.Duration,
+) error {
+	for {
+		offset, err := ntpOffset()
+		switch {
+		case err != nil:
+			log.Warnf("Couldn't get the NTP offset: %v", err)
+		case offset > maxOffset || offset < -maxOffset:
+			return fmt.Errorf(
+				"the NTP offset (%s) exceeds the maximum allowed (%s)",
+				offset,
+				maxOffset,
+			)
+		default:
+			log.Infof("NTP offset: %s", offset)
+		}
+		time.Sleep(interval)
+	}
+}
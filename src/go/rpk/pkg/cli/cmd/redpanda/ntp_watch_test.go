@@ -0,0 +1,89 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNtpWatchCommand(t *testing.T) {
+	type reading struct {
+		offset time.Duration
+		err    error
+	}
+	tests := []struct {
+		name           string
+		args           []string
+		readings       []reading
+		expectedCalls  int
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name: "it should fail on the first out-of-bounds offset",
+			args: []string{"--max-offset", "50ms"},
+			readings: []reading{
+				{offset: 10 * time.Millisecond},
+				{offset: -20 * time.Millisecond},
+				{err: errors.New("chronyc timed out")},
+				{offset: 75 * time.Millisecond},
+				{offset: 0},
+			},
+			expectedCalls: 4,
+			expectedOutput: []string{
+				"NTP offset: 10ms",
+				"NTP offset: -20ms",
+				"Couldn't get the NTP offset: chronyc timed out",
+			},
+			expectedErrMsg: "the NTP offset (75ms) exceeds the maximum allowed (50ms)",
+		},
+		{
+			name: "it should fail if the clock is behind",
+			args: []string{"--max-offset", "1s"},
+			readings: []reading{
+				{offset: -1500 * time.Millisecond},
+			},
+			expectedCalls:  1,
+			expectedErrMsg: "the NTP offset (-1.5s) exceeds the maximum allowed (1s)",
+		},
+		{
+			name:           "it should fail if the interval isn't positive",
+			args:           []string{"--interval", "0s"},
+			expectedErrMsg: "--interval must be positive, but got 0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			calls := 0
+			ntpOffset := func(time.Duration) (time.Duration, error) {
+				require.Less(st, calls, len(tt.readings), "too many NTP queries")
+				r := tt.readings[calls]
+				calls++
+				return r.offset, r.err
+			}
+			cmd := newNtpWatchCommand(ntpOffset)
+			cmd.SetArgs(append([]string{"--interval", "1ms"}, tt.args...))
+			err := cmd.Execute()
+			require.EqualError(st, err, tt.expectedErrMsg)
+			require.Equal(st, tt.expectedCalls, calls)
+			for _, o := range tt.expectedOutput {
+				require.Contains(st, out.String(), o)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	vos "github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/hwloc"
+)
+
+const (
+	sourceFlag     = "flag"
+	sourceConfig   = "rpk.additional_start_flags"
+	sourceDetected = "detected"
+)
+
+func NewResourcesCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	return newResourcesCommand(
+		mgr,
+		func(timeout time.Duration) (string, error) {
+			return hwloc.NewHwLocCmd(vos.NewProc(), timeout).All()
+		},
+		func() (int, error) {
+			return system.GetMemTotalMB(fs)
+		},
+	)
+}
+
+func newResourcesCommand(
+	mgr config.Manager,
+	allCpus func(time.Duration) (string, error),
+	memTotalMB func() (int, error),
+) *cobra.Command {
+	var (
+		configFile string
+		memory     string
+		cpuSet     string
+		timeout    time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "resources",
+		Short: "Show the memory and CPUs redpanda will use",
+		Long: `Show the memory and CPUs redpanda will use.
+
+The values are resolved the same way 'rpk redpanda start' does: the --memory and
+--cpuset flags are used if passed, or if they're set in
+rpk.additional_start_flags. Otherwise, the memory is the total memory available
+(or the cgroup memory limit, if lower), and the CPUs are all the available
+ones.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			memSource, cpuSource := sourceFlag, sourceFlag
+			conf, err := mgr.ReadOrFind(configFile)
+			if err != nil {
+				log.Warnf(
+					"Couldn't read the config, ignoring"+
+						" rpk.additional_start_flags: %v",
+					err,
+				)
+			} else {
+				startFlags := parseFlags(conf.Rpk.AdditionalStartFlags)
+				if !cmd.Flags().Changed(memoryFlag) {
+					memory, memSource = startFlags[memoryFlag], sourceConfig
+				}
+				if !cmd.Flags().Changed(cpuSetFlag) {
+					cpuSet, cpuSource = startFlags[cpuSetFlag], sourceConfig
+				}
+			}
+			mem, source, err := resolveMemory(memory, memTotalMB)
+			if err != nil {
+				return err
+			}
+			if source == sourceFlag {
+				source = memSource
+			}
+			cpus, err := cpuSetMask(
+				cpuSet,
+				func() (string, error) { return allCpus(timeout) },
+			)
+			if err != nil {
+				return err
+			}
+			if cpuSet == "" {
+				cpuSource = sourceDetected
+			}
+			printResources(cmd.OutOrStdout(), [][]string{
+				{"Memory", mem, source},
+				{"CPU set", cpus, cpuSource},
+			})
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		configFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	command.Flags().StringVar(
+		&memory,
+		memoryFlag,
+		"",
+		"The amount of memory that would be passed to 'rpk redpanda start'",
+	)
+	command.Flags().StringVar(
+		&cpuSet,
+		cpuSetFlag,
+		"",
+		"The set of CPUs that would be passed to 'rpk redpanda start', in"+
+			" cpuset(7) format",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		10*time.Second,
+		"The maximum time to wait for the hardware detection to complete",
+	)
+	return command
+}
+
+// Returns the amount of memory redpanda will use and where it was resolved
+// from: the --memory flag, or the total memory available if it's not set.
+func resolveMemory(
+	memory string, memTotalMB func() (int, error),
+) (string, string, error) {
+	if memory != "" {
+		return memory, sourceFlag, nil
+	}
+	mb, err := memTotalMB()
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't detect the available memory: %v", err)
+	}
+	return fmt.Sprintf("%dM", mb), sourceDetected, nil
+}
+
+func printResources(out io.Writer, rows [][]string) {
+	t := ui.NewRpkTable(out)
+	t.SetHeader([]string{"Resource", "Value", "Source"})
+	t.AppendBulk(rows)
+	t.Render()
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func TestResourcesCommand(t *testing.T) {
+	notCalled := errors.New("detection shouldn't have been run")
+	tests := []struct {
+		name           string
+		args           []string
+		startFlags     []string
+		allCpus        func(time.Duration) (string, error)
+		memTotalMB     func() (int, error)
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name: "it should use the flags if they're set",
+			args: []string{"--memory", "2G", "--cpuset", "0-1,4"},
+			allCpus: func(time.Duration) (string, error) {
+				return "", notCalled
+			},
+			memTotalMB: func() (int, error) {
+				return 0, notCalled
+			},
+			expectedOutput: []string{
+				`Memory\s+2G\s+flag`,
+				`CPU set\s+PU:0-1 PU:4\s+flag`,
+			},
+		},
+		{
+			name:       "it should use the flags in rpk.additional_start_flags",
+			startFlags: []string{"--memory=1G", "--cpuset=2-3"},
+			allCpus: func(time.Duration) (string, error) {
+				return "", notCalled
+			},
+			memTotalMB: func() (int, error) {
+				return 0, notCalled
+			},
+			expectedOutput: []string{
+				`Memory\s+1G\s+rpk.additional_start_flags`,
+				`CPU set\s+PU:2-3\s+rpk.additional_start_flags`,
+			},
+		},
+		{
+			name: "it should detect the resources if the flags aren't set",
+			allCpus: func(time.Duration) (string, error) {
+				return "0x000000ff", nil
+			},
+			memTotalMB: func() (int, error) {
+				return 4096, nil
+			},
+			expectedOutput: []string{
+				`Memory\s+4096M\s+detected`,
+				`CPU set\s+0x000000ff\s+detected`,
+			},
+		},
+		{
+			name: "it should fail if the memory can't be detected",
+			allCpus: func(time.Duration) (string, error) {
+				return "0x000000ff", nil
+			},
+			memTotalMB: func() (int, error) {
+				return 0, errors.New("unable to determine cgroup memory limit")
+			},
+			expectedErrMsg: "couldn't detect the available memory:" +
+				" unable to determine cgroup memory limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			conf.Rpk.AdditionalStartFlags = tt.startFlags
+			err := mgr.Write(conf)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			cmd := newResourcesCommand(mgr, tt.allCpus, tt.memTotalMB)
+			cmd.SetArgs(append([]string{"--config", conf.ConfigFile}, tt.args...))
+			cmd.SetOut(&out)
+			err = cmd.Execute()
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			for _, o := range tt.expectedOutput {
+				require.Regexp(t, o, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	rp "github.com/vectorizedio/redpanda/src/go/rpk/pkg/redpanda"
+)
+
+func NewSeastarFlagsCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configFile  string
+		wellKnownIo string
+	)
+	sFlags := seastarFlags{}
+	command := &cobra.Command{
+		Use:   "seastar-flags",
+		Short: "Print the seastar flags 'rpk redpanda start' would pass to redpanda",
+		Long: `Print the seastar flags 'rpk redpanda start' would pass to redpanda.
+
+The flags are resolved the same way 'rpk redpanda start' resolves them, from
+the given flags and the config (e.g. rpk.additional_start_flags, rpk.smp and
+rpk.enable_memory_locking), and printed one per line.
+
+The flags are then validated, and the command exits with code 2 if any of them
+is invalid, e.g. if --cpuset is empty or --io-properties-file doesn't exist.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(ccmd *cobra.Command, _ []string) error {
+			conf, err := mgr.ReadOrFind(configFile)
+			if err != nil {
+				return err
+			}
+			updateConfigWithFlags(conf, ccmd.Flags())
+			rpArgs, err := buildRedpandaFlags(
+				fs,
+				conf,
+				nil,
+				sFlags,
+				ccmd.Flags(),
+				true,
+			)
+			if err != nil {
+				return err
+			}
+			for _, arg := range rp.SeastarArgs(rpArgs.SeastarFlags) {
+				fmt.Fprintln(ccmd.OutOrStdout(), arg)
+			}
+			errs := checkSeastarFlags(fs, rpArgs.SeastarFlags)
+			for _, e := range errs {
+				log.Error(e)
+			}
+			if len(errs) > 0 {
+				return cli.NewExitError(
+					cli.ExitConfigInvalid,
+					fmt.Errorf("found %d invalid seastar flag(s)", len(errs)),
+				)
+			}
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		configFlag,
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	addSeastarFlags(command.Flags(), &sFlags)
+	addWellKnownIOFlag(command.Flags(), &wellKnownIo)
+	return command
+}
+
+// Checks the seastar flags which redpanda would fail to start with, or would
+// ignore.
+func checkSeastarFlags(fs afero.Fs, flags map[string]string) []error {
+	errs := []error{}
+	if cpuset, ok := flags[cpuSetFlag]; ok && strings.TrimSpace(cpuset) == "" {
+		errs = append(errs, fmt.Errorf(
+			"--%s can't be empty. Remove it to use all the available CPUs",
+			cpuSetFlag,
+		))
+	}
+	if smp, ok := flags[smpFlag]; ok {
+		if n, err := strconv.Atoi(smp); err != nil || n <= 0 {
+			errs = append(errs, fmt.Errorf(
+				"--%s must be a positive integer, but got '%s'",
+				smpFlag,
+				smp,
+			))
+		}
+	}
+	if mem, ok := flags[memoryFlag]; ok {
+		if _, err := units.RAMInBytes(mem); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"--%s '%s' isn't a valid amount of memory, e.g. 4G",
+				memoryFlag,
+				mem,
+			))
+		}
+	}
+	ioPropsFile, fileSet := flags[ioPropertiesFileFlag]
+	if _, propsSet := flags[ioPropertiesFlag]; propsSet && fileSet {
+		errs = append(errs, fmt.Errorf(
+			"--%s and --%s can't be set at the same time",
+			ioPropertiesFlag,
+			ioPropertiesFileFlag,
+		))
+	}
+	if fileSet {
+		exists, err := afero.Exists(fs, ioPropsFile)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf(
+				"couldn't check whether --%s %s exists: %v",
+				ioPropertiesFileFlag,
+				ioPropsFile,
+				err,
+			))
+		case !exists:
+			errs = append(errs, fmt.Errorf(
+				"--%s %s doesn't exist",
+				ioPropertiesFileFlag,
+				ioPropsFile,
+			))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func TestSeastarFlagsCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		startFlags     []string
+		expectedFlags  string
+		expectedErrors []string
+	}{
+		{
+			name: "it should print the resolved flags",
+			args: []string{"--memory", "2G", "--cpuset", "0-1", "--smp", "2"},
+			expectedFlags: "--cpuset=0-1\n--lock-memory=false\n--memory=2G\n" +
+				"--smp=2\n",
+		},
+		{
+			name:       "it should include the flags set in the config",
+			args:       []string{"--overprovisioned"},
+			startFlags: []string{"--default-log-level=debug"},
+			expectedFlags: "--default-log-level=debug\n" +
+				"--lock-memory=false\n--overprovisioned\n",
+		},
+		{
+			name: "it should fail if the flags are invalid",
+			args: []string{
+				"--cpuset", "",
+				"--smp", "0",
+				"--io-properties-file", "/etc/redpanda/missing.yaml",
+			},
+			startFlags: []string{"--memory=lots"},
+			expectedFlags: "--cpuset\n" +
+				"--io-properties-file=/etc/redpanda/missing.yaml\n" +
+				"--lock-memory=false\n--memory=lots\n--smp=0\n",
+			expectedErrors: []string{
+				"--cpuset can't be empty. Remove it to use all the available CPUs",
+				"--smp must be a positive integer, but got '0'",
+				"--memory 'lots' isn't a valid amount of memory, e.g. 4G",
+				"--io-properties-file /etc/redpanda/missing.yaml doesn't exist",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			conf := config.Default()
+			conf.Rpk.AdditionalStartFlags = tt.startFlags
+			err := mgr.Write(conf)
+			require.NoError(st, err)
+
+			var out, logs bytes.Buffer
+			logrus.SetOutput(&logs)
+			cmd := NewSeastarFlagsCommand(fs, mgr)
+			cmd.SetArgs(append([]string{"--config", conf.ConfigFile}, tt.args...))
+			cmd.SetOut(&out)
+			err = cmd.Execute()
+			require.Equal(st, tt.expectedFlags, out.String())
+			if len(tt.expectedErrors) == 0 {
+				require.NoError(st, err)
+				return
+			}
+			require.EqualError(
+				st,
+				err,
+				fmt.Sprintf("found %d invalid seastar flag(s)", len(tt.expectedErrors)),
+			)
+			require.Equal(st, cli.ExitConfigInvalid, cli.ExitCode(err))
+			for _, e := range tt.expectedErrors {
+				require.Contains(st, logs.String(), e)
+			}
+		})
+	}
+}
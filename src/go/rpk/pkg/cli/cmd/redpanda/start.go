@@ -17,6 +17,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +33,7 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	vos "github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
 	rp "github.com/vectorizedio/redpanda/src/go/rpk/pkg/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/hwloc"
@@ -354,6 +356,14 @@ func NewStartCommand(
 				sendEnv(fs, mgr, env, conf, !prestartCfg.checkEnabled, err)
 				return err
 			}
+			// The values overridden through the environment or resolved
+			// from references aren't written to the config file, so
+			// redpanda is given the one with them instead.
+			rpArgs.ConfigFilePath, err = mgr.EffectiveConfigFile(conf)
+			if err != nil {
+				sendEnv(fs, mgr, env, conf, !prestartCfg.checkEnabled, err)
+				return err
+			}
 
 			sendEnv(fs, mgr, env, conf, !prestartCfg.checkEnabled, nil)
 			rpArgs.ExtraArgs = args
@@ -426,65 +436,76 @@ func NewStartCommand(
 		"",
 		"The advertised RPC address (<host>:<port>)",
 	)
-	command.Flags().StringVar(&sFlags.memory,
-		memoryFlag, "", "Amount of memory for redpanda to use, "+
-			"if not specified redpanda will use all available memory")
-	command.Flags().BoolVar(&sFlags.lockMemory,
-		lockMemoryFlag, false, "If set, will prevent redpanda from swapping")
-	command.Flags().StringVar(&sFlags.cpuSet, cpuSetFlag, "",
-		"Set of CPUs for redpanda to use in cpuset(7) format, "+
-			"if not specified redpanda will use all available CPUs")
+	addSeastarFlags(command.Flags(), &sFlags)
+	addWellKnownIOFlag(command.Flags(), &wellKnownIo)
 	command.Flags().StringVar(&installDirFlag,
 		"install-dir", "",
-		"Directory where redpanda has been installed")
+		"Directory where redpanda has been installed. Can also be set"+
+			" with the REDPANDA_INSTALL_DIR environment variable")
 	command.Flags().BoolVar(&prestartCfg.tuneEnabled, "tune", false,
 		"When present will enable tuning before starting redpanda")
 	command.Flags().BoolVar(&prestartCfg.checkEnabled, "check", true,
 		"When set to false will disable system checking before starting redpanda")
-	command.Flags().IntVar(&sFlags.smp, smpFlag, 0, "Restrict redpanda to"+
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		10000*time.Millisecond,
+		"The maximum time to wait for the checks and tune processes to complete. "+
+			"The value passed is a sequence of decimal numbers, each with optional "+
+			"fraction and a unit suffix, such as '300ms', '1.5s' or '2h45m'. "+
+			"Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'",
+	)
+	for flag := range flagsMap(sFlags) {
+		command.Flag(flag).Hidden = true
+	}
+	return command
+}
+
+// Registers the flags which are passed through to seastar, which both start
+// and seastar-flags take.
+func addSeastarFlags(flags *pflag.FlagSet, sFlags *seastarFlags) {
+	flags.StringVar(&sFlags.memory,
+		memoryFlag, "", "Amount of memory for redpanda to use, "+
+			"if not specified redpanda will use all available memory")
+	flags.BoolVar(&sFlags.lockMemory,
+		lockMemoryFlag, false, "If set, will prevent redpanda from swapping")
+	flags.StringVar(&sFlags.cpuSet, cpuSetFlag, "",
+		"Set of CPUs for redpanda to use in cpuset(7) format, "+
+			"if not specified redpanda will use all available CPUs")
+	flags.IntVar(&sFlags.smp, smpFlag, 0, "Restrict redpanda to"+
 		" the given number of CPUs. This option does not mandate a"+
 		" specific placement of CPUs. See --cpuset if you need to do so.")
-	command.Flags().StringVar(&sFlags.reserveMemory, reserveMemoryFlag, "",
+	flags.StringVar(&sFlags.reserveMemory, reserveMemoryFlag, "",
 		"Memory reserved for the OS (if --memory isn't specified)")
-	command.Flags().StringVar(&sFlags.hugepages, hugepagesFlag, "",
+	flags.StringVar(&sFlags.hugepages, hugepagesFlag, "",
 		"Path to accessible hugetlbfs mount (typically /dev/hugepages/something)")
-	command.Flags().BoolVar(&sFlags.threadAffinity, threadAffinityFlag, true,
+	flags.BoolVar(&sFlags.threadAffinity, threadAffinityFlag, true,
 		"Pin threads to their cpus (disable for overprovisioning)")
-	command.Flags().IntVar(&sFlags.numIoQueues, numIoQueuesFlag, 0,
+	flags.IntVar(&sFlags.numIoQueues, numIoQueuesFlag, 0,
 		"Number of IO queues. Each IO unit will be responsible for a fraction "+
 			"of the IO requests. Defaults to the number of threads")
-	command.Flags().IntVar(&sFlags.maxIoRequests, maxIoRequestsFlag, 0,
+	flags.IntVar(&sFlags.maxIoRequests, maxIoRequestsFlag, 0,
 		"Maximum amount of concurrent requests to be sent to the disk. "+
 			"Defaults to 128 times the number of IO queues")
-	command.Flags().StringVar(&sFlags.ioPropertiesFile, ioPropertiesFileFlag, "",
+	flags.StringVar(&sFlags.ioPropertiesFile, ioPropertiesFileFlag, "",
 		"Path to a YAML file describing the characteristics of the I/O Subsystem")
-	command.Flags().StringVar(&sFlags.ioProperties, ioPropertiesFlag, "",
+	flags.StringVar(&sFlags.ioProperties, ioPropertiesFlag, "",
 		"A YAML string describing the characteristics of the I/O Subsystem")
-	command.Flags().StringVar(
-		&wellKnownIo,
-		wellKnownIOFlag,
-		"",
-		"The cloud vendor and VM type, in the format <vendor>:<vm type>:<storage type>")
-	command.Flags().BoolVar(&sFlags.mbind, mbindFlag, true, "enable mbind")
-	command.Flags().BoolVar(
+	flags.BoolVar(&sFlags.mbind, mbindFlag, true, "enable mbind")
+	flags.BoolVar(
 		&sFlags.overprovisioned,
 		overprovisionedFlag,
 		false,
 		"Enable overprovisioning",
 	)
-	command.Flags().DurationVar(
-		&timeout,
-		"timeout",
-		10000*time.Millisecond,
-		"The maximum time to wait for the checks and tune processes to complete. "+
-			"The value passed is a sequence of decimal numbers, each with optional "+
-			"fraction and a unit suffix, such as '300ms', '1.5s' or '2h45m'. "+
-			"Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'",
-	)
-	for flag := range flagsMap(sFlags) {
-		command.Flag(flag).Hidden = true
-	}
-	return command
+}
+
+func addWellKnownIOFlag(flags *pflag.FlagSet, wellKnownIo *string) {
+	flags.StringVar(
+		wellKnownIo,
+		wellKnownIOFlag,
+		"",
+		"The cloud vendor and VM type, in the format <vendor>:<vm type>:<storage type>")
 }
 
 func flagsMap(sFlags seastarFlags) map[string]interface{} {
@@ -523,6 +544,9 @@ func prestart(
 		log.Info("System check - PASSED")
 	}
 	if prestartCfg.tuneEnabled {
+		warnUnprivilegedTuners(conf, func() (bool, error) {
+			return system.IsPrivileged(fs)
+		})
 		cpuset := fmt.Sprint(args.SeastarFlags[cpuSetFlag])
 		tunerPayloads, err = tuneAll(fs, cpuset, conf, timeout)
 		if err != nil {
@@ -697,27 +721,28 @@ func resolveWellKnownIo(
 	return ioProps, nil
 }
 
+// Returns the set of CPUs redpanda will use as a hwloc location: the given
+// cpuset(7) list (i.e. --cpuset), or all the available CPUs if it's empty.
+func cpuSetMask(cpuSet string, allCpus func() (string, error)) (string, error) {
+	if cpuSet == "" {
+		return allCpus()
+	}
+	return hwloc.TranslateToHwLocCpuSet(cpuSet)
+}
+
 func tuneAll(
 	fs afero.Fs, cpuSet string, conf *config.Config, timeout time.Duration,
 ) ([]api.TunerPayload, error) {
-	params := &factory.TunerParams{}
+	params := &factory.TunerParams{StartsRedpanda: true}
 	tunerFactory := factory.NewDirectExecutorTunersFactory(fs, *conf, timeout)
 	hw := hwloc.NewHwLocCmd(vos.NewProc(), timeout)
-	if cpuSet == "" {
-		cpuMask, err := hw.All()
-		if err != nil {
-			return []api.TunerPayload{}, err
-		}
-		params.CpuMask = cpuMask
-	} else {
-		cpuMask, err := hwloc.TranslateToHwLocCpuSet(cpuSet)
-		if err != nil {
-			return []api.TunerPayload{}, err
-		}
-		params.CpuMask = cpuMask
+	cpuMask, err := cpuSetMask(cpuSet, hw.All)
+	if err != nil {
+		return []api.TunerPayload{}, err
 	}
+	params.CpuMask = cpuMask
 
-	err := factory.FillTunerParamsWithValuesFromConfig(params, conf)
+	err = factory.FillTunerParamsWithValuesFromConfig(params, conf)
 	if err != nil {
 		return []api.TunerPayload{}, err
 	}
@@ -755,6 +780,47 @@ func tuneAll(
 	return tunerPayloads, nil
 }
 
+// Returns the enabled tuners if the process lacks the privileges to apply
+// them, in which case they're reported as unsupported and skipped.
+func unprivilegedTuners(
+	conf *config.Config, isPrivileged func() (bool, error),
+) ([]string, error) {
+	enabled := []string{}
+	for _, name := range factory.AvailableTuners() {
+		if factory.IsTunerEnabled(name, conf.Rpk) {
+			enabled = append(enabled, name)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, nil
+	}
+	privileged, err := isPrivileged()
+	if err != nil || privileged {
+		return nil, err
+	}
+	sort.Strings(enabled)
+	return enabled, nil
+}
+
+func warnUnprivilegedTuners(
+	conf *config.Config, isPrivileged func() (bool, error),
+) {
+	names, err := unprivilegedTuners(conf, isPrivileged)
+	if err != nil {
+		log.Debugf("Couldn't check rpk's privileges: %v", err)
+		return
+	}
+	if len(names) == 0 {
+		return
+	}
+	log.Warnf(
+		"rpk isn't running as root, nor with CAP_SYS_ADMIN, so the"+
+			" following enabled tuners will be skipped: %s. Run"+
+			" 'rpk redpanda start' as root to apply them",
+		strings.Join(names, ", "),
+	)
+}
+
 type checkFailedAction func(*tuners.CheckResult)
 
 func checkFailedActions(
@@ -972,7 +1038,15 @@ func sendEnv(
 			conf.ConfigFile,
 			err,
 		)
-		confBytes, err := json.Marshal(conf)
+		redacted, err := mgr.Redacted(conf)
+		if err != nil {
+			log.Warnf(
+				"Couldn't redact the loaded config: %s",
+				err,
+			)
+			redacted = &config.Config{}
+		}
+		confBytes, err := json.Marshal(redacted)
 		if err != nil {
 			log.Warnf(
 				"Couldn't marshal the loaded config: %s",
@@ -11,6 +11,8 @@ package redpanda
 
 import (
 	"bytes"
+	"fmt"
+	"net"
 	"os"
 	"testing"
 
@@ -21,6 +23,7 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/redpanda"
 	rp "github.com/vectorizedio/redpanda/src/go/rpk/pkg/redpanda"
+	"gopkg.in/yaml.v2"
 )
 
 type noopLauncher struct {
@@ -148,6 +151,7 @@ func TestStartCommand(t *testing.T) {
 		args           []string
 		before         func(afero.Fs) error
 		after          func()
+		interfaceAddrs config.InterfaceAddrs
 		postCheck      func(afero.Fs, *rp.RedpandaArgs, *testing.T)
 		expectedErrMsg string
 	}{{
@@ -1390,6 +1394,97 @@ func TestStartCommand(t *testing.T) {
 			}
 			require.Equal(st, expected, rpArgs.ExtraArgs)
 		},
+	}, {
+		name: "it should start redpanda with the values overridden through the environment",
+		args: []string{
+			"--install-dir", "/var/lib/redpanda",
+		},
+		before: func(fs afero.Fs) error {
+			return os.Setenv("REDPANDA_NODE_ID", "7")
+		},
+		after: func() {
+			os.Unsetenv("REDPANDA_NODE_ID")
+		},
+		postCheck: func(
+			fs afero.Fs,
+			rpArgs *rp.RedpandaArgs,
+			st *testing.T,
+		) {
+			effective := "/var/lib/redpanda/data/redpanda.effective.yaml"
+			require.Equal(st, effective, rpArgs.ConfigFilePath)
+			info, err := fs.Stat(effective)
+			require.NoError(st, err)
+			require.Equal(st, os.FileMode(0600), info.Mode().Perm())
+
+			read := func(path string) *config.Config {
+				bs, err := afero.ReadFile(fs, path)
+				require.NoError(st, err)
+				conf := &config.Config{}
+				require.NoError(st, yaml.Unmarshal(bs, conf))
+				return conf
+			}
+			require.Equal(st, 7, read(effective).Redpanda.Id)
+			// The override isn't written to the config file.
+			require.Equal(st, 0, read(config.Default().ConfigFile).Redpanda.Id)
+		},
+	}, {
+		name: "it should start redpanda with the interface references resolved",
+		args: []string{
+			"--install-dir", "/var/lib/redpanda",
+		},
+		interfaceAddrs: func(name string) ([]net.Addr, error) {
+			if name != "eth0" {
+				return nil, fmt.Errorf("no such interface %s", name)
+			}
+			return []net.Addr{&net.IPNet{
+				IP:   net.ParseIP("10.0.0.7"),
+				Mask: net.CIDRMask(24, 32),
+			}}, nil
+		},
+		before: func(fs afero.Fs) error {
+			conf := config.Default()
+			conf.Redpanda.KafkaApi[0].Address = "iface:eth0"
+			return config.NewManager(fs).Write(conf)
+		},
+		postCheck: func(
+			fs afero.Fs,
+			rpArgs *rp.RedpandaArgs,
+			st *testing.T,
+		) {
+			effective := "/var/lib/redpanda/data/redpanda.effective.yaml"
+			require.Equal(st, effective, rpArgs.ConfigFilePath)
+			read := func(path string) *config.Config {
+				bs, err := afero.ReadFile(fs, path)
+				require.NoError(st, err)
+				conf := &config.Config{}
+				require.NoError(st, yaml.Unmarshal(bs, conf))
+				return conf
+			}
+			// redpanda can bind to the interface's IP, while the reference
+			// is kept in the config file.
+			require.Equal(
+				st,
+				"10.0.0.7",
+				read(effective).Redpanda.KafkaApi[0].Address,
+			)
+			require.Equal(
+				st,
+				"iface:eth0",
+				read(config.Default().ConfigFile).Redpanda.KafkaApi[0].Address,
+			)
+		},
+	}, {
+		name: "it should start redpanda with the config file if nothing is overridden",
+		args: []string{
+			"--install-dir", "/var/lib/redpanda",
+		},
+		postCheck: func(
+			_ afero.Fs,
+			rpArgs *rp.RedpandaArgs,
+			st *testing.T,
+		) {
+			require.Equal(st, config.Default().ConfigFile, rpArgs.ConfigFilePath)
+		},
 	}}
 
 	for _, tt := range tests {
@@ -1398,7 +1493,13 @@ func TestStartCommand(t *testing.T) {
 				defer tt.after()
 			}
 			fs := afero.NewMemMapFs()
+			// The install dir passed with --install-dir must look like a
+			// valid one.
+			require.NoError(st, fs.MkdirAll("/var/lib/redpanda/lib", 0755))
+			err := afero.WriteFile(fs, "/var/lib/redpanda/bin/redpanda", []byte{}, 0755)
+			require.NoError(st, err)
 			mgr := config.NewManager(fs)
+			mgr.SetInterfaceAddrs(tt.interfaceAddrs)
 			var launcher rp.Launcher = &noopLauncher{}
 			if tt.launcher != nil {
 				launcher = tt.launcher
@@ -1410,7 +1511,7 @@ func TestStartCommand(t *testing.T) {
 			logrus.SetOutput(&out)
 			c := NewStartCommand(fs, mgr, launcher)
 			c.SetArgs(tt.args)
-			err := c.Execute()
+			err = c.Execute()
 			if tt.expectedErrMsg != "" {
 				require.EqualError(st, err, tt.expectedErrMsg)
 				return
@@ -1473,3 +1574,50 @@ func TestExtraFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestWarnUnprivilegedTuners(t *testing.T) {
+	tests := []struct {
+		name           string
+		conf           func() *config.Config
+		privileged     bool
+		expectedOutput string
+	}{
+		{
+			name: "it shouldn't warn if rpk is privileged",
+			conf: func() *config.Config {
+				c := config.Default()
+				c.Rpk.TuneCpu = true
+				return c
+			},
+			privileged: true,
+		},
+		{
+			name: "it shouldn't warn if no tuners are enabled",
+			conf: config.Default,
+		},
+		{
+			name: "it should list the enabled tuners if rpk isn't privileged",
+			conf: func() *config.Config {
+				c := config.Default()
+				c.Rpk.TuneCpu = true
+				c.Rpk.TuneAioEvents = true
+				return c
+			},
+			expectedOutput: "the following enabled tuners will be skipped: aio_events, cpu.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			warnUnprivilegedTuners(tt.conf(), func() (bool, error) {
+				return tt.privileged, nil
+			})
+			if tt.expectedOutput == "" {
+				require.Empty(st, out.String())
+				return
+			}
+			require.Contains(st, out.String(), tt.expectedOutput)
+		})
+	}
+}
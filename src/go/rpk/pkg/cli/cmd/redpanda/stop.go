@@ -11,6 +11,8 @@ package redpanda
 
 import (
 	"errors"
+	"fmt"
+	"net"
 	"strconv"
 	"syscall"
 	"time"
@@ -18,15 +20,26 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 )
 
+// The time to wait between the queries to the drain status of the node.
+const drainPollInterval = time.Second
+
 func NewStopCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	var (
-		configFile string
-		timeout    time.Duration
+		configFile             string
+		timeout                time.Duration
+		drain                  bool
+		drainTimeout           time.Duration
+		adminAPIEnableTLS      bool
+		adminAPICertFile       string
+		adminAPIKeyFile        string
+		adminAPITruststoreFile string
 	)
 	command := &cobra.Command{
 		Use:   "stop",
@@ -34,10 +47,32 @@ func NewStopCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 		Long: `Stop a local redpanda process. 'rpk stop'
 first sends SIGINT, and waits for the specified timeout. Then, if redpanda
 hasn't stopped, it sends SIGTERM. Lastly, it sends SIGKILL if it's still
-running.`,
+running.
+
+With --drain, the node is first put in maintenance mode through its admin API,
+so that it stops accepting the leadership of partitions and transfers the one
+it has to other nodes. The node is stopped once it's drained, once the
+leadership of some of its partitions fails to be transferred, or once
+--drain-timeout elapses. A warning is logged in the latter cases.
+
+The node stays in maintenance mode after it's restarted. Once it's running
+again, run 'rpk redpanda undrain' to take it out of maintenance mode.`,
 		SilenceUsage: true,
 		RunE: func(ccmd *cobra.Command, args []string) error {
-			return executeStop(fs, mgr, configFile, timeout)
+			var api func(*config.Config) (admin.AdminAPI, error)
+			if drain {
+				api = func(conf *config.Config) (admin.AdminAPI, error) {
+					return localAdminAPI(
+						fs,
+						conf,
+						&adminAPIEnableTLS,
+						&adminAPICertFile,
+						&adminAPIKeyFile,
+						&adminAPITruststoreFile,
+					)
+				}
+			}
+			return executeStop(fs, mgr, configFile, timeout, api, drainTimeout)
 		},
 	}
 	command.Flags().StringVar(
@@ -58,11 +93,39 @@ running.`,
 			" or '2h45m'. Valid time units are 'ns', 'us' (or"+
 			" 'µs'), 'ms', 's', 'm', 'h'",
 	)
+	command.Flags().BoolVar(
+		&drain,
+		"drain",
+		false,
+		"Put the node in maintenance mode and wait for it to be drained"+
+			" before stopping it",
+	)
+	command.Flags().DurationVar(
+		&drainTimeout,
+		"drain-timeout",
+		5*time.Minute,
+		"The maximum amount of time to wait for the node to be drained"+
+			" before stopping it anyway",
+	)
+	common.AddAdminAPITLSFlags(
+		command,
+		&adminAPIEnableTLS,
+		&adminAPICertFile,
+		&adminAPIKeyFile,
+		&adminAPITruststoreFile,
+	)
 	return command
 }
 
+// If api isn't nil, the node is drained through the admin API it returns
+// before it's stopped.
 func executeStop(
-	fs afero.Fs, mgr config.Manager, configFile string, timeout time.Duration,
+	fs afero.Fs,
+	mgr config.Manager,
+	configFile string,
+	timeout time.Duration,
+	api func(*config.Config) (admin.AdminAPI, error),
+	drainTimeout time.Duration,
 ) error {
 	conf, err := mgr.ReadOrFind(configFile)
 	if err != nil {
@@ -90,9 +153,116 @@ func executeStop(
 	if err != nil {
 		return err
 	}
+	if api != nil {
+		a, err := api(conf)
+		if err != nil {
+			return err
+		}
+		err = drainNode(a, conf.Redpanda.Id, drainTimeout)
+		if err != nil {
+			return err
+		}
+	}
 	return signalAndWait(fs, pid, timeout)
 }
 
+// Puts the node in maintenance mode and waits for it to be drained. It only
+// fails if the node can't be put in maintenance mode: if it isn't drained
+// before the timeout, or the leadership of some of its partitions couldn't be
+// transferred, a warning is logged so that it's stopped anyway.
+func drainNode(api admin.AdminAPI, nodeID int, timeout time.Duration) error {
+	log.Infof("Putting node %d in maintenance mode", nodeID)
+	err := api.EnableMaintenanceMode(nodeID)
+	if err != nil {
+		return fmt.Errorf(
+			"couldn't put node %d in maintenance mode: %v",
+			nodeID,
+			err,
+		)
+	}
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		status, err := api.MaintenanceStatus()
+		if err != nil {
+			log.Debugf("Couldn't get the drain status of node %d: %v", nodeID, err)
+		} else if status.Errors || status.Failed > 0 {
+			log.Warnf(
+				"Node %d couldn't be drained: the leadership of %d"+
+					" partition(s) failed to be transferred. Stopping it"+
+					" anyway",
+				nodeID,
+				status.Failed,
+			)
+			return nil
+		} else if status.Finished {
+			log.Infof("Node %d was drained", nodeID)
+			return nil
+		} else {
+			log.Debugf(
+				"Waiting for node %d to be drained (%d partition(s) transferring)",
+				nodeID,
+				status.Transferring,
+			)
+		}
+		select {
+		case <-deadline:
+			log.Warnf(
+				"Node %d wasn't drained after %s. Stopping it anyway",
+				nodeID,
+				timeout,
+			)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns a client for the admin API of the local node, configured with the
+// given TLS flags or, if they're not set, with the config's.
+func localAdminAPI(
+	fs afero.Fs,
+	conf *config.Config,
+	enableTLS *bool,
+	certFile, keyFile, truststoreFile *string,
+) (admin.AdminAPI, error) {
+	tlsConfig, err := common.BuildAdminApiTLSConfig(
+		fs,
+		enableTLS,
+		certFile,
+		keyFile,
+		truststoreFile,
+		func() (*config.Config, error) { return conf, nil },
+	)()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := localAdminAPIAddress(conf)
+	if err != nil {
+		return nil, err
+	}
+	return admin.NewAdminAPI([]string{addr}, tlsConfig)
+}
+
+// Returns the address of the node's first admin API listener, with the
+// wildcard addresses (0.0.0.0 or ::) replaced with the loopback one of the
+// same family, since the node is local.
+func localAdminAPIAddress(conf *config.Config) (string, error) {
+	if len(conf.Redpanda.AdminApi) == 0 {
+		return "", errors.New("redpanda.admin is empty, so the node's admin API can't be reached")
+	}
+	addr := conf.Redpanda.AdminApi[0]
+	host := addr.Address
+	switch ip := net.ParseIP(host); {
+	case host == "" || ip.Equal(net.IPv4zero):
+		host = loopbackAddress
+	case ip.Equal(net.IPv6unspecified):
+		host = net.IPv6loopback.String()
+	}
+	return net.JoinHostPort(host, strconv.Itoa(addr.Port)), nil
+}
+
 func signalAndWait(fs afero.Fs, pid int, timeout time.Duration) error {
 	var f func(int, []syscall.Signal) error
 	f = func(pid int, signals []syscall.Signal) error {
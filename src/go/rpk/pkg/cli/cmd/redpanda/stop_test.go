@@ -12,7 +12,11 @@ package redpanda_test
 import (
 	"bytes"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"testing"
 
@@ -20,6 +24,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/redpanda"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
@@ -94,3 +99,130 @@ func TestStopCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestStopCommandDrain(t *testing.T) {
+	tests := []struct {
+		name               string
+		enableStatus       int
+		finished           bool
+		failed             int
+		args               []string
+		expectedOutput     string
+		expectedErr        string
+		expectStillRunning bool
+	}{
+		{
+			name:           "it should drain the node before stopping it",
+			enableStatus:   http.StatusOK,
+			finished:       true,
+			expectedOutput: "Node 2 was drained",
+		},
+		{
+			name:           "it should stop the node if it isn't drained before the timeout",
+			enableStatus:   http.StatusOK,
+			args:           []string{"--drain-timeout", "100ms"},
+			expectedOutput: "Node 2 wasn't drained after 100ms. Stopping it anyway",
+		},
+		{
+			name:         "it should stop the node if some partitions fail to be transferred",
+			enableStatus: http.StatusOK,
+			failed:       3,
+			expectedOutput: "Node 2 couldn't be drained: the leadership of 3" +
+				" partition(s) failed to be transferred. Stopping it anyway",
+		},
+		{
+			name:               "it shouldn't stop the node if it can't be put in maintenance mode",
+			enableStatus:       http.StatusInternalServerError,
+			expectedErr:        "couldn't put node 2 in maintenance mode",
+			expectStillRunning: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			// The PID file's lock is checked in the actual filesystem.
+			fs := afero.NewOsFs()
+			dir := st.TempDir()
+			ecmd := exec.Command("bash", "-c", "while :; do sleep 1; done")
+			err := ecmd.Start()
+			require.NoError(st, err)
+			pid := ecmd.Process.Pid
+			defer func() {
+				ecmd.Process.Kill()
+				ecmd.Wait()
+			}()
+
+			enabled := false
+			ts := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch {
+					case r.Method == http.MethodPut &&
+						r.URL.Path == "/v1/brokers/2/maintenance":
+						// The node must still be running while it's drained.
+						running, err := os.IsRunningPID(fs, pid)
+						require.NoError(st, err)
+						require.True(st, running)
+						enabled = true
+						w.WriteHeader(tt.enableStatus)
+					case r.Method == http.MethodGet &&
+						r.URL.Path == "/v1/maintenance":
+						require.True(st, enabled)
+						fmt.Fprintf(
+							w,
+							`{"draining":true,"finished":%t,"errors":%t,"failed":%d}`,
+							tt.finished,
+							tt.failed > 0,
+							tt.failed,
+						)
+					default:
+						st.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+						w.WriteHeader(http.StatusNotFound)
+					}
+				}),
+			)
+			defer ts.Close()
+			addr := ts.Listener.Addr().(*net.TCPAddr)
+
+			conf := config.Default()
+			conf.ConfigFile = filepath.Join(dir, "redpanda.yaml")
+			conf.Redpanda.Directory = filepath.Join(dir, "data")
+			conf.Redpanda.Id = 2
+			conf.Redpanda.AdminApi[0].Address = addr.IP.String()
+			conf.Redpanda.AdminApi[0].Port = addr.Port
+			mgr := config.NewManager(fs)
+			err = mgr.Write(conf)
+			require.NoError(st, err)
+			err = fs.MkdirAll(conf.Redpanda.Directory, 0755)
+			require.NoError(st, err)
+			_, err = utils.WriteBytes(
+				fs,
+				[]byte(strconv.Itoa(pid)),
+				conf.PIDFile(),
+			)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := redpanda.NewStopCommand(fs, mgr)
+			c.SetArgs(append(
+				[]string{
+					"--config", conf.ConfigFile,
+					"--timeout", "100ms",
+					"--drain",
+				},
+				tt.args...,
+			))
+			err = c.Execute()
+			if tt.expectedErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErr)
+			} else {
+				require.NoError(st, err)
+				require.Contains(st, out.String(), tt.expectedOutput)
+			}
+
+			running, err := os.IsRunningPID(fs, pid)
+			require.NoError(st, err)
+			require.Equal(st, tt.expectStillRunning, running)
+		})
+	}
+}
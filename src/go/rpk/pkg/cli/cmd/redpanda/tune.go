@@ -24,9 +24,12 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
 	tunecmd "github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/redpanda/tune"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/hwloc"
 )
@@ -47,6 +50,7 @@ func NewTuneCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 		cpuSet            string
 		timeout           time.Duration
 		interactive       bool
+		simulate          bool
 	)
 	baseMsg := "Sets the OS parameters to tune system performance." +
 		" Available tuners: all, " +
@@ -77,6 +81,9 @@ func NewTuneCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 			if !tunerParamsEmpty(&tunerParams) && configFile != "" {
 				return errors.New("Use either tuner params or redpanda config file")
 			}
+			if simulate && outTuneScriptFile != "" {
+				return errors.New("Use either --simulate or --output-script")
+			}
 			var tuners []string
 			if args[0] == "all" {
 				tuners = factory.AvailableTuners()
@@ -88,7 +95,15 @@ func NewTuneCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 				return err
 			}
 			tunerParams.CpuMask = cpuMask
-			conf, err := mgr.FindOrGenerate(configFile)
+			var conf *config.Config
+			if simulate {
+				// A simulation mustn't write anything, so the
+				// default config is used if there's none, rather
+				// than generating it.
+				conf, err = common.FindConfigFile(mgr, &configFile)()
+			} else {
+				conf, err = mgr.FindOrGenerate(configFile)
+			}
 			if err != nil {
 				if !interactive {
 					return err
@@ -115,7 +130,23 @@ Would you like to continue with the default configuration?`,
 				tunerFactory = factory.NewDirectExecutorTunersFactory(
 					fs, *conf, timeout)
 			}
-			return tune(fs, conf, tuners, tunerFactory, &tunerParams)
+			if simulate {
+				return simulateTune(
+					conf,
+					tuners,
+					tunerFactory,
+					&tunerParams,
+					cmd.OutOrStdout(),
+				)
+			}
+			return tune(
+				fs,
+				conf,
+				tuners,
+				tunerFactory,
+				&tunerParams,
+				args[0] != "all",
+			)
 		},
 	}
 	command.Flags().StringVarP(&tunerParams.Mode,
@@ -165,7 +196,16 @@ Would you like to continue with the default configuration?`,
 		"Ask for confirmation on every step (e.g. tuner execution,"+
 			" configuration generation)",
 	)
+	command.Flags().BoolVar(
+		&simulate,
+		"simulate",
+		false,
+		"Print the current values and the ones the tuners would set, without"+
+			" changing anything. Doesn't require root privileges",
+	)
 	command.AddCommand(tunecmd.NewHelpCommand())
+	command.AddCommand(newTuneListCommand(fs, mgr))
+	command.AddCommand(newTuneScoreCommand(fs, mgr))
 	return command
 }
 
@@ -205,6 +245,7 @@ func tune(
 	tunerNames []string,
 	tunersFactory factory.TunersFactory,
 	params *factory.TunerParams,
+	explicit bool,
 ) error {
 	params, err := factory.MergeTunerParamsConfig(params, conf)
 	if err != nil {
@@ -215,6 +256,7 @@ func tune(
 	results := []result{}
 	includeErr := false
 	allDisabled := true
+	unsupported := []string{}
 	for _, tunerName := range tunerNames {
 		enabled := factory.IsTunerEnabled(tunerName, conf.Rpk)
 		allDisabled = allDisabled && !enabled
@@ -222,6 +264,9 @@ func tune(
 		supported, reason := tuner.CheckIfSupported()
 		if !enabled || !supported {
 			includeErr = includeErr || !supported
+			if enabled && !supported {
+				unsupported = append(unsupported, tunerName)
+			}
 			results = append(results, result{tunerName, false, enabled, supported, reason})
 			continue
 		}
@@ -254,6 +299,77 @@ func tune(
 			strings.Join(tunerNames, ","),
 		)
 	}
+	// Unsupported tuners are only an error if they were explicitly
+	// requested, since 'all' is expected to skip them.
+	if explicit && len(unsupported) > 0 {
+		return cli.NewExitError(
+			cli.ExitTunerUnsupported,
+			fmt.Errorf(
+				"the following tuners aren't supported in this system: %s",
+				strings.Join(unsupported, ", "),
+			),
+		)
+	}
+	return nil
+}
+
+// Runs the checks associated with each tuner and prints the current values,
+// and the ones the tuner would set, without tuning anything.
+func simulateTune(
+	conf *config.Config,
+	tunerNames []string,
+	tunersFactory factory.TunersFactory,
+	params *factory.TunerParams,
+	out io.Writer,
+) error {
+	params, err := factory.MergeTunerParamsConfig(params, conf)
+	if err != nil {
+		return err
+	}
+	sort.Strings(tunerNames)
+
+	t := ui.NewRpkTable(out)
+	t.SetHeader([]string{
+		"Tuner",
+		"Enabled",
+		"Supported",
+		"Check",
+		"Current",
+		"Would Set",
+		"Would Change",
+		"Notes",
+	})
+	for _, tunerName := range tunerNames {
+		enabled := strconv.FormatBool(factory.IsTunerEnabled(tunerName, conf.Rpk))
+		tuner := tunersFactory.CreateTuner(tunerName, params)
+		supported, reason := tuner.CheckIfSupported()
+		if !supported {
+			t.Append([]string{tunerName, enabled, "false", "", "", "", "false", reason})
+			continue
+		}
+		results, err := tuners.Simulate(tuner)
+		if err != nil {
+			t.Append([]string{tunerName, enabled, "true", "", "", "", "", err.Error()})
+			continue
+		}
+		for _, res := range results {
+			notes := ""
+			if res.Err != nil {
+				notes = res.Err.Error()
+			}
+			t.Append([]string{
+				tunerName,
+				enabled,
+				"true",
+				res.Desc,
+				res.Current,
+				res.Required,
+				strconv.FormatBool(res.Err == nil && !res.IsOk),
+				notes,
+			})
+		}
+	}
+	t.Render()
 	return nil
 }
 
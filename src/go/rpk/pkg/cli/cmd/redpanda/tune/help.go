@@ -30,6 +30,12 @@ func NewHelpCommand() *cobra.Command {
 		"transparent_hugepages": transparentHugepagesTunerHelp,
 		"clocksource":           clocksourceTunerHelp,
 		"nomerges":              nomergesTunerHelp,
+		"swapfile":              swapfileTunerHelp,
+		"dirty_pages":           dirtyPagesTunerHelp,
+		"cstates":               cstatesTunerHelp,
+		"max_map_count":         maxMapCountTunerHelp,
+		"fd_limit":              fdLimitTunerHelp,
+		"mount_options":         mountOptionsTunerHelp,
 	}
 
 	return &cobra.Command{
@@ -170,6 +176,32 @@ Tunes the kernel to keep process data in-memory for as long as possible, instead
 of swapping it out to disk.
 `
 
+const swapfileTunerHelp = `
+Creates and enables a swap file if swap isn't enabled already. It's disabled by
+default, and can be enabled by setting 'rpk.tune_swapfile' to true. The swap
+file's path and size can be set with 'rpk.swapfile_path' (default '/swapfile')
+and 'rpk.swapfile_size' (default '1G', in any format accepted by 'fallocate -l').
+
+The swap file isn't added to /etc/fstab, so it won't be enabled again after a
+reboot unless the tuner runs again.
+`
+
+const dirtyPagesTunerHelp = `
+Sets the thresholds of dirty pages (data written to the page cache, but not to
+disk yet) at which the kernel starts flushing them in the background
+(vm.dirty_background_ratio) and at which writers are blocked until they're
+flushed (vm.dirty_ratio). Lower values avoid long write stalls under redpanda's
+append-heavy load, especially on machines with lots of memory.
+
+It's disabled by default, and can be enabled by setting 'rpk.tune_dirty_pages'
+to true. The values can be set with 'rpk.dirty_ratio' (default 10) and
+'rpk.dirty_background_ratio' (default 5), or as an absolute amount of bytes with
+'rpk.dirty_bytes' and 'rpk.dirty_background_bytes', which can't be set along
+with their ratio counterparts. If 'rpk.persist_dirty_pages' is true, the values
+are also written to /etc/sysctl.d/90-redpanda-dirty-pages.conf, so that they're
+applied on boot.
+`
+
 const fstrimTunerHelp = `
 Will start the default 'fstrim' systemd service, which runs in the background on
 a weekly basis and "trims" or "wipes" blocks which are not in use by the
@@ -189,6 +221,11 @@ const aioEventsTunerHelp = `
 Increases the maximum number of outstanding asynchronous IO operations if the
 current value is below a certain threshold. This allows redpanda to make as many
 simultaneous IO requests as possible, increasing throughput.
+
+The threshold (fs.aio-max-nr) scales with the number of shards redpanda runs
+('rpk.smp', or one per CPU), and is never below 1048576. If
+'rpk.persist_aio_events' is true, the value is also written to
+/etc/sysctl.d/90-redpanda-aio-max-nr.conf, so that it's applied on boot.
 `
 
 const transparentHugepagesTunerHelp = `
@@ -199,14 +236,62 @@ means less time is spent searching and loading pages.
 `
 
 const clocksourceTunerHelp = `
-Sets the clock source to TSC (Time Stamp Counter) to get the time more
-efficiently via the Virtual Dynamic Shared Object. Most VMs run on Xen, with
-'xen' as the default clock source, which doesn't support reading the time in
-userspace via the vDSO, requiring making an actual syscall with the overhead it
-entails.
+Sets the clock source to TSC (Time Stamp Counter), or to arch_sys_counter on
+arm64, to get the time more efficiently via the Virtual Dynamic Shared Object.
+Most VMs run on Xen, with 'xen' as the default clock source, which doesn't
+support reading the time in userspace via the vDSO, requiring making an actual
+syscall with the overhead it entails.
+
+On some hypervisors, the clock source changes silently after a live migration,
+which causes time jumps. 'rpk redpanda check' reports it, and running this tuner
+again sets it back.
 `
 
 const nomergesTunerHelp = `
 Disables merging adjacent IO requests, which would require checking outstanding
 IO requests to batch them where possible, incurring in some CPU overhead.
 `
+
+const cstatesTunerHelp = `
+Disables the CPU idle states (C-states) deeper than 'rpk.max_cstate' (1 by
+default) on every CPU. Waking up from the deeper states takes longer, which adds
+latency to the requests arriving while the CPUs are idle.
+
+The states are disabled by writing to
+/sys/devices/system/cpu/cpu*/cpuidle/state*/disable, which doesn't persist
+across reboots. On VMs, the idle states are up to the hypervisor, so the tuner
+is reported as unsupported.
+`
+
+const maxMapCountTunerHelp = `
+Raises the maximum number of memory map areas a process may have
+(vm.max_map_count) to 1048576 if it's below that. Seastar maps memory per
+shard, and with the kernel's default (65530) redpanda can crash with "too many
+open memory mappings".
+
+The value is written to /proc/sys/vm/max_map_count, which doesn't persist
+across reboots.
+`
+
+const fdLimitTunerHelp = `
+Raises the soft limit on the number of open file descriptors (RLIMIT_NOFILE) to
+the hard limit if it's below 'rpk.min_fd_limit' (1048576 by default). Redpanda
+keeps its segment files open, and crashes with EMFILE once it runs out.
+
+Redpanda inherits the limit from the 'rpk redpanda start' process it replaces,
+so the tuner is only supported when run by 'rpk redpanda start --tune', and is
+reported as unsupported by 'rpk redpanda tune'. Only privileged processes can
+raise the hard limit, so if it's below the minimum the tuner is reported as
+unsupported too; raise it with LimitNOFILE in redpanda's systemd unit instead.
+`
+
+const mountOptionsTunerHelp = `
+Checks that the filesystem the data directory is on is mounted with 'noatime'
+(which implies 'nodiratime'), so that reading a file doesn't update its access
+time, and without disabling the write barriers ('nobarrier' or 'barrier=0'),
+which the filesystem needs to keep its journal consistent on power loss.
+
+Remounting the filesystem redpanda's data is on is risky, so the tuner doesn't
+change anything: it prints the /etc/fstab entry with the recommended options
+instead, which is rendered as a comment when generating a tuning script.
+`
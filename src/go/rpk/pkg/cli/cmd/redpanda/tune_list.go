@@ -0,0 +1,106 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
+)
+
+func newTuneListCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	return newTuneListCommandWithFactory(
+		mgr,
+		func(conf *config.Config, timeout time.Duration) factory.TunersFactory {
+			return factory.NewDirectExecutorTunersFactory(fs, *conf, timeout)
+		},
+	)
+}
+
+func newTuneListCommandWithFactory(
+	mgr config.Manager,
+	newFactory func(*config.Config, time.Duration) factory.TunersFactory,
+) *cobra.Command {
+	var (
+		configFile string
+		timeout    time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List the available tuners, and whether they're supported and enabled",
+		Long: `List the available tuners, and whether they're supported and enabled.
+
+A tuner is supported if it can run on this host, and enabled if it's turned on
+in the config (see 'rpk redpanda mode'). Only the tuners which are both
+supported and enabled are run by 'rpk redpanda tune'.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			conf, err := mgr.FindOrGenerate(configFile)
+			if err != nil {
+				return err
+			}
+			params, err := factory.MergeTunerParamsConfig(
+				&factory.TunerParams{},
+				conf,
+			)
+			if err != nil {
+				log.Warnf("Couldn't detect the tuners' parameters: %v", err)
+			}
+			tunerFactory := newFactory(conf, timeout)
+			tunerNames := factory.AvailableTuners()
+			sort.Strings(tunerNames)
+
+			t := ui.NewRpkTable(cmd.OutOrStdout())
+			t.SetAutoWrapText(false)
+			t.SetHeader([]string{
+				"Tuner",
+				"Description",
+				"Supported",
+				"Enabled",
+				"Notes",
+			})
+			for _, tunerName := range tunerNames {
+				tuner := tunerFactory.CreateTuner(tunerName, params)
+				supported, reason := tuner.CheckIfSupported()
+				t.Append([]string{
+					tunerName,
+					factory.TunerDescription(tunerName),
+					strconv.FormatBool(supported),
+					strconv.FormatBool(factory.IsTunerEnabled(tunerName, conf.Rpk)),
+					reason,
+				})
+			}
+			t.Render()
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		"config",
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		10*time.Second,
+		"The maximum time to wait for the support checks to complete",
+	)
+	return command
+}
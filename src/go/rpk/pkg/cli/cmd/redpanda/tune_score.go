@@ -0,0 +1,228 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
+)
+
+type failedTuneCheck struct {
+	Check    string `json:"check"`
+	Current  string `json:"current"`
+	Required string `json:"required"`
+	Error    string `json:"error,omitempty"`
+}
+
+type nonCompliantTuner struct {
+	Tuner  string            `json:"tuner"`
+	Checks []failedTuneCheck `json:"checks"`
+}
+
+type skippedTuner struct {
+	Tuner  string `json:"tuner"`
+	Reason string `json:"reason"`
+}
+
+// The share of the tuners which are in the recommended state, out of the
+// ones that could be checked. Unsupported tuners, and the ones which can't
+// be checked without running them, are skipped.
+type tuneScore struct {
+	Score        float64             `json:"score"`
+	Compliant    int                 `json:"compliant"`
+	Checked      int                 `json:"checked"`
+	NonCompliant []nonCompliantTuner `json:"non_compliant"`
+	Skipped      []skippedTuner      `json:"skipped"`
+}
+
+func newTuneScoreCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	return newTuneScoreCommandWithFactory(
+		mgr,
+		func(conf *config.Config, timeout time.Duration) factory.TunersFactory {
+			return factory.NewDirectExecutorTunersFactory(fs, *conf, timeout)
+		},
+	)
+}
+
+func newTuneScoreCommandWithFactory(
+	mgr config.Manager,
+	newFactory func(*config.Config, time.Duration) factory.TunersFactory,
+) *cobra.Command {
+	var (
+		configFile string
+		format     string
+		timeout    time.Duration
+	)
+	command := &cobra.Command{
+		Use:   "score",
+		Short: "Print the percentage of the tuners which are in the recommended state",
+		Long: `Print the percentage of the tuners which are in the recommended state.
+
+Every tuner's checks are run, without changing anything, and the tuners whose
+checks all pass are counted as compliant. The tuners which aren't supported on
+this host, or which can't be checked without running them, are skipped. The
+non-compliant tuners are listed along with the checks that failed.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf(
+					"unsupported format '%s', it must be 'text' or 'json'",
+					format,
+				)
+			}
+			conf, err := mgr.FindOrGenerate(configFile)
+			if err != nil {
+				return err
+			}
+			params, err := factory.MergeTunerParamsConfig(
+				&factory.TunerParams{},
+				conf,
+			)
+			if err != nil {
+				log.Warnf("Couldn't detect the tuners' parameters: %v", err)
+			}
+			score := computeTuneScore(
+				newFactory(conf, timeout),
+				factory.AvailableTuners(),
+				params,
+			)
+			if format == "json" {
+				out, err := json.MarshalIndent(score, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return nil
+			}
+			printTuneScore(cmd.OutOrStdout(), score)
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		"config",
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	command.Flags().StringVar(
+		&format,
+		"format",
+		"text",
+		"The output format. Can be 'text' or 'json'",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		10*time.Second,
+		"The maximum time to wait for the checks to complete",
+	)
+	return command
+}
+
+func computeTuneScore(
+	tunersFactory factory.TunersFactory,
+	tunerNames []string,
+	params *factory.TunerParams,
+) tuneScore {
+	names := append([]string{}, tunerNames...)
+	sort.Strings(names)
+	score := tuneScore{
+		NonCompliant: []nonCompliantTuner{},
+		Skipped:      []skippedTuner{},
+	}
+	for _, tunerName := range names {
+		tuner := tunersFactory.CreateTuner(tunerName, params)
+		supported, reason := tuner.CheckIfSupported()
+		if !supported {
+			score.Skipped = append(score.Skipped, skippedTuner{tunerName, reason})
+			continue
+		}
+		results, err := tuners.Simulate(tuner)
+		if err != nil {
+			score.Skipped = append(score.Skipped, skippedTuner{tunerName, err.Error()})
+			continue
+		}
+		score.Checked++
+		failed := []failedTuneCheck{}
+		for _, res := range results {
+			if res.IsOk && res.Err == nil {
+				continue
+			}
+			check := failedTuneCheck{
+				Check:    res.Desc,
+				Current:  res.Current,
+				Required: res.Required,
+			}
+			if res.Err != nil {
+				check.Error = res.Err.Error()
+			}
+			failed = append(failed, check)
+		}
+		if len(failed) > 0 {
+			score.NonCompliant = append(
+				score.NonCompliant,
+				nonCompliantTuner{tunerName, failed},
+			)
+			continue
+		}
+		score.Compliant++
+	}
+	if score.Checked > 0 {
+		pct := float64(score.Compliant) / float64(score.Checked) * 100
+		score.Score = math.Round(pct*10) / 10
+	}
+	return score
+}
+
+func printTuneScore(out io.Writer, score tuneScore) {
+	fmt.Fprintf(
+		out,
+		"Tuning score: %.1f%% (%d of %d checked tuners in the recommended state)\n",
+		score.Score,
+		score.Compliant,
+		score.Checked,
+	)
+	if len(score.NonCompliant) > 0 {
+		fmt.Fprintln(out, "\nNon-compliant tuners")
+		t := ui.NewRpkTable(out)
+		t.SetAutoWrapText(false)
+		t.SetHeader([]string{"Tuner", "Check", "Current", "Required", "Notes"})
+		for _, tuner := range score.NonCompliant {
+			for _, c := range tuner.Checks {
+				t.Append([]string{tuner.Tuner, c.Check, c.Current, c.Required, c.Error})
+			}
+		}
+		t.Render()
+	}
+	if len(score.Skipped) > 0 {
+		fmt.Fprintln(out, "\nSkipped tuners")
+		t := ui.NewRpkTable(out)
+		t.SetAutoWrapText(false)
+		t.SetHeader([]string{"Tuner", "Reason"})
+		for _, s := range score.Skipped {
+			t.Append([]string{s.Tuner, s.Reason})
+		}
+		t.Render()
+	}
+}
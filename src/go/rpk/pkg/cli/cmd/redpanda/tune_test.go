@@ -11,13 +11,20 @@ package redpanda
 
 import (
 	"bytes"
+	"encoding/json"
 	"log"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 )
 
@@ -92,3 +99,274 @@ func TestInteractive(t *testing.T) {
 		})
 	}
 }
+
+func TestSimulate(t *testing.T) {
+	const swappinessFile = "/proc/sys/vm/swappiness"
+	conf := config.Default()
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	err := mgr.Write(conf)
+	require.NoError(t, err)
+	_, err = utils.WriteBytes(fs, []byte("60"), swappinessFile)
+	require.NoError(t, err)
+
+	before := readAllFiles(t, fs)
+
+	var out bytes.Buffer
+	cmd := NewTuneCommand(fs, mgr)
+	cmd.SetArgs([]string{"swappiness", "--simulate", "--config", conf.ConfigFile})
+	cmd.SetOut(&out)
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	// Nothing should have been written.
+	require.Equal(t, before, readAllFiles(t, fs))
+
+	// The intended changes should be reported.
+	output := out.String()
+	require.Contains(t, output, "Swappiness")
+	require.Regexp(t, `swappiness\s+false\s+true\s+Swappiness\s+60\s+1\s+true`, output)
+}
+
+func TestSimulateWithoutConfig(t *testing.T) {
+	const swappinessFile = "/proc/sys/vm/swappiness"
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	_, err := utils.WriteBytes(fs, []byte("60"), swappinessFile)
+	require.NoError(t, err)
+
+	before := readAllFiles(t, fs)
+
+	var out bytes.Buffer
+	cmd := NewTuneCommand(fs, mgr)
+	cmd.SetArgs([]string{"swappiness", "--simulate"})
+	cmd.SetOut(&out)
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	// The default config should be used without generating it.
+	require.Equal(t, before, readAllFiles(t, fs))
+	exists, err := afero.Exists(fs, config.Default().ConfigFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Regexp(t, `swappiness\s+false\s+true\s+Swappiness\s+60\s+1\s+true`, out.String())
+}
+
+type fakeTunable struct {
+	supported bool
+	reason    string
+}
+
+func (t *fakeTunable) CheckIfSupported() (bool, string) {
+	return t.supported, t.reason
+}
+
+func (*fakeTunable) Tune() tuners.TuneResult {
+	return tuners.NewTuneResult(false)
+}
+
+type fakeTunersFactory struct {
+	unsupported map[string]string
+}
+
+func (f *fakeTunersFactory) CreateTuner(
+	tunerName string, _ *factory.TunerParams,
+) tuners.Tunable {
+	reason, unsupported := f.unsupported[tunerName]
+	return &fakeTunable{supported: !unsupported, reason: reason}
+}
+
+func TestTuneList(t *testing.T) {
+	conf := config.Default()
+	conf.Rpk.TuneSwappiness = true
+	conf.Rpk.TuneCpu = true
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	err := mgr.Write(conf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	cmd := newTuneListCommandWithFactory(
+		mgr,
+		func(*config.Config, time.Duration) factory.TunersFactory {
+			return &fakeTunersFactory{unsupported: map[string]string{
+				"cpu": "Unable to run hwloc",
+			}}
+		},
+	)
+	cmd.SetArgs([]string{"--config", conf.ConfigFile})
+	cmd.SetOut(&out)
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	output := out.String()
+	for _, tuner := range factory.AvailableTuners() {
+		require.Regexp(t, `(?m)^\s*`+tuner+`\s`, output)
+	}
+	require.Regexp(t, `cpu\s+.+\s+false\s+true\s+Unable to run hwloc`, output)
+	require.Regexp(t, `swappiness\s+.+\s+true\s+true`, output)
+	require.Regexp(t, `swapfile\s+.+\s+true\s+false`, output)
+}
+
+func TestTuneUnsupportedExitCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		explicit     bool
+		expectedCode int
+	}{
+		{
+			name:         "it should fail if an explicitly requested tuner is unsupported",
+			explicit:     true,
+			expectedCode: cli.ExitTunerUnsupported,
+		},
+		{
+			name:         "it shouldn't fail for unsupported tuners when tuning all",
+			explicit:     false,
+			expectedCode: cli.ExitOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			conf := config.Default()
+			conf.Rpk.TuneCpu = true
+			conf.Rpk.TuneSwappiness = true
+			f := &fakeTunersFactory{unsupported: map[string]string{
+				"cpu": "Unable to run hwloc",
+			}}
+			params := &factory.TunerParams{Nics: []string{"eth0"}}
+			err := tune(
+				afero.NewMemMapFs(),
+				conf,
+				[]string{"cpu", "swappiness"},
+				f,
+				params,
+				tt.explicit,
+			)
+			require.Equal(st, tt.expectedCode, cli.ExitCode(err))
+			if tt.expectedCode != cli.ExitOK {
+				require.EqualError(
+					st,
+					err,
+					"the following tuners aren't supported in this system: cpu",
+				)
+			}
+		})
+	}
+}
+
+type scoreTunersFactory struct {
+	tunables map[string]tuners.Tunable
+}
+
+func (f *scoreTunersFactory) CreateTuner(
+	tunerName string, _ *factory.TunerParams,
+) tuners.Tunable {
+	return f.tunables[tunerName]
+}
+
+func newScoreTunable(current, required int) tuners.Tunable {
+	return tuners.NewCheckedTunable(
+		tuners.NewEqualityChecker(
+			tuners.Swappiness,
+			"Fake check",
+			tuners.Warning,
+			required,
+			func() (interface{}, error) { return current, nil },
+		),
+		func() tuners.TuneResult { return tuners.NewTuneResult(false) },
+		func() (bool, string) { return true, "" },
+		false,
+	)
+}
+
+func TestTuneScore(t *testing.T) {
+	f := &scoreTunersFactory{tunables: map[string]tuners.Tunable{
+		"a_compliant":     newScoreTunable(1, 1),
+		"b_compliant":     newScoreTunable(2, 2),
+		"c_compliant":     newScoreTunable(3, 3),
+		"d_non_compliant": newScoreTunable(60, 1),
+		"e_unsupported":   &fakeTunable{supported: false, reason: "Not on this host"},
+		"f_unchecked":     &fakeTunable{supported: true},
+	}}
+	names := []string{}
+	for name := range f.tunables {
+		names = append(names, name)
+	}
+
+	score := computeTuneScore(f, names, &factory.TunerParams{})
+	require.Equal(t, 3, score.Compliant)
+	require.Equal(t, 4, score.Checked)
+	require.Equal(t, 75.0, score.Score)
+	require.Equal(t, []nonCompliantTuner{{
+		Tuner: "d_non_compliant",
+		Checks: []failedTuneCheck{{
+			Check:    "Fake check",
+			Current:  "60",
+			Required: "1",
+		}},
+	}}, score.NonCompliant)
+	require.Equal(t, []skippedTuner{
+		{"e_unsupported", "Not on this host"},
+		{"f_unchecked", tuners.ErrSimulationUnsupported.Error()},
+	}, score.Skipped)
+
+	var out bytes.Buffer
+	printTuneScore(&out, score)
+	require.Contains(
+		t,
+		out.String(),
+		"Tuning score: 75.0% (3 of 4 checked tuners in the recommended state)",
+	)
+	require.Regexp(t, `d_non_compliant\s+Fake check\s+60\s+1`, out.String())
+}
+
+func TestTuneScoreJSON(t *testing.T) {
+	conf := config.Default()
+	fs := afero.NewMemMapFs()
+	mgr := config.NewManager(fs)
+	err := mgr.Write(conf)
+	require.NoError(t, err)
+
+	tunables := map[string]tuners.Tunable{}
+	for i, name := range factory.AvailableTuners() {
+		// Every other tuner is compliant.
+		tunables[name] = newScoreTunable(i%2, 0)
+	}
+	var out bytes.Buffer
+	cmd := newTuneScoreCommandWithFactory(
+		mgr,
+		func(*config.Config, time.Duration) factory.TunersFactory {
+			return &scoreTunersFactory{tunables: tunables}
+		},
+	)
+	cmd.SetArgs([]string{"--config", conf.ConfigFile, "--format", "json"})
+	cmd.SetOut(&out)
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	score := tuneScore{}
+	err = json.Unmarshal(out.Bytes(), &score)
+	require.NoError(t, err)
+	total := len(factory.AvailableTuners())
+	require.Equal(t, total, score.Checked)
+	require.Equal(t, (total+1)/2, score.Compliant)
+	require.Len(t, score.NonCompliant, total/2)
+	require.Empty(t, score.Skipped)
+}
+
+func readAllFiles(t *testing.T, fs afero.Fs) map[string]string {
+	files := map[string]string{}
+	err := afero.Walk(fs, "/", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		bs, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		files[path] = string(bs)
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
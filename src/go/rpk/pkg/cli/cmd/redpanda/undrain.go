@@ -0,0 +1,84 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func NewUndrainCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var (
+		configFile             string
+		adminAPIEnableTLS      bool
+		adminAPICertFile       string
+		adminAPIKeyFile        string
+		adminAPITruststoreFile string
+	)
+	command := &cobra.Command{
+		Use:   "undrain",
+		Short: "Take the local node out of maintenance mode.",
+		Long: `Take the local node out of maintenance mode through its admin API, so
+that it accepts the leadership of partitions again.
+
+A node drained with 'rpk redpanda stop --drain' stays in maintenance mode
+after it's restarted, so this should be run once it's running again.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			conf, err := mgr.ReadOrFind(configFile)
+			if err != nil {
+				return err
+			}
+			api, err := localAdminAPI(
+				fs,
+				conf,
+				&adminAPIEnableTLS,
+				&adminAPICertFile,
+				&adminAPIKeyFile,
+				&adminAPITruststoreFile,
+			)
+			if err != nil {
+				return err
+			}
+			nodeID := conf.Redpanda.Id
+			err = api.DisableMaintenanceMode(nodeID)
+			if err != nil {
+				return fmt.Errorf(
+					"couldn't take node %d out of maintenance mode: %v",
+					nodeID,
+					err,
+				)
+			}
+			log.Infof("Node %d was taken out of maintenance mode", nodeID)
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		"config",
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations",
+	)
+	common.AddAdminAPITLSFlags(
+		command,
+		&adminAPIEnableTLS,
+		&adminAPICertFile,
+		&adminAPIKeyFile,
+		&adminAPITruststoreFile,
+	)
+	return command
+}
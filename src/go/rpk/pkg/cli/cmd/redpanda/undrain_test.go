@@ -0,0 +1,82 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func TestUndrainCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		expectedOutput string
+		expectedErr    string
+	}{
+		{
+			name:           "it should take the node out of maintenance mode",
+			status:         http.StatusOK,
+			expectedOutput: "Node 2 was taken out of maintenance mode",
+		},
+		{
+			name:        "it should fail if the admin API request fails",
+			status:      http.StatusInternalServerError,
+			expectedErr: "couldn't take node 2 out of maintenance mode",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			disabled := false
+			ts := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					require.Exactly(st, http.MethodDelete, r.Method)
+					require.Exactly(st, "/v1/brokers/2/maintenance", r.URL.Path)
+					disabled = true
+					w.WriteHeader(tt.status)
+				}),
+			)
+			defer ts.Close()
+			addr := ts.Listener.Addr().(*net.TCPAddr)
+
+			fs := afero.NewMemMapFs()
+			conf := config.Default()
+			conf.Redpanda.Id = 2
+			conf.Redpanda.AdminApi[0].Address = addr.IP.String()
+			conf.Redpanda.AdminApi[0].Port = addr.Port
+			mgr := config.NewManager(fs)
+			err := mgr.Write(conf)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			c := redpanda.NewUndrainCommand(fs, mgr)
+			c.SetArgs([]string{"--config", conf.ConfigFile})
+			err = c.Execute()
+			require.True(st, disabled)
+			if tt.expectedErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+			require.Contains(st, out.String(), tt.expectedOutput)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/api/admin"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/common"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func NewValidateConfigCommand(
+	fs afero.Fs, mgr config.Manager,
+) *cobra.Command {
+	var (
+		against                string
+		adminAPIEnableTLS      bool
+		adminAPICertFile       string
+		adminAPIKeyFile        string
+		adminAPITruststoreFile string
+		resolve                bool
+	)
+	command := &cobra.Command{
+		Use:   "validate-config [path]",
+		Short: "Validate the redpanda config file",
+		Long: `Validate the redpanda config file and exit.
+
+If path is omitted, the config file will be searched for in the default
+locations. The command doesn't modify the config or start anything, so it's
+suitable to be run e.g. in an init container. It exits with code 2 if the
+config is invalid.
+
+With --against, the redpanda properties in the config are also checked against
+the ones supported by the node whose admin API is at the given address, which
+catches the properties an older config has but the node's version renamed or
+removed.
+
+With --resolve, the seed servers' hostnames are also looked up, which catches
+typos in them. Otherwise, they're only checked to be valid hostnames.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			var api admin.AdminAPI
+			if against != "" {
+				tlsConfig, err := common.BuildAdminApiTLSConfig(
+					fs,
+					&adminAPIEnableTLS,
+					&adminAPICertFile,
+					&adminAPIKeyFile,
+					&adminAPITruststoreFile,
+					func() (*config.Config, error) {
+						return mgr.ReadOrFind(path)
+					},
+				)()
+				if err != nil {
+					return err
+				}
+				api, err = admin.NewAdminAPI([]string{against}, tlsConfig)
+				if err != nil {
+					return err
+				}
+			}
+			return executeValidateConfig(fs, mgr, path, api, against, resolve)
+		},
+	}
+	command.Flags().StringVar(
+		&against,
+		"against",
+		"",
+		"The admin API address of a node to check the supported properties"+
+			" against",
+	)
+	command.Flags().BoolVar(
+		&resolve,
+		"resolve",
+		false,
+		"Check that the seed servers' hostnames can be resolved",
+	)
+	common.AddAdminAPITLSFlags(
+		command,
+		&adminAPIEnableTLS,
+		&adminAPICertFile,
+		&adminAPIKeyFile,
+		&adminAPITruststoreFile,
+	)
+	return command
+}
+
+// If api isn't nil, the config's redpanda properties are also checked against
+// the ones the node at the address in against supports. If resolve is true,
+// the seed servers' hostnames are looked up too.
+func executeValidateConfig(
+	fs afero.Fs,
+	mgr config.Manager,
+	path string,
+	api admin.AdminAPI,
+	against string,
+	resolve bool,
+) error {
+	var err error
+	if path == "" {
+		path, err = config.FindConfigFile(fs)
+		if err != nil {
+			return err
+		}
+	}
+	conf, err := mgr.Read(path)
+	if err != nil {
+		return err
+	}
+	_, errs := config.Check(conf)
+	errs = append(errs, config.CheckFiles(fs, conf)...)
+	if resolve {
+		errs = append(
+			errs,
+			config.CheckSeedServersResolvable(conf, net.LookupHost)...,
+		)
+	}
+	if api != nil {
+		supported, err := api.Config()
+		if err != nil {
+			return fmt.Errorf(
+				"couldn't fetch the supported properties from %s: %v",
+				against,
+				err,
+			)
+		}
+		keys, err := config.UnsupportedKeys(conf, supported)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			errs = append(
+				errs,
+				fmt.Errorf("%s isn't supported by the node at %s", k, against),
+			)
+		}
+	}
+	if len(errs) == 0 {
+		log.Infof("%s: OK", conf.ConfigFile)
+		return nil
+	}
+	for _, e := range errs {
+		log.Errorf("%s: %v", conf.ConfigFile, e)
+	}
+	return cli.NewExitError(
+		cli.ExitConfigInvalid,
+		fmt.Errorf("found %d error(s) in %s", len(errs), conf.ConfigFile),
+	)
+}
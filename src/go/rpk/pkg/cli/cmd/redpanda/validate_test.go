@@ -0,0 +1,200 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestValidateConfigCommand(t *testing.T) {
+	path := "/etc/redpanda/redpanda.yaml"
+	tests := []struct {
+		name           string
+		conf           func() *config.Config
+		args           []string
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name:           "it should succeed if the config is valid",
+			conf:           config.Default,
+			args:           []string{path},
+			expectedOutput: []string{path + ": OK"},
+		},
+		{
+			name:           "it should look for the config in the default locations",
+			conf:           config.Default,
+			expectedOutput: []string{path + ": OK"},
+		},
+		{
+			name: "it should fail if the config has fatal errors",
+			conf: func() *config.Config {
+				conf := config.Default()
+				conf.Redpanda.Directory = ""
+				conf.Redpanda.RPCServer.Port = 0
+				return conf
+			},
+			args: []string{path},
+			expectedOutput: []string{
+				path + ": redpanda.data_directory can't be empty",
+				path + ": redpanda.rpc_server.port can't be 0",
+			},
+			expectedErrMsg: "found 2 error(s) in " + path,
+		},
+		{
+			name: "it should fail if a TLS file doesn't exist",
+			conf: func() *config.Config {
+				conf := config.Default()
+				conf.Redpanda.KafkaApiTLS = []config.ServerTLS{{
+					Enabled:  true,
+					KeyFile:  "/etc/redpanda/certs/key.pem",
+					CertFile: path,
+				}}
+				return conf
+			},
+			args: []string{path},
+			expectedOutput: []string{
+				path + ": redpanda.kafka_api_tls.0.key_file: /etc/redpanda/certs/key.pem doesn't exist",
+			},
+			expectedErrMsg: "found 1 error(s) in " + path,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := config.NewManager(fs)
+			bs, err := yaml.Marshal(tt.conf())
+			require.NoError(t, err)
+			err = afero.WriteFile(fs, path, bs, 0644)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := NewValidateConfigCommand(fs, mgr)
+			cmd.SetArgs(tt.args)
+			err = cmd.Execute()
+			for _, o := range tt.expectedOutput {
+				require.Contains(t, out.String(), o)
+			}
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+				require.Equal(t, cli.ExitConfigInvalid, cli.ExitCode(err))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateConfigCommandAgainstNode(t *testing.T) {
+	path := "/etc/redpanda/redpanda.yaml"
+	conf := config.Default()
+	conf.Redpanda.DeveloperMode = false
+	conf.Redpanda.SeedServers = []config.SeedServer{{
+		Host: config.SocketAddress{Address: "redpanda-0.local", Port: 33145},
+	}}
+	logSegmentSize := 536870912
+	conf.Redpanda.LogSegmentSize = &logSegmentSize
+	conf.Redpanda.Other = map[string]interface{}{
+		"enable_idempotence":  true,
+		"enable_transactions": true,
+	}
+	bs, err := yaml.Marshal(conf)
+	require.NoError(t, err)
+	// The properties reported by a node's /v1/config, which doesn't include
+	// the node-specific ones, such as data_directory or seed_servers, but the
+	// given ones.
+	supported := func(omit ...string) map[string]interface{} {
+		props := map[string]interface{}{}
+		err := json.Unmarshal([]byte(`{
+			"auto_create_topics_enabled": false,
+			"cloud_storage_enabled": false,
+			"default_topic_partitions": 1,
+			"default_topic_replications": 1,
+			"delete_retention_ms": 604800000,
+			"enable_idempotence": false,
+			"enable_sasl": false,
+			"enable_transactions": false,
+			"group_topic_partitions": 1,
+			"log_compression_type": "producer",
+			"log_segment_size": 1073741824,
+			"superusers": []
+		}`), &props)
+		require.NoError(t, err)
+		for _, k := range omit {
+			delete(props, k)
+		}
+		return props
+	}
+	tests := []struct {
+		name           string
+		supported      map[string]interface{}
+		expectedOutput []string
+		expectedErrMsg string
+	}{
+		{
+			name:           "it should succeed if the node supports all the properties",
+			supported:      supported(),
+			expectedOutput: []string{path + ": OK"},
+		},
+		{
+			name:      "it should fail if the node doesn't support a property",
+			supported: supported("enable_transactions"),
+			expectedOutput: []string{
+				path + ": redpanda.enable_transactions isn't supported by the node at",
+			},
+			expectedErrMsg: "found 1 error(s) in " + path,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			ts := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(st, "/v1/config", r.URL.Path)
+					res, err := json.Marshal(tt.supported)
+					require.NoError(st, err)
+					w.WriteHeader(http.StatusOK)
+					w.Write(res)
+				}),
+			)
+			defer ts.Close()
+
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, path, bs, 0644)
+			require.NoError(st, err)
+
+			var out bytes.Buffer
+			logrus.SetOutput(&out)
+			cmd := NewValidateConfigCommand(fs, config.NewManager(fs))
+			cmd.SetArgs([]string{path, "--against", ts.URL})
+			err = cmd.Execute()
+			for _, o := range tt.expectedOutput {
+				require.Contains(st, out.String(), o)
+			}
+			if tt.expectedErrMsg != "" {
+				require.EqualError(st, err, tt.expectedErrMsg)
+				require.Equal(st, cli.ExitConfigInvalid, cli.ExitCode(err))
+				return
+			}
+			require.NoError(st, err)
+		})
+	}
+}
@@ -72,8 +72,8 @@ func Execute() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose",
 		"v", false, "enable verbose logging (default false)")
 
-	rootCmd.AddCommand(NewModeCommand(mgr))
-	rootCmd.AddCommand(NewGenerateCommand(mgr))
+	rootCmd.AddCommand(NewModeCommand(fs, mgr))
+	rootCmd.AddCommand(NewGenerateCommand(fs, mgr))
 	rootCmd.AddCommand(NewVersionCommand())
 	rootCmd.AddCommand(NewWasmCommand(fs, mgr))
 	rootCmd.AddCommand(NewContainerCommand())
@@ -129,7 +129,7 @@ func Execute() {
 		}
 	}
 	if err != nil {
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
 
@@ -0,0 +1,74 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cli
+
+import (
+	"errors"
+	"os"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+// The codes rpk exits with, so that scripts wrapping it can tell the
+// different kinds of failures apart.
+const (
+	ExitOK = iota
+	// Any failure not covered by the codes below.
+	ExitGeneric
+	// The config is invalid.
+	ExitConfigInvalid
+	// A fatal system check failed.
+	ExitCheckFailed
+	// rpk lacks the permissions to read or write a file.
+	ExitPermission
+	// A tuner which was explicitly requested isn't supported in the system.
+	ExitTunerUnsupported
+	// The live config differs from the desired one (config apply
+	// --detect-only).
+	ExitConfigDrift
+)
+
+// ExitError attaches an exit code to an error.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func NewExitError(code int, err error) *ExitError {
+	return &ExitError{Code: code, Err: err}
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the code rpk should exit with after a command returned
+// err.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if errors.Is(err, os.ErrPermission) {
+		return ExitPermission
+	}
+	var invalidErr *config.InvalidConfigError
+	if errors.As(err, &invalidErr) {
+		return ExitConfigInvalid
+	}
+	return ExitGeneric
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cli_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "it should return 0 if there's no error",
+			expected: cli.ExitOK,
+		},
+		{
+			name:     "it should return the generic code for unclassified errors",
+			err:      errors.New("something went wrong"),
+			expected: cli.ExitGeneric,
+		},
+		{
+			name: "it should return the code in a wrapped ExitError",
+			err: fmt.Errorf("tune: %w", cli.NewExitError(
+				cli.ExitTunerUnsupported,
+				errors.New("cpu isn't supported"),
+			)),
+			expected: cli.ExitTunerUnsupported,
+		},
+		{
+			name: "it should return the permission code for permission errors",
+			err: fmt.Errorf("unable to create backup: %w", &os.PathError{
+				Op:   "open",
+				Path: "/etc/redpanda/redpanda.yaml",
+				Err:  os.ErrPermission,
+			}),
+			expected: cli.ExitPermission,
+		},
+		{
+			name: "it should return the config-invalid code for invalid configs",
+			err: fmt.Errorf("refusing to apply: %w", &config.InvalidConfigError{
+				Errs: []error{errors.New("redpanda.data_directory can't be empty")},
+			}),
+			expected: cli.ExitConfigInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			require.Equal(st, tt.expected, cli.ExitCode(tt.err))
+		})
+	}
+}
@@ -11,19 +11,33 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/redpanda"
 )
 
+const InstallDirEnv = "REDPANDA_INSTALL_DIR"
+
+// GetOrFindInstallDir returns the given install directory, falling back to
+// the one in REDPANDA_INSTALL_DIR, and validates it. If neither is set, it
+// looks for the install directory relative to rpk's executable.
 func GetOrFindInstallDir(fs afero.Fs, installDir string) (string, error) {
+	if installDir == "" {
+		installDir = os.Getenv(InstallDirEnv)
+	}
 	if installDir != "" {
+		err := redpanda.CheckInstallDir(fs, installDir)
+		if err != nil {
+			return "", err
+		}
 		return installDir, nil
 	}
 	foundConfig, err := redpanda.FindInstallDir(fs)
 	if err != nil {
-		return "", fmt.Errorf("Unable to find redpanda installation. " +
-			"Please provide the install directory with flag --install-dir")
+		return "", fmt.Errorf("Unable to find redpanda installation. "+
+			"Please provide the install directory with flag --install-dir"+
+			" or the %s environment variable", InstallDirEnv)
 	}
 	return foundConfig, nil
 }
@@ -0,0 +1,93 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli"
+)
+
+func TestGetOrFindInstallDir(t *testing.T) {
+	tests := []struct {
+		name        string
+		flag        string
+		env         string
+		dirs        []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "it should accept a valid install dir",
+			flag:     "/opt/redpanda",
+			dirs:     []string{"/opt/redpanda"},
+			expected: "/opt/redpanda",
+		},
+		{
+			name: "it should fail if the install dir is missing the binary",
+			flag: "/opt/redpanda",
+			dirs: []string{"/opt/redpanda"},
+			expectedErr: "'/opt/redpanda' isn't a valid redpanda install" +
+				" directory, it's missing: bin/redpanda",
+		},
+		{
+			name: "it should list everything that's missing",
+			flag: "/opt/empty",
+			expectedErr: "'/opt/empty' isn't a valid redpanda install" +
+				" directory, it's missing: bin/redpanda, lib",
+		},
+		{
+			name:     "it should use the env var if the flag isn't set",
+			env:      "/opt/redpanda",
+			dirs:     []string{"/opt/redpanda"},
+			expected: "/opt/redpanda",
+		},
+		{
+			name:     "it should prefer the flag over the env var",
+			flag:     "/opt/redpanda",
+			env:      "/opt/other",
+			dirs:     []string{"/opt/redpanda"},
+			expected: "/opt/redpanda",
+		},
+		{
+			name: "it should validate the install dir in the env var",
+			env:  "/opt/other",
+			dirs: []string{"/opt/redpanda"},
+			expectedErr: "'/opt/other' isn't a valid redpanda install" +
+				" directory, it's missing: bin/redpanda, lib",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			for _, dir := range tt.dirs {
+				require.NoError(st, fs.MkdirAll(filepath.Join(dir, "lib"), 0755))
+				if tt.expectedErr == "" {
+					bin := filepath.Join(dir, "bin", "redpanda")
+					require.NoError(st, afero.WriteFile(fs, bin, []byte{}, 0755))
+				}
+			}
+			os.Setenv(cli.InstallDirEnv, tt.env)
+			defer os.Unsetenv(cli.InstallDirEnv)
+
+			dir, err := cli.GetOrFindInstallDir(fs, tt.flag)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, dir)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigChecksum returns the hex-encoded SHA256 sum of the config file at
+// path. The file is normalized before hashing by re-marshaling it, so that
+// files which differ only in comments, whitespace or key order yield the same
+// checksum.
+func ConfigChecksum(fs afero.Fs, path string) (string, error) {
+	bs, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return "", err
+	}
+	var conf map[string]interface{}
+	err = yaml.Unmarshal(bs, &conf)
+	if err != nil {
+		return "", err
+	}
+	// yaml.v2 sorts map keys when marshaling, which makes the output
+	// stable.
+	normalized, err := yaml.Marshal(conf)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(normalized)
+	return hex.EncodeToString(sum[:]), nil
+}
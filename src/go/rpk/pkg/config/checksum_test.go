@@ -0,0 +1,97 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigChecksum(t *testing.T) {
+	base := `redpanda:
+  data_directory: /var/lib/redpanda/data
+  node_id: 1
+  seed_servers: []
+rpk:
+  tune_network: true
+`
+	tests := []struct {
+		name          string
+		other         string
+		expectedEqual bool
+	}{
+		{
+			name:          "it should be stable for the same content",
+			other:         base,
+			expectedEqual: true,
+		},
+		{
+			name: "it should ignore comments and whitespace",
+			other: `# The redpanda config
+redpanda:
+    data_directory:   /var/lib/redpanda/data # the data dir
+    node_id: 1
+
+    seed_servers: []
+rpk:
+    tune_network: true
+`,
+			expectedEqual: true,
+		},
+		{
+			name: "it should ignore the order of the keys",
+			other: `rpk:
+  tune_network: true
+redpanda:
+  seed_servers: []
+  node_id: 1
+  data_directory: /var/lib/redpanda/data
+`,
+			expectedEqual: true,
+		},
+		{
+			name: "it should change if a value changes",
+			other: `redpanda:
+  data_directory: /var/lib/redpanda/data
+  node_id: 2
+  seed_servers: []
+rpk:
+  tune_network: true
+`,
+			expectedEqual: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, "/base.yaml", []byte(base), 0644)
+			require.NoError(t, err)
+			err = afero.WriteFile(fs, "/other.yaml", []byte(tt.other), 0644)
+			require.NoError(t, err)
+
+			baseSum, err := ConfigChecksum(fs, "/base.yaml")
+			require.NoError(t, err)
+			otherSum, err := ConfigChecksum(fs, "/other.yaml")
+			require.NoError(t, err)
+			if tt.expectedEqual {
+				require.Equal(t, baseSum, otherSum)
+			} else {
+				require.NotEqual(t, baseSum, otherSum)
+			}
+		})
+	}
+}
+
+func TestConfigChecksumMissingFile(t *testing.T) {
+	_, err := ConfigChecksum(afero.NewMemMapFs(), "/etc/redpanda/redpanda.yaml")
+	require.Error(t, err)
+}
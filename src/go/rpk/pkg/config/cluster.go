@@ -0,0 +1,298 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A port a node listens on, and the key it's set in.
+type listenerPort struct {
+	key  string
+	port int
+}
+
+// The node config file and key a port was first seen in.
+type nodePort struct {
+	file string
+	key  string
+}
+
+// CheckCluster verifies that the given node configs, each of them read from
+// its own file, can form a cluster together: each of them must be valid on
+// its own, their node IDs must be unique, their seed servers must point to
+// the nodes' RPC addresses, the nodes on the same host can't listen on the
+// same ports, and at least one of them must be able to bootstrap the cluster
+// (i.e. have no seed servers).
+func CheckCluster(confs []*Config) []error {
+	errs := []error{}
+	if len(confs) == 0 {
+		return []error{errors.New("no node configs were given")}
+	}
+	for _, conf := range confs {
+		_, nodeErrs := Check(conf)
+		for _, err := range nodeErrs {
+			errs = append(errs, fmt.Errorf("%s: %v", conf.ConfigFile, err))
+		}
+	}
+	errs = append(errs, checkUniqueNodeIDs(confs)...)
+	errs = append(errs, checkClusterSeeds(confs)...)
+	errs = append(errs, checkClusterPorts(confs)...)
+	return errs
+}
+
+func checkUniqueNodeIDs(confs []*Config) []error {
+	errs := []error{}
+	files := map[int]string{}
+	for _, conf := range confs {
+		id := conf.Redpanda.Id
+		if file, ok := files[id]; ok {
+			errs = append(errs, fmt.Errorf(
+				"redpanda.node_id %d is used by both %s and %s",
+				id,
+				file,
+				conf.ConfigFile,
+			))
+			continue
+		}
+		files[id] = conf.ConfigFile
+	}
+	return errs
+}
+
+// The address the other nodes reach the node's RPC server at.
+func rpcAddress(conf *Config) SocketAddress {
+	if conf.Redpanda.AdvertisedRPCAPI != nil {
+		return *conf.Redpanda.AdvertisedRPCAPI
+	}
+	return conf.Redpanda.RPCServer
+}
+
+// Each seed server has to be one of the nodes, and the node with no seed
+// servers is the one which bootstraps the cluster, which the rest join.
+func checkClusterSeeds(confs []*Config) []error {
+	errs := []error{}
+	nodes := map[SocketAddress]bool{}
+	for _, conf := range confs {
+		nodes[rpcAddress(conf)] = true
+	}
+	canBootstrap := false
+	for _, conf := range confs {
+		if len(conf.Redpanda.SeedServers) == 0 {
+			canBootstrap = true
+			continue
+		}
+		for i, seed := range conf.Redpanda.SeedServers {
+			if nodes[seed.Host] {
+				continue
+			}
+			errs = append(errs, fmt.Errorf(
+				"%s: redpanda.seed_servers.%d.host %s:%d isn't the RPC"+
+					" address of any of the nodes",
+				conf.ConfigFile,
+				i,
+				seed.Host.Address,
+				seed.Host.Port,
+			))
+		}
+	}
+	if !canBootstrap {
+		errs = append(errs, errors.New(
+			"all the nodes have redpanda.seed_servers set, so none of them"+
+				" can bootstrap the cluster. Leave it empty in one of them",
+		))
+	}
+	return errs
+}
+
+// The nodes are told apart by their RPC address, so the ones which share it
+// are on the same host, where they can't listen on the same ports.
+func checkClusterPorts(confs []*Config) []error {
+	errs := []error{}
+	byHost := map[string]map[int]nodePort{}
+	for _, conf := range confs {
+		host := rpcAddress(conf).Address
+		ports, ok := byHost[host]
+		if !ok {
+			ports = map[int]nodePort{}
+			byHost[host] = ports
+		}
+		for _, p := range listenerPorts(conf) {
+			used, ok := ports[p.port]
+			if !ok {
+				ports[p.port] = nodePort{file: conf.ConfigFile, key: p.key}
+				continue
+			}
+			errs = append(errs, fmt.Errorf(
+				"port %d on %s is used by both %s (%s) and %s (%s)",
+				p.port,
+				host,
+				used.key,
+				used.file,
+				p.key,
+				conf.ConfigFile,
+			))
+		}
+	}
+	return errs
+}
+
+// Returns the ports the node listens on, along with the keys they're set in.
+func listenerPorts(conf *Config) []listenerPort {
+	ports := []listenerPort{}
+	add := func(key string, port int) {
+		if port == 0 {
+			return
+		}
+		ports = append(ports, listenerPort{key, port})
+	}
+	add("redpanda.rpc_server", conf.Redpanda.RPCServer.Port)
+	for i, l := range conf.Redpanda.KafkaApi {
+		add(fmt.Sprintf("redpanda.kafka_api.%d", i), l.Port)
+	}
+	for i, l := range conf.Redpanda.AdminApi {
+		add(fmt.Sprintf("redpanda.admin.%d", i), l.Port)
+	}
+	if conf.Pandaproxy != nil {
+		for i, l := range conf.Pandaproxy.PandaproxyAPI {
+			add(fmt.Sprintf("pandaproxy.pandaproxy_api.%d", i), l.Port)
+		}
+	}
+	if conf.SchemaRegistry != nil {
+		for i, l := range conf.SchemaRegistry.SchemaRegistryAPI {
+			add(fmt.Sprintf("schema_registry.schema_registry_api.%d", i), l.Port)
+		}
+	}
+	return ports
+}
+
+// ClusterNode is a node to generate a config for with GenerateCluster.
+type ClusterNode struct {
+	ID   int
+	Host string
+}
+
+// ParseClusterNode parses a node given as 'id@host', e.g. '0@10.0.0.1'.
+func ParseClusterNode(s string) (ClusterNode, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), "@", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return ClusterNode{}, fmt.Errorf(
+			"'%s' isn't a valid node, it must be 'id@host'",
+			s,
+		)
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id < 0 {
+		return ClusterNode{}, fmt.Errorf(
+			"'%s' isn't a valid node ID in '%s'",
+			parts[0],
+			s,
+		)
+	}
+	return ClusterNode{ID: id, Host: parts[1]}, nil
+}
+
+// GenerateCluster returns a config for each of the given nodes, merged onto
+// template with MergeConfig, and written to dir/redpanda-<id>.yaml. Each
+// node's redpanda.node_id is set to its ID, and the addresses of its RPC
+// server, Kafka API and admin API listeners (and the advertised ones, if the
+// template sets them) to its host, keeping the template's ports. The first
+// node bootstraps the cluster, so it has no seed servers, while the rest get
+// the RPC addresses of all the nodes as their seed servers. The configs
+// aren't checked, which can be done with CheckCluster. The node IDs must be
+// unique, since they name the files.
+func GenerateCluster(
+	template *Config, nodes []ClusterNode, dir string,
+) ([]*Config, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("no nodes were given")
+	}
+	// Each node's config is written to a file named after its ID.
+	ids := map[int]bool{}
+	seeds := []SeedServer{}
+	for _, node := range nodes {
+		if ids[node.ID] {
+			return nil, fmt.Errorf("node ID %d is given more than once", node.ID)
+		}
+		ids[node.ID] = true
+		seeds = append(seeds, SeedServer{Host: nodeRPCAddress(template, node)})
+	}
+	confs := []*Config{}
+	for i, node := range nodes {
+		overlay := nodeOverlay(template, node)
+		overlay.Redpanda.SeedServers = seeds
+		if i == 0 {
+			overlay.Redpanda.SeedServers = []SeedServer{}
+		}
+		overlay.SetKeys["redpanda.seed_servers"] = true
+		conf, err := MergeConfig(template, overlay)
+		if err != nil {
+			return nil, err
+		}
+		conf.ConfigFile = filepath.Join(
+			dir,
+			fmt.Sprintf("redpanda-%d.yaml", node.ID),
+		)
+		confs = append(confs, conf)
+	}
+	return confs, nil
+}
+
+// The address the other nodes will reach the node's RPC server at, as
+// rpcAddress returns it once the node's config is generated.
+func nodeRPCAddress(template *Config, node ClusterNode) SocketAddress {
+	port := template.Redpanda.RPCServer.Port
+	if template.Redpanda.AdvertisedRPCAPI != nil {
+		port = template.Redpanda.AdvertisedRPCAPI.Port
+	}
+	return SocketAddress{Address: node.Host, Port: port}
+}
+
+// Returns an overlay with the node's ID and addresses, as a partial config
+// whose set keys are the only ones MergeConfig merges.
+func nodeOverlay(template *Config, node ClusterNode) *Overlay {
+	overlay := &Overlay{Config: &Config{}, SetKeys: map[string]bool{}}
+	rp := &overlay.Redpanda
+	rp.Id = node.ID
+	overlay.SetKeys["redpanda.node_id"] = true
+	rp.RPCServer.Address = node.Host
+	overlay.SetKeys["redpanda.rpc_server.address"] = true
+	if template.Redpanda.AdvertisedRPCAPI != nil {
+		rp.AdvertisedRPCAPI = &SocketAddress{
+			Address: node.Host,
+			Port:    template.Redpanda.AdvertisedRPCAPI.Port,
+		}
+		overlay.SetKeys["redpanda.advertised_rpc_api.address"] = true
+	}
+	// Lists are merged as a whole, so they're copied with the host set.
+	withHost := func(key string, ls []NamedSocketAddress) []NamedSocketAddress {
+		if len(ls) == 0 {
+			return nil
+		}
+		copied := make([]NamedSocketAddress, 0, len(ls))
+		for _, l := range ls {
+			l.Address = node.Host
+			copied = append(copied, l)
+		}
+		overlay.SetKeys[key] = true
+		return copied
+	}
+	rp.KafkaApi = withHost("redpanda.kafka_api", template.Redpanda.KafkaApi)
+	rp.AdvertisedKafkaApi = withHost(
+		"redpanda.advertised_kafka_api",
+		template.Redpanda.AdvertisedKafkaApi,
+	)
+	rp.AdminApi = withHost("redpanda.admin", template.Redpanda.AdminApi)
+	return overlay
+}
@@ -0,0 +1,263 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Returns the configs of a three node cluster, where the first node
+// bootstraps it and the others join it through it.
+func clusterConfigs() []*Config {
+	confs := []*Config{}
+	for i := 0; i < 3; i++ {
+		conf := Default()
+		conf.ConfigFile = fmt.Sprintf("/etc/redpanda/node-%d.yaml", i)
+		conf.Redpanda.Id = i
+		conf.Redpanda.RPCServer = SocketAddress{
+			Address: fmt.Sprintf("10.0.0.%d", i+1),
+			Port:    33145,
+		}
+		if i > 0 {
+			conf.Redpanda.SeedServers = []SeedServer{
+				{Host: SocketAddress{Address: "10.0.0.1", Port: 33145}},
+			}
+		}
+		confs = append(confs, conf)
+	}
+	return confs
+}
+
+func TestCheckCluster(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func([]*Config)
+		expected []string
+	}{
+		{
+			name:   "it should pass if the nodes can form a cluster",
+			modify: func([]*Config) {},
+		},
+		{
+			name: "it should use the advertised RPC address to match the seeds",
+			modify: func(confs []*Config) {
+				confs[0].Redpanda.RPCServer.Address = "0.0.0.0"
+				confs[0].Redpanda.AdvertisedRPCAPI = &SocketAddress{
+					Address: "10.0.0.1",
+					Port:    33145,
+				}
+			},
+		},
+		{
+			name: "it should fail if the node IDs aren't unique",
+			modify: func(confs []*Config) {
+				confs[2].Redpanda.Id = 1
+			},
+			expected: []string{
+				"redpanda.node_id 1 is used by both /etc/redpanda/node-1.yaml" +
+					" and /etc/redpanda/node-2.yaml",
+			},
+		},
+		{
+			name: "it should fail if a seed isn't one of the nodes",
+			modify: func(confs []*Config) {
+				confs[1].Redpanda.SeedServers[0].Host.Port = 33146
+			},
+			expected: []string{
+				"/etc/redpanda/node-1.yaml: redpanda.seed_servers.0.host" +
+					" 10.0.0.1:33146 isn't the RPC address of any of the nodes",
+			},
+		},
+		{
+			name: "it should fail if no node can bootstrap the cluster",
+			modify: func(confs []*Config) {
+				confs[0].Redpanda.SeedServers = []SeedServer{
+					{Host: SocketAddress{Address: "10.0.0.2", Port: 33145}},
+				}
+			},
+			expected: []string{
+				"all the nodes have redpanda.seed_servers set, so none of" +
+					" them can bootstrap the cluster. Leave it empty in one" +
+					" of them",
+			},
+		},
+		{
+			name: "it should fail if nodes on the same host use the same ports",
+			modify: func(confs []*Config) {
+				confs[2].Redpanda.RPCServer = SocketAddress{
+					Address: "10.0.0.2",
+					Port:    33146,
+				}
+			},
+			expected: []string{
+				"port 9092 on 10.0.0.2 is used by both redpanda.kafka_api.0" +
+					" (/etc/redpanda/node-1.yaml) and redpanda.kafka_api.0" +
+					" (/etc/redpanda/node-2.yaml)",
+				"port 9644 on 10.0.0.2 is used by both redpanda.admin.0" +
+					" (/etc/redpanda/node-1.yaml) and redpanda.admin.0" +
+					" (/etc/redpanda/node-2.yaml)",
+			},
+		},
+		{
+			name: "it should include the errors of each node",
+			modify: func(confs []*Config) {
+				confs[1].Redpanda.SeedServers = append(
+					confs[1].Redpanda.SeedServers,
+					confs[1].Redpanda.SeedServers[0],
+				)
+			},
+			expected: []string{
+				"/etc/redpanda/node-1.yaml: redpanda.seed_servers has" +
+					" duplicate address 10.0.0.1:33145 at indexes 0 and 1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			confs := clusterConfigs()
+			tt.modify(confs)
+			errs := CheckCluster(confs)
+			msgs := []string{}
+			for _, err := range errs {
+				msgs = append(msgs, err.Error())
+			}
+			if len(tt.expected) == 0 {
+				require.Empty(st, msgs)
+				return
+			}
+			require.Equal(st, tt.expected, msgs)
+		})
+	}
+}
+
+func TestCheckClusterNoConfigs(t *testing.T) {
+	errs := CheckCluster(nil)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "no node configs were given")
+}
+
+func TestParseClusterNode(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    ClusterNode
+		expectedErr string
+	}{
+		{
+			input:    "0@10.0.0.1",
+			expected: ClusterNode{ID: 0, Host: "10.0.0.1"},
+		},
+		{
+			input:    " 12@redpanda-2.local ",
+			expected: ClusterNode{ID: 12, Host: "redpanda-2.local"},
+		},
+		{
+			input:       "10.0.0.1",
+			expectedErr: "'10.0.0.1' isn't a valid node, it must be 'id@host'",
+		},
+		{
+			input:       "1@",
+			expectedErr: "'1@' isn't a valid node, it must be 'id@host'",
+		},
+		{
+			input:       "one@10.0.0.1",
+			expectedErr: "'one' isn't a valid node ID in 'one@10.0.0.1'",
+		},
+		{
+			input:       "-1@10.0.0.1",
+			expectedErr: "'-1' isn't a valid node ID in '-1@10.0.0.1'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(st *testing.T) {
+			node, err := ParseClusterNode(tt.input)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, node)
+		})
+	}
+}
+
+func TestGenerateCluster(t *testing.T) {
+	template := Default()
+	template.Redpanda.Directory = "/data/redpanda"
+	template.Redpanda.AdminApi = []NamedSocketAddress{{
+		SocketAddress: SocketAddress{Address: "0.0.0.0", Port: 9644},
+	}}
+	template.Redpanda.AdvertisedKafkaApi = []NamedSocketAddress{{
+		SocketAddress: SocketAddress{Address: "localhost", Port: 9092},
+		Name:          "internal",
+	}}
+	nodes := []ClusterNode{
+		{ID: 1, Host: "10.0.0.1"},
+		{ID: 2, Host: "10.0.0.2"},
+		{ID: 3, Host: "10.0.0.3"},
+	}
+	confs, err := GenerateCluster(template, nodes, "/etc/redpanda/cluster")
+	require.NoError(t, err)
+	require.Len(t, confs, 3)
+	require.Empty(t, CheckCluster(confs))
+
+	expectedSeeds := []SeedServer{
+		{Host: SocketAddress{Address: "10.0.0.1", Port: 33145}},
+		{Host: SocketAddress{Address: "10.0.0.2", Port: 33145}},
+		{Host: SocketAddress{Address: "10.0.0.3", Port: 33145}},
+	}
+	for i, conf := range confs {
+		node := nodes[i]
+		require.Equal(
+			t,
+			fmt.Sprintf("/etc/redpanda/cluster/redpanda-%d.yaml", node.ID),
+			conf.ConfigFile,
+		)
+		require.Equal(t, node.ID, conf.Redpanda.Id)
+		require.Equal(
+			t,
+			SocketAddress{Address: node.Host, Port: 33145},
+			conf.Redpanda.RPCServer,
+		)
+		require.Equal(t, node.Host, conf.Redpanda.KafkaApi[0].Address)
+		require.Equal(t, 9092, conf.Redpanda.KafkaApi[0].Port)
+		require.Equal(t, node.Host, conf.Redpanda.AdvertisedKafkaApi[0].Address)
+		require.Equal(t, "internal", conf.Redpanda.AdvertisedKafkaApi[0].Name)
+		require.Equal(t, node.Host, conf.Redpanda.AdminApi[0].Address)
+		require.Equal(t, 9644, conf.Redpanda.AdminApi[0].Port)
+		// The rest of the template is kept.
+		require.Equal(t, "/data/redpanda", conf.Redpanda.Directory)
+		// The first node bootstraps the cluster, and the rest share the
+		// seed servers.
+		if i == 0 {
+			require.Empty(t, conf.Redpanda.SeedServers)
+		} else {
+			require.Equal(t, expectedSeeds, conf.Redpanda.SeedServers)
+		}
+	}
+	// The template isn't modified.
+	require.Equal(t, "0.0.0.0", template.Redpanda.RPCServer.Address)
+	require.Equal(t, 0, template.Redpanda.Id)
+}
+
+func TestGenerateClusterErrors(t *testing.T) {
+	_, err := GenerateCluster(Default(), nil, "/etc/redpanda")
+	require.EqualError(t, err, "no nodes were given")
+
+	nodes := []ClusterNode{
+		{ID: 0, Host: "10.0.0.1"},
+		{ID: 1, Host: "10.0.0.2"},
+		{ID: 0, Host: "10.0.0.3"},
+	}
+	_, err = GenerateCluster(Default(), nodes, "/etc/redpanda")
+	require.EqualError(t, err, "node ID 0 is given more than once")
+}
@@ -12,14 +12,17 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	fp "path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -30,8 +33,15 @@ const (
 	DefaultSchemaRegPort = 8081
 	DefaultProxyPort     = 8082
 	DefaultAdminPort     = 9644
+
+	// Redpanda's default for redpanda.log_segment_size (1GiB).
+	defaultLogSegmentSize = 1024 * 1024 * 1024
 )
 
+// AuthenticationMethods are the values accepted for a Kafka listener's
+// authentication_method.
+var AuthenticationMethods = []string{"none", "sasl", "mtls_identity"}
+
 func InitViper(fs afero.Fs) *viper.Viper {
 	v := viper.New()
 	v.SetFs(fs)
@@ -133,17 +143,38 @@ func findBackup(fs afero.Fs, dir string) (string, error) {
 	return "", nil
 }
 
+// SetMode sets the defaults of the given mode in conf. The mode may be
+// followed by a comma-separated list of tuners to enable, or to disable if
+// they're prefixed with "no-", on top of those defaults, e.g.
+// prod:no-transparent_hugepages,coredump.
 func SetMode(mode string, conf *Config) (*Config, error) {
+	m, tuners, err := ParseMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	return SetModeWithTuners(m, conf, tuners)
+}
+
+// SetModeWithTuners sets the defaults of the given mode in conf, and then
+// enables or disables the given tuners, keyed by their name (e.g. "cpu").
+func SetModeWithTuners(
+	mode string, conf *Config, tuners map[string]bool,
+) (*Config, error) {
+	for name := range tuners {
+		if _, ok := tunerFlags[name]; !ok {
+			return nil, unknownTunerError(name)
+		}
+	}
 	m, err := NormalizeMode(mode)
 	if err != nil {
 		return nil, err
 	}
 	switch m {
 	case ModeDev:
-		return setDevelopment(conf), nil
+		conf = setDevelopment(conf)
 
 	case ModeProd:
-		return setProduction(conf), nil
+		conf = setProduction(conf)
 
 	default:
 		err := fmt.Errorf(
@@ -153,6 +184,96 @@ func SetMode(mode string, conf *Config) (*Config, error) {
 		)
 		return nil, err
 	}
+	for name, enabled := range tuners {
+		*tunerFlags[name](&conf.Rpk) = enabled
+	}
+	return conf, nil
+}
+
+// ParseMode splits a mode as accepted by SetMode into the mode itself and the
+// tuners to enable or disable on top of it. The tuner names aren't validated.
+func ParseMode(mode string) (string, map[string]bool, error) {
+	parts := strings.SplitN(mode, ":", 2)
+	if len(parts) == 1 {
+		return mode, nil, nil
+	}
+	tuners := map[string]bool{}
+	for _, t := range strings.Split(parts[1], ",") {
+		t = strings.TrimSpace(t)
+		enabled := !strings.HasPrefix(t, "no-")
+		name := strings.TrimPrefix(t, "no-")
+		if name == "" {
+			return "", nil, fmt.Errorf(
+				"'%s' has an empty tuner name. The tuners must be"+
+					" separated by commas, e.g. %s:no-coredump,swapfile",
+				mode,
+				ModeProd,
+			)
+		}
+		if prev, ok := tuners[name]; ok && prev != enabled {
+			return "", nil, fmt.Errorf(
+				"'%s' both enables and disables tuner '%s'",
+				mode,
+				name,
+			)
+		}
+		tuners[name] = enabled
+	}
+	return parts[0], tuners, nil
+}
+
+// TunerNames returns the names of the tuners which may be enabled or disabled
+// on top of a mode, sorted.
+func TunerNames() []string {
+	names := make([]string, 0, len(tunerFlags))
+	for name := range tunerFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func unknownTunerError(name string) error {
+	return fmt.Errorf(
+		"'%s' is not a supported tuner. Available tuners: %s",
+		name,
+		strings.Join(TunerNames(), ", "),
+	)
+}
+
+// SetModePreview returns the flags SetMode would flip, keyed by their path
+// (e.g. rpk.tune_cpu), along with their current and new values. conf isn't
+// modified.
+func SetModePreview(mode string, conf *Config) (map[string][2]bool, error) {
+	// setDevelopment and setProduction only assign the Redpanda and Rpk
+	// fields, so a shallow copy keeps conf as it is.
+	cp := *conf
+	updated, err := SetMode(mode, &cp)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string][2]bool{}
+	if conf.Redpanda.DeveloperMode != updated.Redpanda.DeveloperMode {
+		changes["redpanda.developer_mode"] = [2]bool{
+			conf.Redpanda.DeveloperMode,
+			updated.Redpanda.DeveloperMode,
+		}
+	}
+	before := reflect.ValueOf(conf.Rpk)
+	after := reflect.ValueOf(updated.Rpk)
+	t := before.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() != reflect.Bool {
+			continue
+		}
+		b, a := before.Field(i).Bool(), after.Field(i).Bool()
+		if b != a {
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			changes["rpk."+name] = [2]bool{b, a}
+		}
+	}
+	return changes, nil
 }
 
 func setDevelopment(conf *Config) *Config {
@@ -217,8 +338,24 @@ func AvailableModes() []string {
 	}
 }
 
+// InvalidConfigError is returned when the config fails the checks done
+// before writing it.
+type InvalidConfigError struct {
+	Errs []error
+}
+
+func (e *InvalidConfigError) Error() string {
+	reasons := []string{}
+	for _, err := range e.Errs {
+		reasons = append(reasons, err.Error())
+	}
+	return strings.Join(reasons, ", ")
+}
+
 func Check(conf *Config) (bool, []error) {
-	configMap, err := toMap(conf)
+	// Unlike toMap, the resolved values are kept, since they're the ones
+	// redpanda will use.
+	configMap, err := plainMap(conf)
 	if err != nil {
 		return false, []error{err}
 	}
@@ -233,10 +370,15 @@ func Check(conf *Config) (bool, []error) {
 
 func check(v *viper.Viper) (bool, []error) {
 	errs := checkRedpandaConfig(v)
+	errs = append(errs, checkAdvertisedAddresses(v)...)
+	errs = append(errs, checkListenerAuthenticationMethods(v)...)
 	errs = append(
 		errs,
 		checkRpkConfig(v)...,
 	)
+	for _, w := range checkRetention(v) {
+		log.Warn(w)
+	}
 	ok := len(errs) == 0
 	return ok, errs
 }
@@ -246,7 +388,7 @@ func checkRedpandaConfig(v *viper.Viper) []error {
 	if v.GetString("redpanda.data_directory") == "" {
 		errs = append(errs, fmt.Errorf("redpanda.data_directory can't be empty"))
 	}
-	if v.GetInt("redpanda.node_id") < 0 {
+	if id := v.GetInt(nodeIDKey); id < 0 {
 		errs = append(errs, fmt.Errorf("redpanda.node_id can't be a negative integer"))
 	}
 
@@ -307,6 +449,13 @@ func checkRedpandaConfig(v *viper.Viper) []error {
 					configPath,
 				)...,
 			)
+			errs = append(
+				errs,
+				checkAuthenticationMethod(
+					addr.AuthenticationMethod,
+					configPath,
+				)...,
+			)
 		}
 	}
 
@@ -335,11 +484,191 @@ func checkRedpandaConfig(v *viper.Viper) []error {
 					configPath,
 				)...,
 			)
+			if addr := seed.Host.Address; addr != "" && !isIPOrHostname(addr) {
+				errs = append(errs, fmt.Errorf(
+					"%s.address '%s' is not a valid IP or hostname",
+					configPath,
+					addr,
+				))
+			}
 		}
+		errs = append(errs, checkDuplicateSeedServers(seedServersSlice)...)
 	}
 	return errs
 }
 
+// Seed servers don't have a node ID in the config, so the duplicates (e.g.
+// from copying an entry and forgetting to change it) are detected by their
+// address and port instead.
+func checkDuplicateSeedServers(seeds []*SeedServer) []error {
+	errs := []error{}
+	firstIdx := map[SocketAddress]int{}
+	for i, seed := range seeds {
+		if seed == nil || seed.Host.Address == "" {
+			continue
+		}
+		if j, ok := firstIdx[seed.Host]; ok {
+			errs = append(errs, fmt.Errorf(
+				"redpanda.seed_servers has duplicate address %s:%d at"+
+					" indexes %d and %d",
+				seed.Host.Address,
+				seed.Host.Port,
+				j,
+				i,
+			))
+			continue
+		}
+		firstIdx[seed.Host] = i
+	}
+	return errs
+}
+
+var hostnameLabelRegexp = regexp.MustCompile(
+	`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`,
+)
+
+// Whether the address is an IP, or a syntactically valid hostname as per
+// RFC 1123. It doesn't check whether the hostname resolves.
+func isIPOrHostname(address string) bool {
+	if net.ParseIP(address) != nil {
+		return true
+	}
+	host := strings.TrimSuffix(address, ".")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if !hostnameLabelRegexp.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckSeedServersResolvable verifies that the seed servers' hostnames can be
+// resolved with lookup (e.g. net.LookupHost). It's kept apart from Check,
+// since it depends on the network. The IPs aren't looked up.
+func CheckSeedServersResolvable(
+	conf *Config, lookup func(host string) ([]string, error),
+) []error {
+	errs := []error{}
+	for i, seed := range conf.Redpanda.SeedServers {
+		addr := seed.Host.Address
+		if addr == "" || net.ParseIP(addr) != nil {
+			continue
+		}
+		_, err := lookup(addr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(
+				"redpanda.seed_servers.%d.host.address '%s' couldn't be"+
+					" resolved: %v",
+				i,
+				addr,
+				err,
+			))
+		}
+	}
+	return errs
+}
+
+// Advertised addresses are the ones clients and other nodes connect to, so
+// they must be concrete, rather than wildcards like 0.0.0.0.
+func checkAdvertisedAddresses(v *viper.Viper) []error {
+	errs := []error{}
+	checkAddress := func(address, configPath string) {
+		if address == "" {
+			errs = append(errs, fmt.Errorf("%s.address can't be empty", configPath))
+			return
+		}
+		if ip := net.ParseIP(address); ip != nil && ip.IsUnspecified() {
+			errs = append(errs, fmt.Errorf(
+				"%s.address can't be a wildcard address ('%s'), since"+
+					" clients can't connect to it. Set it to an address"+
+					" reachable by them",
+				configPath,
+				address,
+			))
+		}
+	}
+	advKafkaKey := "redpanda.advertised_kafka_api"
+	if v.Get(advKafkaKey) != nil {
+		var listeners []NamedSocketAddress
+		err := unmarshalKey(v, advKafkaKey, &listeners)
+		if err != nil {
+			log.Error(err)
+			errs = append(errs, fmt.Errorf(
+				"%s doesn't have the expected structure",
+				advKafkaKey,
+			))
+		}
+		for i, l := range listeners {
+			checkAddress(l.Address, fmt.Sprintf("%s.%d", advKafkaKey, i))
+		}
+	}
+	advRPCKey := "redpanda.advertised_rpc_api"
+	if v.Get(advRPCKey) != nil {
+		socket := &SocketAddress{}
+		err := unmarshalKey(v, advRPCKey, socket)
+		if err != nil {
+			log.Error(err)
+			errs = append(errs, fmt.Errorf(
+				"%s doesn't have the expected structure",
+				advRPCKey,
+			))
+		} else {
+			checkAddress(socket.Address, advRPCKey)
+		}
+	}
+	return errs
+}
+
+// CheckFiles verifies that the files referenced by the enabled TLS listeners
+// exist in fs, and that the coredumps can be saved to rpk.coredump_dir.
+func CheckFiles(fs afero.Fs, conf *Config) []error {
+	errs := []error{}
+	checkTLS := func(tlss []ServerTLS, configPath string) {
+		for i, tls := range tlss {
+			if !tls.Enabled {
+				continue
+			}
+			files := []struct{ field, path string }{
+				{"key_file", tls.KeyFile},
+				{"cert_file", tls.CertFile},
+				{"truststore_file", tls.TruststoreFile},
+			}
+			for _, f := range files {
+				if f.path == "" {
+					continue
+				}
+				exists, err := afero.Exists(fs, f.path)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if !exists {
+					errs = append(errs, fmt.Errorf(
+						"%s.%d.%s: %s doesn't exist",
+						configPath,
+						i,
+						f.field,
+						f.path,
+					))
+				}
+			}
+		}
+	}
+	checkTLS(conf.Redpanda.KafkaApiTLS, "redpanda.kafka_api_tls")
+	checkTLS(conf.Redpanda.AdminApiTLS, "redpanda.admin_api_tls")
+	if conf.Pandaproxy != nil {
+		checkTLS(conf.Pandaproxy.PandaproxyAPITLS, "pandaproxy.pandaproxy_api_tls")
+	}
+	if conf.SchemaRegistry != nil {
+		checkTLS(conf.SchemaRegistry.SchemaRegistryAPITLS, "schema_registry.schema_registry_api_tls")
+	}
+	errs = append(errs, checkCoredumpDirWritable(fs, conf)...)
+	return errs
+}
+
 func checkSocketAddress(s SocketAddress, configPath string) []error {
 	errs := []error{}
 	if s.Port == 0 {
@@ -351,6 +680,56 @@ func checkSocketAddress(s SocketAddress, configPath string) []error {
 	return errs
 }
 
+// checkAuthenticationMethod checks that a Kafka listener's authentication
+// method, if set, is one supported by redpanda.
+func checkAuthenticationMethod(method, configPath string) []error {
+	if method == "" {
+		return nil
+	}
+	for _, m := range AuthenticationMethods {
+		if method == m {
+			return nil
+		}
+	}
+	return []error{fmt.Errorf(
+		"%s.authentication_method must be one of %s, but got '%s'",
+		configPath,
+		strings.Join(AuthenticationMethods, ", "),
+		method,
+	)}
+}
+
+// Only redpanda.kafka_api's listeners are authenticated by redpanda, so
+// authentication_method can't be set for the other ones, where it would be
+// silently ignored.
+func checkListenerAuthenticationMethods(v *viper.Viper) []error {
+	errs := []error{}
+	for _, key := range flatListenerKeys {
+		if key == "redpanda.kafka_api" || v.Get(key) == nil {
+			continue
+		}
+		var listeners []NamedSocketAddress
+		err := unmarshalKey(v, key, &listeners)
+		if err != nil {
+			// The structure is checked along with the other fields.
+			log.Debug(err)
+			continue
+		}
+		for i, l := range listeners {
+			if l.AuthenticationMethod == "" {
+				continue
+			}
+			errs = append(errs, fmt.Errorf(
+				"%s.%d.authentication_method can only be set for the"+
+					" redpanda.kafka_api listeners",
+				key,
+				i,
+			))
+		}
+	}
+	return errs
+}
+
 func checkNamedSocketAddress(s NamedSocketAddress, configPath string) []error {
 	return checkSocketAddress(s.SocketAddress, configPath)
 }
@@ -362,6 +741,129 @@ func checkRpkConfig(v *viper.Viper) []error {
 			"rpk.coredump_dir can't be empty"
 		errs = append(errs, errors.New(msg))
 	}
+	errs = append(errs, checkCoredumpDir(v)...)
+	if label := v.GetString("rpk.rack_label"); label != "" &&
+		v.GetString(nodeLabelKey(label)) == "" {
+		errs = append(errs, fmt.Errorf(
+			"rpk.rack_label is set to '%s', but %s isn't set",
+			label,
+			nodeLabelKey(label),
+		))
+	}
+	errs = append(errs, checkDirtyPages(v)...)
+	errs = append(errs, checkConfigFileMode(v)...)
+	if v.IsSet("rpk.disk_nr_requests") && v.GetInt("rpk.disk_nr_requests") <= 0 {
+		errs = append(errs, errors.New(
+			"rpk.disk_nr_requests must be a positive integer",
+		))
+	}
+	if v.IsSet("rpk.max_cstate") && v.GetInt("rpk.max_cstate") < 0 {
+		errs = append(errs, errors.New(
+			"rpk.max_cstate can't be negative",
+		))
+	}
+	if v.IsSet("rpk.os_memory_headroom_percent") {
+		if p := v.GetInt("rpk.os_memory_headroom_percent"); p < 0 || p > 100 {
+			errs = append(errs, fmt.Errorf(
+				"rpk.os_memory_headroom_percent must be between 0 and"+
+					" 100, but got %d",
+				p,
+			))
+		}
+	}
+	return errs
+}
+
+// Returns warnings for the retention settings which are smaller than a log
+// segment. Redpanda accepts them, but since it only deletes whole closed
+// segments, the partitions would keep up to a segment's worth of data
+// regardless of the configured retention. They're not errors, as the topics
+// may override either setting.
+func checkRetention(v *viper.Viper) []string {
+	warnings := []string{}
+	const retentionKey = "redpanda.retention_bytes"
+	if !v.IsSet(retentionKey) {
+		return warnings
+	}
+	retention := v.GetInt(retentionKey)
+	// A non-positive value disables the size-based retention.
+	if retention <= 0 {
+		return warnings
+	}
+	segments := []struct {
+		key  string
+		size int
+	}{
+		{"redpanda.log_segment_size", defaultLogSegmentSize},
+		{"redpanda.compacted_log_segment_size", 0},
+	}
+	for _, seg := range segments {
+		size := seg.size
+		if v.IsSet(seg.key) {
+			size = v.GetInt(seg.key)
+		}
+		if size > 0 && retention < size {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s (%d) is smaller than %s (%d), so the partitions may"+
+					" keep up to a whole segment regardless of the retention",
+				retentionKey,
+				retention,
+				seg.key,
+				size,
+			))
+		}
+	}
+	return warnings
+}
+
+func checkDirtyPages(v *viper.Viper) []error {
+	rpk := RpkConfig{}
+	err := unmarshalKey(v, "rpk", &rpk)
+	if err != nil {
+		return []error{errors.New("invalid structure for rpk")}
+	}
+	return CheckDirtyPages(rpk)
+}
+
+// CheckDirtyPages returns the errors in the rpk.dirty_* settings. The kernel
+// only honors one of each pair of vm.dirty_* settings, so the ratio and bytes
+// variants can't be set at the same time.
+func CheckDirtyPages(conf RpkConfig) []error {
+	errs := []error{}
+	pairs := []struct {
+		ratioKey, bytesKey string
+		ratio, bytes       *int
+	}{
+		{"rpk.dirty_ratio", "rpk.dirty_bytes", conf.DirtyRatio, conf.DirtyBytes},
+		{
+			"rpk.dirty_background_ratio",
+			"rpk.dirty_background_bytes",
+			conf.DirtyBackgroundRatio,
+			conf.DirtyBackgroundBytes,
+		},
+	}
+	for _, p := range pairs {
+		if p.ratio != nil && p.bytes != nil {
+			errs = append(errs, fmt.Errorf(
+				"%s and %s can't both be set",
+				p.ratioKey,
+				p.bytesKey,
+			))
+		}
+		if p.ratio != nil && (*p.ratio < 0 || *p.ratio > 100) {
+			errs = append(errs, fmt.Errorf(
+				"%s must be between 0 and 100, but got %d",
+				p.ratioKey,
+				*p.ratio,
+			))
+		}
+		if p.bytes != nil && *p.bytes < 0 {
+			errs = append(errs, fmt.Errorf(
+				"%s can't be a negative integer",
+				p.bytesKey,
+			))
+		}
+	}
 	return errs
 }
 
@@ -404,12 +906,18 @@ func unmarshalKey(v *viper.Viper, key string, val interface{}) error {
 	)
 }
 
-func toMap(conf *Config) (map[string]interface{}, error) {
-	mapConf := make(map[string]interface{})
-	bs, err := yaml.Marshal(conf)
+// Returns the config as a map, with the values resolved when it was read
+// replaced back with the file's.
+func toMap(
+	conf *Config, resolved resolution,
+) (map[string]interface{}, error) {
+	mapConf, err := plainMap(conf)
 	if err != nil {
 		return nil, err
 	}
-	err = yaml.Unmarshal(bs, &mapConf)
-	return mapConf, err
+	// Never write the values overridden through the environment.
+	restoreEnvOverrides(mapConf, resolved.envOverrides)
+	// Never write nor show the values read from the secrets file.
+	restoreSecrets(mapConf, resolved.secrets)
+	return mapConf, nil
 }
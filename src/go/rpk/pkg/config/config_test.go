@@ -10,10 +10,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 	vyaml "github.com/vectorizedio/redpanda/src/go/rpk/pkg/yaml"
@@ -254,12 +258,62 @@ func TestSet(t *testing.T) {
 			expectErr: true,
 		},
 		{
-			name:      "it should fail if the format isn't supported",
+			name: "it should partially set map fields (toml)",
+			key:  "redpanda.rpc_server",
+			value: `address = "10.21.34.58"
+port = 33146
+`,
+			format: "toml",
+			check: func(st *testing.T, c *Config, _ *manager) {
+				expected := SocketAddress{
+					Address: "10.21.34.58",
+					Port:    33146,
+				}
+				require.Exactly(st, expected, c.Redpanda.RPCServer)
+			},
+		},
+		{
+			name: "it should set nested map fields (toml)",
+			key:  "redpanda",
+			value: `developer_mode = false
+
+[[seed_servers]]
+  [seed_servers.host]
+  address = "192.168.0.1"
+  port = 33145
+`,
+			format: "toml",
+			check: func(st *testing.T, c *Config, _ *manager) {
+				require.False(st, c.Redpanda.DeveloperMode)
+				expected := []SeedServer{{
+					Host: SocketAddress{
+						Address: "192.168.0.1",
+						Port:    33145,
+					},
+				}}
+				require.Exactly(st, expected, c.Redpanda.SeedServers)
+			},
+		},
+		{
+			name:      "it should fail if the value isn't well formatted (toml)",
 			key:       "redpanda",
-			value:     "node_id=1",
+			value:     `node_id = `,
 			format:    "toml",
 			expectErr: true,
 		},
+		{
+			name:      "it should fail if an integer field is given a fraction",
+			key:       "redpanda.rpc_server.port",
+			value:     "33145.5",
+			expectErr: true,
+		},
+		{
+			name:      "it should fail if the format isn't supported",
+			key:       "redpanda",
+			value:     "<node_id>1</node_id>",
+			format:    "xml",
+			expectErr: true,
+		},
 		{
 			name:      "it should fail if no key is passed",
 			value:     `node_id=1`,
@@ -288,6 +342,248 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestSetTOML(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	require.NoError(t, mgr.Set("redpanda.data_directory", "/data", ""))
+	require.NoError(t, mgr.Set("redpanda.custom_field", "kept", ""))
+
+	err := mgr.Set("redpanda", "developer_mode = true\n", "toml")
+	require.NoError(t, err)
+	conf, err := mgr.Get()
+	require.NoError(t, err)
+	// The fields missing from the TOML document are kept.
+	require.True(t, conf.Redpanda.DeveloperMode)
+	require.Equal(t, "/data", conf.Redpanda.Directory)
+	require.Equal(t, "kept", conf.Redpanda.Other["custom_field"])
+
+	err = mgr.Set("redpanda", "developer_mode = ", "toml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "couldn't parse the value as TOML")
+}
+
+func TestSetTOMLOverExistingInt(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := Default()
+	conf.Redpanda.Id = 1
+	require.NoError(t, NewManager(fs).Write(conf))
+	mgr := NewManager(fs)
+	_, err := mgr.Read(conf.ConfigFile)
+	require.NoError(t, err)
+
+	// TOML integers are int64s, which must still replace the int read
+	// from the file.
+	err = mgr.Set("redpanda", "node_id = 5\n", "toml")
+	require.NoError(t, err)
+	conf, err = mgr.Get()
+	require.NoError(t, err)
+	require.Exactly(t, 5, conf.Redpanda.Id)
+}
+
+func TestSetIndexed(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	err := mgr.Set(
+		"redpanda.seed_servers",
+		`[{"host": {"address": "10.0.0.1", "port": 33145}},`+
+			` {"host": {"address": "10.0.0.2", "port": 33145}}]`,
+		"json",
+	)
+	require.NoError(t, err)
+
+	err = mgr.Set("redpanda.seed_servers.0.host.address", "10.0.0.5", "single")
+	require.NoError(t, err)
+	err = mgr.Set("redpanda.seed_servers.1.host.port", "33146", "")
+	require.NoError(t, err)
+	conf, err := mgr.Get()
+	require.NoError(t, err)
+	expected := []SeedServer{
+		{Host: SocketAddress{"10.0.0.5", 33145}},
+		{Host: SocketAddress{"10.0.0.2", 33146}},
+	}
+	require.Exactly(t, expected, conf.Redpanda.SeedServers)
+
+	err = mgr.Set("redpanda.seed_servers.2.host.port", "33145", "single")
+	require.EqualError(
+		t,
+		err,
+		"index 2 is out of range for redpanda.seed_servers, which has 2 element(s)",
+	)
+
+	err = mgr.Set("redpanda.seed_servers.0.host.port", "33145", "json")
+	require.Error(t, err)
+}
+
+func TestGetKey(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	conf := Default()
+	err := mgr.Write(conf)
+	require.NoError(t, err)
+	_, err = mgr.Read(conf.ConfigFile)
+	require.NoError(t, err)
+
+	val, err := mgr.GetKey("redpanda.data_directory")
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/redpanda/data", val)
+
+	val, err = mgr.GetKey("redpanda.kafka_api.0.port")
+	require.NoError(t, err)
+	require.Equal(t, 9092, val)
+
+	val, err = mgr.GetKey("redpanda.rpc_server")
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		map[string]interface{}{"address": "0.0.0.0", "port": 33145},
+		val,
+	)
+
+	_, err = mgr.GetKey("redpanda.kafka_api.port")
+	require.EqualError(
+		t,
+		err,
+		"redpanda.kafka_api is a list, so its elements must be indexed,"+
+			" e.g. redpanda.kafka_api.0.port",
+	)
+
+	_, err = mgr.GetKey("redpanda.kafka_api.1.port")
+	require.EqualError(
+		t,
+		err,
+		"key 'redpanda.kafka_api.1' not found in "+conf.ConfigFile,
+	)
+
+	_, err = mgr.GetKey("redpanda.kafka_api.-1")
+	require.EqualError(
+		t,
+		err,
+		"key 'redpanda.kafka_api.-1' not found in "+conf.ConfigFile,
+	)
+
+	_, err = mgr.GetKey("redpanda.missing")
+	require.EqualError(
+		t,
+		err,
+		"key 'redpanda.missing' not found in "+conf.ConfigFile,
+	)
+}
+
+func TestSetBool(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"true", true},
+		{"TRUE", true},
+		{"1", true},
+		{"yes", true},
+		{"Yes", true},
+		{"on", true},
+		{"ON", true},
+		{"false", false},
+		{"False", false},
+		{"0", false},
+		{"no", false},
+		{"NO", false},
+		{"off", false},
+		{"Off", false},
+	}
+	for _, format := range []string{"", "single"} {
+		for _, tt := range tests {
+			name := fmt.Sprintf("it should parse '%s' as %v (format: '%s')", tt.value, tt.expected, format)
+			t.Run(name, func(t *testing.T) {
+				mgr := NewManager(afero.NewMemMapFs())
+				// Set the opposite value first, to make sure it changes.
+				err := mgr.Set("rpk.tune_network", fmt.Sprint(!tt.expected), format)
+				require.NoError(t, err)
+				err = mgr.Set("rpk.tune_network", tt.value, format)
+				require.NoError(t, err)
+				conf, err := mgr.Get()
+				require.NoError(t, err)
+				require.Exactly(t, tt.expected, conf.Rpk.TuneNetwork)
+			})
+		}
+	}
+
+	t.Run("it should reject values that aren't boolean", func(t *testing.T) {
+		for _, format := range []string{"", "single"} {
+			mgr := NewManager(afero.NewMemMapFs())
+			err := mgr.Set("rpk.tune_network", "maybe", format)
+			require.EqualError(
+				t,
+				err,
+				"invalid value 'maybe' for rpk.tune_network, which must be"+
+					" one of true/false, 1/0, yes/no or on/off",
+			)
+		}
+	})
+
+	t.Run("it should reject boolean values for numeric fields", func(t *testing.T) {
+		mgr := NewManager(afero.NewMemMapFs())
+		err := mgr.Set("redpanda.node_id", "yes", "")
+		require.EqualError(
+			t,
+			err,
+			"invalid value 'yes' for redpanda.node_id, which must be a number",
+		)
+	})
+
+	t.Run("it should keep boolean-like values for string fields", func(t *testing.T) {
+		mgr := NewManager(afero.NewMemMapFs())
+		err := mgr.Set("redpanda.data_directory", "on", "")
+		require.NoError(t, err)
+		conf, err := mgr.Get()
+		require.NoError(t, err)
+		require.Exactly(t, "on", conf.Redpanda.Directory)
+	})
+}
+
+func TestSetWithoutYAMLExtension(t *testing.T) {
+	for _, name := range []string{"redpanda.conf", "redpanda.yml", "redpanda"} {
+		t.Run(fmt.Sprintf("it should set values in %s", name), func(t *testing.T) {
+			path := "/etc/redpanda/" + name
+			content := fmt.Sprintf(`config_file: %s
+redpanda:
+  data_directory: /var/lib/redpanda/data
+  node_id: 1
+  rpc_server:
+    address: 0.0.0.0
+    port: 33145
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+  admin:
+  - address: 0.0.0.0
+    port: 9644
+  seed_servers: []
+`, path)
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, path, []byte(content), 0644)
+			require.NoError(t, err)
+
+			mgr := NewManager(fs)
+			conf, err := mgr.Read(path)
+			require.NoError(t, err)
+			require.Equal(t, 1, conf.Redpanda.Id)
+			require.Equal(t, path, conf.ConfigFile)
+
+			err = mgr.Set("redpanda.node_id", "7", "")
+			require.NoError(t, err)
+			err = mgr.WriteLoaded()
+			require.NoError(t, err)
+
+			bs, err := afero.ReadFile(fs, path)
+			require.NoError(t, err)
+			require.Contains(t, string(bs), "node_id: 7")
+			conf, err = NewManager(fs).Read(path)
+			require.NoError(t, err)
+			require.Equal(t, 7, conf.Redpanda.Id)
+			require.Equal(t, "/var/lib/redpanda/data", conf.Redpanda.Directory)
+		})
+	}
+}
+
 func TestMerge(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -479,12 +775,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -526,12 +830,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -583,12 +895,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -642,12 +962,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -693,12 +1021,20 @@ rpk:
   tune_clocksource: false
   tune_coredump: false
   tune_cpu: false
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: false
   tune_disk_nomerges: false
+  tune_disk_nr_requests: false
   tune_disk_scheduler: false
   tune_disk_write_cache: false
+  tune_fd_limit: false
   tune_fstrim: false
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: false
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: false
   tune_transparent_hugepages: false
 schema_registry: {}
@@ -748,12 +1084,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -827,12 +1171,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -898,12 +1250,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -965,12 +1325,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1028,12 +1396,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1081,12 +1457,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1172,12 +1556,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1237,12 +1629,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1312,12 +1712,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1365,12 +1773,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1418,12 +1834,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1480,12 +1904,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1563,12 +1995,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1620,12 +2060,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1680,12 +2128,20 @@ rpk:
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: true
   tune_disk_nomerges: true
+  tune_disk_nr_requests: false
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_fd_limit: false
   tune_fstrim: true
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: true
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1750,12 +2206,20 @@ rpk:
   tune_clocksource: false
   tune_coredump: false
   tune_cpu: false
+  tune_cstates: false
+  tune_dirty_pages: false
   tune_disk_irq: false
   tune_disk_nomerges: false
+  tune_disk_nr_requests: false
   tune_disk_scheduler: false
   tune_disk_write_cache: false
+  tune_fd_limit: false
   tune_fstrim: false
+  tune_max_map_count: false
+  tune_mount_options: false
   tune_network: false
+  tune_nic_queues: false
+  tune_swapfile: false
   tune_swappiness: false
   tune_transparent_hugepages: false
 schema_registry: {}
@@ -1797,6 +2261,47 @@ schema_registry: {}
 	}
 }
 
+func TestWriteAuthenticationMethods(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	conf := getValidConfig()
+	conf.Redpanda.KafkaApi = []NamedSocketAddress{
+		{
+			SocketAddress: SocketAddress{"0.0.0.0", 9092},
+			Name:          "internal",
+		},
+		{
+			SocketAddress:        SocketAddress{"0.0.0.0", 9093},
+			Name:                 "sasl",
+			AuthenticationMethod: "sasl",
+		},
+		{
+			SocketAddress:        SocketAddress{"0.0.0.0", 9094},
+			Name:                 "mtls",
+			AuthenticationMethod: "mtls_identity",
+		},
+	}
+	err := mgr.Write(conf)
+	require.NoError(t, err)
+
+	bs, err := afero.ReadFile(fs, conf.ConfigFile)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), "authentication_method: mtls_identity")
+
+	read, err := NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Exactly(t, conf.Redpanda.KafkaApi, read.Redpanda.KafkaApi)
+
+	conf.Redpanda.KafkaApi[0].AuthenticationMethod = "kerberos"
+	err = mgr.Write(conf)
+	require.EqualError(
+		t,
+		err,
+		"redpanda.kafka_api.0.authentication_method must be one of"+
+			" none, sasl, mtls_identity, but got 'kerberos'",
+	)
+}
+
 func TestWriteLoaded(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	mgr := NewManager(fs)
@@ -1818,6 +2323,154 @@ func TestWriteLoaded(t *testing.T) {
 	require.Exactly(t, conf, newConf)
 }
 
+func TestWriteRackFromLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     func() *Config
+		expected *string
+		expErr   string
+	}{
+		{
+			name:     "it shouldn't set the rack if rack_label isn't set",
+			conf:     getValidConfig,
+			expected: nil,
+		},
+		{
+			name: "it should set the rack from the node label",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Rpk.NodeLabels = map[string]string{
+					"region": "us-east-1",
+					"zone":   "us-east-1a",
+				}
+				c.Rpk.RackLabel = "zone"
+				return c
+			},
+			expected: func() *string { s := "us-east-1a"; return &s }(),
+		},
+		{
+			name: "it should fail if the node label is missing",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Rpk.NodeLabels = map[string]string{"region": "us-east-1"}
+				c.Rpk.RackLabel = "zone"
+				return c
+			},
+			expErr: "rpk.rack_label is set to 'zone', but rpk.node_labels.zone isn't set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := NewManager(fs)
+			err := mgr.Write(tt.conf())
+			if tt.expErr != "" {
+				require.EqualError(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			conf, err := NewManager(fs).Read(Default().ConfigFile)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, conf.Redpanda.Rack)
+		})
+	}
+}
+
+func TestWriteStrict(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   func(*Config)
+		keys     []string
+		expected func(*Config)
+		expErr   string
+	}{
+		{
+			name: "it should write the intended single-field change",
+			update: func(c *Config) {
+				c.Redpanda.Directory = "/var/lib/redpanda/other"
+			},
+			keys: []string{"redpanda.data_directory"},
+			expected: func(c *Config) {
+				c.Redpanda.Directory = "/var/lib/redpanda/other"
+			},
+		},
+		{
+			name: "it should allow changes to the children of the given keys",
+			update: func(c *Config) {
+				c.Redpanda.SeedServers = c.Redpanda.SeedServers[:1]
+			},
+			keys: []string{"redpanda.seed_servers"},
+			expected: func(c *Config) {
+				c.Redpanda.SeedServers = c.Redpanda.SeedServers[:1]
+			},
+		},
+		{
+			name: "it should fail if an unrelated section is reordered",
+			update: func(c *Config) {
+				c.Redpanda.Directory = "/var/lib/redpanda/other"
+				s := c.Redpanda.SeedServers
+				c.Redpanda.SeedServers = []SeedServer{s[1], s[0]}
+			},
+			keys: []string{"redpanda.data_directory"},
+			expErr: "refusing to write /etc/redpanda/redpanda.yaml, the" +
+				" following fields would change unexpectedly:" +
+				" redpanda.seed_servers.0.host.port," +
+				" redpanda.seed_servers.1.host.port",
+		},
+		{
+			name: "it should fail if no keys are given and something changes",
+			update: func(c *Config) {
+				c.Rpk.TuneCpu = false
+			},
+			expErr: "refusing to write /etc/redpanda/redpanda.yaml, the" +
+				" following fields would change unexpectedly:" +
+				" rpk.tune_cpu",
+		},
+		{
+			name: "it should fail if the rack set from the node labels changes",
+			update: func(c *Config) {
+				c.Rpk.NodeLabels = map[string]string{"zone": "us-east-1a"}
+				c.Rpk.RackLabel = "zone"
+			},
+			keys: []string{"rpk.node_labels", "rpk.rack_label"},
+			expErr: "refusing to write /etc/redpanda/redpanda.yaml, the" +
+				" following fields would change unexpectedly:" +
+				" redpanda.rack",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := NewManager(fs)
+			err := mgr.Write(getValidConfig())
+			require.NoError(t, err)
+			before, err := afero.ReadFile(fs, Default().ConfigFile)
+			require.NoError(t, err)
+
+			conf, err := mgr.Read(Default().ConfigFile)
+			require.NoError(t, err)
+			tt.update(conf)
+			err = mgr.WriteStrict(conf, tt.keys...)
+			if tt.expErr != "" {
+				require.EqualError(t, err, tt.expErr)
+				// The file should be left untouched.
+				after, err := afero.ReadFile(fs, Default().ConfigFile)
+				require.NoError(t, err)
+				require.Equal(t, string(before), string(after))
+				return
+			}
+			require.NoError(t, err)
+
+			expected := getValidConfig()
+			tt.expected(expected)
+			actual, err := NewManager(fs).Read(Default().ConfigFile)
+			require.NoError(t, err)
+			require.Equal(t, expected.Redpanda.Directory, actual.Redpanda.Directory)
+			require.Equal(t, expected.Redpanda.SeedServers, actual.Redpanda.SeedServers)
+		})
+	}
+}
+
 func TestReadOrGenerate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1868,7 +2521,7 @@ func TestReadOrGenerate(t *testing.T) {
 				err := tt.setup(fs)
 				require.NoError(t, err)
 			}
-			_, err := readOrGenerate(fs, InitViper(fs), tt.configFile)
+			err := readOrGenerate(fs, InitViper(fs), tt.configFile)
 			if tt.expectError {
 				require.Error(t, err)
 				return
@@ -1944,6 +2597,45 @@ func TestSetMode(t *testing.T) {
 			mode:           "winning",
 			expectedErrMsg: "'winning' is not a supported mode. Available modes: dev, development, prod, production",
 		},
+		{
+			name: "it should enable and disable tuners on top of the mode",
+			mode: "prod:no-cpu,transparent_hugepages",
+			expectedConfig: func() *Config {
+				conf := fillRpkConfig(ModeProd)()
+				conf.Rpk.TuneCpu = false
+				conf.Rpk.TuneTransparentHugePages = true
+				return conf
+			},
+		},
+		{
+			name: "it should enable tuners on top of dev mode",
+			mode: "dev:coredump",
+			expectedConfig: func() *Config {
+				conf := fillRpkConfig(ModeDev)()
+				conf.Rpk.TuneCoredump = true
+				return conf
+			},
+		},
+		{
+			name:           "it should return an error for unknown tuners",
+			mode:           "prod:no-thp",
+			expectedErrMsg: "'thp' is not a supported tuner. Available tuners: " + strings.Join(TunerNames(), ", "),
+		},
+		{
+			name:           "it should return an error for empty tuner names",
+			mode:           "prod:cpu,",
+			expectedErrMsg: "'prod:cpu,' has an empty tuner name. The tuners must be separated by commas, e.g. prod:no-coredump,swapfile",
+		},
+		{
+			name:           "it should return an error if a tuner is both enabled and disabled",
+			mode:           "prod:cpu,no-cpu",
+			expectedErrMsg: "'prod:cpu,no-cpu' both enables and disables tuner 'cpu'",
+		},
+		{
+			name:           "it should return an error for invalid modes with tuners",
+			mode:           "winning:cpu",
+			expectedErrMsg: "'winning' is not a supported mode. Available modes: dev, development, prod, production",
+		},
 		{
 			name: "it should preserve all the values that shouldn't be reset",
 			startingConf: func() *Config {
@@ -1997,6 +2689,64 @@ func TestSetMode(t *testing.T) {
 	}
 }
 
+func TestSetModePreview(t *testing.T) {
+	for _, mode := range []string{ModeDev, ModeProd} {
+		t.Run(mode, func(st *testing.T) {
+			conf := Default()
+			conf.Rpk.TuneCoredump = true
+			conf.Rpk.EnableMemoryLocking = true
+			before := Default()
+			before.Rpk.TuneCoredump = true
+			before.Rpk.EnableMemoryLocking = true
+
+			changes, err := SetModePreview(mode, conf)
+			require.NoError(st, err)
+			// The preview doesn't modify the config.
+			require.Exactly(st, before, conf)
+
+			updated, err := SetMode(mode, conf)
+			require.NoError(st, err)
+			require.NotEmpty(st, changes)
+			for key, change := range changes {
+				require.Equal(st, change[0], flagValue(st, before, key), key)
+				require.Equal(st, change[1], flagValue(st, updated, key), key)
+			}
+			// The flags missing from the preview weren't flipped.
+			for _, key := range []string{
+				"redpanda.developer_mode",
+				"rpk.tune_cpu",
+				"rpk.tune_coredump",
+				"rpk.overprovisioned",
+			} {
+				if _, ok := changes[key]; !ok {
+					require.Equal(
+						st,
+						flagValue(st, before, key),
+						flagValue(st, updated, key),
+						key,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestSetModePreviewInvalidMode(t *testing.T) {
+	_, err := SetModePreview("winning", Default())
+	require.EqualError(
+		t,
+		err,
+		"'winning' is not a supported mode. Available modes: dev, development, prod, production",
+	)
+}
+
+func flagValue(t *testing.T, conf *Config, key string) bool {
+	flat, err := flattenConfig(conf)
+	require.NoError(t, err)
+	v, _ := flat[key].(bool)
+	return v
+}
+
 func TestCheckConfig(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2062,6 +2812,94 @@ func TestCheckConfig(t *testing.T) {
 			},
 			expected: []string{"redpanda.kafka_api.0.address can't be empty"},
 		},
+		{
+			name: "shall return no errors when the Kafka API authentication methods are valid",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.KafkaApi = []NamedSocketAddress{
+					{
+						SocketAddress:        SocketAddress{"0.0.0.0", 9092},
+						Name:                 "internal",
+						AuthenticationMethod: "none",
+					},
+					{
+						SocketAddress:        SocketAddress{"0.0.0.0", 9093},
+						Name:                 "sasl",
+						AuthenticationMethod: "sasl",
+					},
+					{
+						SocketAddress:        SocketAddress{"0.0.0.0", 9094},
+						Name:                 "mtls",
+						AuthenticationMethod: "mtls_identity",
+					},
+				}
+				return c
+			},
+			expected: []string{},
+		},
+		{
+			name: "shall return an error when a Kafka API authentication method is invalid",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.KafkaApi[0].AuthenticationMethod = "kerberos"
+				return c
+			},
+			expected: []string{"redpanda.kafka_api.0.authentication_method" +
+				" must be one of none, sasl, mtls_identity, but got 'kerberos'"},
+		},
+		{
+			name: "shall return an error when an authentication method is set for another listener",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.AdminApi[0].AuthenticationMethod = "sasl"
+				return c
+			},
+			expected: []string{"redpanda.admin.0.authentication_method can" +
+				" only be set for the redpanda.kafka_api listeners"},
+		},
+		{
+			name: "shall return no errors when the advertised addresses are concrete",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.AdvertisedKafkaApi = []NamedSocketAddress{{
+					SocketAddress: SocketAddress{"redpanda-0.example.com", 9092},
+				}}
+				c.Redpanda.AdvertisedRPCAPI = &SocketAddress{"10.0.0.1", 33145}
+				return c
+			},
+			expected: []string{},
+		},
+		{
+			name: "shall return an error when the advertised addresses are wildcards",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.AdvertisedKafkaApi = []NamedSocketAddress{
+					{SocketAddress: SocketAddress{"192.168.0.10", 9092}},
+					{SocketAddress: SocketAddress{"::", 9093}},
+				}
+				c.Redpanda.AdvertisedRPCAPI = &SocketAddress{"0.0.0.0", 33145}
+				return c
+			},
+			expected: []string{
+				"redpanda.advertised_kafka_api.1.address can't be a wildcard" +
+					" address ('::'), since clients can't connect to it." +
+					" Set it to an address reachable by them",
+				"redpanda.advertised_rpc_api.address can't be a wildcard" +
+					" address ('0.0.0.0'), since clients can't connect to it." +
+					" Set it to an address reachable by them",
+			},
+		},
+		{
+			name: "shall return an error when an advertised address is empty",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.AdvertisedKafkaApi = []NamedSocketAddress{{
+					SocketAddress: SocketAddress{"", 9092},
+				}}
+				return c
+			},
+			expected: []string{"redpanda.advertised_kafka_api.0.address can't be empty"},
+		},
 		{
 			name: "shall return an error when one of the seed servers' address is empty",
 			conf: func() *Config {
@@ -2080,6 +2918,37 @@ func TestCheckConfig(t *testing.T) {
 			},
 			expected: []string{"redpanda.seed_servers.1.host.port can't be 0"},
 		},
+		{
+			name: "shall return an error when a seed server's address isn't a valid IP or hostname",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.SeedServers[1].Host.Address = "bad host"
+				return c
+			},
+			expected: []string{"redpanda.seed_servers.1.host.address 'bad host' is not a valid IP or hostname"},
+		},
+		{
+			name: "shall return an error when two seed servers have the same address",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.SeedServers = append(
+					c.Redpanda.SeedServers,
+					c.Redpanda.SeedServers[0],
+				)
+				return c
+			},
+			expected: []string{"redpanda.seed_servers has duplicate address 127.0.0.1:33145 at indexes 0 and 2"},
+		},
+		{
+			name: "shall return no errors when the seed servers' addresses are hostnames",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.SeedServers[0].Host.Address = "redpanda-0.redpanda.svc.cluster.local"
+				c.Redpanda.SeedServers[1].Host.Address = "node-1"
+				return c
+			},
+			expected: []string{},
+		},
 		{
 			name: "shall return no errors when tune_coredump is set to false," +
 				"regardless of coredump_dir's value",
@@ -2102,6 +2971,77 @@ func TestCheckConfig(t *testing.T) {
 			expected: []string{"if rpk.tune_coredump is set to true," +
 				"rpk.coredump_dir can't be empty"},
 		},
+		{
+			name: "shall return no error if rack_label names an existing node label",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Rpk.NodeLabels = map[string]string{"zone": "us-east-1a"}
+				c.Rpk.RackLabel = "zone"
+				return c
+			},
+			expected: []string{},
+		},
+		{
+			name: "shall return an error if rack_label names a missing node label",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Rpk.NodeLabels = map[string]string{"region": "us-east-1"}
+				c.Rpk.RackLabel = "zone"
+				return c
+			},
+			expected: []string{"rpk.rack_label is set to 'zone'," +
+				" but rpk.node_labels.zone isn't set"},
+		},
+		{
+			name: "shall return an error if a dirty pages ratio and its bytes variant are set",
+			conf: func() *Config {
+				c := getValidConfig()
+				ratio, bytes := 10, 268435456
+				c.Rpk.DirtyRatio = &ratio
+				c.Rpk.DirtyBytes = &bytes
+				c.Rpk.DirtyBackgroundRatio = &ratio
+				return c
+			},
+			expected: []string{
+				"rpk.dirty_ratio and rpk.dirty_bytes can't both be set",
+			},
+		},
+		{
+			name: "shall return an error if a dirty pages ratio is out of range",
+			conf: func() *Config {
+				c := getValidConfig()
+				ratio := 101
+				c.Rpk.DirtyBackgroundRatio = &ratio
+				return c
+			},
+			expected: []string{
+				"rpk.dirty_background_ratio must be between 0 and 100, but got 101",
+			},
+		},
+		{
+			name: "shall return an error if the disks' nr_requests isn't positive",
+			conf: func() *Config {
+				c := getValidConfig()
+				nrRequests := 0
+				c.Rpk.DiskNrRequests = &nrRequests
+				return c
+			},
+			expected: []string{
+				"rpk.disk_nr_requests must be a positive integer",
+			},
+		},
+		{
+			name: "shall return an error if the max C-state is negative",
+			conf: func() *Config {
+				c := getValidConfig()
+				maxCstate := -1
+				c.Rpk.MaxCstate = &maxCstate
+				return c
+			},
+			expected: []string{
+				"rpk.max_cstate can't be negative",
+			},
+		},
 		{
 			name: "shall return no error if setup is empty," +
 				"but coredump_dir is empty",
@@ -2125,6 +3065,123 @@ func TestCheckConfig(t *testing.T) {
 	}
 }
 
+func TestCheckSeedServersResolvable(t *testing.T) {
+	conf := getValidConfig()
+	conf.Redpanda.SeedServers = []SeedServer{
+		{SocketAddress{"10.0.0.1", 33145}},
+		{SocketAddress{"redpanda-1", 33145}},
+		{SocketAddress{"redpanda-2", 33145}},
+	}
+	lookedUp := []string{}
+	lookup := func(host string) ([]string, error) {
+		lookedUp = append(lookedUp, host)
+		if host == "redpanda-2" {
+			return nil, errors.New("no such host")
+		}
+		return []string{"10.0.0.2"}, nil
+	}
+	errs := CheckSeedServersResolvable(conf, lookup)
+	require.Len(t, errs, 1)
+	require.EqualError(
+		t,
+		errs[0],
+		"redpanda.seed_servers.2.host.address 'redpanda-2' couldn't be"+
+			" resolved: no such host",
+	)
+	// IPs aren't looked up.
+	require.Equal(t, []string{"redpanda-1", "redpanda-2"}, lookedUp)
+}
+
+func TestCheckRetention(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     func() *Config
+		expected []string
+	}{
+		{
+			name:     "it shouldn't warn if the retention isn't set",
+			conf:     getValidConfig,
+			expected: []string{},
+		},
+		{
+			name: "it shouldn't warn if the retention is larger than a segment",
+			conf: func() *Config {
+				c := getValidConfig()
+				segmentSize := 134217728
+				c.Redpanda.LogSegmentSize = &segmentSize
+				c.Redpanda.Other = map[string]interface{}{
+					"retention_bytes":            1073741824,
+					"compacted_log_segment_size": 268435456,
+				}
+				return c
+			},
+			expected: []string{},
+		},
+		{
+			name: "it shouldn't warn if the retention is disabled",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.Other = map[string]interface{}{
+					"retention_bytes": -1,
+				}
+				return c
+			},
+			expected: []string{},
+		},
+		{
+			name: "it should warn if the retention is smaller than a segment",
+			conf: func() *Config {
+				c := getValidConfig()
+				segmentSize := 134217728
+				c.Redpanda.LogSegmentSize = &segmentSize
+				c.Redpanda.Other = map[string]interface{}{
+					"retention_bytes": 67108864,
+				}
+				return c
+			},
+			expected: []string{
+				"redpanda.retention_bytes (67108864) is smaller than" +
+					" redpanda.log_segment_size (134217728), so the partitions" +
+					" may keep up to a whole segment regardless of the retention",
+			},
+		},
+		{
+			name: "it should compare against the default segment size if it's not set",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.Other = map[string]interface{}{
+					"retention_bytes":            536870912,
+					"compacted_log_segment_size": 1073741824,
+				}
+				return c
+			},
+			expected: []string{
+				"redpanda.retention_bytes (536870912) is smaller than" +
+					" redpanda.log_segment_size (1073741824), so the partitions" +
+					" may keep up to a whole segment regardless of the retention",
+				"redpanda.retention_bytes (536870912) is smaller than" +
+					" redpanda.compacted_log_segment_size (1073741824), so the" +
+					" partitions may keep up to a whole segment regardless of" +
+					" the retention",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			conf := tt.conf()
+			m, err := toMap(conf, resolution{})
+			require.NoError(st, err)
+			v := viper.New()
+			err = v.MergeConfigMap(m)
+			require.NoError(st, err)
+			require.Exactly(st, tt.expected, checkRetention(v))
+			// They're only warnings, so the config is still valid.
+			ok, errs := Check(conf)
+			require.Empty(st, errs)
+			require.True(st, ok)
+		})
+	}
+}
 func TestReadAsJSON(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -2142,7 +3199,7 @@ func TestReadAsJSON(t *testing.T) {
 				return mgr.Write(conf)
 			},
 			path:     Default().ConfigFile,
-			expected: `{"config_file":"/etc/redpanda/redpanda.yaml","pandaproxy":{},"redpanda":{"admin":[{"address":"0.0.0.0","port":9644}],"data_directory":"/var/lib/redpanda/data","developer_mode":true,"kafka_api":[{"address":"0.0.0.0","name":"internal","port":9092}],"node_id":0,"rpc_server":{"address":"0.0.0.0","port":33145},"seed_servers":[]},"rpk":{"coredump_dir":"/var/lib/redpanda/coredump","enable_memory_locking":false,"enable_usage_stats":false,"overprovisioned":false,"tune_aio_events":false,"tune_clocksource":false,"tune_coredump":false,"tune_cpu":false,"tune_disk_irq":false,"tune_disk_nomerges":false,"tune_disk_scheduler":false,"tune_disk_write_cache":false,"tune_fstrim":false,"tune_network":false,"tune_swappiness":false,"tune_transparent_hugepages":false},"schema_registry":{}}`,
+			expected: `{"config_file":"/etc/redpanda/redpanda.yaml","pandaproxy":{},"redpanda":{"admin":[{"address":"0.0.0.0","port":9644}],"data_directory":"/var/lib/redpanda/data","developer_mode":true,"kafka_api":[{"address":"0.0.0.0","name":"internal","port":9092}],"node_id":0,"rpc_server":{"address":"0.0.0.0","port":33145},"seed_servers":[]},"rpk":{"coredump_dir":"/var/lib/redpanda/coredump","enable_memory_locking":false,"enable_usage_stats":false,"overprovisioned":false,"tune_aio_events":false,"tune_clocksource":false,"tune_coredump":false,"tune_cpu":false,"tune_cstates":false,"tune_dirty_pages":false,"tune_disk_irq":false,"tune_disk_nomerges":false,"tune_disk_nr_requests":false,"tune_disk_scheduler":false,"tune_disk_write_cache":false,"tune_fd_limit":false,"tune_fstrim":false,"tune_max_map_count":false,"tune_mount_options":false,"tune_network":false,"tune_nic_queues":false,"tune_swapfile":false,"tune_swappiness":false,"tune_transparent_hugepages":false},"schema_registry":{}}`,
 		},
 		{
 			name:           "it should fail if the the config isn't found",
@@ -2210,12 +3267,20 @@ func TestReadFlat(t *testing.T) {
 		"rpk.tune_clocksource":                         "false",
 		"rpk.tune_coredump":                            "false",
 		"rpk.tune_cpu":                                 "false",
+		"rpk.tune_cstates":                             "false",
+		"rpk.tune_dirty_pages":                         "false",
 		"rpk.tune_disk_irq":                            "false",
 		"rpk.tune_disk_nomerges":                       "false",
+		"rpk.tune_disk_nr_requests":                    "false",
 		"rpk.tune_disk_scheduler":                      "false",
 		"rpk.tune_disk_write_cache":                    "false",
+		"rpk.tune_fd_limit":                            "false",
 		"rpk.tune_fstrim":                              "false",
+		"rpk.tune_max_map_count":                       "false",
+		"rpk.tune_mount_options":                       "false",
 		"rpk.tune_network":                             "false",
+		"rpk.tune_nic_queues":                          "false",
+		"rpk.tune_swapfile":                            "false",
 		"rpk.tune_swappiness":                          "false",
 		"rpk.tune_transparent_hugepages":               "false",
 		"schema_registry":                              "",
@@ -2304,6 +3369,56 @@ func TestReadFlat(t *testing.T) {
 	require.Exactly(t, expected, props)
 }
 
+func TestWriteFlat(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	conf := Default()
+	conf.Redpanda.SeedServers = []SeedServer{
+		{SocketAddress{"192.168.167.0", 33145}},
+		{SocketAddress{"192.168.167.1", 33145}},
+	}
+	err := mgr.Write(conf)
+	require.NoError(t, err)
+
+	flat, err := mgr.ReadFlat(conf.ConfigFile)
+	require.NoError(t, err)
+	flat["redpanda.node_id"] = "3"
+	flat["rpk.tune_cpu"] = "true"
+	flat["redpanda.seed_servers.1"] = "192.168.167.2:33146"
+	err = mgr.WriteFlat(flat, conf.ConfigFile)
+	require.NoError(t, err)
+
+	written, err := NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Exactly(t, 3, written.Redpanda.Id)
+	require.True(t, written.Rpk.TuneCpu)
+	require.Exactly(t, []SeedServer{
+		{SocketAddress{"192.168.167.0", 33145}},
+		{SocketAddress{"192.168.167.2", 33146}},
+	}, written.Redpanda.SeedServers)
+	// The fields which weren't changed are kept.
+	require.Exactly(t, conf.Redpanda.Directory, written.Redpanda.Directory)
+	require.Exactly(t, conf.Redpanda.KafkaApi, written.Redpanda.KafkaApi)
+	require.Exactly(t, conf.Redpanda.RPCServer, written.Redpanda.RPCServer)
+
+	before, err := afero.ReadFile(fs, conf.ConfigFile)
+	require.NoError(t, err)
+	err = NewManager(fs).WriteFlat(
+		map[string]string{"rpk.tune_cpu": "maybe"},
+		conf.ConfigFile,
+	)
+	require.Error(t, err)
+	err = NewManager(fs).WriteFlat(
+		map[string]string{"redpanda.seed_servers.0": "192.168.167.0:0"},
+		conf.ConfigFile,
+	)
+	require.Error(t, err)
+	// Nothing is written if the result is invalid.
+	after, err := afero.ReadFile(fs, conf.ConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+}
+
 func TestWriteAndGenerateNodeUuid(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	mgr := NewManager(fs)
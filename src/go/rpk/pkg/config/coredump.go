@@ -0,0 +1,118 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+const (
+	nodeIDPlaceholder   = "${node_id}"
+	nodeUUIDPlaceholder = "${node_uuid}"
+)
+
+var placeholderRegexp = regexp.MustCompile(`\$\{[^}]*\}`)
+
+// ExpandedCoredumpDir returns rpk.coredump_dir with the ${node_id} and
+// ${node_uuid} placeholders replaced with the node's values, so that the nodes
+// sharing a host can keep their coredumps apart.
+func (conf *Config) ExpandedCoredumpDir() string {
+	return expandCoredumpDir(
+		conf.Rpk.CoredumpDir,
+		conf.Redpanda.Id,
+		conf.NodeUuid,
+	)
+}
+
+func expandCoredumpDir(dir string, nodeID int, nodeUUID string) string {
+	return strings.NewReplacer(
+		nodeIDPlaceholder, strconv.Itoa(nodeID),
+		nodeUUIDPlaceholder, nodeUUID,
+	).Replace(dir)
+}
+
+func checkCoredumpDir(v *viper.Viper) []error {
+	errs := []error{}
+	dir := v.GetString("rpk.coredump_dir")
+	for _, p := range placeholderRegexp.FindAllString(dir, -1) {
+		switch p {
+		case nodeIDPlaceholder:
+		case nodeUUIDPlaceholder:
+			if v.GetString("node_uuid") == "" {
+				errs = append(errs, fmt.Errorf(
+					"rpk.coredump_dir contains %s, but node_uuid isn't set",
+					nodeUUIDPlaceholder,
+				))
+			}
+		default:
+			errs = append(errs, fmt.Errorf(
+				"rpk.coredump_dir contains the unknown placeholder %s. The"+
+					" supported ones are %s and %s",
+				p,
+				nodeIDPlaceholder,
+				nodeUUIDPlaceholder,
+			))
+		}
+	}
+	return errs
+}
+
+// Checks that files can be created in the expanded rpk.coredump_dir, or in
+// its closest existing parent if it doesn't exist yet, since it's created
+// when the first coredump is saved.
+func checkCoredumpDirWritable(fs afero.Fs, conf *Config) []error {
+	if !conf.Rpk.TuneCoredump || conf.Rpk.CoredumpDir == "" {
+		return nil
+	}
+	dir := conf.ExpandedCoredumpDir()
+	existing := dir
+	for {
+		info, err := fs.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return []error{fmt.Errorf(
+					"rpk.coredump_dir: %s isn't a directory",
+					existing,
+				)}
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return []error{err}
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	f, err := afero.TempFile(fs, existing, ".rpk-write-check-")
+	if err != nil {
+		return []error{fmt.Errorf(
+			"rpk.coredump_dir: %s isn't writable: %v",
+			dir,
+			err,
+		)}
+	}
+	f.Close()
+	err = fs.Remove(f.Name())
+	if err != nil {
+		return []error{err}
+	}
+	return nil
+}
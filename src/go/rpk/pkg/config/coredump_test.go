@@ -0,0 +1,159 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandedCoredumpDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{
+			name:     "it should leave a dir without placeholders as is",
+			dir:      "/var/lib/redpanda/coredump",
+			expected: "/var/lib/redpanda/coredump",
+		},
+		{
+			name:     "it should expand ${node_id}",
+			dir:      "/var/lib/redpanda/coredump/node-${node_id}",
+			expected: "/var/lib/redpanda/coredump/node-4",
+		},
+		{
+			name:     "it should expand ${node_uuid}",
+			dir:      "/var/lib/redpanda/coredump/${node_uuid}/${node_id}",
+			expected: "/var/lib/redpanda/coredump/3y6Hbcv4fZcu/4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			conf := Default()
+			conf.Redpanda.Id = 4
+			conf.NodeUuid = "3y6Hbcv4fZcu"
+			conf.Rpk.CoredumpDir = tt.dir
+			require.Equal(st, tt.expected, conf.ExpandedCoredumpDir())
+		})
+	}
+}
+
+func TestCheckCoredumpDir(t *testing.T) {
+	tests := []struct {
+		name         string
+		dir          string
+		nodeUUID     string
+		expectedErrs []string
+	}{
+		{
+			name: "it should accept the supported placeholders",
+			dir:  "/var/lib/redpanda/coredump/${node_uuid}/${node_id}",
+			// Set, since it's generated when the config is first written.
+			nodeUUID: "3y6Hbcv4fZcu",
+		},
+		{
+			name: "it should fail if ${node_uuid} is used but node_uuid isn't set",
+			dir:  "/var/lib/redpanda/coredump/${node_uuid}",
+			expectedErrs: []string{
+				"rpk.coredump_dir contains ${node_uuid}, but node_uuid isn't set",
+			},
+		},
+		{
+			name: "it should fail if there's an unknown placeholder",
+			dir:  "/var/lib/redpanda/coredump/${hostname}",
+			expectedErrs: []string{
+				"rpk.coredump_dir contains the unknown placeholder ${hostname}." +
+					" The supported ones are ${node_id} and ${node_uuid}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			conf := Default()
+			conf.NodeUuid = tt.nodeUUID
+			conf.Rpk.TuneCoredump = true
+			conf.Rpk.CoredumpDir = tt.dir
+			_, errs := Check(conf)
+			msgs := []string{}
+			for _, err := range errs {
+				msgs = append(msgs, err.Error())
+			}
+			if len(tt.expectedErrs) == 0 {
+				require.Empty(st, msgs)
+				return
+			}
+			require.Equal(st, tt.expectedErrs, msgs)
+		})
+	}
+}
+
+func TestCheckCoredumpDirWritable(t *testing.T) {
+	tests := []struct {
+		name        string
+		before      func(afero.Fs) error
+		readOnly    bool
+		expectedErr string
+	}{
+		{
+			name: "it should pass if the expanded dir exists",
+			before: func(fs afero.Fs) error {
+				return fs.MkdirAll("/var/lib/redpanda/coredump/2", 0755)
+			},
+		},
+		{
+			name: "it should pass if the expanded dir can be created",
+		},
+		{
+			name: "it should fail if the expanded path isn't a directory",
+			before: func(fs afero.Fs) error {
+				return afero.WriteFile(fs, "/var/lib/redpanda/coredump/2", []byte{}, 0644)
+			},
+			expectedErr: "rpk.coredump_dir: /var/lib/redpanda/coredump/2 isn't a directory",
+		},
+		{
+			name:        "it should fail if the expanded dir isn't writable",
+			readOnly:    true,
+			expectedErr: "rpk.coredump_dir: /var/lib/redpanda/coredump/2 isn't writable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.before != nil {
+				require.NoError(st, tt.before(fs))
+			}
+			if tt.readOnly {
+				fs = afero.NewReadOnlyFs(fs)
+			}
+			conf := Default()
+			conf.Redpanda.Id = 2
+			conf.Rpk.TuneCoredump = true
+			conf.Rpk.CoredumpDir = "/var/lib/redpanda/coredump/${node_id}"
+			errs := CheckFiles(fs, conf)
+			if tt.expectedErr == "" {
+				require.Empty(st, errs)
+				// The check doesn't leave anything behind.
+				err := afero.Walk(fs, "/", func(path string, _ os.FileInfo, err error) error {
+					require.NotContains(st, path, ".rpk-write-check-")
+					return err
+				})
+				require.NoError(st, err)
+				return
+			}
+			require.Len(st, errs, 1)
+			require.Contains(st, errs[0].Error(), tt.expectedErr)
+		})
+	}
+}
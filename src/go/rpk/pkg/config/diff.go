@@ -0,0 +1,80 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Change is a field whose value differs between two configs.
+type Change struct {
+	// The flattened path to the field, e.g. redpanda.kafka_api.0.port.
+	Key string
+	// The field's values. Before is nil if the field was added, and After
+	// if it was removed.
+	Before interface{}
+	After  interface{}
+}
+
+// Diff returns the fields which differ between the given configs, sorted by
+// their key. The configs are compared as they are, so they should be passed
+// through Manager.Redacted first for their secrets to be compared (and
+// returned) as their placeholders.
+func Diff(before, after *Config) ([]Change, error) {
+	b, err := flattenConfig(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := flattenConfig(after)
+	if err != nil {
+		return nil, err
+	}
+	return diffFlat(b, a), nil
+}
+
+func flattenConfig(conf *Config) (map[string]interface{}, error) {
+	confMap, err := plainMap(conf)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := yaml.Marshal(confMap)
+	if err != nil {
+		return nil, err
+	}
+	return flattenYAML(bs)
+}
+
+// Compares two configs flattened with flattenYAML. Lists are compared element
+// by element, so reordering them counts as a change.
+func diffFlat(before, after map[string]interface{}) []Change {
+	changes := []Change{}
+	for k, b := range before {
+		a, ok := after[k]
+		if !ok {
+			changes = append(changes, Change{Key: k, Before: b})
+			continue
+		}
+		if !reflect.DeepEqual(a, b) {
+			changes = append(changes, Change{Key: k, Before: b, After: a})
+		}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok {
+			changes = append(changes, Change{Key: k, After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
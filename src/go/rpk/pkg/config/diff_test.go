@@ -0,0 +1,42 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := Default()
+	before.Redpanda.Other = map[string]interface{}{"removed": 1}
+	after := Default()
+	after.Redpanda.Id = 2
+	after.Redpanda.SeedServers = []SeedServer{{
+		Host: SocketAddress{Address: "10.0.0.1", Port: 33145},
+	}}
+	after.Redpanda.KafkaApi[0].Port = 9093
+
+	changes, err := Diff(before, after)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Key: "redpanda.kafka_api.0.port", Before: 9092, After: 9093},
+		{Key: "redpanda.node_id", Before: 0, After: 2},
+		{Key: "redpanda.removed", Before: 1},
+		{Key: "redpanda.seed_servers", Before: []interface{}{}},
+		{Key: "redpanda.seed_servers.0.host.address", After: "10.0.0.1"},
+		{Key: "redpanda.seed_servers.0.host.port", After: 33145},
+	}, changes)
+
+	changes, err = Diff(after, after)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
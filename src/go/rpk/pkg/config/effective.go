@@ -0,0 +1,49 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"path"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// The name of the file in the data directory holding the config redpanda is
+// started with, if it differs from the one in the config file.
+const effectiveConfigFileName = "redpanda.effective.yaml"
+
+// Returns the path of the config file redpanda should be started with. The
+// values overridden through the environment and the resolved secrets, file
+// and interface references are never written to the config file, so if conf
+// has any, it's written with them to a file in the data directory which only
+// its owner can read, and that file's path is returned. Otherwise,
+// conf.ConfigFile is.
+func effectiveConfigFile(
+	fs afero.Fs, conf *Config, resolved resolution,
+) (string, error) {
+	if len(resolved.envOverrides) == 0 && len(resolved.secrets) == 0 {
+		return conf.ConfigFile, nil
+	}
+	confMap, err := plainMap(conf)
+	if err != nil {
+		return "", err
+	}
+	bs, err := yaml.Marshal(confMap)
+	if err != nil {
+		return "", err
+	}
+	err = fs.MkdirAll(conf.Redpanda.Directory, 0755)
+	if err != nil {
+		return "", err
+	}
+	p := path.Join(conf.Redpanda.Directory, effectiveConfigFileName)
+	return p, writeFileWithMode(fs, p, bs, 0600)
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// The prefix of the environment variables which override the config.
+const EnvPrefix = "REDPANDA"
+
+type envBinding struct {
+	// The variable's name, without the prefix.
+	name string
+	// Whether it's a comma-separated list.
+	list bool
+}
+
+// The keys which can be overridden through environment variables, which
+// take precedence over the config file. Their values are in the same format
+// as ReadFlat's, e.g.
+//
+// REDPANDA_NODE_ID=7
+// REDPANDA_RPC_SERVER=0.0.0.0:33145
+// REDPANDA_KAFKA_API=internal://0.0.0.0:9092,external://0.0.0.0:19092
+// REDPANDA_SEED_SERVERS=10.0.0.1:33145,10.0.0.2:33145
+var envBindings = map[string]envBinding{
+	"redpanda.node_id":              {name: "NODE_ID"},
+	"redpanda.data_directory":       {name: "DATA_DIRECTORY"},
+	"redpanda.rpc_server":           {name: "RPC_SERVER"},
+	"redpanda.kafka_api":            {name: "KAFKA_API", list: true},
+	"redpanda.advertised_kafka_api": {name: "ADVERTISED_KAFKA_API", list: true},
+	"redpanda.seed_servers":         {name: "SEED_SERVERS", list: true},
+}
+
+// A value overridden through the environment, kept to restore the file's
+// value when the config is written.
+type envOverride struct {
+	file interface{}
+	env  interface{}
+}
+
+// EnvVar returns the name of the environment variable which overrides the
+// given key, or an empty string if it can't be overridden.
+func EnvVar(key string) string {
+	b, ok := envBindings[key]
+	if !ok {
+		return ""
+	}
+	return EnvPrefix + "_" + b.name
+}
+
+// Returns a viper instance bound to the environment variables in
+// envBindings.
+func envViper() (*viper.Viper, error) {
+	v := viper.New()
+	for key := range envBindings {
+		err := v.BindEnv(key, EnvVar(key))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Returns a copy of the config with the values set in the environment
+// applied over it, which must still pass Check, along with the file's values
+// they override, keyed by their path.
+func applyEnvOverrides(
+	conf *Config,
+) (*Config, map[string]envOverride, error) {
+	ev, err := envViper()
+	if err != nil {
+		return nil, nil, err
+	}
+	flat := map[string]string{}
+	keys := []string{}
+	for key, b := range envBindings {
+		if !ev.IsSet(key) {
+			continue
+		}
+		val := strings.TrimSpace(ev.GetString(key))
+		if val == "" {
+			continue
+		}
+		keys = append(keys, key)
+		if !b.list {
+			flat[key] = val
+			continue
+		}
+		for i, elem := range strings.Split(val, ",") {
+			flat[key+"."+strconv.Itoa(i)] = strings.TrimSpace(elem)
+		}
+	}
+	if len(keys) == 0 {
+		return conf, nil, nil
+	}
+	sort.Strings(keys)
+
+	parsed, err := FromFlat(flat)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"couldn't parse the environment overrides: %v",
+			err,
+		)
+	}
+	parsedMap, err := plainMap(parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+	pv := viper.New()
+	err = pv.MergeConfigMap(parsedMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseMap, err := plainMap(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged := viper.New()
+	err = merged.MergeConfigMap(baseMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range keys {
+		merged.Set(key, pv.Get(key))
+	}
+
+	result := &Config{}
+	decoderConfig := decoderConfig()
+	decoderConfig.Result = result
+	decoder, err := mapstructure.NewDecoder(&decoderConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = decoder.Decode(merged.AllSettings())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vars := []string{}
+	for _, key := range keys {
+		vars = append(vars, EnvVar(key))
+	}
+	ok, errs := Check(result)
+	if !ok {
+		return nil, nil, fmt.Errorf(
+			"the config is invalid with the overrides in %s: %w",
+			strings.Join(vars, ", "),
+			&InvalidConfigError{errs},
+		)
+	}
+
+	resultMap, err := plainMap(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	overrides := map[string]envOverride{}
+	for _, key := range keys {
+		overrides[key] = envOverride{
+			file: lookupPath(baseMap, key),
+			env:  lookupPath(resultMap, key),
+		}
+	}
+	return result, overrides, nil
+}
+
+// Replaces the values overridden through the environment in confMap with the
+// file's, as long as they weren't changed after being read.
+func restoreEnvOverrides(
+	confMap map[string]interface{}, overrides map[string]envOverride,
+) {
+	for path, o := range overrides {
+		parts := strings.Split(path, ".")
+		var parent interface{} = confMap
+		for _, p := range parts[:len(parts)-1] {
+			parent = child(parent, p)
+		}
+		last := parts[len(parts)-1]
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if reflect.DeepEqual(p[last], o.env) {
+				if o.file == nil {
+					delete(p, last)
+				} else {
+					p[last] = o.file
+				}
+			}
+		case map[interface{}]interface{}:
+			if reflect.DeepEqual(p[last], o.env) {
+				if o.file == nil {
+					delete(p, last)
+				} else {
+					p[last] = o.file
+				}
+			}
+		}
+	}
+}
+
+func lookupPath(confMap map[string]interface{}, path string) interface{} {
+	var val interface{} = confMap
+	for _, p := range strings.Split(path, ".") {
+		val = child(val, p)
+	}
+	return val
+}
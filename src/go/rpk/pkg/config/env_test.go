@@ -0,0 +1,168 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func setEnv(t *testing.T, key, value string) {
+	prev, wasSet := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if wasSet {
+			os.Setenv(key, prev)
+			return
+		}
+		os.Unsetenv(key)
+	})
+}
+
+func writeEnvTestConfig(t *testing.T, fs afero.Fs) *Config {
+	conf := getValidConfig()
+	conf.Redpanda.Id = 0
+	err := NewManager(fs).Write(conf)
+	require.NoError(t, err)
+	return conf
+}
+
+func readWrittenNodeID(t *testing.T, fs afero.Fs, path string) int {
+	bs, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	written := &Config{}
+	err = yaml.Unmarshal(bs, written)
+	require.NoError(t, err)
+	return written.Redpanda.Id
+}
+
+func TestEnvVar(t *testing.T) {
+	require.Equal(t, "REDPANDA_NODE_ID", EnvVar("redpanda.node_id"))
+	require.Equal(t, "REDPANDA_KAFKA_API", EnvVar("redpanda.kafka_api"))
+	require.Equal(t, "REDPANDA_RPC_SERVER", EnvVar("redpanda.rpc_server"))
+	require.Equal(t, "", EnvVar("rpk.tune_cpu"))
+}
+
+func TestEnvViper(t *testing.T) {
+	setEnv(t, "REDPANDA_NODE_ID", "7")
+	v, err := envViper()
+	require.NoError(t, err)
+	require.True(t, v.IsSet("redpanda.node_id"))
+	require.Equal(t, "7", v.GetString("redpanda.node_id"))
+	require.False(t, v.IsSet("redpanda.seed_servers"))
+}
+
+func TestReadEnvOverrides(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := writeEnvTestConfig(t, fs)
+
+	setEnv(t, "REDPANDA_NODE_ID", "7")
+	setEnv(t, "REDPANDA_RPC_SERVER", "10.0.0.7:33145")
+	setEnv(
+		t,
+		"REDPANDA_KAFKA_API",
+		"internal://0.0.0.0:9092, external://10.0.0.7:19092",
+	)
+	setEnv(t, "REDPANDA_SEED_SERVERS", "10.0.0.1:33145,10.0.0.2:33145")
+
+	mgr := NewManager(fs)
+	read, err := mgr.Read(conf.ConfigFile)
+	require.NoError(t, err)
+	// The environment takes precedence over the file.
+	require.Equal(t, 7, read.Redpanda.Id)
+	require.Equal(
+		t,
+		SocketAddress{"10.0.0.7", 33145},
+		read.Redpanda.RPCServer,
+	)
+	require.Equal(t, []NamedSocketAddress{
+		{SocketAddress: SocketAddress{"0.0.0.0", 9092}, Name: "internal"},
+		{SocketAddress: SocketAddress{"10.0.0.7", 19092}, Name: "external"},
+	}, read.Redpanda.KafkaApi)
+	require.Equal(t, []SeedServer{
+		{SocketAddress{"10.0.0.1", 33145}},
+		{SocketAddress{"10.0.0.2", 33145}},
+	}, read.Redpanda.SeedServers)
+	// The rest is read from the file.
+	require.Equal(t, conf.Redpanda.Directory, read.Redpanda.Directory)
+	require.Equal(t, conf.ConfigFile, read.ConfigFile)
+
+	// The overrides aren't written back to the file.
+	read.Rpk.TuneCpu = true
+	err = mgr.Write(read)
+	require.NoError(t, err)
+	require.Equal(t, 0, readWrittenNodeID(t, fs, conf.ConfigFile))
+	os.Unsetenv("REDPANDA_NODE_ID")
+	os.Unsetenv("REDPANDA_RPC_SERVER")
+	os.Unsetenv("REDPANDA_KAFKA_API")
+	os.Unsetenv("REDPANDA_SEED_SERVERS")
+	written, err := NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.True(t, written.Rpk.TuneCpu)
+	require.Equal(t, conf.Redpanda.RPCServer, written.Redpanda.RPCServer)
+	require.Equal(t, conf.Redpanda.KafkaApi, written.Redpanda.KafkaApi)
+	require.Equal(t, conf.Redpanda.SeedServers, written.Redpanda.SeedServers)
+}
+
+func TestReadEnvOverridesChangedAfterRead(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := writeEnvTestConfig(t, fs)
+	setEnv(t, "REDPANDA_NODE_ID", "7")
+
+	mgr := NewManager(fs)
+	read, err := mgr.Read(conf.ConfigFile)
+	require.NoError(t, err)
+	// The values changed after being read are written.
+	read.Redpanda.Id = 9
+	err = mgr.Write(read)
+	require.NoError(t, err)
+	require.Equal(t, 9, readWrittenNodeID(t, fs, conf.ConfigFile))
+}
+
+func TestReadEnvOverridesInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		value       string
+		expectedErr string
+	}{
+		{
+			name:        "it should fail if the overridden config is invalid",
+			key:         "REDPANDA_RPC_SERVER",
+			value:       "10.0.0.7:0",
+			expectedErr: "the config is invalid with the overrides in REDPANDA_RPC_SERVER: redpanda.rpc_server.port can't be 0",
+		},
+		{
+			name:        "it should fail if an override can't be parsed",
+			key:         "REDPANDA_SEED_SERVERS",
+			value:       "10.0.0.1",
+			expectedErr: "couldn't parse the environment overrides: redpanda.seed_servers.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			conf := writeEnvTestConfig(st, fs)
+			setEnv(st, tt.key, tt.value)
+			_, err := NewManager(fs).Read(conf.ConfigFile)
+			require.Error(st, err)
+			require.Contains(st, err.Error(), tt.expectedErr)
+			if tt.key == "REDPANDA_RPC_SERVER" {
+				var invalid *InvalidConfigError
+				require.True(st, errors.As(err, &invalid))
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+const (
+	configFileModeKey = "rpk.config_file_mode"
+
+	// The mode of the config files and their backups if
+	// rpk.config_file_mode isn't set.
+	DefaultConfigFileMode os.FileMode = 0644
+)
+
+// Returns the mode the config files and their backups are written with, from
+// rpk.config_file_mode, an octal string such as "0600".
+func configFileMode(v *viper.Viper) (os.FileMode, error) {
+	s := v.GetString(configFileModeKey)
+	if s == "" {
+		return DefaultConfigFileMode, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf(
+			"%s must be an octal file mode, such as 0600, but got '%s'",
+			configFileModeKey,
+			s,
+		)
+	}
+	// rpk rewrites the config (e.g. with 'rpk redpanda config set'), so the
+	// owner must be able to read it and write to it.
+	if mode&0600 != 0600 {
+		return 0, fmt.Errorf(
+			"%s %04o must allow the owner to read and write the config",
+			configFileModeKey,
+			mode,
+		)
+	}
+	return os.FileMode(mode), nil
+}
+
+func checkConfigFileMode(v *viper.Viper) []error {
+	if _, err := configFileMode(v); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// Backs up the config file at path, as utils.BackupFile does, restricting
+// the backup to the same mode as the config, since it holds the same values.
+func backupConfigFile(
+	fs afero.Fs, v *viper.Viper, path string,
+) (string, error) {
+	mode, err := configFileMode(v)
+	if err != nil {
+		return "", err
+	}
+	md5, err := utils.FileMd5(fs, path)
+	if err != nil {
+		return "", err
+	}
+	backup := fmt.Sprintf("%s.vectorized.%s.bk", path, md5)
+	bs, err := afero.ReadFile(fs, path)
+	if err == nil {
+		err = writeFileWithMode(fs, backup, bs, mode)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to create backup of %s: %w", path, err)
+	}
+	return backup, nil
+}
+
+// Writes data to the file at path, which is set to the given mode whether it
+// existed or not. Unlike with afero.WriteFile, the mode is set before the
+// data is written, so that it's never readable with a broader one, such as
+// the existing file's.
+func writeFileWithMode(
+	fs afero.Fs, path string, data []byte, mode os.FileMode,
+) error {
+	f, err := fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	// New files are created with the mode minus the umask.
+	err = fs.Chmod(path, mode)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"os"
+	fp "path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func requireMode(t *testing.T, fs afero.Fs, path string, mode os.FileMode) {
+	info, err := fs.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, mode, info.Mode().Perm(), path)
+}
+
+func TestWriteConfigFileMode(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	conf := getValidConfig()
+
+	require.NoError(t, mgr.Write(conf))
+	requireMode(t, fs, conf.ConfigFile, DefaultConfigFileMode)
+
+	// The mode is applied to the existing file, and to its backup.
+	conf.Rpk.ConfigFileMode = "0600"
+	require.NoError(t, mgr.Write(conf))
+	requireMode(t, fs, conf.ConfigFile, 0600)
+	backup, err := findBackup(fs, fp.Dir(conf.ConfigFile))
+	require.NoError(t, err)
+	require.NotEmpty(t, backup)
+	requireMode(t, fs, backup, 0600)
+}
+
+// An afero.Fs which records the mode each file has when it's written to.
+type modeRecordingFs struct {
+	afero.Fs
+	modes map[string]os.FileMode
+}
+
+func (f *modeRecordingFs) OpenFile(
+	name string, flag int, perm os.FileMode,
+) (afero.File, error) {
+	file, err := f.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &modeRecordingFile{file, f}, nil
+}
+
+type modeRecordingFile struct {
+	afero.File
+	fs *modeRecordingFs
+}
+
+func (f *modeRecordingFile) Write(b []byte) (int, error) {
+	info, err := f.fs.Stat(f.Name())
+	if err != nil {
+		return 0, err
+	}
+	f.fs.modes[f.Name()] = info.Mode().Perm()
+	return f.File.Write(b)
+}
+
+func TestConfigFileModeIsSetBeforeWriting(t *testing.T) {
+	fs := &modeRecordingFs{afero.NewMemMapFs(), map[string]os.FileMode{}}
+	mgr := NewManager(fs)
+	conf := getValidConfig()
+	require.NoError(t, mgr.Write(conf))
+
+	// The new values are never readable with the file's previous mode.
+	conf.Rpk.ConfigFileMode = "0600"
+	require.NoError(t, mgr.Write(conf))
+	require.Equal(t, os.FileMode(0600), fs.modes[conf.ConfigFile])
+	backup, err := findBackup(fs, fp.Dir(conf.ConfigFile))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fs.modes[backup])
+}
+
+func TestReadOrGenerateConfigFileMode(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	conf, err := mgr.FindOrGenerate("/etc/redpanda/redpanda.yaml")
+	require.NoError(t, err)
+	requireMode(t, fs, conf.ConfigFile, DefaultConfigFileMode)
+}
+
+func TestConfigFileModeCheck(t *testing.T) {
+	tests := []struct {
+		mode   string
+		expErr string
+	}{
+		{mode: "0640"},
+		{
+			mode:   "rw-r--r--",
+			expErr: "rpk.config_file_mode must be an octal file mode, such as 0600, but got 'rw-r--r--'",
+		},
+		{
+			mode:   "01777",
+			expErr: "rpk.config_file_mode must be an octal file mode, such as 0600, but got '01777'",
+		},
+		{
+			mode:   "0400",
+			expErr: "rpk.config_file_mode 0400 must allow the owner to read and write the config",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := NewManager(fs)
+			conf := getValidConfig()
+			conf.Rpk.ConfigFileMode = tt.mode
+			err := mgr.Write(conf)
+			if tt.expErr == "" {
+				require.NoError(st, err)
+				return
+			}
+			require.EqualError(st, err, tt.expErr)
+		})
+	}
+}
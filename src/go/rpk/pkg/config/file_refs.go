@@ -0,0 +1,52 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	fp "path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// String values with this prefix are replaced with the contents of the file
+// at the path following it, e.g. '@file:/etc/redpanda/certs/ca.pem'.
+const fileRefPrefix = "@file:"
+
+// Values starting with an extra '@' (e.g. '@@file:foo') are taken literally,
+// without the leading '@'.
+const escapedFileRefPrefix = "@" + fileRefPrefix
+
+func isFileRef(val string) bool {
+	return strings.HasPrefix(val, fileRefPrefix) ||
+		strings.HasPrefix(val, escapedFileRefPrefix)
+}
+
+// Returns the value a file reference resolves to. Relative paths are relative
+// to the config file's directory. A single trailing newline is trimmed from
+// the file's contents.
+func resolveFileRef(fs afero.Fs, configFile, val string) (string, error) {
+	if strings.HasPrefix(val, escapedFileRefPrefix) {
+		return strings.TrimPrefix(val, "@"), nil
+	}
+	path := strings.TrimPrefix(val, fileRefPrefix)
+	if path == "" {
+		return "", fmt.Errorf("'%s' doesn't reference any file", val)
+	}
+	if !fp.IsAbs(path) {
+		path = fp.Join(fp.Dir(configFile), path)
+	}
+	bs, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read the file referenced by '%s': %w", val, err)
+	}
+	return strings.TrimSuffix(string(bs), "\n"), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const confWithFileRef = `config_file: /etc/redpanda/redpanda.yaml
+redpanda:
+  data_directory: /var/lib/redpanda/data
+  node_id: 1
+  rack: '%s'
+  rpc_server:
+    address: 0.0.0.0
+    port: 33145
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+  admin:
+  - address: 0.0.0.0
+    port: 9644
+  seed_servers: []
+`
+
+func TestReadFileRefs(t *testing.T) {
+	const confPath = "/etc/redpanda/redpanda.yaml"
+	tests := []struct {
+		name     string
+		value    string
+		files    map[string]string
+		expected string
+		expErr   string
+	}{
+		{
+			name:     "it should replace the value with the file's contents",
+			value:    "@file:/etc/redpanda/rack",
+			files:    map[string]string{"/etc/redpanda/rack": "us-east-1a\n"},
+			expected: "us-east-1a",
+		},
+		{
+			name:     "it should read relative paths from the config's directory",
+			value:    "@file:values/rack",
+			files:    map[string]string{"/etc/redpanda/values/rack": "us-east-1b"},
+			expected: "us-east-1b",
+		},
+		{
+			name:     "it should take escaped references literally",
+			value:    "@@file:/etc/redpanda/rack",
+			files:    map[string]string{"/etc/redpanda/rack": "us-east-1a\n"},
+			expected: "@file:/etc/redpanda/rack",
+		},
+		{
+			name:   "it should fail if the referenced file doesn't exist",
+			value:  "@file:/etc/redpanda/rack",
+			expErr: "redpanda.rack: couldn't read the file referenced by '@file:/etc/redpanda/rack'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			conf := fmt.Sprintf(confWithFileRef, tt.value)
+			err := afero.WriteFile(fs, confPath, []byte(conf), 0644)
+			require.NoError(t, err)
+			for path, content := range tt.files {
+				err = afero.WriteFile(fs, path, []byte(content), 0644)
+				require.NoError(t, err)
+			}
+			c, err := NewManager(fs).Read(confPath)
+			if tt.expErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, c.Redpanda.Rack)
+			require.Equal(t, tt.expected, *c.Redpanda.Rack)
+		})
+	}
+}
+
+func TestFileRefsAreKeptOnWrite(t *testing.T) {
+	const (
+		confPath = "/etc/redpanda/redpanda.yaml"
+		ref      = "@file:/etc/redpanda/rack"
+	)
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, confPath, []byte(fmt.Sprintf(confWithFileRef, ref)), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "/etc/redpanda/rack", []byte("us-east-1a\n"), 0644)
+	require.NoError(t, err)
+
+	mgr := NewManager(fs)
+	conf, err := mgr.Read(confPath)
+	require.NoError(t, err)
+	conf.Redpanda.Id = 2
+	err = mgr.Write(conf)
+	require.NoError(t, err)
+
+	bs, err := afero.ReadFile(fs, confPath)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), ref)
+	require.NotContains(t, string(bs), "us-east-1a")
+}
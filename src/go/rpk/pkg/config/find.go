@@ -18,33 +18,44 @@ import (
 	"github.com/spf13/afero"
 )
 
-func FindConfigFile(fs afero.Fs) (string, error) {
-	log.Debugf("Looking for the redpanda config file")
+// SearchPaths returns the paths where the config file is looked for when
+// it's not given explicitly, in the order they're checked. Locations that
+// can't be determined (e.g. the home directory when $HOME isn't set) are
+// skipped, so that the remaining ones can still be searched.
+func SearchPaths() []string {
 	var configPathProviders = []func() ([]string, error){
 		currentDirectory,
 		sysConfDirectory,
 		homeDirectory,
 	}
 
-	lookedUpPaths := []string{}
+	candidates := []string{}
 	for _, provider := range configPathProviders {
 		paths, err := provider()
 		if err != nil {
-			return "", err
+			log.Debugf("Skipping a config search location: %v", err)
+			continue
 		}
 		for _, path := range paths {
-			candidate := filepath.Join(path, "redpanda.yaml")
-			lookedUpPaths = append(lookedUpPaths, candidate)
-			log.Debugf("Looking for redpanda config file in '%s'", path)
-			if exists, _ := afero.Exists(fs, candidate); exists {
-				return candidate, nil
-			}
+			candidates = append(candidates, filepath.Join(path, "redpanda.yaml"))
+		}
+	}
+	return candidates
+}
+
+func FindConfigFile(fs afero.Fs) (string, error) {
+	log.Debugf("Looking for the redpanda config file")
+	candidates := SearchPaths()
+	for _, candidate := range candidates {
+		log.Debugf("Looking for redpanda config file in '%s'", filepath.Dir(candidate))
+		if exists, _ := afero.Exists(fs, candidate); exists {
+			return candidate, nil
 		}
 	}
 	// os.PathError can be checked with os.IsNotExist.
 	return "", &os.PathError{
 		Op:   "Open",
-		Path: strings.Join(lookedUpPaths, ", "),
+		Path: strings.Join(candidates, ", "),
 		Err:  os.ErrNotExist,
 	}
 }
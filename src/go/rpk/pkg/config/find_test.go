@@ -52,6 +52,14 @@ func TestFindConfig(t *testing.T) {
 			},
 			want: filepath.Join(currentDir(), "redpanda.yaml"),
 		},
+		{
+			name: "should prefer the 'etc' directory over the home directory",
+			before: func(fs afero.Fs) {
+				createConfigIn(fs, homeDir())
+				createConfigIn(fs, filepath.Join("/", "etc", "redpanda"))
+			},
+			want: filepath.Join("/", "etc", "redpanda", "redpanda.yaml"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -68,6 +76,40 @@ func TestFindConfig(t *testing.T) {
 	}
 }
 
+func TestSearchPaths(t *testing.T) {
+	paths := SearchPaths()
+	require.Equal(
+		t,
+		[]string{
+			filepath.Join(currentDir(), "redpanda.yaml"),
+			filepath.Join("/", "etc", "redpanda", "redpanda.yaml"),
+			filepath.Join(homeDir(), "redpanda.yaml"),
+		},
+		paths,
+	)
+}
+
+func TestFindConfigWithoutHome(t *testing.T) {
+	etcConfig := filepath.Join("/", "etc", "redpanda", "redpanda.yaml")
+	setEnv(t, "HOME", "")
+
+	paths := SearchPaths()
+	require.Equal(
+		t,
+		[]string{
+			filepath.Join(currentDir(), "redpanda.yaml"),
+			etcConfig,
+		},
+		paths,
+	)
+
+	fs := afero.NewMemMapFs()
+	createConfigIn(fs, filepath.Dir(etcConfig))
+	got, err := FindConfigFile(fs)
+	require.NoError(t, err)
+	require.Equal(t, etcConfig, got)
+}
+
 func createConfigIn(fs afero.Fs, path string) {
 	fs.Create(filepath.Join(path, "redpanda.yaml"))
 }
@@ -0,0 +1,227 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+// The lists of listeners ReadFlat writes as [<name>://]<address>:<port>, e.g.
+// "redpanda.kafka_api.0" => "internal://0.0.0.0:9092", along with their
+// authentication method, e.g. "redpanda.kafka_api.0.authentication_method" =>
+// "sasl".
+var flatListenerKeys = []string{
+	"redpanda.kafka_api",
+	"redpanda.advertised_kafka_api",
+	"redpanda.admin",
+	"pandaproxy.pandaproxy_api",
+	"pandaproxy.advertised_pandaproxy_api",
+	"schema_registry.schema_registry_api",
+}
+
+// The lists of TLS configs ReadFlat writes field by field, e.g.
+// "redpanda.kafka_api_tls.0.key_file" => "/etc/redpanda/key.pem".
+var flatTLSKeys = []string{
+	"redpanda.kafka_api_tls",
+	"redpanda.admin_api_tls",
+	"pandaproxy.pandaproxy_api_tls",
+	"schema_registry.schema_registry_api_tls",
+}
+
+// The socket addresses ReadFlat writes as <address>:<port>, e.g.
+// "redpanda.rpc_server" => "0.0.0.0:33145".
+var flatSocketAddressKeys = []string{
+	"redpanda.rpc_server",
+	"redpanda.advertised_rpc_api",
+}
+
+// The sections ReadFlat writes as empty values when they're present but
+// empty, as pandaproxy's and schema_registry's defaults are.
+var flatSectionKeys = []string{
+	"pandaproxy",
+	"schema_registry",
+}
+
+// FromFlat builds a Config from a map of flattened paths to values, inverting
+// Manager.ReadFlat. e.g. "redpanda.seed_servers.0" => "127.0.0.1:33145" or
+// "redpanda.kafka_api_tls.0.key_file" => "/etc/redpanda/key.pem".
+// Empty values are skipped. Fields which ReadFlat doesn't output can't be
+// recovered, and are left with their zero value.
+func FromFlat(flat map[string]string) (*Config, error) {
+	root := map[string]interface{}{}
+	// Lists are collected by key and index first, since the indexes may be
+	// sparse or come in any order.
+	lists := map[string]map[int]interface{}{}
+	addToList := func(key string, idx int, val interface{}) {
+		if lists[key] == nil {
+			lists[key] = map[int]interface{}{}
+		}
+		lists[key][idx] = val
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		val := flat[k]
+		if val == "" {
+			_, exists := root[k]
+			if !exists && utils.StringInSlice(k, flatSectionKeys) {
+				root[k] = map[string]interface{}{}
+			}
+			continue
+		}
+		parent, idx, field, isListKey := splitListKey(k)
+		switch {
+		case isListKey && parent == "redpanda.seed_servers" && field == "":
+			sa, err := parseSocketAddress(val)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			addToList(parent, idx, map[string]interface{}{
+				"host": sa,
+			})
+
+		case isListKey && field == "" && utils.StringInSlice(parent, flatListenerKeys):
+			name := ""
+			if i := strings.Index(val, "://"); i != -1 {
+				name = val[:i]
+				val = val[i+len("://"):]
+			}
+			sa, err := parseSocketAddress(val)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			if name != "" {
+				sa["name"] = name
+			}
+			// The listener's other fields, such as authentication_method,
+			// may have been added already.
+			if lists[parent] != nil && lists[parent][idx] != nil {
+				for f, v := range lists[parent][idx].(map[string]interface{}) {
+					sa[f] = v
+				}
+			}
+			addToList(parent, idx, sa)
+
+		case isListKey && field == "authentication_method" &&
+			utils.StringInSlice(parent, flatListenerKeys):
+			if lists[parent] == nil || lists[parent][idx] == nil {
+				addToList(parent, idx, map[string]interface{}{})
+			}
+			l := lists[parent][idx].(map[string]interface{})
+			l[field] = val
+
+		case isListKey && field != "" && utils.StringInSlice(parent, flatTLSKeys):
+			if lists[parent] == nil || lists[parent][idx] == nil {
+				addToList(parent, idx, map[string]interface{}{})
+			}
+			tls := lists[parent][idx].(map[string]interface{})
+			tls[field] = parse(val)
+
+		case utils.StringInSlice(k, flatSocketAddressKeys):
+			sa, err := parseSocketAddress(val)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			setNested(root, k, sa)
+
+		case isListKey && field == "":
+			addToList(parent, idx, parse(val))
+
+		default:
+			setNested(root, k, parse(val))
+		}
+	}
+
+	for key, elems := range lists {
+		idxs := make([]int, 0, len(elems))
+		for i := range elems {
+			idxs = append(idxs, i)
+		}
+		sort.Ints(idxs)
+		list := make([]interface{}, 0, len(idxs))
+		for _, i := range idxs {
+			list = append(list, elems[i])
+		}
+		setNested(root, key, list)
+	}
+
+	conf := &Config{}
+	decoderConfig := decoderConfig()
+	decoderConfig.Result = conf
+	decoder, err := mapstructure.NewDecoder(&decoderConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(root)
+	if err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// Splits keys such as "redpanda.kafka_api_tls.0.key_file" into the list key
+// ("redpanda.kafka_api_tls"), the index (0) and the field ("key_file"), which
+// is empty for keys such as "redpanda.seed_servers.0". The last return value
+// is false if the key doesn't have an index.
+func splitListKey(key string) (string, int, string, bool) {
+	parts := strings.Split(key, ".")
+	for i := 1; i < len(parts); i++ {
+		idx, err := strconv.Atoi(parts[i])
+		if err != nil {
+			continue
+		}
+		return strings.Join(parts[:i], "."),
+			idx,
+			strings.Join(parts[i+1:], "."),
+			true
+	}
+	return "", 0, "", false
+}
+
+func parseSocketAddress(s string) (map[string]interface{}, error) {
+	host, portStr, err := net.SplitHostPort(s)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port '%s'", portStr)
+	}
+	return map[string]interface{}{
+		"address": host,
+		"port":    port,
+	}, nil
+}
+
+func setNested(root map[string]interface{}, key string, val interface{}) {
+	parts := strings.Split(key, ".")
+	m := root
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = val
+}
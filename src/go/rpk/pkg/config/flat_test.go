@@ -0,0 +1,131 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromFlat(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   func() *Config
+		flat   map[string]string
+		expErr string
+	}{
+		{
+			name: "it should round-trip a valid config",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Pandaproxy = nil
+				c.SchemaRegistry = nil
+				return c
+			},
+		},
+		{
+			name: "it should round-trip named listeners and TLS",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Pandaproxy = nil
+				c.SchemaRegistry = nil
+				c.Redpanda.Id = 3
+				c.Redpanda.KafkaApi = []NamedSocketAddress{
+					{SocketAddress: SocketAddress{"0.0.0.0", 9092}, Name: "internal"},
+					{
+						SocketAddress:        SocketAddress{"192.168.0.2", 9093},
+						Name:                 "external",
+						AuthenticationMethod: "sasl",
+					},
+				}
+				c.Redpanda.AdvertisedKafkaApi = []NamedSocketAddress{
+					{SocketAddress: SocketAddress{"redpanda-0.local", 9092}, Name: "internal"},
+					{SocketAddress: SocketAddress{"redpanda.example.com", 30092}, Name: "external"},
+				}
+				c.Redpanda.KafkaApiTLS = []ServerTLS{{
+					Name:              "external",
+					KeyFile:           "/etc/redpanda/key.pem",
+					CertFile:          "/etc/redpanda/cert.pem",
+					TruststoreFile:    "/etc/redpanda/ca.pem",
+					Enabled:           true,
+					RequireClientAuth: true,
+				}}
+				c.Redpanda.AdminApiTLS = []ServerTLS{{
+					KeyFile:  "/etc/redpanda/admin-key.pem",
+					CertFile: "/etc/redpanda/admin-cert.pem",
+					Enabled:  true,
+				}}
+				return c
+			},
+		},
+		{
+			name: "it should round-trip the advertised RPC API and the proxy listeners",
+			conf: func() *Config {
+				c := getValidConfig()
+				c.Redpanda.AdvertisedRPCAPI = &SocketAddress{"redpanda-0.local", 33145}
+				c.Pandaproxy = &Pandaproxy{
+					PandaproxyAPI: []NamedSocketAddress{
+						{SocketAddress: SocketAddress{"0.0.0.0", 8082}, Name: "internal"},
+					},
+					AdvertisedPandaproxyAPI: []NamedSocketAddress{
+						{SocketAddress: SocketAddress{"redpanda-0.local", 8082}, Name: "internal"},
+					},
+					PandaproxyAPITLS: []ServerTLS{{
+						Name:     "internal",
+						KeyFile:  "/etc/redpanda/proxy-key.pem",
+						CertFile: "/etc/redpanda/proxy-cert.pem",
+						Enabled:  true,
+					}},
+				}
+				c.SchemaRegistry = &SchemaRegistry{
+					SchemaRegistryAPI: []NamedSocketAddress{
+						{SocketAddress: SocketAddress{"0.0.0.0", 8081}},
+					},
+				}
+				return c
+			},
+		},
+		{
+			name:   "it should fail if a seed server isn't host:port",
+			flat:   map[string]string{"redpanda.seed_servers.0": "127.0.0.1"},
+			expErr: "redpanda.seed_servers.0: address 127.0.0.1: missing port in address",
+		},
+		{
+			name:   "it should fail if a listener port isn't a number",
+			flat:   map[string]string{"redpanda.kafka_api.0": "internal://0.0.0.0:port"},
+			expErr: "redpanda.kafka_api.0: invalid port 'port'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.conf == nil {
+				_, err := FromFlat(tt.flat)
+				require.EqualError(t, err, tt.expErr)
+				return
+			}
+			fs := afero.NewMemMapFs()
+			mgr := NewManager(fs)
+			conf := tt.conf()
+			err := mgr.Write(conf)
+			require.NoError(t, err)
+
+			expected, err := mgr.Read(conf.ConfigFile)
+			require.NoError(t, err)
+			flat, err := mgr.ReadFlat(conf.ConfigFile)
+			require.NoError(t, err)
+
+			actual, err := FromFlat(flat)
+			require.NoError(t, err)
+			require.Exactly(t, expected, actual)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	vos "github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+)
+
+const postWriteHookTimeout = 30 * time.Second
+
+// Runs the command in rpk.post_write_hook, if set, with the path of the
+// config that was just written as its last argument. If the hook fails, the
+// error is only logged, unless rpk.post_write_hook_fatal is set.
+func runPostWriteHook(proc vos.Proc, v *viper.Viper, path string) error {
+	hook := strings.Fields(v.GetString("rpk.post_write_hook"))
+	if len(hook) == 0 {
+		return nil
+	}
+	log.Debugf("Running the post-write hook '%s'", strings.Join(hook, " "))
+	_, err := proc.RunWithSystemLdPath(
+		postWriteHookTimeout,
+		hook[0],
+		append(hook[1:], path)...,
+	)
+	if err == nil {
+		return nil
+	}
+	err = fmt.Errorf(
+		"the config was written to %s, but the post-write hook '%s' failed: %v",
+		path,
+		strings.Join(hook, " "),
+		err,
+	)
+	if v.GetBool("rpk.post_write_hook_fatal") {
+		return err
+	}
+	log.Warn(err)
+	return nil
+}
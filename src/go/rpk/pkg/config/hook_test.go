@@ -0,0 +1,100 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+type hookProc struct {
+	calls []string
+	err   error
+}
+
+func (p *hookProc) RunWithSystemLdPath(
+	_ time.Duration, cmd string, args ...string,
+) ([]string, error) {
+	p.calls = append(p.calls, strings.Join(append([]string{cmd}, args...), " "))
+	return nil, p.err
+}
+
+func (*hookProc) IsRunning(_ time.Duration, _ string) bool {
+	return true
+}
+
+func TestPostWriteHook(t *testing.T) {
+	tests := []struct {
+		name          string
+		hook          string
+		fatal         bool
+		hookErr       error
+		expectedCalls []string
+		expectedErr   string
+	}{
+		{
+			name: "it shouldn't run anything if the hook isn't set",
+		},
+		{
+			name:          "it should pass the config path to the hook",
+			hook:          "/usr/local/bin/notify --reason write",
+			expectedCalls: []string{"/usr/local/bin/notify --reason write /etc/redpanda/redpanda.yaml"},
+		},
+		{
+			name:          "it shouldn't fail the write if the hook fails",
+			hook:          "/usr/local/bin/notify",
+			hookErr:       errors.New("exit status 1"),
+			expectedCalls: []string{"/usr/local/bin/notify /etc/redpanda/redpanda.yaml"},
+		},
+		{
+			name:          "it should fail the write if the hook fails and it's fatal",
+			hook:          "/usr/local/bin/notify",
+			fatal:         true,
+			hookErr:       errors.New("exit status 1"),
+			expectedCalls: []string{"/usr/local/bin/notify /etc/redpanda/redpanda.yaml"},
+			expectedErr: "the config was written to /etc/redpanda/redpanda.yaml," +
+				" but the post-write hook '/usr/local/bin/notify' failed:" +
+				" exit status 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			proc := &hookProc{err: tt.hookErr}
+			mgr := &manager{
+				fs:              fs,
+				v:               InitViper(fs),
+				proc:            proc,
+				backup:          true,
+				nodeIDValidator: NoopNodeIDValidator,
+			}
+			conf := Default()
+			conf.Rpk.PostWriteHook = tt.hook
+			conf.Rpk.PostWriteHookFatal = tt.fatal
+
+			err := mgr.Write(conf)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+			} else {
+				require.NoError(st, err)
+			}
+			require.Equal(st, tt.expectedCalls, proc.calls)
+			// The config is written regardless of the hook's result.
+			written, err := NewManager(fs).Read(conf.ConfigFile)
+			require.NoError(st, err)
+			require.Equal(st, tt.hook, written.Rpk.PostWriteHook)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Listener addresses with this prefix are replaced with the current primary
+// IP of the network interface following it, e.g. 'iface:eth0', so that they
+// don't have to be updated when the IP changes (e.g. through DHCP).
+const interfaceRefPrefix = "iface:"
+
+// InterfaceAddrs returns the addresses assigned to the network interface with
+// the given name.
+type InterfaceAddrs func(name string) ([]net.Addr, error)
+
+// SystemInterfaceAddrs returns the addresses of the host's network interface
+// with the given name. It's the manager's default InterfaceAddrs.
+func SystemInterfaceAddrs(name string) ([]net.Addr, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return iface.Addrs()
+}
+
+// Whether the value at the given path is an interface reference. Only the
+// addresses the node listens on or advertises can be, since the seed servers
+// are the other nodes' addresses.
+func isInterfaceRef(path, val string) bool {
+	return strings.HasPrefix(val, interfaceRefPrefix) &&
+		strings.HasSuffix(path, ".address") &&
+		!strings.HasPrefix(path, "redpanda.seed_servers.")
+}
+
+// Returns a copy of val where the interface references are replaced with the
+// interfaces' IPs, as returned by addrs. The replaced values are added to
+// resolved, keyed by their path.
+func resolveInterfaceRefs(
+	val interface{}, addrs InterfaceAddrs, resolved map[string]resolvedSecret,
+) (interface{}, error) {
+	return replaceStrings(val, func(path, v string) (string, error) {
+		if !isInterfaceRef(path, v) {
+			return v, nil
+		}
+		value, err := resolveInterfaceRef(addrs, v)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		resolved[path] = resolvedSecret{raw: v, value: value}
+		return value, nil
+	})
+}
+
+// Returns the primary IP of the referenced interface: its first IPv4
+// address, or its first IPv6 one if it has none. Link-local addresses are
+// skipped.
+func resolveInterfaceRef(addrs InterfaceAddrs, val string) (string, error) {
+	name := strings.TrimPrefix(val, interfaceRefPrefix)
+	if name == "" {
+		return "", fmt.Errorf("'%s' doesn't reference any interface", val)
+	}
+	ifaceAddrs, err := addrs(name)
+	if err != nil {
+		return "", fmt.Errorf(
+			"couldn't get the addresses of the interface referenced by '%s': %w",
+			val,
+			err,
+		)
+	}
+	var ipv6 net.IP
+	for _, addr := range ifaceAddrs {
+		var ip net.IP
+		switch a := addr.(type) {
+		case *net.IPNet:
+			ip = a.IP
+		case *net.IPAddr:
+			ip = a.IP
+		}
+		if ip == nil || ip.IsLinkLocalUnicast() {
+			continue
+		}
+		if ip.To4() != nil {
+			return ip.String(), nil
+		}
+		if ipv6 == nil {
+			ipv6 = ip
+		}
+	}
+	if ipv6 == nil {
+		return "", fmt.Errorf("the interface referenced by '%s' has no address", val)
+	}
+	return ipv6.String(), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const confWithInterfaceRefs = `config_file: /etc/redpanda/redpanda.yaml
+redpanda:
+  data_directory: /var/lib/redpanda/data
+  node_id: 1
+  rpc_server:
+    address: iface:eth0
+    port: 33145
+  kafka_api:
+  - address: iface:eth0
+    port: 9092
+  admin:
+  - address: 0.0.0.0
+    port: 9644
+  seed_servers: []
+`
+
+func fakeInterfaceAddrs(ifaces map[string][]string) InterfaceAddrs {
+	return func(name string) ([]net.Addr, error) {
+		ips, ok := ifaces[name]
+		if !ok {
+			return nil, errors.New("no such network interface")
+		}
+		addrs := []net.Addr{}
+		for _, ip := range ips {
+			addrs = append(addrs, &net.IPNet{IP: net.ParseIP(ip)})
+		}
+		return addrs, nil
+	}
+}
+
+func TestReadInterfaceRefs(t *testing.T) {
+	const confPath = "/etc/redpanda/redpanda.yaml"
+	tests := []struct {
+		name     string
+		ifaces   map[string][]string
+		expected string
+		expErr   string
+	}{
+		{
+			name:     "it should replace the reference with the interface's IP",
+			ifaces:   map[string][]string{"eth0": {"10.0.0.5"}},
+			expected: "10.0.0.5",
+		},
+		{
+			name: "it should prefer IPv4 addresses and skip link-local ones",
+			ifaces: map[string][]string{
+				"eth0": {"fe80::1", "2001:db8::5", "169.254.0.5", "10.0.0.5"},
+			},
+			expected: "10.0.0.5",
+		},
+		{
+			name:     "it should use the IPv6 address if there's no IPv4 one",
+			ifaces:   map[string][]string{"eth0": {"fe80::1", "2001:db8::5"}},
+			expected: "2001:db8::5",
+		},
+		{
+			name:   "it should fail if the interface has no address",
+			ifaces: map[string][]string{"eth0": {}},
+			expErr: "redpanda.kafka_api.0.address: the interface referenced by 'iface:eth0' has no address",
+		},
+		{
+			name:   "it should fail if the interface doesn't exist",
+			ifaces: map[string][]string{"eth1": {"10.0.0.5"}},
+			expErr: "couldn't get the addresses of the interface referenced by 'iface:eth0': no such network interface",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, confPath, []byte(confWithInterfaceRefs), 0644)
+			require.NoError(t, err)
+			mgr := NewManager(fs)
+			mgr.SetInterfaceAddrs(fakeInterfaceAddrs(tt.ifaces))
+			conf, err := mgr.Read(confPath)
+			if tt.expErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, conf.Redpanda.RPCServer.Address)
+			require.Equal(t, tt.expected, conf.Redpanda.KafkaApi[0].Address)
+			require.Equal(t, "0.0.0.0", conf.Redpanda.AdminApi[0].Address)
+		})
+	}
+}
+
+func TestInterfaceRefsAreKept(t *testing.T) {
+	const confPath = "/etc/redpanda/redpanda.yaml"
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, confPath, []byte(confWithInterfaceRefs), 0644)
+	require.NoError(t, err)
+
+	mgr := NewManager(fs)
+	mgr.SetInterfaceAddrs(
+		fakeInterfaceAddrs(map[string][]string{"eth0": {"10.0.0.5"}}),
+	)
+	conf, err := mgr.Read(confPath)
+	require.NoError(t, err)
+	conf.Redpanda.Id = 2
+	err = mgr.Write(conf)
+	require.NoError(t, err)
+	bs, err := afero.ReadFile(fs, confPath)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), "iface:eth0")
+	require.NotContains(t, string(bs), "10.0.0.5")
+}
+
+func TestSeedServersAreNotInterfaceRefs(t *testing.T) {
+	require.False(t, isInterfaceRef("redpanda.seed_servers.0.host.address", "iface:eth0"))
+	require.True(t, isInterfaceRef("redpanda.advertised_rpc_api.address", "iface:eth0"))
+	require.False(t, isInterfaceRef("rpk.kafka_api.sasl.user", "iface:eth0"))
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// KnownKeys returns the sorted list of dotted keys that map to a field in
+// Config, e.g. "redpanda.data_directory" or "rpk.kafka_api.tls.key_file".
+// Both the parent keys (e.g. "rpk") and the leaf keys are returned, since
+// either can be passed to Manager.Set. Fields captured by the inline "Other"
+// maps aren't known ahead of time and aren't returned.
+func KnownKeys() []string {
+	keys := []string{}
+	collectKeys(reflect.TypeOf(Config{}), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			// Embedded structs (e.g. NamedSocketAddress' SocketAddress)
+			// are inlined, so their fields belong to the current prefix.
+			if f.Anonymous {
+				collectKeys(f.Type, prefix, keys)
+			}
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		*keys = append(*keys, key)
+		collectKeys(f.Type, key, keys)
+	}
+}
+
+// Returns the kind of the field the given key maps to in Config, with
+// pointers dereferenced. List indexes and map keys are accepted, e.g.
+// "redpanda.seed_servers.0.host.port". It returns false if the key doesn't
+// map to a known field.
+func kindOf(key string) (reflect.Kind, bool) {
+	t := reflect.TypeOf(Config{})
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Slice, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			f, ok := fieldByYAMLName(t, part)
+			if !ok {
+				return reflect.Invalid, false
+			}
+			t = f.Type
+		default:
+			return reflect.Invalid, false
+		}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind(), true
+}
+
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldName := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if fieldName == "" && f.Anonymous {
+			if inner, ok := fieldByYAMLName(f.Type, name); ok {
+				return inner, true
+			}
+			continue
+		}
+		if fieldName == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// UnrecognizedKeys returns the sorted dotted keys in conf which don't map to
+// a field in Config, and were kept as they were in its inline "Other" maps,
+// e.g. "redpanda.enable_idempotence". They're fields redpanda supports but
+// rpk doesn't model yet, or typos.
+func UnrecognizedKeys(conf *Config) []string {
+	keys := []string{}
+	collectOther := func(prefix string, other map[string]interface{}) {
+		for k := range other {
+			keys = append(keys, prefix+k)
+		}
+	}
+	collectOther("", conf.Other)
+	collectOther("redpanda.", conf.Redpanda.Other)
+	if conf.Pandaproxy != nil {
+		collectOther("pandaproxy.", conf.Pandaproxy.Other)
+	}
+	if conf.PandaproxyClient != nil {
+		collectOther("pandaproxy_client.", conf.PandaproxyClient.Other)
+	}
+	if conf.SchemaRegistryClient != nil {
+		collectOther("schema_registry_client.", conf.SchemaRegistryClient.Other)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// The redpanda.* keys which are specific to each node, so a node's admin API
+// doesn't report them among the supported properties in /v1/config.
+var nodeOnlyKeys = map[string]bool{
+	"data_directory":       true,
+	"node_id":              true,
+	"rack":                 true,
+	"seed_servers":         true,
+	"rpc_server":           true,
+	"advertised_rpc_api":   true,
+	"kafka_api":            true,
+	"advertised_kafka_api": true,
+	"kafka_api_tls":        true,
+	"admin":                true,
+	"admin_api_tls":        true,
+	"developer_mode":       true,
+}
+
+// UnsupportedKeys returns the sorted redpanda.* keys set in conf which aren't
+// among the supported properties, e.g. as reported by a node's admin API.
+// They're usually properties renamed or removed in the node's version. The
+// node-specific keys, such as redpanda.data_directory, aren't checked.
+func UnsupportedKeys(
+	conf *Config, supported map[string]interface{},
+) ([]string, error) {
+	bs, err := yaml.Marshal(conf.Redpanda)
+	if err != nil {
+		return nil, err
+	}
+	set := map[string]interface{}{}
+	err = yaml.Unmarshal(bs, &set)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for k := range set {
+		if nodeOnlyKeys[k] {
+			continue
+		}
+		if _, ok := supported[k]; !ok {
+			keys = append(keys, "redpanda."+k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
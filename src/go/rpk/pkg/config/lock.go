@@ -0,0 +1,89 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+var (
+	// How long a write waits for another process to release the config's
+	// lock before giving up.
+	configLockTimeout = 10 * time.Second
+	// How often the lock is retried while it's held by another process.
+	configLockRetryInterval = 100 * time.Millisecond
+)
+
+// Returns the path of the lock file for the config at the given path, which
+// is kept next to it, e.g. /etc/redpanda/redpanda.yaml.lock.
+func configLockPath(path string) string {
+	return path + ".lock"
+}
+
+// Takes an exclusive flock on the config's lock file, so that concurrent rpk
+// processes writing the same config (and its backup) are serialized. It waits
+// for up to timeout if another process holds it. The returned func releases
+// the lock. The lock file itself is never removed, since another process may
+// be waiting on it.
+func lockConfig(
+	fs afero.Fs, path string, timeout time.Duration,
+) (func(), error) {
+	err := createConfigDir(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	lockPath := configLockPath(path)
+	f, err := fs.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open the lock file %s: %w", lockPath, err)
+	}
+	osFile, ok := f.(*os.File)
+	if !ok {
+		// Files in in-memory filesystems can't be flocked, and aren't
+		// shared with other processes anyway.
+		return func() { f.Close() }, nil
+	}
+	fd := int(osFile.Fd())
+	deadline := time.Now().Add(timeout)
+	for {
+		err = syscall.Flock(fd, syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK {
+			f.Close()
+			return nil, fmt.Errorf("couldn't lock %s: %w", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf(
+				"config is locked by another process: %s is still"+
+					" locked after %s. Try again once the other"+
+					" rpk process is done",
+				lockPath,
+				timeout,
+			)
+		}
+		time.Sleep(configLockRetryInterval)
+	}
+	return func() {
+		err := syscall.Flock(fd, syscall.LOCK_UN)
+		if err != nil {
+			log.Debugf("Couldn't unlock %s: %v", lockPath, err)
+		}
+		f.Close()
+	}, nil
+}
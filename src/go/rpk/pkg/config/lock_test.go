@@ -0,0 +1,170 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func withLockTimeout(t *testing.T, timeout time.Duration) {
+	prevTimeout, prevInterval := configLockTimeout, configLockRetryInterval
+	configLockTimeout, configLockRetryInterval = timeout, 10*time.Millisecond
+	t.Cleanup(func() {
+		configLockTimeout, configLockRetryInterval = prevTimeout, prevInterval
+	})
+}
+
+func TestWriteLockedConfig(t *testing.T) {
+	withLockTimeout(t, 200*time.Millisecond)
+	// The lock is taken in the actual filesystem.
+	fs := afero.NewOsFs()
+	conf := Default()
+	conf.ConfigFile = filepath.Join(t.TempDir(), "redpanda.yaml")
+	err := NewManager(fs).Write(conf)
+	require.NoError(t, err)
+	before, err := afero.ReadFile(fs, conf.ConfigFile)
+	require.NoError(t, err)
+
+	// Hold the lock as another process would.
+	unlock, err := lockConfig(fs, conf.ConfigFile, time.Second)
+	require.NoError(t, err)
+
+	conf.Redpanda.Id = 2
+	err = NewManager(fs).Write(conf)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "config is locked by another process")
+	require.Contains(t, err.Error(), configLockPath(conf.ConfigFile))
+	after, err := afero.ReadFile(fs, conf.ConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+
+	// The write goes through once the lock is released.
+	unlock()
+	err = NewManager(fs).Write(conf)
+	require.NoError(t, err)
+	written, err := NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, 2, written.Redpanda.Id)
+}
+
+func TestWriteConfigSequentially(t *testing.T) {
+	withLockTimeout(t, time.Second)
+	fs := afero.NewOsFs()
+	conf := Default()
+	conf.ConfigFile = filepath.Join(t.TempDir(), "redpanda.yaml")
+	for id := 1; id <= 3; id++ {
+		conf.Redpanda.Id = id
+		err := NewManager(fs).Write(conf)
+		require.NoError(t, err)
+	}
+	written, err := NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, 3, written.Redpanda.Id)
+}
+
+func TestWriteWaitsForConfigLock(t *testing.T) {
+	withLockTimeout(t, 5*time.Second)
+	fs := afero.NewOsFs()
+	conf := Default()
+	conf.ConfigFile = filepath.Join(t.TempDir(), "redpanda.yaml")
+
+	unlock, err := lockConfig(fs, conf.ConfigFile, time.Second)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		unlock()
+	}()
+	// The writer waits for the lock to be released, rather than failing.
+	err = NewManager(fs).Write(conf)
+	require.NoError(t, err)
+}
+
+func TestWriteMultiLocked(t *testing.T) {
+	withLockTimeout(t, 200*time.Millisecond)
+	fs := afero.NewOsFs()
+	dir := t.TempDir()
+	paths := []string{
+		filepath.Join(dir, "redpanda.yaml"),
+		filepath.Join(dir, "copy.yaml"),
+	}
+	unlock, err := lockConfig(fs, paths[1], time.Second)
+	require.NoError(t, err)
+
+	// None of the files are written while any of them is locked.
+	err = NewManager(fs).WriteMulti(Default(), paths)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "config is locked by another process")
+	for _, path := range paths {
+		exists, err := afero.Exists(fs, path)
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+
+	unlock()
+	err = NewManager(fs).WriteMulti(Default(), paths)
+	require.NoError(t, err)
+}
+
+func TestLockHeldAcrossReadAndWrite(t *testing.T) {
+	withLockTimeout(t, 10*time.Second)
+	fs := afero.NewOsFs()
+	conf := Default()
+	conf.ConfigFile = filepath.Join(t.TempDir(), "redpanda.yaml")
+	err := NewManager(fs).Write(conf)
+	require.NoError(t, err)
+
+	// Each cycle increments the node ID, so none of the increments should
+	// be lost if the config is locked from when it's read until it's
+	// written.
+	const writers, cycles = 2, 5
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*cycles)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < cycles; j++ {
+				errs <- func() error {
+					mgr := NewManager(fs)
+					unlock, err := mgr.Lock(conf.ConfigFile)
+					if err != nil {
+						return err
+					}
+					defer unlock()
+					current, err := mgr.Read(conf.ConfigFile)
+					if err != nil {
+						return err
+					}
+					next := strconv.Itoa(current.Redpanda.Id + 1)
+					err = mgr.Set("redpanda.node_id", next, "")
+					if err != nil {
+						return err
+					}
+					return mgr.WriteLoaded()
+				}()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	written, err := NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, writers*cycles, written.Redpanda.Id)
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+const managedKeysKey = "rpk.managed_keys"
+
+// ErrUnmanagedKey is returned when rpk is asked to modify a field which isn't
+// in rpk.managed_keys.
+var ErrUnmanagedKey = errors.New("permission denied")
+
+// Reads rpk.managed_keys from the file at path. The list is always read from
+// the file, rather than from the loaded config, so that it can only be
+// changed by editing the file directly.
+func readManagedKeys(fs afero.Fs, path string) ([]string, error) {
+	exists, err := afero.Exists(fs, path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	bs, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	conf := struct {
+		Rpk struct {
+			ManagedKeys []string `yaml:"managed_keys"`
+		} `yaml:"rpk"`
+	}{}
+	err = yaml.Unmarshal(bs, &conf)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %v", path, err)
+	}
+	keys := make([]string, 0, len(conf.Rpk.ManagedKeys))
+	for _, k := range conf.Rpk.ManagedKeys {
+		// viper's keys are always lowercase.
+		keys = append(keys, strings.ToLower(k))
+	}
+	return keys, nil
+}
+
+// Returns an ErrUnmanagedKey error if rpk isn't allowed to modify the given
+// key in the config file at path.
+func checkManagedKey(fs afero.Fs, path, key string) error {
+	key = strings.ToLower(key)
+	if isUnderAnyKey(key, []string{managedKeysKey}) {
+		return managedKeysChangedErr(path)
+	}
+	managed, err := readManagedKeys(fs, path)
+	if err != nil {
+		return err
+	}
+	return checkManagedFields(path, managed, []string{key})
+}
+
+// Returns an ErrUnmanagedKey error if writing the config in v to path would
+// change any field rpk isn't allowed to modify.
+func checkManagedChanges(fs afero.Fs, v *viper.Viper, path string) error {
+	managed, err := readManagedKeys(fs, path)
+	if err != nil {
+		return err
+	}
+	if len(managed) == 0 {
+		// Only the list itself needs to be protected.
+		if len(v.GetStringSlice(managedKeysKey)) > 0 {
+			return managedKeysChangedErr(path)
+		}
+		return nil
+	}
+	changed, err := changedFields(fs, v, path)
+	if err != nil {
+		return err
+	}
+	for _, f := range changed {
+		if isUnderAnyKey(f, []string{managedKeysKey}) {
+			return managedKeysChangedErr(path)
+		}
+	}
+	return checkManagedFields(path, managed, changed)
+}
+
+func checkManagedFields(path string, managed, fields []string) error {
+	if len(managed) == 0 {
+		return nil
+	}
+	denied := []string{}
+	for _, f := range fields {
+		if !isUnderAnyKey(f, managed) {
+			denied = append(denied, f)
+		}
+	}
+	if len(denied) > 0 {
+		return fmt.Errorf(
+			"%w: rpk isn't allowed to modify %s, since it's not listed"+
+				" in %s in %s",
+			ErrUnmanagedKey,
+			strings.Join(denied, ", "),
+			managedKeysKey,
+			path,
+		)
+	}
+	return nil
+}
+
+func managedKeysChangedErr(path string) error {
+	return fmt.Errorf(
+		"%w: %s can only be changed by editing %s directly",
+		ErrUnmanagedKey,
+		managedKeysKey,
+		path,
+	)
+}
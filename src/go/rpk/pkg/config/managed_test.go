@@ -0,0 +1,189 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func writeManagedConfig(t *testing.T, fs afero.Fs, managed ...string) {
+	conf := getValidConfig()
+	conf.Rpk.ManagedKeys = managed
+	bs, err := yaml.Marshal(conf)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, conf.ConfigFile, bs, 0644)
+	require.NoError(t, err)
+}
+
+func TestSetManagedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		managed []string
+		key     string
+		value   string
+		expErr  string
+	}{
+		{
+			name:    "it should allow setting a managed key",
+			managed: []string{"redpanda.node_id"},
+			key:     "redpanda.node_id",
+			value:   "42",
+		},
+		{
+			name:    "it should allow setting the children of a managed key",
+			managed: []string{"redpanda.rpc_server"},
+			key:     "redpanda.rpc_server.port",
+			value:   "33146",
+		},
+		{
+			name:  "it should allow setting any key if the list is empty",
+			key:   "redpanda.node_id",
+			value: "42",
+		},
+		{
+			name:    "it should reject setting a key that isn't managed",
+			managed: []string{"redpanda.node_id"},
+			key:     "redpanda.data_directory",
+			value:   "/var/lib/redpanda/other",
+			expErr: "permission denied: rpk isn't allowed to modify" +
+				" redpanda.data_directory, since it's not listed in" +
+				" rpk.managed_keys in /etc/redpanda/redpanda.yaml",
+		},
+		{
+			name:    "it should reject setting the list itself",
+			managed: []string{"rpk"},
+			key:     "rpk.managed_keys",
+			value:   `["redpanda"]`,
+			expErr: "permission denied: rpk.managed_keys can only be" +
+				" changed by editing /etc/redpanda/redpanda.yaml directly",
+		},
+		{
+			name:   "it should reject setting the list if it's not set",
+			key:    "rpk.managed_keys",
+			value:  `["redpanda"]`,
+			expErr: "permission denied: rpk.managed_keys can only be",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeManagedConfig(t, fs, tt.managed...)
+			mgr := NewManager(fs)
+			_, err := mgr.Read(Default().ConfigFile)
+			require.NoError(t, err)
+			err = mgr.Set(tt.key, tt.value, "")
+			if tt.expErr != "" {
+				require.Error(t, err)
+				require.True(t, errors.Is(err, ErrUnmanagedKey))
+				require.Contains(t, err.Error(), tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			err = mgr.WriteLoaded()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWriteManagedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		managed []string
+		update  func(*Config)
+		expErr  string
+	}{
+		{
+			name:    "it should write changes to managed keys",
+			managed: []string{"redpanda.node_id", "redpanda.seed_servers"},
+			update: func(c *Config) {
+				c.Redpanda.Id = 42
+				c.Redpanda.SeedServers = c.Redpanda.SeedServers[:1]
+			},
+		},
+		{
+			name:    "it should reject changes to keys that aren't managed",
+			managed: []string{"redpanda.node_id"},
+			update: func(c *Config) {
+				c.Redpanda.Id = 42
+				c.Rpk.TuneCpu = false
+			},
+			expErr: "permission denied: rpk isn't allowed to modify" +
+				" rpk.tune_cpu",
+		},
+		{
+			name:    "it should reject changes to the list",
+			managed: []string{"redpanda"},
+			update: func(c *Config) {
+				c.Rpk.ManagedKeys = []string{"redpanda", "rpk"}
+			},
+			expErr: "permission denied: rpk.managed_keys can only be",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeManagedConfig(t, fs, tt.managed...)
+			before, err := afero.ReadFile(fs, Default().ConfigFile)
+			require.NoError(t, err)
+
+			mgr := NewManager(fs)
+			conf, err := mgr.Read(Default().ConfigFile)
+			require.NoError(t, err)
+			tt.update(conf)
+			err = mgr.Write(conf)
+			if tt.expErr != "" {
+				require.Error(t, err)
+				require.True(t, errors.Is(err, ErrUnmanagedKey))
+				require.Contains(t, err.Error(), tt.expErr)
+				// The file should be left untouched.
+				after, err := afero.ReadFile(fs, Default().ConfigFile)
+				require.NoError(t, err)
+				require.Equal(t, string(before), string(after))
+				return
+			}
+			require.NoError(t, err)
+			conf, err = NewManager(fs).Read(Default().ConfigFile)
+			require.NoError(t, err)
+			require.Equal(t, 42, conf.Redpanda.Id)
+		})
+	}
+}
+
+func TestWriteManagedKeysWithRackLabel(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := getValidConfig()
+	conf.Rpk.ManagedKeys = []string{"redpanda.node_id"}
+	conf.Rpk.NodeLabels = map[string]string{"zone": "us-east-1a"}
+	conf.Rpk.RackLabel = "zone"
+	bs, err := yaml.Marshal(conf)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, conf.ConfigFile, bs, 0644)
+	require.NoError(t, err)
+
+	// redpanda.rack isn't managed, but it's set by rpk rather than by the
+	// user, so it shouldn't be rejected.
+	mgr := NewManager(fs)
+	conf, err = mgr.Read(conf.ConfigFile)
+	require.NoError(t, err)
+	conf.Redpanda.Id = 42
+	err = mgr.Write(conf)
+	require.NoError(t, err)
+
+	conf, err = NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, 42, conf.Redpanda.Id)
+	require.NotNil(t, conf.Redpanda.Rack)
+	require.Equal(t, "us-east-1a", *conf.Redpanda.Rack)
+}
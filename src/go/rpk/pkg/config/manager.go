@@ -13,20 +13,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"os"
 	"path/filepath"
 	fp "path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/icza/dyno"
 	"github.com/mitchellh/mapstructure"
+	"github.com/pelletier/go-toml"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
+	vos "github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 	"gopkg.in/yaml.v2"
 )
@@ -40,10 +44,22 @@ type Manager interface {
 	Read(path string) (*Config, error)
 	// Writes the config to Config.ConfigFile
 	Write(conf *Config) error
+	// Writes the config to each of the given paths, e.g. to keep a copy of
+	// it for an external tool. If writing any of them fails, the ones
+	// written before it are restored, so that they don't end up out of sync.
+	WriteMulti(conf *Config, paths []string) error
+	// Like Write, but fails without writing anything if the result would
+	// change any field in the current file other than the given keys (or
+	// their children).
+	WriteStrict(conf *Config, keys ...string) error
 	// Writes the currently-loaded config to redpanda.config_file
 	WriteLoaded() error
 	// Get the currently-loaded config
 	Get() (*Config, error)
+	// Gets the value at the given key in the currently-loaded config, which
+	// is the whole subtree for objects and lists. List elements are indexed
+	// the same way Set's keys are, e.g. redpanda.seed_servers.0.host.
+	GetKey(key string) (interface{}, error)
 	// Sets key to the given value (parsing it according to the format)
 	Set(key, value, format string) error
 	// If path is empty, tries to find the file in the default locations.
@@ -57,24 +73,101 @@ type Manager interface {
 	// paths.
 	// e.g. "redpanda.tls.key_file" => "value"
 	ReadFlat(path string) (map[string]string, error)
+	// Applies a map of flattened paths to values, as returned by ReadFlat,
+	// to the config at the given path, and writes it once it's checked.
+	WriteFlat(props map[string]string, path string) error
 	// Reads the configuration as JSON
 	ReadAsJSON(path string) (string, error)
 	// Generates and writes the node's UUID
 	WriteNodeUUID(conf *Config) error
 	// Merges an input config to the currently-loaded map
 	Merge(conf *Config) error
+	// Sets whether the current config file is backed up before it's
+	// overwritten by the following writes. It's backed up by default.
+	SetBackup(backup bool)
+	// Sets the validator called with redpanda.node_id when it changes. If
+	// it's nil, any node ID is accepted, which is the default.
+	SetNodeIDValidator(validator NodeIDValidator)
+	// Sets the function used to get the addresses of the network interfaces
+	// referenced in the config (e.g. 'iface:eth0'). If it's nil, the host's
+	// are used, which is the default.
+	SetInterfaceAddrs(addrs InterfaceAddrs)
+	// Locks the config at the given path until the returned func is called,
+	// so that other rpk processes can't change it between it being read and
+	// written back. The writes to it through the manager while it's locked
+	// don't lock it again.
+	Lock(path string) (func(), error)
+	// Returns a copy of the config with the values set in the environment
+	// applied over it, as Read and FindOrGenerate do, which must still pass
+	// Check. The file's values are restored when the config is written, so
+	// that they aren't replaced by the environment's.
+	ApplyEnvOverrides(conf *Config) (*Config, error)
+	// Returns a copy of the config where the values read from the secrets
+	// file are replaced back with their placeholders, and the ones
+	// overridden through the environment with the file's, so that it can be
+	// shown or sent elsewhere.
+	Redacted(conf *Config) (*Config, error)
+	// Returns where each of the config's values comes from, keyed by its
+	// flattened path, e.g. redpanda.kafka_api.0.port: SourceEnv if it's
+	// overridden through the environment, SourceSet if it's under any of
+	// the given keys, set from the command line, SourceFile if it's in the
+	// config's file, or SourceDefault otherwise.
+	Sources(conf *Config, setKeys []string) (map[string]string, error)
+	// Renders the config with the values overridden through the
+	// environment, and the values read from the secrets file replaced back
+	// with their placeholders. If sources isn't nil, each value is annotated
+	// with its source in a comment.
+	EffectiveYAML(conf *Config, sources map[string]string) ([]byte, error)
+	// Returns the path of the config file redpanda should be started with,
+	// which is conf.ConfigFile, unless the config has values which are
+	// never written to it (i.e. the environment overrides and the resolved
+	// secrets), in which case it's written with them to a file in the data
+	// directory which only its owner can read.
+	EffectiveConfigFile(conf *Config) (string, error)
 }
 
 type manager struct {
-	fs afero.Fs
-	v  *viper.Viper
+	fs              afero.Fs
+	v               *viper.Viper
+	proc            vos.Proc
+	backup          bool
+	nodeIDValidator NodeIDValidator
+	interfaceAddrs  InterfaceAddrs
+	// The absolute paths of the configs the manager holds the locks of.
+	locked map[string]bool
+	// The values resolved in the config last returned by the manager, which
+	// are replaced back with the file's when a config is written or shown.
+	resolved resolution
+}
+
+// The values in a config which differ from the ones in its file.
+type resolution struct {
+	// The values which were read from the secrets file, keyed by their path.
+	secrets map[string]resolvedSecret
+	// The values overridden through the environment, keyed by their path.
+	envOverrides map[string]envOverride
 }
 
 func NewManager(fs afero.Fs) Manager {
-	return &manager{fs, InitViper(fs)}
+	return &manager{
+		fs:              fs,
+		v:               InitViper(fs),
+		proc:            vos.NewProc(),
+		backup:          true,
+		nodeIDValidator: NoopNodeIDValidator,
+		interfaceAddrs:  SystemInterfaceAddrs,
+	}
 }
 
 func (m *manager) FindOrGenerate(path string) (*Config, error) {
+	conf, err := m.findOrGenerate(path)
+	if err != nil {
+		return nil, err
+	}
+	return m.ApplyEnvOverrides(conf)
+}
+
+func (m *manager) findOrGenerate(path string) (*Config, error) {
 	if path == "" {
 		addConfigPaths(m.v)
 		err := m.v.ReadInConfig()
@@ -85,7 +178,7 @@ func (m *manager) FindOrGenerate(path string) (*Config, error) {
 			}
 			path = Default().ConfigFile
 		} else {
-			conf, err := unmarshal(m.v)
+			conf, err := m.unmarshal()
 			if err != nil {
 				return nil, err
 			}
@@ -94,26 +187,30 @@ func (m *manager) FindOrGenerate(path string) (*Config, error) {
 		}
 
 	}
-	return readOrGenerate(m.fs, m.v, path)
+	err := readOrGenerate(m.fs, m.v, path)
+	if err != nil {
+		return nil, err
+	}
+	return m.unmarshal()
 }
 
-// Tries reading a config file at the given path, or generates a default config
-// and writes it to the path.
-func readOrGenerate(fs afero.Fs, v *viper.Viper, path string) (*Config, error) {
+// Tries reading a config file at the given path into v, or generates a
+// default config and writes it to the path.
+func readOrGenerate(fs afero.Fs, v *viper.Viper, path string) error {
 	abs, err := absPath(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	v.SetConfigFile(abs)
+	setConfigFile(v, abs)
 	err = v.ReadInConfig()
 	if err == nil {
 		// The config file's there, there's nothing to do.
-		return unmarshal(v)
+		return nil
 	}
 	_, notFound := err.(viper.ConfigFileNotFoundError)
 	notExist := os.IsNotExist(err)
 	if err != nil && !notFound && !notExist {
-		return nil, fmt.Errorf(
+		return fmt.Errorf(
 			"An error happened while trying to read %s: %v",
 			abs,
 			err,
@@ -127,17 +224,17 @@ func readOrGenerate(fs afero.Fs, v *viper.Viper, path string) (*Config, error) {
 	v.Set("config_file", abs)
 	err = createConfigDir(fs, abs)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	err = v.WriteConfigAs(abs)
+	err = writeYAML(fs, v, abs)
 	if err != nil {
-		return nil, fmt.Errorf(
+		return fmt.Errorf(
 			"Couldn't write config to %s: %v",
 			abs,
 			err,
 		)
 	}
-	return unmarshal(v)
+	return nil
 }
 
 func (m *manager) ReadOrFind(path string) (*Config, error) {
@@ -152,16 +249,13 @@ func (m *manager) ReadOrFind(path string) (*Config, error) {
 }
 
 func (m *manager) ReadFlat(path string) (map[string]string, error) {
-	m.v.SetConfigFile(path)
+	setConfigFile(m.v, path)
 	err := m.v.ReadInConfig()
 	if err != nil {
 		return nil, err
 	}
 	keys := m.v.AllKeys()
 	flatMap := map[string]string{}
-	compactAddrFields := []string{
-		"redpanda.rpc_server",
-	}
 	for _, k := range keys {
 		if k == "redpanda.seed_servers" {
 			seeds := &[]SeedServer{}
@@ -179,7 +273,7 @@ func (m *manager) ReadFlat(path string) (map[string]string, error) {
 			}
 			continue
 		}
-		if k == "redpanda.advertised_kafka_api" || k == "redpanda.kafka_api" || k == "redpanda.admin" {
+		if utils.StringInSlice(k, flatListenerKeys) {
 			addrs := []NamedSocketAddress{}
 			err := unmarshalKey(m.v, k, &addrs)
 			if err != nil {
@@ -196,10 +290,13 @@ func (m *manager) ReadFlat(path string) (map[string]string, error) {
 					str = fmt.Sprintf("%s://%s", a.Name, str)
 				}
 				flatMap[key] = str
+				if a.AuthenticationMethod != "" {
+					flatMap[key+".authentication_method"] = a.AuthenticationMethod
+				}
 			}
 			continue
 		}
-		if k == "redpanda.kafka_api_tls" || k == "redpanda.admin_api_tls" {
+		if utils.StringInSlice(k, flatTLSKeys) {
 			tlss := []map[string]interface{}{}
 			err := unmarshalKey(m.v, k, &tlss)
 			if err != nil {
@@ -225,7 +322,10 @@ func (m *manager) ReadFlat(path string) (map[string]string, error) {
 		s := m.v.GetString(k)
 		flatMap[k] = s
 	}
-	for _, k := range compactAddrFields {
+	for _, k := range flatSocketAddressKeys {
+		if !m.v.IsSet(k) {
+			continue
+		}
 		sa := &SocketAddress{}
 		err := unmarshalKey(m.v, k, sa)
 		if err != nil {
@@ -236,6 +336,79 @@ func (m *manager) ReadFlat(path string) (map[string]string, error) {
 	return flatMap, nil
 }
 
+// The keys ReadFlat writes in a compact form, e.g. the seed servers as
+// <address>:<port>, which are parsed back with FromFlat.
+var compactFlatKeys = append(
+	append(
+		append([]string{"redpanda.seed_servers"}, flatListenerKeys...),
+		flatTLSKeys...,
+	),
+	flatSocketAddressKeys...,
+)
+
+func (m *manager) WriteFlat(props map[string]string, path string) error {
+	_, err := m.Read(path)
+	if err != nil {
+		return err
+	}
+	compact := map[string]string{}
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		val := props[k]
+		// ReadFlat outputs unset fields as empty values.
+		if val == "" {
+			continue
+		}
+		if compactFlatKey(k) != "" {
+			compact[k] = val
+			continue
+		}
+		err = m.Set(k, val, "single")
+		if err != nil {
+			return fmt.Errorf("%s: %v", k, err)
+		}
+	}
+	if len(compact) > 0 {
+		// The lists are replaced as a whole, since ReadFlat outputs all
+		// of their elements.
+		conf, err := FromFlat(compact)
+		if err != nil {
+			return err
+		}
+		confMap, err := plainMap(conf)
+		if err != nil {
+			return err
+		}
+		parsed := viper.New()
+		err = parsed.MergeConfigMap(confMap)
+		if err != nil {
+			return err
+		}
+		set := map[string]bool{}
+		for k := range compact {
+			set[compactFlatKey(k)] = true
+		}
+		for k := range set {
+			m.v.Set(k, parsed.Get(k))
+		}
+	}
+	return m.persist(m.v, path)
+}
+
+// Returns the compact key the flattened key belongs to, if any.
+func compactFlatKey(key string) string {
+	for _, k := range compactFlatKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return k
+		}
+	}
+	return ""
+}
+
 func (m *manager) ReadAsJSON(path string) (string, error) {
 	confMap, err := m.readMap(path)
 	if err != nil {
@@ -254,21 +427,25 @@ func (m *manager) Read(path string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	m.v.SetConfigFile(abs)
+	setConfigFile(m.v, abs)
 	err = m.v.ReadInConfig()
 	if err != nil {
 		return nil, err
 	}
-	conf, err := unmarshal(m.v)
+	warnIfWritable(m.fs, abs)
+	conf, err := m.unmarshal()
 	if err != nil {
 		return nil, err
 	}
 	conf.ConfigFile, err = absPath(m.v.ConfigFileUsed())
-	return conf, err
+	if err != nil {
+		return nil, err
+	}
+	return m.ApplyEnvOverrides(conf)
 }
 
 func (m *manager) readMap(path string) (map[string]interface{}, error) {
-	m.v.SetConfigFile(path)
+	setConfigFile(m.v, path)
 	err := m.v.ReadInConfig()
 	if err != nil {
 		return nil, err
@@ -287,34 +464,205 @@ func (m *manager) WriteNodeUUID(conf *Config) error {
 }
 
 func (m *manager) Get() (*Config, error) {
-	return unmarshal(m.v)
+	return m.unmarshal()
+}
+
+func (m *manager) GetKey(key string) (interface{}, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return nil, errors.New("the key can't be empty")
+	}
+	var val interface{} = m.v.AllSettings()
+	path := ""
+	for _, part := range strings.Split(key, ".") {
+		if _, isList := val.([]interface{}); isList {
+			if _, err := strconv.Atoi(part); err != nil {
+				return nil, fmt.Errorf(
+					"%s is a list, so its elements must be indexed,"+
+						" e.g. %s.0.%s",
+					path,
+					path,
+					part,
+				)
+			}
+		}
+		val = child(val, part)
+		path = joinPath(path, part)
+		if val == nil {
+			return nil, fmt.Errorf(
+				"key '%s' not found in %s",
+				path,
+				m.v.ConfigFileUsed(),
+			)
+		}
+	}
+	return dyno.ConvertMapI2MapS(val), nil
 }
 
 // Checks config and writes it to the given path.
 func (m *manager) Write(conf *Config) error {
-	confMap, err := toMap(conf)
+	v, err := m.merge(conf)
 	if err != nil {
 		return err
 	}
-	// Merge the config into a new viper.Viper instance to prevent
-	// concurrent writes to the underlying config map.
-	v := InitViper(m.fs)
-	current, err := unmarshal(m.v)
+	return m.persist(v, conf.ConfigFile)
+}
+
+// Checks config and writes it to the given path, as long as the only fields
+// that change with respect to the current file are the given keys.
+func (m *manager) WriteStrict(conf *Config, keys ...string) error {
+	v, err := m.merge(conf)
 	if err != nil {
 		return err
 	}
-	currentMap, err := toMap(current)
+	return m.persist(v, conf.ConfigFile, func(v *viper.Viper) error {
+		return checkUnintendedChanges(m.fs, v, conf.ConfigFile, keys)
+	})
+}
+
+// Merges the config with the currently-loaded one into a new viper.Viper
+// instance, to prevent concurrent writes to the underlying config map.
+func (m *manager) merge(conf *Config) (*viper.Viper, error) {
+	confMap, err := toMap(conf, m.resolved)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	v := InitViper(m.fs)
+	current, _, err := unmarshal(m.fs, m.v, m.interfaceAddrs)
+	if err != nil {
+		return nil, err
+	}
+	currentMap, err := toMap(current, m.resolved)
+	if err != nil {
+		return nil, err
 	}
 	v.MergeConfigMap(currentMap)
 	v.MergeConfigMap(confMap)
-	return checkAndWrite(m.fs, v, conf.ConfigFile)
+	return v, nil
 }
 
 // Writes the currently loaded config.
 func (m *manager) WriteLoaded() error {
-	return checkAndWrite(m.fs, m.v, m.v.GetString("config_file"))
+	return m.persist(m.v, m.v.GetString("config_file"))
+}
+
+func (m *manager) SetBackup(backup bool) {
+	m.backup = backup
+}
+
+func (m *manager) SetNodeIDValidator(validator NodeIDValidator) {
+	if validator == nil {
+		validator = NoopNodeIDValidator
+	}
+	m.nodeIDValidator = validator
+}
+
+func (m *manager) ApplyEnvOverrides(conf *Config) (*Config, error) {
+	result, overrides, err := applyEnvOverrides(conf)
+	if err != nil {
+		return nil, err
+	}
+	m.resolved.envOverrides = overrides
+	return result, nil
+}
+
+func (m *manager) Redacted(conf *Config) (*Config, error) {
+	return redact(conf, m.resolved)
+}
+
+func (m *manager) Sources(
+	conf *Config, setKeys []string,
+) (map[string]string, error) {
+	return sources(m.fs, conf, m.resolved, setKeys)
+}
+
+func (m *manager) EffectiveYAML(
+	conf *Config, sources map[string]string,
+) ([]byte, error) {
+	return effectiveYAML(conf, m.resolved, sources)
+}
+
+func (m *manager) EffectiveConfigFile(conf *Config) (string, error) {
+	return effectiveConfigFile(m.fs, conf, m.resolved)
+}
+
+func (m *manager) SetInterfaceAddrs(addrs InterfaceAddrs) {
+	if addrs == nil {
+		addrs = SystemInterfaceAddrs
+	}
+	m.interfaceAddrs = addrs
+}
+
+func (m *manager) Lock(path string) (func(), error) {
+	abs, err := absPath(path)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockConfig(m.fs, abs, configLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	release := m.hold(abs)
+	return func() {
+		release()
+		unlock()
+	}, nil
+}
+
+// Records that the manager holds the locks of the given configs, until the
+// returned func is called, so that its writes to them don't lock them again.
+func (m *manager) hold(paths ...string) func() {
+	if m.locked == nil {
+		m.locked = map[string]bool{}
+	}
+	for _, p := range paths {
+		m.locked[p] = true
+	}
+	return func() {
+		for _, p := range paths {
+			delete(m.locked, p)
+		}
+	}
+}
+
+// Locks the config at the given path, unless the manager already holds its
+// lock.
+func (m *manager) lockUnlessHeld(path string) (func(), error) {
+	abs, err := absPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if m.locked[abs] {
+		return func() {}, nil
+	}
+	return lockConfig(m.fs, abs, configLockTimeout)
+}
+
+// Checks and writes the config, and runs rpk.post_write_hook if it's set.
+func (m *manager) persist(
+	v *viper.Viper, path string, checks ...func(*viper.Viper) error,
+) error {
+	err := m.lockAndWrite(v, path, checks...)
+	if err != nil {
+		return err
+	}
+	return runPostWriteHook(m.proc, v, path)
+}
+
+// Checks and writes the config while holding its lock.
+func (m *manager) lockAndWrite(
+	v *viper.Viper, path string, checks ...func(*viper.Viper) error,
+) error {
+	unlock, err := m.lockUnlessHeld(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	err = checkNodeIDChange(m.fs, m.nodeIDValidator, v, path)
+	if err != nil {
+		return err
+	}
+	return checkAndWrite(m.fs, v, path, m.backup, checks...)
 }
 
 func write(fs afero.Fs, v *viper.Viper, path string) error {
@@ -322,7 +670,7 @@ func write(fs afero.Fs, v *viper.Viper, path string) error {
 	if err != nil {
 		return err
 	}
-	err = v.WriteConfigAs(path)
+	err = writeYAML(fs, v, path)
 	if err != nil {
 		return err
 	}
@@ -333,6 +681,30 @@ func write(fs afero.Fs, v *viper.Viper, path string) error {
 	return nil
 }
 
+// Sets the file v reads the config from. The config is always parsed as YAML,
+// since viper would otherwise infer the format from the file's extension, and
+// fail for files such as redpanda.yml or redpanda.conf.
+func setConfigFile(v *viper.Viper, path string) {
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+}
+
+// Writes the config in v to the given path as YAML, keeping the comments in
+// the current file. viper's WriteConfigAs isn't used because it infers the
+// format from the file's extension, and drops the comments.
+// The file is set to rpk.config_file_mode, whether it existed or not.
+func writeYAML(fs afero.Fs, v *viper.Viper, path string) error {
+	mode, err := configFileMode(v)
+	if err != nil {
+		return err
+	}
+	bs, err := renderYAML(fs, path, v.AllSettings())
+	if err != nil {
+		return err
+	}
+	return writeFileWithMode(fs, path, bs, mode)
+}
+
 func (m *manager) setDeduceFormat(key, value string) error {
 	replace := func(key string, newValue interface{}) error {
 		newV := viper.New()
@@ -343,7 +715,11 @@ func (m *manager) setDeduceFormat(key, value string) error {
 	var newVal interface{}
 	switch {
 	case json.Unmarshal([]byte(value), &newVal) == nil: // Try JSON
-		return replace(key, newVal)
+		coerced, err := coerceNumber(key, newVal)
+		if err != nil {
+			return err
+		}
+		return replace(key, coerced)
 
 	case yaml.Unmarshal([]byte(value), &newVal) == nil: // Try YAML
 		return replace(key, newVal)
@@ -358,6 +734,36 @@ func (m *manager) Set(key, value, format string) error {
 	if key == "" {
 		return errors.New("empty config field key")
 	}
+	err := checkManagedKey(m.fs, m.v.ConfigFileUsed(), key)
+	if err != nil {
+		return err
+	}
+	if key == nodeIDKey {
+		err = checkNodeIDValue(m.nodeIDValidator, m.v, value)
+		if err != nil {
+			return err
+		}
+	}
+	if isIndexedKey(key) {
+		if format != "" && strings.ToLower(format) != "single" {
+			return fmt.Errorf(
+				"keys with list indexes, such as %s, can only be set"+
+					" with the single format",
+				key,
+			)
+		}
+		return m.setIndexed(key, value)
+	}
+	if format == "" || strings.ToLower(format) == "single" {
+		val, ok, err := coerce(key, value)
+		if err != nil {
+			return err
+		}
+		if ok {
+			m.v.Set(key, val)
+			return nil
+		}
+	}
 	if format == "" {
 		return m.setDeduceFormat(key, value)
 	}
@@ -376,6 +782,16 @@ func (m *manager) Set(key, value, format string) error {
 		if err != nil {
 			return err
 		}
+	case "toml":
+		// A TOML document is always a table, so it can only set objects.
+		tree, err := toml.Load(value)
+		if err != nil {
+			return fmt.Errorf("couldn't parse the value as TOML: %v", err)
+		}
+		newConfValue, err = normalizeNumbers(tree.ToMap())
+		if err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported format %s", format)
 	}
@@ -384,22 +800,159 @@ func (m *manager) Set(key, value, format string) error {
 	return m.v.MergeConfigMap(newV.AllSettings())
 }
 
+// Round-trips the value through YAML, so that its numbers are decoded as ints,
+// as they are when the config file is read, rather than e.g. the int64s
+// parsed from TOML. viper won't merge a value over an existing one of a
+// different type, and it doesn't report it.
+func normalizeNumbers(val interface{}) (interface{}, error) {
+	bs, err := yaml.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = yaml.Unmarshal(bs, &normalized)
+	return normalized, err
+}
+
+// Whether any of the key's segments is a list index, e.g. the 0 in
+// redpanda.seed_servers.0.host.address.
+func isIndexedKey(key string) bool {
+	for _, part := range strings.Split(key, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Sets a single value at a key which goes through list elements, such as
+// redpanda.seed_servers.0.host.address, the same way ReadFlat flattens them.
+// Only the indexed element is changed, and the rest of the list is kept.
+func (m *manager) setIndexed(key, value string) error {
+	val, ok, err := coerce(key, value)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		val = parse(value)
+	}
+	parts := strings.Split(key, ".")
+	// viper can't set list elements, so the whole list, found at the key's
+	// first index, is replaced with its updated copy.
+	first := 0
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil {
+			first = i
+			break
+		}
+	}
+	if first == 0 {
+		return fmt.Errorf("invalid key %s, which can't start with an index", key)
+	}
+	listKey := strings.Join(parts[:first], ".")
+	list, err := setPath(m.v.Get(listKey), parts[first:], val, listKey)
+	if err != nil {
+		return err
+	}
+	m.v.Set(listKey, list)
+	return nil
+}
+
+// Returns a copy of current with val set at the given path, whose segments are
+// either list indexes or map keys. prefix is the key current is found at.
+func setPath(
+	current interface{}, path []string, val interface{}, prefix string,
+) (interface{}, error) {
+	if len(path) == 0 {
+		return val, nil
+	}
+	part, rest := path[0], path[1:]
+	key := prefix + "." + part
+	if idx, err := strconv.Atoi(part); err == nil {
+		list, ok := current.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"can't set %s, since %s isn't a list",
+				key,
+				prefix,
+			)
+		}
+		if idx < 0 || idx >= len(list) {
+			return nil, fmt.Errorf(
+				"index %d is out of range for %s, which has %d element(s)",
+				idx,
+				prefix,
+				len(list),
+			)
+		}
+		elem, err := setPath(list[idx], rest, val, key)
+		if err != nil {
+			return nil, err
+		}
+		updated := make([]interface{}, len(list))
+		copy(updated, list)
+		updated[idx] = elem
+		return updated, nil
+	}
+	updated := map[string]interface{}{}
+	switch m := current.(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, v := range m {
+			updated[k] = v
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			updated[fmt.Sprint(k)] = v
+		}
+	default:
+		return nil, fmt.Errorf(
+			"can't set %s, since %s isn't an object",
+			key,
+			prefix,
+		)
+	}
+	child, err := setPath(updated[part], rest, val, key)
+	if err != nil {
+		return nil, err
+	}
+	updated[part] = child
+	return updated, nil
+}
+
 func (m *manager) Merge(conf *Config) error {
-	confMap, err := toMap(conf)
+	confMap, err := toMap(conf, m.resolved)
 	if err != nil {
 		return err
 	}
 	return m.v.MergeConfigMap(confMap)
 }
 
-func checkAndWrite(fs afero.Fs, v *viper.Viper, path string) error {
+// The config's lock must be held while it's checked and written. The given
+// checks are run last, on the config as it would be written.
+func checkAndWrite(
+	fs afero.Fs,
+	v *viper.Viper,
+	path string,
+	backup bool,
+	checks ...func(*viper.Viper) error,
+) error {
 	ok, errs := check(v)
 	if !ok {
-		reasons := []string{}
-		for _, err := range errs {
-			reasons = append(reasons, err.Error())
+		return &InvalidConfigError{errs}
+	}
+	// The managed keys only restrict the user's changes, so the rack is set
+	// after they're checked.
+	err := checkManagedChanges(fs, v, path)
+	if err != nil {
+		return err
+	}
+	setRackFromLabel(v)
+	for _, c := range checks {
+		err = c(v)
+		if err != nil {
+			return err
 		}
-		return errors.New(strings.Join(reasons, ", "))
 	}
 	lastBackupFile, err := findBackup(fs, fp.Dir(path))
 	if err != nil {
@@ -413,25 +966,49 @@ func checkAndWrite(fs afero.Fs, v *viper.Viper, path string) error {
 		// If the config doesn't exist, just write it.
 		return write(fs, v, path)
 	}
+	if !backup {
+		log.Debugf("Writing the new redpanda config to '%s' without a backup", path)
+		return write(fs, v, path)
+	}
 	// Otherwise, backup the current config file, write the new one, and
 	// try to recover if there's an error.
 	log.Debug("Backing up the current config")
-	backup, err := utils.BackupFile(fs, path)
+	backupFile, err := backupConfigFile(fs, v, path)
 	if err != nil {
 		return err
 	}
-	log.Debugf("Backed up the current config to %s", backup)
-	if lastBackupFile != "" && lastBackupFile != backup {
-		log.Debug("Removing previous backup file")
-		err = fs.Remove(lastBackupFile)
-		if err != nil {
-			return err
-		}
+	log.Debugf("Backed up the current config to %s", backupFile)
+	err = removePreviousBackups(fs, []string{lastBackupFile}, backupFile)
+	if err != nil {
+		return err
 	}
 	log.Debugf("Writing the new redpanda config to '%s'", path)
 	err = write(fs, v, path)
 	if err != nil {
-		return recover(fs, backup, path, err)
+		return recover(fs, backupFile, path, err)
+	}
+	return nil
+}
+
+// Removes the backups left by the previous writes, since only the last one is
+// kept, except for the ones which were just created.
+func removePreviousBackups(
+	fs afero.Fs, previous []string, current ...string,
+) error {
+	keep := map[string]bool{"": true}
+	for _, c := range current {
+		keep[c] = true
+	}
+	for _, p := range previous {
+		if keep[p] {
+			continue
+		}
+		log.Debugf("Removing previous backup file %s", p)
+		err := fs.Remove(p)
+		if err != nil {
+			return err
+		}
+		keep[p] = true
 	}
 	return nil
 }
@@ -447,25 +1024,54 @@ func recover(fs afero.Fs, backup, path string, err error) error {
 	return fmt.Errorf("couldn't persist the new config due to '%v'", err)
 }
 
-func unmarshal(v *viper.Viper) (*Config, error) {
+// Unmarshals the currently-loaded config, which resets the values it
+// resolved to the ones in it.
+func (m *manager) unmarshal() (*Config, error) {
+	conf, secrets, err := unmarshal(m.fs, m.v, m.interfaceAddrs)
+	if err != nil {
+		return nil, err
+	}
+	m.resolved = resolution{secrets: secrets}
+	return conf, nil
+}
+
+// Unmarshals the config in v, with its interface references resolved with
+// addrs, along with the values read from the secrets file and the
+// interfaces, keyed by their path.
+func unmarshal(
+	fs afero.Fs, v *viper.Viper, addrs InterfaceAddrs,
+) (*Config, map[string]resolvedSecret, error) {
 	result := &Config{}
 	decoderConfig := decoderConfig()
 	decoderConfig.Result = result
 	decoder, err := mapstructure.NewDecoder(&decoderConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	configFile := v.ConfigFileUsed()
+	if configFile == "" {
+		configFile = v.GetString("config_file")
 	}
-	err = decoder.Decode(v.AllSettings())
+	secrets := map[string]resolvedSecret{}
+	settings, err := resolveInterfaceRefs(v.AllSettings(), addrs, secrets)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	settings, err = resolveSecrets(fs, configFile, settings, secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = decoder.Decode(settings)
+	if err != nil {
+		return nil, nil, err
 	}
 	if result.ConfigFile == "" {
 		result.ConfigFile, err = absPath(v.ConfigFileUsed())
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	return result, nil
+	return result, secrets, nil
 }
 
 // Redpanda version < 21.1.4 only supported a single anonymous listener and a
@@ -566,6 +1172,87 @@ func parse(val string) interface{} {
 	return val
 }
 
+// Values accepted for bool fields, which are matched case-insensitively.
+var boolValues = map[string]bool{
+	"true":  true,
+	"1":     true,
+	"yes":   true,
+	"on":    true,
+	"false": false,
+	"0":     false,
+	"no":    false,
+	"off":   false,
+}
+
+// Coerces a single value according to the type of the field the key maps to,
+// so that bool fields accept any of the values in boolValues, and the
+// non-numeric ones (e.g. 'yes') aren't silently turned into bools for other
+// fields. It returns false if the value should be parsed as usual.
+func coerce(key, value string) (interface{}, bool, error) {
+	kind, known := kindOf(key)
+	if !known {
+		return nil, false, nil
+	}
+	b, isBool := boolValues[strings.ToLower(strings.TrimSpace(value))]
+	switch kind {
+	case reflect.Bool:
+		if !isBool {
+			return nil, false, fmt.Errorf(
+				"invalid value '%s' for %s, which must be one of"+
+					" true/false, 1/0, yes/no or on/off",
+				value,
+				key,
+			)
+		}
+		return b, true, nil
+	case reflect.String:
+		if isBool {
+			// Keep e.g. 'on' as is, rather than letting it be parsed
+			// as YAML's true.
+			return value, true, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); isBool && err != nil {
+			return nil, false, fmt.Errorf(
+				"invalid value '%s' for %s, which must be a number",
+				value,
+				key,
+			)
+		}
+	}
+	return nil, false, nil
+}
+
+// Converts a number decoded from JSON, which is always a float64, to an int if
+// the key maps to an integer field. viper won't merge a value over an
+// existing one of a different type, and it doesn't report it.
+func coerceNumber(key string, val interface{}) (interface{}, error) {
+	f, isFloat := val.(float64)
+	if !isFloat {
+		return val, nil
+	}
+	kind, known := kindOf(key)
+	if !known {
+		return val, nil
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf(
+				"invalid value '%v' for %s, which must be an integer",
+				val,
+				key,
+			)
+		}
+		return int(f), nil
+	}
+	return val, nil
+}
+
 func absPath(path string) (string, error) {
 	absPath, err := fp.Abs(path)
 	if err != nil {
@@ -583,7 +1270,7 @@ func createConfigDir(fs afero.Fs, configFile string) error {
 	err := fs.MkdirAll(dir, 0755)
 	if err != nil {
 		return fmt.Errorf(
-			"Couldn't create config dir %s: %v",
+			"Couldn't create config dir %s: %w",
 			dir,
 			err,
 		)
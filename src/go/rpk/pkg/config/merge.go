@@ -0,0 +1,133 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"reflect"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// Overlay is a partial config, e.g. a set of environment-specific overrides,
+// to be merged onto a base config with MergeConfig.
+type Overlay struct {
+	*Config
+	// The keys the overlay sets, which are merged even if they're set to a
+	// zero value. If it's nil, there's no telling whether a zero-valued
+	// field was set, so only the non-zero ones are merged.
+	SetKeys map[string]bool
+}
+
+// ReadOverlay reads an overlay from the given file. Unlike Manager.Read, the
+// fields missing from the file aren't set to their defaults, and the keys
+// present in the file are recorded, so that the fields it explicitly sets to
+// a zero value (e.g. 'tune_cpu: false') still take precedence when merged.
+// The secret placeholders and the file and interface references are kept as
+// they are, so that they're resolved once the merged config is read back.
+func ReadOverlay(fs afero.Fs, path string) (*Overlay, error) {
+	v := viper.New()
+	v.SetFs(fs)
+	setConfigFile(v, path)
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+	conf := &Config{}
+	decoderConfig := decoderConfig()
+	decoderConfig.Result = conf
+	decoder, err := mapstructure.NewDecoder(&decoderConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(v.AllSettings())
+	if err != nil {
+		return nil, err
+	}
+	setKeys := map[string]bool{}
+	for _, k := range v.AllKeys() {
+		setKeys[k] = true
+	}
+	return &Overlay{Config: conf, SetKeys: setKeys}, nil
+}
+
+// MergeConfig returns a new config with the fields set in overlay merged onto
+// base, which are left untouched. Nested objects are merged field by field,
+// while lists, such as the seed servers, are replaced as a whole. The
+// overlay's config_file is never merged.
+func MergeConfig(base *Config, overlay *Overlay) (*Config, error) {
+	baseMap, err := plainMap(base)
+	if err != nil {
+		return nil, err
+	}
+	overlayMap, err := plainMap(overlay.Config)
+	if err != nil {
+		return nil, err
+	}
+	merged := viper.New()
+	err = merged.MergeConfigMap(baseMap)
+	if err != nil {
+		return nil, err
+	}
+	ov := viper.New()
+	err = ov.MergeConfigMap(overlayMap)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range ov.AllKeys() {
+		if k == "config_file" {
+			continue
+		}
+		val := ov.Get(k)
+		set := overlay.SetKeys[k]
+		if overlay.SetKeys == nil {
+			set = !isZeroValue(val)
+		}
+		if set {
+			merged.Set(k, val)
+		}
+	}
+
+	result := &Config{}
+	decoderConfig := decoderConfig()
+	decoderConfig.Result = result
+	decoder, err := mapstructure.NewDecoder(&decoderConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(merged.AllSettings())
+	return result, err
+}
+
+// Returns the config as a map, with the values read from the secrets file and
+// the environment (unlike toMap).
+func plainMap(conf *Config) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	bs, err := yaml.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(bs, &m)
+	return m, err
+}
+
+func isZeroValue(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func mergeBase() *Config {
+	base := Default()
+	base.Redpanda.Id = 1
+	base.Redpanda.SeedServers = []SeedServer{
+		{SocketAddress{"10.0.0.1", 33145}},
+		{SocketAddress{"10.0.0.2", 33145}},
+	}
+	base.Rpk.TuneCpu = true
+	base.Rpk.NodeLabels = map[string]string{"region": "us-east-1"}
+	return base
+}
+
+func TestMergeConfig(t *testing.T) {
+	base := mergeBase()
+	overlay := &Config{
+		Redpanda: RedpandaConfig{
+			Id: 3,
+			SeedServers: []SeedServer{
+				{SocketAddress{"10.0.1.1", 33145}},
+			},
+		},
+		Rpk: RpkConfig{
+			TuneNetwork: true,
+			NodeLabels:  map[string]string{"zone": "us-east-1a"},
+		},
+	}
+	merged, err := MergeConfig(base, &Overlay{Config: overlay})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, merged.Redpanda.Id)
+	// Lists are replaced as a whole.
+	require.Equal(t, overlay.Redpanda.SeedServers, merged.Redpanda.SeedServers)
+	require.True(t, merged.Rpk.TuneNetwork)
+	// The overlay's zero values aren't merged, since they may not be set.
+	require.True(t, merged.Rpk.TuneCpu)
+	require.Equal(t, base.Redpanda.Directory, merged.Redpanda.Directory)
+	require.Equal(t, base.Redpanda.KafkaApi, merged.Redpanda.KafkaApi)
+	require.Equal(t, base.ConfigFile, merged.ConfigFile)
+	// Maps are merged key by key.
+	require.Equal(
+		t,
+		map[string]string{"region": "us-east-1", "zone": "us-east-1a"},
+		merged.Rpk.NodeLabels,
+	)
+
+	// The inputs aren't modified.
+	require.Equal(t, mergeBase(), base)
+	require.Equal(t, 3, overlay.Redpanda.Id)
+	require.Len(t, overlay.Redpanda.SeedServers, 1)
+}
+
+func TestMergeConfigReadOverlay(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/etc/redpanda/overrides.yaml"
+	err := afero.WriteFile(fs, path, []byte(`redpanda:
+  node_id: 0
+rpk:
+  tune_cpu: false
+  tune_disk_irq: true
+`), 0644)
+	require.NoError(t, err)
+	overlay, err := ReadOverlay(fs, path)
+	require.NoError(t, err)
+
+	base := mergeBase()
+	merged, err := MergeConfig(base, overlay)
+	require.NoError(t, err)
+	// The zero values set in the overlay's file take precedence.
+	require.Equal(t, 0, merged.Redpanda.Id)
+	require.False(t, merged.Rpk.TuneCpu)
+	require.True(t, merged.Rpk.TuneDiskIrq)
+	// The rest is kept, rather than replaced with the overlay's zero values.
+	require.Equal(t, base.Redpanda.SeedServers, merged.Redpanda.SeedServers)
+	require.Equal(t, base.Redpanda.Directory, merged.Redpanda.Directory)
+	require.Equal(t, base.Rpk.NodeLabels, merged.Rpk.NodeLabels)
+	require.Equal(t, base.ConfigFile, merged.ConfigFile)
+}
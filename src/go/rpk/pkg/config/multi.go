@@ -0,0 +1,201 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// A config file written by WriteMulti, and its content before it was
+// written, so that it can be restored. If the file didn't exist, existed is
+// false.
+type writtenFile struct {
+	path    string
+	existed bool
+	content []byte
+	mode    os.FileMode
+}
+
+// Writes conf to each of the given paths, e.g. to keep a copy of the config
+// for an external tool. Each file is written the way Write writes one, but
+// the post-write hook only runs once all of them are. If writing any of them
+// fails, the ones written before it are restored to their previous content
+// (or removed, if they didn't exist), so that they don't end up out of sync.
+func (m *manager) WriteMulti(conf *Config, paths []string) error {
+	if len(paths) == 0 {
+		return errors.New("no paths to write the config to were given")
+	}
+	confMap, err := toMap(conf, m.resolved)
+	if err != nil {
+		return err
+	}
+	v := InitViper(m.fs)
+	err = v.MergeConfigMap(confMap)
+	if err != nil {
+		return err
+	}
+	// Nothing is written if the config is invalid.
+	ok, errs := check(v)
+	if !ok {
+		return &InvalidConfigError{errs}
+	}
+	abs := []string{}
+	seen := map[string]bool{}
+	for _, path := range paths {
+		a, err := absPath(path)
+		if err != nil {
+			return err
+		}
+		if !seen[a] {
+			seen[a] = true
+			abs = append(abs, a)
+		}
+	}
+	err = m.lockAndWriteAll(v, abs)
+	if err != nil {
+		return err
+	}
+	for _, path := range abs {
+		err := runPostWriteHook(m.proc, v, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the config to each of the given paths while holding their locks,
+// rolling back the ones already written if any of them fails.
+func (m *manager) lockAndWriteAll(v *viper.Viper, paths []string) error {
+	unheld := []string{}
+	for _, path := range paths {
+		if !m.locked[path] {
+			unheld = append(unheld, path)
+		}
+	}
+	unlock, err := lockConfigs(m.fs, unheld)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	defer m.hold(unheld...)()
+
+	written := []writtenFile{}
+	for _, path := range paths {
+		f, err := readWrittenFile(m.fs, path)
+		if err == nil {
+			err = m.lockAndWrite(v, path)
+		}
+		if err != nil {
+			return rollback(m.fs, written, path, err)
+		}
+		written = append(written, f)
+	}
+	return nil
+}
+
+// Locks each of the given configs, so that they're held for the whole write
+// and a rollback, if it's needed. They're locked in order, so that concurrent
+// writes to overlapping sets of files can't deadlock. The returned func
+// releases all of them.
+func lockConfigs(fs afero.Fs, paths []string) (func(), error) {
+	sorted := []string{}
+	seen := map[string]bool{}
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			sorted = append(sorted, path)
+		}
+	}
+	sort.Strings(sorted)
+	unlocks := []func(){}
+	unlockAll := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for _, path := range sorted {
+		unlock, err := lockConfig(fs, path, configLockTimeout)
+		if err != nil {
+			unlockAll()
+			return nil, err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+	return unlockAll, nil
+}
+
+// Reads the file at the given path as it is before it's written.
+func readWrittenFile(fs afero.Fs, path string) (writtenFile, error) {
+	f := writtenFile{path: path}
+	info, err := fs.Stat(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	f.existed = true
+	f.mode = info.Mode().Perm()
+	f.content, err = afero.ReadFile(fs, path)
+	return f, err
+}
+
+// Restores the files which were written before the write to failedPath
+// failed.
+func rollback(
+	fs afero.Fs, written []writtenFile, failedPath string, err error,
+) error {
+	if len(written) == 0 {
+		return fmt.Errorf("couldn't write the config to %s: %w", failedPath, err)
+	}
+	restored := []string{}
+	unrestored := []string{}
+	for _, f := range written {
+		var recErr error
+		if f.existed {
+			recErr = afero.WriteFile(fs, f.path, f.content, f.mode)
+		} else {
+			recErr = fs.Remove(f.path)
+		}
+		if recErr != nil {
+			unrestored = append(
+				unrestored,
+				fmt.Sprintf("%s (%v)", f.path, recErr),
+			)
+			continue
+		}
+		restored = append(restored, f.path)
+	}
+	if len(unrestored) > 0 {
+		return fmt.Errorf(
+			"couldn't write the config to %s: %w. The new config was"+
+				" already written to these files, which couldn't be"+
+				" restored: %s",
+			failedPath,
+			err,
+			strings.Join(unrestored, ", "),
+		)
+	}
+	return fmt.Errorf(
+		"couldn't write the config to %s: %w. The previous config was"+
+			" restored in: %s",
+		failedPath,
+		err,
+		strings.Join(restored, ", "),
+	)
+}
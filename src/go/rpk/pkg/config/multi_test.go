@@ -0,0 +1,223 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// An afero.Fs which fails to open the given path for writing the first time
+// it's attempted.
+type failingWriteFs struct {
+	afero.Fs
+	path   string
+	failed bool
+}
+
+func (f *failingWriteFs) OpenFile(
+	name string, flag int, perm os.FileMode,
+) (afero.File, error) {
+	if name == f.path && flag&(os.O_WRONLY|os.O_RDWR) != 0 && !f.failed {
+		f.failed = true
+		return nil, &os.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  errors.New("no space left on device"),
+		}
+	}
+	return f.Fs.OpenFile(name, flag, perm)
+}
+
+func TestWriteMulti(t *testing.T) {
+	const (
+		first  = "/etc/redpanda/redpanda.yaml"
+		second = "/opt/tool/redpanda.yaml"
+	)
+	tests := []struct {
+		name     string
+		existing map[string]string
+		failOn   string
+		conf     func() *Config
+		expErr   string
+		check    func(*testing.T, afero.Fs)
+	}{
+		{
+			name:     "it should write the config to all the paths",
+			existing: map[string]string{first: "redpanda:\n  node_id: 0\n"},
+			conf: func() *Config {
+				c := Default()
+				c.Redpanda.Id = 3
+				return c
+			},
+			check: func(st *testing.T, fs afero.Fs) {
+				for _, path := range []string{first, second} {
+					conf, err := NewManager(fs).Read(path)
+					require.NoError(st, err)
+					require.Equal(st, 3, conf.Redpanda.Id)
+				}
+			},
+		},
+		{
+			name: "it should restore the written files if a later write fails",
+			existing: map[string]string{
+				first:  "redpanda:\n  node_id: 0\n",
+				second: "redpanda:\n  node_id: 1\n",
+			},
+			failOn: second,
+			conf: func() *Config {
+				c := Default()
+				c.Redpanda.Id = 3
+				return c
+			},
+			expErr: "couldn't write the config to /opt/tool/redpanda.yaml:" +
+				" couldn't persist the new config due to 'open" +
+				" /opt/tool/redpanda.yaml: no space left on device'." +
+				" The previous config was restored in: /etc/redpanda/redpanda.yaml",
+			check: func(st *testing.T, fs afero.Fs) {
+				bs, err := afero.ReadFile(fs, first)
+				require.NoError(st, err)
+				require.Equal(st, "redpanda:\n  node_id: 0\n", string(bs))
+				bs, err = afero.ReadFile(fs, second)
+				require.NoError(st, err)
+				require.Equal(st, "redpanda:\n  node_id: 1\n", string(bs))
+			},
+		},
+		{
+			name:   "it should remove the written files which didn't exist before",
+			failOn: second,
+			conf:   Default,
+			expErr: "couldn't write the config to /opt/tool/redpanda.yaml:" +
+				" open /opt/tool/redpanda.yaml: no space left on device." +
+				" The previous config was restored in: /etc/redpanda/redpanda.yaml",
+			check: func(st *testing.T, fs afero.Fs) {
+				exists, err := afero.Exists(fs, first)
+				require.NoError(st, err)
+				require.False(st, exists)
+			},
+		},
+		{
+			name: "it shouldn't write anything if the config is invalid",
+			conf: func() *Config {
+				c := Default()
+				c.Redpanda.Directory = ""
+				return c
+			},
+			expErr: "redpanda.data_directory can't be empty",
+			check: func(st *testing.T, fs afero.Fs) {
+				for _, path := range []string{first, second} {
+					exists, err := afero.Exists(fs, path)
+					require.NoError(st, err)
+					require.False(st, exists)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var fs afero.Fs = afero.NewMemMapFs()
+			for path, content := range tt.existing {
+				err := afero.WriteFile(fs, path, []byte(content), 0644)
+				require.NoError(st, err)
+			}
+			if tt.failOn != "" {
+				fs = &failingWriteFs{Fs: fs, path: tt.failOn}
+			}
+			err := NewManager(fs).WriteMulti(tt.conf(), []string{first, second})
+			if tt.expErr != "" {
+				require.EqualError(st, err, tt.expErr)
+			} else {
+				require.NoError(st, err)
+			}
+			tt.check(st, fs)
+		})
+	}
+}
+
+func TestWriteMultiRemovesPreviousBackups(t *testing.T) {
+	const (
+		first  = "/etc/redpanda/redpanda.yaml"
+		second = "/opt/tool/redpanda.yaml"
+	)
+	fs := afero.NewMemMapFs()
+	for _, path := range []string{first, second} {
+		err := afero.WriteFile(fs, path, []byte("redpanda:\n  node_id: 0\n"), 0644)
+		require.NoError(t, err)
+	}
+	staleBackup := first + ".vectorized.stale.bk"
+	err := afero.WriteFile(fs, staleBackup, []byte("redpanda:\n  node_id: 9\n"), 0644)
+	require.NoError(t, err)
+
+	for _, id := range []int{1, 2} {
+		c := Default()
+		c.Redpanda.Id = id
+		err = NewManager(fs).WriteMulti(c, []string{first, second})
+		require.NoError(t, err)
+	}
+
+	exists, err := afero.Exists(fs, staleBackup)
+	require.NoError(t, err)
+	require.False(t, exists)
+	// Only the backup of the last write is kept, in each dir.
+	for _, path := range []string{first, second} {
+		backups, err := afero.Glob(fs, path+".vectorized.*.bk")
+		require.NoError(t, err)
+		require.Len(t, backups, 1, path)
+		conf, err := NewManager(fs).Read(backups[0])
+		require.NoError(t, err)
+		require.Equal(t, 1, conf.Redpanda.Id)
+	}
+}
+
+func TestWriteMultiUsesTheManagerSettings(t *testing.T) {
+	const (
+		first  = "/etc/redpanda/redpanda.yaml"
+		second = "/opt/tool/redpanda.yaml"
+	)
+	fs := afero.NewMemMapFs()
+	for _, path := range []string{first, second} {
+		err := afero.WriteFile(fs, path, []byte("redpanda:\n  node_id: 0\n"), 0644)
+		require.NoError(t, err)
+	}
+	mgr := NewManager(fs)
+	mgr.SetBackup(false)
+	mgr.SetNodeIDValidator(func(id int) error {
+		if id > 2 {
+			return errors.New("it's out of range")
+		}
+		return nil
+	})
+
+	c := Default()
+	c.Redpanda.Id = 3
+	err := mgr.WriteMulti(c, []string{first, second})
+	require.EqualError(
+		t,
+		err,
+		"couldn't write the config to /etc/redpanda/redpanda.yaml:"+
+			" redpanda.node_id 3 can't be used: it's out of range",
+	)
+
+	c.Redpanda.Id = 2
+	err = mgr.WriteMulti(c, []string{first, second})
+	require.NoError(t, err)
+	for _, path := range []string{first, second} {
+		conf, err := NewManager(fs).Read(path)
+		require.NoError(t, err)
+		require.Equal(t, 2, conf.Redpanda.Id)
+		backups, err := afero.Glob(fs, path+".vectorized.*.bk")
+		require.NoError(t, err)
+		require.Empty(t, backups, path)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+const nodeIDKey = "redpanda.node_id"
+
+// NodeIDValidator is called with the node ID whenever redpanda.node_id
+// changes, i.e. when it's set or when a config with a different ID is written.
+// It should return an error if the ID can't be used by this node, e.g.
+// because it's already assigned to another node in the fleet.
+type NodeIDValidator func(id int) error
+
+// NoopNodeIDValidator accepts any node ID. It's the default validator.
+func NoopNodeIDValidator(int) error {
+	return nil
+}
+
+func validateNodeID(validator NodeIDValidator, id int) error {
+	err := validator(id)
+	if err != nil {
+		return fmt.Errorf("redpanda.node_id %d can't be used: %w", id, err)
+	}
+	return nil
+}
+
+// Validates the value given for redpanda.node_id before it's set, unless it's
+// the current one. Values that aren't integers are left for the usual parsing
+// and checks to handle.
+func checkNodeIDValue(
+	validator NodeIDValidator, v *viper.Viper, value string,
+) error {
+	id, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	if v.IsSet(nodeIDKey) && v.GetInt(nodeIDKey) == id {
+		return nil
+	}
+	return validateNodeID(validator, id)
+}
+
+// Validates the node ID in v if it's different from the one in the file at
+// path, or if there's no file yet.
+func checkNodeIDChange(
+	fs afero.Fs, validator NodeIDValidator, v *viper.Viper, path string,
+) error {
+	exists, err := afero.Exists(fs, path)
+	if err != nil {
+		return err
+	}
+	if exists {
+		changed, err := changedFields(fs, v, path)
+		if err != nil {
+			return err
+		}
+		if !isUnderAnyKey(nodeIDKey, changed) {
+			return nil
+		}
+	}
+	return validateNodeID(validator, v.GetInt(nodeIDKey))
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a validator which rejects the given IDs, as fleet tooling would for
+// IDs already assigned to other nodes.
+func rejectIDs(used ...int) NodeIDValidator {
+	return func(id int) error {
+		for _, u := range used {
+			if id == u {
+				return errors.New("it's already assigned to another node")
+			}
+		}
+		return nil
+	}
+}
+
+func TestNodeIDValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		validator   NodeIDValidator
+		value       string
+		expectedErr string
+	}{
+		{
+			name:  "it should allow any ID by default",
+			value: "3",
+		},
+		{
+			name:      "it should allow an ID the validator accepts",
+			validator: rejectIDs(1, 2),
+			value:     "3",
+		},
+		{
+			name:      "it should reject an ID that's already in use",
+			validator: rejectIDs(1, 2),
+			value:     "2",
+			expectedErr: "redpanda.node_id 2 can't be used: it's already" +
+				" assigned to another node",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			mgr := NewManager(fs)
+			mgr.SetNodeIDValidator(tt.validator)
+			_, err := mgr.FindOrGenerate(Default().ConfigFile)
+			require.NoError(st, err)
+
+			err = mgr.Set("redpanda.node_id", tt.value, "")
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				conf, err := mgr.Get()
+				require.NoError(st, err)
+				require.Exactly(st, 0, conf.Redpanda.Id)
+				return
+			}
+			require.NoError(st, err)
+			require.NoError(st, mgr.WriteLoaded())
+			conf, err := mgr.Get()
+			require.NoError(st, err)
+			require.Exactly(st, 3, conf.Redpanda.Id)
+		})
+	}
+}
+
+func TestNodeIDValidatorOnlyRunsOnChange(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := Default()
+	conf.Redpanda.Id = 1
+	require.NoError(t, NewManager(fs).Write(conf))
+
+	// Other nodes report the current ID as in use, e.g. because it's this
+	// node's own, so it shouldn't be validated unless it changes.
+	mgr := NewManager(fs)
+	mgr.SetNodeIDValidator(rejectIDs(1, 2))
+	_, err := mgr.Read(conf.ConfigFile)
+	require.NoError(t, err)
+
+	ok, errs := Check(conf)
+	require.True(t, ok)
+	require.Empty(t, errs)
+
+	require.NoError(t, mgr.Set("redpanda.node_id", "1", ""))
+	require.NoError(t, mgr.Set("redpanda.developer_mode", "false", ""))
+	require.NoError(t, mgr.WriteLoaded())
+
+	conf.Redpanda.DeveloperMode = false
+	require.NoError(t, mgr.Write(conf))
+
+	// Writing a config with a different ID should validate it.
+	conf.Redpanda.Id = 2
+	err = mgr.Write(conf)
+	require.EqualError(
+		t,
+		err,
+		"redpanda.node_id 2 can't be used: it's already assigned to another node",
+	)
+	written, err := NewManager(fs).Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Exactly(t, 1, written.Redpanda.Id)
+
+	conf.Redpanda.Id = 3
+	require.NoError(t, mgr.Write(conf))
+}
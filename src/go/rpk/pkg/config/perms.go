@@ -0,0 +1,58 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	fp "path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// Returns a warning for the config file and for its directory if they can be
+// written by users other than their owner, since anyone who can modify the
+// config can change how redpanda runs.
+func writablePermsWarnings(fs afero.Fs, path string) ([]string, error) {
+	warnings := []string{}
+	for _, p := range []string{path, fp.Dir(path)} {
+		info, err := fs.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		perm := info.Mode().Perm()
+		if perm&0022 == 0 {
+			continue
+		}
+		// Keep the permissions as they are, minus write access for the
+		// group and others.
+		fixed := perm &^ 0022
+		warnings = append(warnings, fmt.Sprintf(
+			"%s has permissions %04o, which allow users other than its"+
+				" owner to modify it. Please run 'chmod %04o %s' to fix it.",
+			p,
+			perm,
+			fixed,
+			p,
+		))
+	}
+	return warnings, nil
+}
+
+func warnIfWritable(fs afero.Fs, path string) {
+	warnings, err := writablePermsWarnings(fs, path)
+	if err != nil {
+		log.Debugf("Couldn't check the permissions of %s: %v", path, err)
+		return
+	}
+	for _, w := range warnings {
+		log.Warn(w)
+	}
+}
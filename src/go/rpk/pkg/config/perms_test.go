@@ -0,0 +1,93 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritablePermsWarnings(t *testing.T) {
+	const (
+		dir  = "/etc/redpanda"
+		path = "/etc/redpanda/redpanda.yaml"
+	)
+	tests := []struct {
+		name     string
+		filePerm os.FileMode
+		dirPerm  os.FileMode
+		expected []string
+	}{
+		{
+			name:     "it shouldn't warn if only the owner can write",
+			filePerm: 0644,
+			dirPerm:  0755,
+			expected: []string{},
+		},
+		{
+			name:     "it should warn if the file is world-writable",
+			filePerm: 0666,
+			dirPerm:  0755,
+			expected: []string{
+				"/etc/redpanda/redpanda.yaml has permissions 0666, which allow" +
+					" users other than its owner to modify it. Please run" +
+					" 'chmod 0644 /etc/redpanda/redpanda.yaml' to fix it.",
+			},
+		},
+		{
+			name:     "it should warn if the directory is group-writable",
+			filePerm: 0640,
+			dirPerm:  0775,
+			expected: []string{
+				"/etc/redpanda has permissions 0775, which allow" +
+					" users other than its owner to modify it. Please run" +
+					" 'chmod 0755 /etc/redpanda' to fix it.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, fs.MkdirAll(dir, tt.dirPerm))
+			require.NoError(t, afero.WriteFile(fs, path, []byte{}, tt.filePerm))
+			// Set the modes explicitly, so they aren't affected by
+			// the umask.
+			require.NoError(t, fs.Chmod(dir, tt.dirPerm))
+			require.NoError(t, fs.Chmod(path, tt.filePerm))
+
+			warnings, err := writablePermsWarnings(fs, path)
+			require.NoError(t, err)
+			require.Exactly(t, tt.expected, warnings)
+		})
+	}
+}
+
+func TestReadWarnsIfWritable(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	mgr := NewManager(fs)
+	conf := getValidConfig()
+	require.NoError(t, mgr.Write(conf))
+	require.NoError(t, fs.Chmod(conf.ConfigFile, 0666))
+
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	_, err := mgr.Read(conf.ConfigFile)
+	require.NoError(t, err)
+	require.Contains(
+		t,
+		out.String(),
+		"/etc/redpanda/redpanda.yaml has permissions 0666",
+	)
+}
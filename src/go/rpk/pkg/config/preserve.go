@@ -0,0 +1,162 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Renders the settings as the YAML to write to path. If the file there has
+// comments, the new values are applied over its nodes, so that its comments
+// and key ordering are kept, and the keys which weren't in it are added after
+// the existing ones. Otherwise, or if the file can't be parsed, the settings
+// are rendered as rpk always has, with the keys sorted.
+func renderYAML(
+	fs afero.Fs, path string, settings map[string]interface{},
+) ([]byte, error) {
+	rendered, err := yaml.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	current, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debugf("Couldn't read %s to keep its comments: %v", path, err)
+		}
+		return rendered, nil
+	}
+	var doc yamlv3.Node
+	err = yamlv3.Unmarshal(current, &doc)
+	if err != nil {
+		log.Debugf("Couldn't parse %s to keep its comments: %v", path, err)
+		return rendered, nil
+	}
+	if doc.Kind != yamlv3.DocumentNode || len(doc.Content) == 0 ||
+		!hasComments(&doc) {
+		return rendered, nil
+	}
+	preserved, err := applyToNodes(&doc, settings)
+	if err != nil {
+		log.Debugf("Couldn't keep the comments in %s: %v", path, err)
+		return rendered, nil
+	}
+	return preserved, nil
+}
+
+func hasComments(node *yamlv3.Node) bool {
+	if node.HeadComment != "" || node.LineComment != "" ||
+		node.FootComment != "" {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasComments(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies the settings to the nodes of the given YAML document, and returns
+// the result.
+func applyToNodes(
+	doc *yamlv3.Node, settings map[string]interface{},
+) ([]byte, error) {
+	var updated yamlv3.Node
+	err := updated.Encode(settings)
+	if err != nil {
+		return nil, err
+	}
+	doc.Content[0] = mergeNodes(doc.Content[0], &updated)
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Returns the node with updated's values, reusing current's nodes where they
+// match so that their comments, order and style are kept. Keys are matched
+// case-insensitively, like viper does, and list elements by their index.
+func mergeNodes(current, updated *yamlv3.Node) *yamlv3.Node {
+	if current.Kind != updated.Kind {
+		copyComments(current, updated)
+		return updated
+	}
+	switch updated.Kind {
+	case yamlv3.MappingNode:
+		values := map[string]*yamlv3.Node{}
+		for i := 0; i+1 < len(updated.Content); i += 2 {
+			values[strings.ToLower(updated.Content[i].Value)] = updated.Content[i+1]
+		}
+		kept := map[string]bool{}
+		content := []*yamlv3.Node{}
+		for i := 0; i+1 < len(current.Content); i += 2 {
+			k, v := current.Content[i], current.Content[i+1]
+			key := strings.ToLower(k.Value)
+			val, ok := values[key]
+			// The keys which were removed are left out.
+			if !ok || kept[key] {
+				continue
+			}
+			kept[key] = true
+			content = append(content, k, mergeNodes(v, val))
+		}
+		// The new keys go after the existing ones.
+		for i := 0; i+1 < len(updated.Content); i += 2 {
+			if !kept[strings.ToLower(updated.Content[i].Value)] {
+				content = append(
+					content,
+					updated.Content[i],
+					updated.Content[i+1],
+				)
+			}
+		}
+		current.Content = content
+		return current
+	case yamlv3.SequenceNode:
+		content := make([]*yamlv3.Node, 0, len(updated.Content))
+		for i, elem := range updated.Content {
+			if i < len(current.Content) {
+				elem = mergeNodes(current.Content[i], elem)
+			}
+			content = append(content, elem)
+		}
+		current.Content = content
+		return current
+	case yamlv3.ScalarNode:
+		if current.Value == updated.Value &&
+			current.ShortTag() == updated.ShortTag() {
+			return current
+		}
+	}
+	copyComments(current, updated)
+	return updated
+}
+
+func copyComments(from, to *yamlv3.Node) {
+	to.HeadComment = from.HeadComment
+	to.LineComment = from.LineComment
+	to.FootComment = from.FootComment
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteKeepsComments(t *testing.T) {
+	const path = "/etc/redpanda/redpanda.yaml"
+	const original = `# Managed by the ops team.
+config_file: /etc/redpanda/redpanda.yaml
+redpanda:
+  # Where the data lives.
+  data_directory: /var/lib/redpanda/data
+  node_id: 1 # Unique per node.
+  rpc_server:
+    address: 0.0.0.0
+    port: 33145
+  kafka_api:
+    - address: 0.0.0.0
+      port: 9092
+rpk:
+  # Tuned by the provisioning scripts.
+  tune_cpu: true
+`
+	fs := afero.NewMemMapFs()
+	err := fs.MkdirAll(filepath.Dir(path), 0755)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, path, []byte(original), 0644)
+	require.NoError(t, err)
+
+	mgr := NewManager(fs)
+	_, err = mgr.Read(path)
+	require.NoError(t, err)
+	err = mgr.Set("redpanda.node_id", "3", "single")
+	require.NoError(t, err)
+	err = mgr.Set("rpk.tune_network", "true", "single")
+	require.NoError(t, err)
+	err = mgr.WriteLoaded()
+	require.NoError(t, err)
+
+	bs, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	content := string(bs)
+	require.Contains(t, content, "# Managed by the ops team.")
+	require.Contains(t, content, "  # Where the data lives.\n")
+	require.Contains(t, content, "node_id: 3 # Unique per node.")
+	require.Contains(t, content, "  # Tuned by the provisioning scripts.\n")
+
+	// The keys are kept in their order, and the new ones come after them.
+	keys := []string{
+		"config_file:",
+		"redpanda:",
+		"data_directory:",
+		"node_id:",
+		"rpc_server:",
+		"kafka_api:",
+		"rpk:",
+		"tune_cpu:",
+		"tune_network:",
+	}
+	last := -1
+	for _, k := range keys {
+		i := strings.Index(content, k)
+		require.Greater(t, i, last, "%s is out of order in\n%s", k, content)
+		last = i
+	}
+
+	conf, err := mgr.Read(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, conf.Redpanda.Id)
+	require.True(t, conf.Rpk.TuneNetwork)
+	require.True(t, conf.Rpk.TuneCpu)
+
+	// The backup is the original file, comments included.
+	backup, err := findBackup(fs, filepath.Dir(path))
+	require.NoError(t, err)
+	backedUp, err := afero.ReadFile(fs, backup)
+	require.NoError(t, err)
+	require.Equal(t, original, string(backedUp))
+}
+
+func TestWriteWithoutCommentsSortsKeys(t *testing.T) {
+	const path = "/etc/redpanda/redpanda.yaml"
+	fs := afero.NewMemMapFs()
+	err := fs.MkdirAll(filepath.Dir(path), 0755)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, path, []byte(`redpanda:
+  rpc_server:
+    address: 0.0.0.0
+    port: 33145
+  node_id: 1
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+  data_directory: /var/lib/redpanda/data
+config_file: /etc/redpanda/redpanda.yaml
+`), 0644)
+	require.NoError(t, err)
+
+	mgr := NewManager(fs)
+	_, err = mgr.Read(path)
+	require.NoError(t, err)
+	err = mgr.Set("redpanda.node_id", "3", "single")
+	require.NoError(t, err)
+	err = mgr.WriteLoaded()
+	require.NoError(t, err)
+
+	// The defaults which aren't in the file are written too.
+	bs, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	require.Equal(t, `config_file: /etc/redpanda/redpanda.yaml
+pandaproxy: {}
+redpanda:
+  admin:
+  - address: 0.0.0.0
+    port: 9644
+  data_directory: /var/lib/redpanda/data
+  developer_mode: true
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+  node_id: 3
+  rpc_server:
+    address: 0.0.0.0
+    port: 33145
+  seed_servers: []
+rpk:
+  coredump_dir: /var/lib/redpanda/coredump
+schema_registry: {}
+`, string(bs))
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import "github.com/spf13/viper"
+
+// If rpk.rack_label is set, sets redpanda.rack to the value of the node label
+// it names, e.g. rpk.node_labels.zone for "zone". This enables rack-aware
+// replica placement without setting redpanda.rack by hand. The label is
+// expected to have been validated by check.
+func setRackFromLabel(v *viper.Viper) {
+	label := v.GetString("rpk.rack_label")
+	if label == "" {
+		return
+	}
+	rack := v.GetString(nodeLabelKey(label))
+	if rack == "" {
+		return
+	}
+	v.Set("redpanda.rack", rack)
+}
+
+func nodeLabelKey(label string) string {
+	return "rpk.node_labels." + label
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import "fmt"
+
+// Repair is a fix for a field which makes the config fail Check, which sets
+// it to its default value.
+type Repair struct {
+	// The field's key, e.g. redpanda.kafka_api.0.port.
+	Key string
+	// What's wrong with the field, as reported by Check.
+	Problem string
+	// The value the field is set to.
+	Value interface{}
+
+	set func(*Config)
+}
+
+// Apply sets the field to the repair's value in conf.
+func (r Repair) Apply(conf *Config) {
+	r.set(conf)
+}
+
+// Repairs returns the fixes for the fields which make the config fail Check,
+// and which can be set to their default value: an empty
+// redpanda.data_directory, and the RPC, Kafka API and seed server ports set
+// to 0. The rest of the issues have to be fixed by hand.
+func Repairs(conf *Config) []Repair {
+	defaults := Default()
+	repairs := []Repair{}
+	if conf.Redpanda.Directory == "" {
+		repairs = append(repairs, Repair{
+			Key:     "redpanda.data_directory",
+			Problem: "redpanda.data_directory can't be empty",
+			Value:   defaults.Redpanda.Directory,
+			set: func(c *Config) {
+				c.Redpanda.Directory = defaults.Redpanda.Directory
+			},
+		})
+	}
+	portRepair := func(key string, port int, set func(*Config)) Repair {
+		return Repair{
+			Key:     key,
+			Problem: fmt.Sprintf("%s can't be 0", key),
+			Value:   port,
+			set:     set,
+		}
+	}
+	rpcPort := defaults.Redpanda.RPCServer.Port
+	if conf.Redpanda.RPCServer.Port == 0 {
+		repairs = append(repairs, portRepair(
+			"redpanda.rpc_server.port",
+			rpcPort,
+			func(c *Config) { c.Redpanda.RPCServer.Port = rpcPort },
+		))
+	}
+	for i, l := range conf.Redpanda.KafkaApi {
+		if l.Port != 0 {
+			continue
+		}
+		i := i
+		repairs = append(repairs, portRepair(
+			fmt.Sprintf("redpanda.kafka_api.%d.port", i),
+			DefaultKafkaPort,
+			func(c *Config) { c.Redpanda.KafkaApi[i].Port = DefaultKafkaPort },
+		))
+	}
+	for i, s := range conf.Redpanda.SeedServers {
+		if s.Host.Port != 0 {
+			continue
+		}
+		i := i
+		repairs = append(repairs, portRepair(
+			fmt.Sprintf("redpanda.seed_servers.%d.host.port", i),
+			rpcPort,
+			func(c *Config) { c.Redpanda.SeedServers[i].Host.Port = rpcPort },
+		))
+	}
+	return repairs
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairs(t *testing.T) {
+	tests := []struct {
+		name         string
+		modify       func(*Config)
+		expectedKeys []string
+		expectValid  bool
+	}{
+		{
+			name:         "it shouldn't return any repairs for a valid config",
+			modify:       func(*Config) {},
+			expectedKeys: []string{},
+			expectValid:  true,
+		},
+		{
+			name: "it should repair an empty data_directory",
+			modify: func(c *Config) {
+				c.Redpanda.Directory = ""
+			},
+			expectedKeys: []string{"redpanda.data_directory"},
+			expectValid:  true,
+		},
+		{
+			name: "it should repair the ports set to 0",
+			modify: func(c *Config) {
+				c.Redpanda.RPCServer.Port = 0
+				c.Redpanda.KafkaApi[0].Port = 0
+				c.Redpanda.SeedServers = []SeedServer{{
+					Host: SocketAddress{Address: "10.0.0.1"},
+				}}
+			},
+			expectedKeys: []string{
+				"redpanda.rpc_server.port",
+				"redpanda.kafka_api.0.port",
+				"redpanda.seed_servers.0.host.port",
+			},
+			expectValid: true,
+		},
+		{
+			name: "it shouldn't repair the issues without a default",
+			modify: func(c *Config) {
+				c.Redpanda.Id = -1
+			},
+			expectedKeys: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			conf := Default()
+			tt.modify(conf)
+			keys := []string{}
+			for _, r := range Repairs(conf) {
+				keys = append(keys, r.Key)
+				r.Apply(conf)
+			}
+			require.Equal(st, tt.expectedKeys, keys)
+			ok, errs := Check(conf)
+			require.Equal(st, tt.expectValid, ok, "%v", errs)
+		})
+	}
+}
@@ -53,6 +53,7 @@ type RedpandaConfig struct {
 	EnableSASL                           *bool                  `yaml:"enable_sasl,omitempty" mapstructure:"enable_sasl,omitempty" json:"enableSasl,omitempty"`
 	GroupTopicPartitions                 *int                   `yaml:"group_topic_partitions,omitempty" mapstructure:"group_topic_partitions,omitempty" json:"groupTopicPartitions,omitempty"`
 	LogSegmentSize                       *int                   `yaml:"log_segment_size,omitempty" mapstructure:"log_segment_size,omitempty" json:"log_segment_size,omitempty"`
+	Rack                                 *string                `yaml:"rack,omitempty" mapstructure:"rack,omitempty" json:"rack,omitempty"`
 	Other                                map[string]interface{} `yaml:",inline" mapstructure:",remain"`
 }
 
@@ -87,8 +88,9 @@ type SocketAddress struct {
 }
 
 type NamedSocketAddress struct {
-	SocketAddress `yaml:",inline" mapstructure:",squash"`
-	Name          string `yaml:"name,omitempty" mapstructure:"name,omitempty" json:"name,omitempty"`
+	SocketAddress        `yaml:",inline" mapstructure:",squash"`
+	Name                 string `yaml:"name,omitempty" mapstructure:"name,omitempty" json:"name,omitempty"`
+	AuthenticationMethod string `yaml:"authentication_method,omitempty" mapstructure:"authentication_method,omitempty" json:"authenticationMethod,omitempty"`
 }
 
 type TLS struct {
@@ -112,27 +114,56 @@ type RpkConfig struct {
 	// Deprecated 2021-07-1
 	SASL *SASL `yaml:"sasl,omitempty" mapstructure:"sasl,omitempty" json:"sasl,omitempty"`
 
-	KafkaApi                 RpkKafkaApi `yaml:"kafka_api,omitempty" mapstructure:"kafka_api,omitempty" json:"kafkaApi"`
-	AdminApi                 RpkAdminApi `yaml:"admin_api,omitempty" mapstructure:"admin_api,omitempty" json:"adminApi"`
-	AdditionalStartFlags     []string    `yaml:"additional_start_flags,omitempty" mapstructure:"additional_start_flags,omitempty" json:"additionalStartFlags"`
-	EnableUsageStats         bool        `yaml:"enable_usage_stats" mapstructure:"enable_usage_stats" json:"enableUsageStats"`
-	TuneNetwork              bool        `yaml:"tune_network" mapstructure:"tune_network" json:"tuneNetwork"`
-	TuneDiskScheduler        bool        `yaml:"tune_disk_scheduler" mapstructure:"tune_disk_scheduler" json:"tuneDiskScheduler"`
-	TuneNomerges             bool        `yaml:"tune_disk_nomerges" mapstructure:"tune_disk_nomerges" json:"tuneNomerges"`
-	TuneDiskWriteCache       bool        `yaml:"tune_disk_write_cache" mapstructure:"tune_disk_write_cache" json:"tuneDiskWriteCache"`
-	TuneDiskIrq              bool        `yaml:"tune_disk_irq" mapstructure:"tune_disk_irq" json:"tuneDiskIrq"`
-	TuneFstrim               bool        `yaml:"tune_fstrim" mapstructure:"tune_fstrim" json:"tuneFstrim"`
-	TuneCpu                  bool        `yaml:"tune_cpu" mapstructure:"tune_cpu" json:"tuneCpu"`
-	TuneAioEvents            bool        `yaml:"tune_aio_events" mapstructure:"tune_aio_events" json:"tuneAioEvents"`
-	TuneClocksource          bool        `yaml:"tune_clocksource" mapstructure:"tune_clocksource" json:"tuneClocksource"`
-	TuneSwappiness           bool        `yaml:"tune_swappiness" mapstructure:"tune_swappiness" json:"tuneSwappiness"`
-	TuneTransparentHugePages bool        `yaml:"tune_transparent_hugepages" mapstructure:"tune_transparent_hugepages" json:"tuneTransparentHugePages"`
-	EnableMemoryLocking      bool        `yaml:"enable_memory_locking" mapstructure:"enable_memory_locking" json:"enableMemoryLocking"`
-	TuneCoredump             bool        `yaml:"tune_coredump" mapstructure:"tune_coredump" json:"tuneCoredump"`
-	CoredumpDir              string      `yaml:"coredump_dir,omitempty" mapstructure:"coredump_dir,omitempty" json:"coredumpDir"`
-	WellKnownIo              string      `yaml:"well_known_io,omitempty" mapstructure:"well_known_io,omitempty" json:"wellKnownIo"`
-	Overprovisioned          bool        `yaml:"overprovisioned" mapstructure:"overprovisioned" json:"overprovisioned"`
-	SMP                      *int        `yaml:"smp,omitempty" mapstructure:"smp,omitempty" json:"smp,omitempty"`
+	KafkaApi                 RpkKafkaApi       `yaml:"kafka_api,omitempty" mapstructure:"kafka_api,omitempty" json:"kafkaApi"`
+	AdminApi                 RpkAdminApi       `yaml:"admin_api,omitempty" mapstructure:"admin_api,omitempty" json:"adminApi"`
+	AdditionalStartFlags     []string          `yaml:"additional_start_flags,omitempty" mapstructure:"additional_start_flags,omitempty" json:"additionalStartFlags"`
+	EnableUsageStats         bool              `yaml:"enable_usage_stats" mapstructure:"enable_usage_stats" json:"enableUsageStats"`
+	TuneNetwork              bool              `yaml:"tune_network" mapstructure:"tune_network" json:"tuneNetwork"`
+	TuneDiskScheduler        bool              `yaml:"tune_disk_scheduler" mapstructure:"tune_disk_scheduler" json:"tuneDiskScheduler"`
+	TuneNomerges             bool              `yaml:"tune_disk_nomerges" mapstructure:"tune_disk_nomerges" json:"tuneNomerges"`
+	TuneDiskWriteCache       bool              `yaml:"tune_disk_write_cache" mapstructure:"tune_disk_write_cache" json:"tuneDiskWriteCache"`
+	TuneDiskIrq              bool              `yaml:"tune_disk_irq" mapstructure:"tune_disk_irq" json:"tuneDiskIrq"`
+	TuneFstrim               bool              `yaml:"tune_fstrim" mapstructure:"tune_fstrim" json:"tuneFstrim"`
+	TuneCpu                  bool              `yaml:"tune_cpu" mapstructure:"tune_cpu" json:"tuneCpu"`
+	TuneAioEvents            bool              `yaml:"tune_aio_events" mapstructure:"tune_aio_events" json:"tuneAioEvents"`
+	TuneClocksource          bool              `yaml:"tune_clocksource" mapstructure:"tune_clocksource" json:"tuneClocksource"`
+	TuneSwappiness           bool              `yaml:"tune_swappiness" mapstructure:"tune_swappiness" json:"tuneSwappiness"`
+	TuneTransparentHugePages bool              `yaml:"tune_transparent_hugepages" mapstructure:"tune_transparent_hugepages" json:"tuneTransparentHugePages"`
+	EnableMemoryLocking      bool              `yaml:"enable_memory_locking" mapstructure:"enable_memory_locking" json:"enableMemoryLocking"`
+	TuneCoredump             bool              `yaml:"tune_coredump" mapstructure:"tune_coredump" json:"tuneCoredump"`
+	CoredumpDir              string            `yaml:"coredump_dir,omitempty" mapstructure:"coredump_dir,omitempty" json:"coredumpDir"`
+	WellKnownIo              string            `yaml:"well_known_io,omitempty" mapstructure:"well_known_io,omitempty" json:"wellKnownIo"`
+	Overprovisioned          bool              `yaml:"overprovisioned" mapstructure:"overprovisioned" json:"overprovisioned"`
+	SMP                      *int              `yaml:"smp,omitempty" mapstructure:"smp,omitempty" json:"smp,omitempty"`
+	TuneSwapfile             bool              `yaml:"tune_swapfile" mapstructure:"tune_swapfile" json:"tuneSwapfile"`
+	SwapfilePath             string            `yaml:"swapfile_path,omitempty" mapstructure:"swapfile_path,omitempty" json:"swapfilePath,omitempty"`
+	SwapfileSize             string            `yaml:"swapfile_size,omitempty" mapstructure:"swapfile_size,omitempty" json:"swapfileSize,omitempty"`
+	NodeLabels               map[string]string `yaml:"node_labels,omitempty" mapstructure:"node_labels,omitempty" json:"nodeLabels,omitempty"`
+	RackLabel                string            `yaml:"rack_label,omitempty" mapstructure:"rack_label,omitempty" json:"rackLabel,omitempty"`
+	ManagedKeys              []string          `yaml:"managed_keys,omitempty" mapstructure:"managed_keys,omitempty" json:"managedKeys,omitempty"`
+	TuneDirtyPages           bool              `yaml:"tune_dirty_pages" mapstructure:"tune_dirty_pages" json:"tuneDirtyPages"`
+	DirtyRatio               *int              `yaml:"dirty_ratio,omitempty" mapstructure:"dirty_ratio,omitempty" json:"dirtyRatio,omitempty"`
+	DirtyBackgroundRatio     *int              `yaml:"dirty_background_ratio,omitempty" mapstructure:"dirty_background_ratio,omitempty" json:"dirtyBackgroundRatio,omitempty"`
+	DirtyBytes               *int              `yaml:"dirty_bytes,omitempty" mapstructure:"dirty_bytes,omitempty" json:"dirtyBytes,omitempty"`
+	DirtyBackgroundBytes     *int              `yaml:"dirty_background_bytes,omitempty" mapstructure:"dirty_background_bytes,omitempty" json:"dirtyBackgroundBytes,omitempty"`
+	PersistDirtyPages        bool              `yaml:"persist_dirty_pages,omitempty" mapstructure:"persist_dirty_pages,omitempty" json:"persistDirtyPages,omitempty"`
+	PersistAioEvents         bool              `yaml:"persist_aio_events,omitempty" mapstructure:"persist_aio_events,omitempty" json:"persistAioEvents,omitempty"`
+	BallastFilePath          string            `yaml:"ballast_file_path,omitempty" mapstructure:"ballast_file_path,omitempty" json:"ballastFilePath,omitempty"`
+	PostWriteHook            string            `yaml:"post_write_hook,omitempty" mapstructure:"post_write_hook,omitempty" json:"postWriteHook,omitempty"`
+	PostWriteHookFatal       bool              `yaml:"post_write_hook_fatal,omitempty" mapstructure:"post_write_hook_fatal,omitempty" json:"postWriteHookFatal,omitempty"`
+	TuneDiskNrRequests       bool              `yaml:"tune_disk_nr_requests" mapstructure:"tune_disk_nr_requests" json:"tuneDiskNrRequests"`
+	DiskNrRequests           *int              `yaml:"disk_nr_requests,omitempty" mapstructure:"disk_nr_requests,omitempty" json:"diskNrRequests,omitempty"`
+	ConfigFileMode           string            `yaml:"config_file_mode,omitempty" mapstructure:"config_file_mode,omitempty" json:"configFileMode,omitempty"`
+	OSMemoryHeadroomPercent  *int              `yaml:"os_memory_headroom_percent,omitempty" mapstructure:"os_memory_headroom_percent,omitempty" json:"osMemoryHeadroomPercent,omitempty"`
+	TuneNicQueues            bool              `yaml:"tune_nic_queues" mapstructure:"tune_nic_queues" json:"tuneNicQueues"`
+	NicQueuesInterface       string            `yaml:"nic_queues_interface,omitempty" mapstructure:"nic_queues_interface,omitempty" json:"nicQueuesInterface,omitempty"`
+	TuneCstates              bool              `yaml:"tune_cstates" mapstructure:"tune_cstates" json:"tuneCstates"`
+	MaxCstate                *int              `yaml:"max_cstate,omitempty" mapstructure:"max_cstate,omitempty" json:"maxCstate,omitempty"`
+	TuneMaxMapCount          bool              `yaml:"tune_max_map_count" mapstructure:"tune_max_map_count" json:"tuneMaxMapCount"`
+	TuneFdLimit              bool              `yaml:"tune_fd_limit" mapstructure:"tune_fd_limit" json:"tuneFdLimit"`
+	MinFdLimit               *int              `yaml:"min_fd_limit,omitempty" mapstructure:"min_fd_limit,omitempty" json:"minFdLimit,omitempty"`
+	TuneMountOptions         bool              `yaml:"tune_mount_options" mapstructure:"tune_mount_options" json:"tuneMountOptions"`
+	RedpandaUser             string            `yaml:"redpanda_user,omitempty" mapstructure:"redpanda_user,omitempty" json:"redpandaUser,omitempty"`
 }
 
 type RpkKafkaApi struct {
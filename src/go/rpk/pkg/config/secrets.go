@@ -0,0 +1,250 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	fp "path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// SecretsFileName is the name of the file holding the values for the
+// ${secret:<key>} placeholders in the config. It's expected to be in the same
+// directory as the config file, and to be readable only by its owner.
+const SecretsFileName = "secrets.yaml"
+
+var secretRegexp = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// A value in the config which had secret placeholders, a file reference or
+// an interface reference, before (raw) and after (value) resolving them.
+type resolvedSecret struct {
+	raw   string
+	value string
+}
+
+// Returns a copy of the config where the resolved values are replaced back
+// with the file's, so that it can be shown or sent elsewhere.
+func redact(conf *Config, resolved resolution) (*Config, error) {
+	confMap, err := toMap(conf, resolved)
+	if err != nil {
+		return nil, err
+	}
+	result := &Config{}
+	decoderConfig := decoderConfig()
+	decoderConfig.Result = result
+	decoder, err := mapstructure.NewDecoder(&decoderConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(confMap)
+	return result, err
+}
+
+// Reads the secrets file next to the given config file. It fails if the file
+// can be accessed by users other than its owner.
+func readSecrets(fs afero.Fs, configFile string) (map[string]string, error) {
+	path := fp.Join(fp.Dir(configFile), SecretsFileName)
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the secrets file: %w", err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return nil, fmt.Errorf(
+			"%s has permissions %04o, but it must only be accessible"+
+				" by its owner (e.g. 0600)",
+			path,
+			perm,
+		)
+	}
+	bs, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	secrets := map[string]string{}
+	err = yaml.Unmarshal(bs, &secrets)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %v", path, err)
+	}
+	return secrets, nil
+}
+
+// Returns a copy of val where the ${secret:<key>} placeholders in the string
+// values are replaced with the corresponding values in the secrets file,
+// which is read only if there's at least one placeholder, and the '@file:'
+// references are replaced with the referenced files' contents. The replaced
+// values are added to resolved, keyed by their path.
+func resolveSecrets(
+	fs afero.Fs,
+	configFile string,
+	val interface{},
+	resolved map[string]resolvedSecret,
+) (interface{}, error) {
+	var secrets map[string]string
+	return replaceStrings(val, func(path, v string) (string, error) {
+		if isFileRef(v) {
+			value, err := resolveFileRef(fs, configFile, v)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", path, err)
+			}
+			resolved[path] = resolvedSecret{raw: v, value: value}
+			return value, nil
+		}
+		if !secretRegexp.MatchString(v) {
+			return v, nil
+		}
+		if secrets == nil {
+			var err error
+			secrets, err = readSecrets(fs, configFile)
+			if err != nil {
+				return "", err
+			}
+		}
+		var missing []string
+		value := secretRegexp.ReplaceAllStringFunc(v, func(m string) string {
+			key := secretRegexp.FindStringSubmatch(m)[1]
+			s, ok := secrets[key]
+			if !ok {
+				missing = append(missing, key)
+			}
+			return s
+		})
+		if len(missing) > 0 {
+			return "", fmt.Errorf(
+				"%s: secret(s) not found in %s: %s",
+				path,
+				SecretsFileName,
+				strings.Join(missing, ", "),
+			)
+		}
+		resolved[path] = resolvedSecret{raw: v, value: value}
+		return value, nil
+	})
+}
+
+// Returns a copy of val where each string value is replaced with the one
+// returned by replace for it and its path.
+func replaceStrings(
+	val interface{}, replace func(path, val string) (string, error),
+) (interface{}, error) {
+	var walk func(string, interface{}) (interface{}, error)
+	walk = func(path string, val interface{}) (interface{}, error) {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			// The keys are walked in order, so that the same error is
+			// reported if there are several.
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			res := make(map[string]interface{}, len(v))
+			for _, k := range keys {
+				r, err := walk(joinPath(path, k), v[k])
+				if err != nil {
+					return nil, err
+				}
+				res[k] = r
+			}
+			return res, nil
+		case map[interface{}]interface{}:
+			keys := make([]interface{}, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Slice(keys, func(i, j int) bool {
+				return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+			})
+			res := make(map[interface{}]interface{}, len(v))
+			for _, k := range keys {
+				r, err := walk(joinPath(path, fmt.Sprint(k)), v[k])
+				if err != nil {
+					return nil, err
+				}
+				res[k] = r
+			}
+			return res, nil
+		case []interface{}:
+			res := make([]interface{}, len(v))
+			for i, e := range v {
+				r, err := walk(joinPath(path, strconv.Itoa(i)), e)
+				if err != nil {
+					return nil, err
+				}
+				res[i] = r
+			}
+			return res, nil
+		case string:
+			return replace(path, v)
+		default:
+			return v, nil
+		}
+	}
+	return walk("", val)
+}
+
+// Replaces the resolved secrets in confMap with their placeholders, as long
+// as they weren't changed after being read.
+func restoreSecrets(
+	confMap map[string]interface{}, resolved map[string]resolvedSecret,
+) {
+	for path, s := range resolved {
+		parts := strings.Split(path, ".")
+		var parent interface{} = confMap
+		for _, p := range parts[:len(parts)-1] {
+			parent = child(parent, p)
+		}
+		last := parts[len(parts)-1]
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if p[last] == s.value {
+				p[last] = s.raw
+			}
+		case map[interface{}]interface{}:
+			if p[last] == s.value {
+				p[last] = s.raw
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(last)
+			if err == nil && i < len(p) && p[i] == s.value {
+				p[i] = s.raw
+			}
+		}
+	}
+}
+
+func child(val interface{}, key string) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return v[key]
+	case map[interface{}]interface{}:
+		return v[key]
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil
+		}
+		return v[i]
+	}
+	return nil
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
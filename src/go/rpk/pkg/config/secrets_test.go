@@ -0,0 +1,147 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const confWithSecrets = `config_file: /etc/redpanda/redpanda.yaml
+redpanda:
+  data_directory: /var/lib/redpanda/data
+  node_id: 1
+  rpc_server:
+    address: 0.0.0.0
+    port: 33145
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+  admin:
+  - address: 0.0.0.0
+    port: 9644
+  seed_servers: []
+rpk:
+  kafka_api:
+    sasl:
+      user: admin
+      password: ${secret:sasl_password}
+      type: SCRAM-SHA-256
+`
+
+func TestReadSecrets(t *testing.T) {
+	const (
+		confPath    = "/etc/redpanda/redpanda.yaml"
+		secretsPath = "/etc/redpanda/secrets.yaml"
+	)
+	tests := []struct {
+		name        string
+		secrets     string
+		secretsPerm os.FileMode
+		noSecrets   bool
+		expected    string
+		expErr      string
+	}{
+		{
+			name:        "it should replace the placeholders with the secrets",
+			secrets:     "sasl_password: s3cr3t\n",
+			secretsPerm: 0600,
+			expected:    "s3cr3t",
+		},
+		{
+			name:        "it should fail if a secret is missing",
+			secrets:     "other_password: s3cr3t\n",
+			secretsPerm: 0600,
+			expErr:      "rpk.kafka_api.sasl.password: secret(s) not found in secrets.yaml: sasl_password",
+		},
+		{
+			name:        "it should fail if the secrets file can be read by others",
+			secrets:     "sasl_password: s3cr3t\n",
+			secretsPerm: 0644,
+			expErr:      "/etc/redpanda/secrets.yaml has permissions 0644, but it must only be accessible by its owner (e.g. 0600)",
+		},
+		{
+			name:      "it should fail if the secrets file doesn't exist",
+			noSecrets: true,
+			expErr:    "couldn't read the secrets file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, confPath, []byte(confWithSecrets), 0644)
+			require.NoError(t, err)
+			if !tt.noSecrets {
+				err = afero.WriteFile(fs, secretsPath, []byte(tt.secrets), tt.secretsPerm)
+				require.NoError(t, err)
+				// Make sure the permissions aren't affected by the umask.
+				err = fs.Chmod(secretsPath, tt.secretsPerm)
+				require.NoError(t, err)
+			}
+			mgr := NewManager(fs)
+			conf, err := mgr.Read(confPath)
+			if tt.expErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, conf.Rpk.KafkaApi.SASL.Password)
+		})
+	}
+}
+
+func TestSecretsAreNotLeaked(t *testing.T) {
+	const (
+		confPath    = "/etc/redpanda/redpanda.yaml"
+		secretsPath = "/etc/redpanda/secrets.yaml"
+		placeholder = "${secret:sasl_password}"
+	)
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, confPath, []byte(confWithSecrets), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, secretsPath, []byte("sasl_password: s3cr3t\n"), 0600)
+	require.NoError(t, err)
+	require.NoError(t, fs.Chmod(secretsPath, 0600))
+
+	mgr := NewManager(fs)
+	conf, err := mgr.Read(confPath)
+	require.NoError(t, err)
+
+	// Redacted configs show the placeholder.
+	redacted, err := mgr.Redacted(conf)
+	require.NoError(t, err)
+	require.Equal(t, placeholder, redacted.Rpk.KafkaApi.SASL.Password)
+
+	// Dumps read the file as is.
+	flat, err := mgr.ReadFlat(confPath)
+	require.NoError(t, err)
+	require.Equal(t, placeholder, flat["rpk.kafka_api.sasl.password"])
+	confJSON, err := mgr.ReadAsJSON(confPath)
+	require.NoError(t, err)
+	require.NotContains(t, confJSON, "s3cr3t")
+
+	// Writing the config keeps the placeholder.
+	conf.Redpanda.Id = 2
+	err = mgr.Write(conf)
+	require.NoError(t, err)
+	bs, err := afero.ReadFile(fs, confPath)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), placeholder)
+	require.NotContains(t, string(bs), "s3cr3t")
+
+	newConf, err := NewManager(fs).Read(confPath)
+	require.NoError(t, err)
+	require.Equal(t, 2, newConf.Redpanda.Id)
+	require.Equal(t, "s3cr3t", newConf.Rpk.KafkaApi.SASL.Password)
+}
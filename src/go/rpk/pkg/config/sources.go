@@ -0,0 +1,158 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Where a config value comes from, as returned by Manager.Sources.
+const (
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceSet     = "set"
+	SourceDefault = "default"
+)
+
+// Returns the flattened paths of the values in the config file at path, or
+// nil if there's no file.
+func readFileKeys(fs afero.Fs, path string) (map[string]bool, error) {
+	if path == "" {
+		return nil, nil
+	}
+	bs, err := afero.ReadFile(fs, path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	flat, err := flattenYAML(bs)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for k := range flat {
+		keys[k] = true
+	}
+	return keys, nil
+}
+
+// Returns where each of the config's values comes from, keyed by its
+// flattened path, e.g. redpanda.kafka_api.0.port: SourceEnv if it's
+// overridden through the environment, SourceSet if it's under any of the
+// given keys, set from the command line, SourceFile if it's in the config's
+// file, or SourceDefault otherwise. The environment overrides take precedence
+// over the rest, as they do when the config is read.
+func sources(
+	fs afero.Fs, conf *Config, resolved resolution, setKeys []string,
+) (map[string]string, error) {
+	flat, err := effectiveFlat(conf, resolved)
+	if err != nil {
+		return nil, err
+	}
+	fileKeys, err := readFileKeys(fs, conf.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	envKeys := []string{}
+	for k := range resolved.envOverrides {
+		envKeys = append(envKeys, k)
+	}
+	sources := map[string]string{}
+	for path := range flat {
+		switch {
+		case isUnderAnyKey(path, envKeys):
+			sources[path] = SourceEnv
+		case isUnderAnyKey(path, setKeys):
+			sources[path] = SourceSet
+		case fileKeys[path]:
+			sources[path] = SourceFile
+		default:
+			sources[path] = SourceDefault
+		}
+	}
+	return sources, nil
+}
+
+// Renders the config with the values overridden through the environment,
+// which are left out when it's written, and the values read from the secrets
+// file replaced back with their placeholders. If sources isn't nil, each
+// value is annotated with its source in a comment.
+func effectiveYAML(
+	conf *Config, resolved resolution, sources map[string]string,
+) ([]byte, error) {
+	bs, err := redactedYAML(conf, resolved)
+	if err != nil || sources == nil {
+		return bs, err
+	}
+	var doc yamlv3.Node
+	err = yamlv3.Unmarshal(bs, &doc)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range doc.Content {
+		annotateSources(n, "", sources)
+	}
+	return yamlv3.Marshal(&doc)
+}
+
+func redactedYAML(conf *Config, resolved resolution) ([]byte, error) {
+	confMap, err := plainMap(conf)
+	if err != nil {
+		return nil, err
+	}
+	restoreSecrets(confMap, resolved.secrets)
+	return yaml.Marshal(confMap)
+}
+
+func effectiveFlat(
+	conf *Config, resolved resolution,
+) (map[string]interface{}, error) {
+	bs, err := redactedYAML(conf, resolved)
+	if err != nil {
+		return nil, err
+	}
+	return flattenYAML(bs)
+}
+
+// Sets the source of each value under node, at the given path, as its line
+// comment. Empty maps and lists are values too, as they are when flattened.
+func annotateSources(node *yamlv3.Node, path string, sources map[string]string) {
+	join := func(k string) string {
+		if path == "" {
+			return k
+		}
+		return path + "." + k
+	}
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		if len(node.Content) == 0 {
+			node.LineComment = sources[path]
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			annotateSources(node.Content[i+1], join(node.Content[i].Value), sources)
+		}
+	case yamlv3.SequenceNode:
+		if len(node.Content) == 0 {
+			node.LineComment = sources[path]
+		}
+		for i, e := range node.Content {
+			annotateSources(e, join(strconv.Itoa(i)), sources)
+		}
+	default:
+		node.LineComment = sources[path]
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// The admin API isn't set, so it's filled with its default.
+const confWithoutAdmin = `config_file: /etc/redpanda/redpanda.yaml
+redpanda:
+  data_directory: /var/lib/redpanda/data
+  node_id: 1
+  rpc_server:
+    address: 0.0.0.0
+    port: 33145
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+  seed_servers: []
+`
+
+func readSourcesTestConfig(t *testing.T) (Manager, *Config) {
+	const path = "/etc/redpanda/redpanda.yaml"
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, path, []byte(confWithoutAdmin), 0644)
+	require.NoError(t, err)
+	mgr := NewManager(fs)
+	conf, err := mgr.Read(path)
+	require.NoError(t, err)
+	return mgr, conf
+}
+
+func TestSources(t *testing.T) {
+	setEnv(t, "REDPANDA_NODE_ID", "7")
+	mgr, conf := readSourcesTestConfig(t)
+
+	sources, err := mgr.Sources(conf, []string{"redpanda.kafka_api.0.port"})
+	require.NoError(t, err)
+	require.Equal(t, SourceEnv, sources["redpanda.node_id"])
+	require.Equal(t, SourceSet, sources["redpanda.kafka_api.0.port"])
+	require.Equal(t, SourceFile, sources["redpanda.kafka_api.0.address"])
+	require.Equal(t, SourceFile, sources["redpanda.data_directory"])
+	require.Equal(t, SourceFile, sources["redpanda.seed_servers"])
+	require.Equal(t, SourceDefault, sources["redpanda.admin.0.address"])
+	require.Equal(t, SourceDefault, sources["redpanda.admin.0.port"])
+}
+
+func TestEffectiveYAMLWithSources(t *testing.T) {
+	setEnv(t, "REDPANDA_NODE_ID", "7")
+	mgr, conf := readSourcesTestConfig(t)
+
+	sources, err := mgr.Sources(conf, nil)
+	require.NoError(t, err)
+	bs, err := mgr.EffectiveYAML(conf, sources)
+	require.NoError(t, err)
+	out := string(bs)
+	require.Contains(t, out, "node_id: 7 # env\n")
+	require.Contains(t, out, "data_directory: /var/lib/redpanda/data # file\n")
+	require.Contains(t, out, "seed_servers: [] # file\n")
+	require.Contains(t, out, "port: 9644 # default\n")
+
+	// Without sources, the effective config is printed as is.
+	bs, err = mgr.EffectiveYAML(conf, nil)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), "node_id: 7\n")
+	require.NotContains(t, string(bs), "#")
+}
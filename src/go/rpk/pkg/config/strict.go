@@ -0,0 +1,135 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// Compares the config that would be written to path with the one currently
+// in it, and returns an error listing the fields that would change other than
+// the given keys or their children. Lists are compared element by element,
+// so reordering them counts as a change.
+func checkUnintendedChanges(
+	fs afero.Fs, v *viper.Viper, path string, keys []string,
+) error {
+	changed, err := changedFields(fs, v, path)
+	if err != nil {
+		return err
+	}
+	unintended := []string{}
+	for _, k := range changed {
+		if !isUnderAnyKey(k, keys) {
+			unintended = append(unintended, k)
+		}
+	}
+	if len(unintended) > 0 {
+		return fmt.Errorf(
+			"refusing to write %s, the following fields would change"+
+				" unexpectedly: %s",
+			path,
+			strings.Join(unintended, ", "),
+		)
+	}
+	return nil
+}
+
+// Returns the sorted flattened paths of the fields which would change if the
+// config in v was written to path. If there's no file at path, it returns an
+// empty list.
+func changedFields(fs afero.Fs, v *viper.Viper, path string) ([]string, error) {
+	exists, err := afero.Exists(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		// There's nothing that could be changed by mistake.
+		return nil, nil
+	}
+	current, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := yaml.Marshal(v.AllSettings())
+	if err != nil {
+		return nil, err
+	}
+	before, err := flattenYAML(current)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %v", path, err)
+	}
+	after, err := flattenYAML(rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for _, c := range diffFlat(before, after) {
+		changed = append(changed, c.Key)
+	}
+	return changed, nil
+}
+
+// Parses the given YAML document and returns a map where the keys are the
+// flattened paths to each value, with list elements keyed by their index.
+// e.g. "redpanda.seed_servers.0.host.port" => 33145
+func flattenYAML(bs []byte) (map[string]interface{}, error) {
+	var doc interface{}
+	err := yaml.Unmarshal(bs, &doc)
+	if err != nil {
+		return nil, err
+	}
+	flat := map[string]interface{}{}
+	flatten("", doc, flat)
+	return flat, nil
+}
+
+func flatten(prefix string, val interface{}, flat map[string]interface{}) {
+	join := func(k interface{}) string {
+		if prefix == "" {
+			return fmt.Sprint(k)
+		}
+		return fmt.Sprintf("%s.%v", prefix, k)
+	}
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		if len(v) == 0 && prefix != "" {
+			flat[prefix] = v
+		}
+		for k, e := range v {
+			flatten(join(k), e, flat)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			flat[prefix] = v
+		}
+		for i, e := range v {
+			flatten(join(i), e, flat)
+		}
+	default:
+		if prefix != "" {
+			flat[prefix] = v
+		}
+	}
+}
+
+func isUnderAnyKey(field string, keys []string) bool {
+	for _, k := range keys {
+		if field == k || strings.HasPrefix(field, k+".") {
+			return true
+		}
+	}
+	return false
+}
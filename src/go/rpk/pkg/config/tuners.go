@@ -0,0 +1,152 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The rpk fields, other than the tune_* ones, which configure the tuners.
+var tunerParamKeys = map[string]bool{
+	"coredump_dir":           true,
+	"well_known_io":          true,
+	"overprovisioned":        true,
+	"smp":                    true,
+	"enable_memory_locking":  true,
+	"swapfile_path":          true,
+	"swapfile_size":          true,
+	"dirty_ratio":            true,
+	"dirty_background_ratio": true,
+	"dirty_bytes":            true,
+	"dirty_background_bytes": true,
+	"persist_dirty_pages":    true,
+	"persist_aio_events":     true,
+	"ballast_file_path":      true,
+	"disk_nr_requests":       true,
+	"nic_queues_interface":   true,
+	"max_cstate":             true,
+	"min_fd_limit":           true,
+}
+
+// The rpk flags which enable each tuner, keyed by the tuner's name. The names
+// match the ones the tuners factory exposes.
+var tunerFlags = map[string]func(*RpkConfig) *bool{
+	"disk_irq":              func(r *RpkConfig) *bool { return &r.TuneDiskIrq },
+	"disk_scheduler":        func(r *RpkConfig) *bool { return &r.TuneDiskScheduler },
+	"disk_nomerges":         func(r *RpkConfig) *bool { return &r.TuneNomerges },
+	"disk_write_cache":      func(r *RpkConfig) *bool { return &r.TuneDiskWriteCache },
+	"fstrim":                func(r *RpkConfig) *bool { return &r.TuneFstrim },
+	"net":                   func(r *RpkConfig) *bool { return &r.TuneNetwork },
+	"cpu":                   func(r *RpkConfig) *bool { return &r.TuneCpu },
+	"aio_events":            func(r *RpkConfig) *bool { return &r.TuneAioEvents },
+	"clocksource":           func(r *RpkConfig) *bool { return &r.TuneClocksource },
+	"swappiness":            func(r *RpkConfig) *bool { return &r.TuneSwappiness },
+	"transparent_hugepages": func(r *RpkConfig) *bool { return &r.TuneTransparentHugePages },
+	"coredump":              func(r *RpkConfig) *bool { return &r.TuneCoredump },
+	"swapfile":              func(r *RpkConfig) *bool { return &r.TuneSwapfile },
+	"dirty_pages":           func(r *RpkConfig) *bool { return &r.TuneDirtyPages },
+	"disk_nr_requests":      func(r *RpkConfig) *bool { return &r.TuneDiskNrRequests },
+	"nic_queues":            func(r *RpkConfig) *bool { return &r.TuneNicQueues },
+	"cstates":               func(r *RpkConfig) *bool { return &r.TuneCstates },
+	"max_map_count":         func(r *RpkConfig) *bool { return &r.TuneMaxMapCount },
+	"fd_limit":              func(r *RpkConfig) *bool { return &r.TuneFdLimit },
+	"mount_options":         func(r *RpkConfig) *bool { return &r.TuneMountOptions },
+}
+
+// The tuner fields which depend on each node's disks layout.
+var nodeSpecificTunerKeys = map[string]bool{
+	"coredump_dir":      true,
+	"swapfile_path":     true,
+	"ballast_file_path": true,
+}
+
+func isTunerKey(key string) bool {
+	return strings.HasPrefix(key, "tune_") || tunerParamKeys[key]
+}
+
+// TunerProfile returns the rpk fields which configure the tuners, keyed by
+// their name, so that they can be shared across nodes.
+func TunerProfile(conf *Config) (map[string]interface{}, error) {
+	rpk, err := rpkMap(conf.Rpk)
+	if err != nil {
+		return nil, err
+	}
+	profile := map[string]interface{}{}
+	for k, v := range rpk {
+		if isTunerKey(k) {
+			profile[k] = v
+		}
+	}
+	return profile, nil
+}
+
+// ApplyTunerProfile replaces the tuner fields in conf with the ones in the
+// given profile, as returned by TunerProfile. Tuner fields missing from the
+// profile are unset, except for the node-specific ones (e.g. coredump_dir)
+// if keepNodeSpecific is true, which are kept as they are.
+func ApplyTunerProfile(
+	conf *Config, profile map[string]interface{}, keepNodeSpecific bool,
+) error {
+	invalid := []string{}
+	for k := range profile {
+		if !isTunerKey(k) {
+			invalid = append(invalid, k)
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf(
+			"the profile contains fields which don't configure the tuners: %s",
+			strings.Join(invalid, ", "),
+		)
+	}
+	rpk, err := rpkMap(conf.Rpk)
+	if err != nil {
+		return err
+	}
+	for k := range rpk {
+		if isTunerKey(k) && !(keepNodeSpecific && nodeSpecificTunerKeys[k]) {
+			delete(rpk, k)
+		}
+	}
+	for k, v := range profile {
+		if keepNodeSpecific && nodeSpecificTunerKeys[k] {
+			if _, exists := rpk[k]; exists {
+				continue
+			}
+		}
+		rpk[k] = v
+	}
+	bs, err := yaml.Marshal(rpk)
+	if err != nil {
+		return err
+	}
+	updated := RpkConfig{}
+	err = yaml.Unmarshal(bs, &updated)
+	if err != nil {
+		return err
+	}
+	conf.Rpk = updated
+	return nil
+}
+
+func rpkMap(rpk RpkConfig) (map[string]interface{}, error) {
+	bs, err := yaml.Marshal(rpk)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	err = yaml.Unmarshal(bs, &m)
+	return m, err
+}
@@ -16,6 +16,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -75,7 +76,13 @@ func collectRedpandaArgs(args *RedpandaArgs) []string {
 		"--redpanda-cfg",
 		args.ConfigFilePath,
 	}
+	redpandaArgs = append(redpandaArgs, SeastarArgs(args.SeastarFlags)...)
+	return append(redpandaArgs, args.ExtraArgs...)
+}
 
+// SeastarArgs returns the command line arguments the given seastar flags are
+// passed to redpanda as, sorted by the flags' names.
+func SeastarArgs(flags map[string]string) []string {
 	singleFlags := []string{"overprovisioned"}
 
 	isSingle := func(f string) bool {
@@ -87,7 +94,14 @@ func collectRedpandaArgs(args *RedpandaArgs) []string {
 		return false
 	}
 
-	for flag, value := range args.SeastarFlags {
+	names := make([]string, 0, len(flags))
+	for flag := range flags {
+		names = append(names, flag)
+	}
+	sort.Strings(names)
+	args := []string{}
+	for _, flag := range names {
+		value := flags[flag]
 		single := isSingle(flag)
 		if single && value != "true" {
 			// If it's a 'single'-type flag and it's set to false,
@@ -95,13 +109,10 @@ func collectRedpandaArgs(args *RedpandaArgs) []string {
 			continue
 		}
 		if single || value == "" {
-			redpandaArgs = append(redpandaArgs, "--"+flag)
+			args = append(args, "--"+flag)
 			continue
 		}
-		redpandaArgs = append(
-			redpandaArgs,
-			fmt.Sprintf("--%s=%s", flag, value),
-		)
+		args = append(args, fmt.Sprintf("--%s=%s", flag, value))
 	}
-	return append(redpandaArgs, args.ExtraArgs...)
+	return args
 }
@@ -102,3 +102,18 @@ func Test_collectRedpandaArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestSeastarArgs(t *testing.T) {
+	got := SeastarArgs(map[string]string{
+		"smp":             "2",
+		"overprovisioned": "false",
+		"cpuset":          "",
+		"memory":          "1G",
+		"lock-memory":     "true",
+	})
+	require.Exactly(
+		t,
+		[]string{"--cpuset", "--lock-memory=true", "--memory=1G", "--smp=2"},
+		got,
+	)
+}
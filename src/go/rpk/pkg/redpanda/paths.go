@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
@@ -26,6 +27,13 @@ var redpandaInstallDirContent = []string{
 	"libexec/redpanda",
 }
 
+// The paths which must be present in an install directory passed explicitly
+// for redpanda to be launched from it.
+var requiredInstallDirContent = []string{
+	"bin/redpanda",
+	"lib",
+}
+
 func GetIOConfigPath(configFileDirectory string) string {
 	return filepath.Join(configFileDirectory, "io-config.yaml")
 }
@@ -48,3 +56,22 @@ func FindInstallDir(fs afero.Fs) (string, error) {
 	log.Debugf("Redpanda is installed in '%s'", installDirCandidate)
 	return installDirCandidate, nil
 }
+
+// CheckInstallDir returns an error listing the paths required to launch
+// redpanda which are missing from the given install directory.
+func CheckInstallDir(fs afero.Fs, installDir string) error {
+	missing := []string{}
+	for _, path := range requiredInstallDirContent {
+		if exists, _ := afero.Exists(fs, filepath.Join(installDir, path)); !exists {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(
+			"'%s' isn't a valid redpanda install directory, it's missing: %s",
+			installDir,
+			strings.Join(missing, ", "),
+		)
+	}
+	return nil
+}
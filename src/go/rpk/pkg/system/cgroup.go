@@ -44,6 +44,58 @@ func ReadCgroupEffectiveCpusNo(fs afero.Fs) (uint64, error) {
 	return calculateEffectiveCpus(cpuList)
 }
 
+// ReadCgroupCpuLimit returns the number of CPUs the CFS quota allows the
+// process to use, which may be fractional, or +Inf if the quota is unlimited.
+func ReadCgroupCpuLimit(fs afero.Fs) (float64, error) {
+	v2CgroupPath, err := v2CgroupPath(fs)
+	if err != nil {
+		return 0, err
+	}
+	if v2CgroupPath != "" {
+		// e.g. '150000 100000', or 'max 100000' if it's unlimited.
+		val, err := readCgroupFile(fs, "", "/cpu.max")
+		if err != nil {
+			return 0, err
+		}
+		fields := strings.Fields(val)
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("invalid cpu.max value '%s'", val)
+		}
+		if fields[0] == "max" {
+			return math.Inf(1), nil
+		}
+		return cpuLimit(fields[0], fields[1])
+	}
+	quota, err := readCgroupFile(fs, "/cpu/cpu.cfs_quota_us", "")
+	if err != nil {
+		return 0, err
+	}
+	quota = strings.TrimSpace(quota)
+	if quota == "-1" {
+		return math.Inf(1), nil
+	}
+	period, err := readCgroupFile(fs, "/cpu/cpu.cfs_period_us", "")
+	if err != nil {
+		return 0, err
+	}
+	return cpuLimit(quota, strings.TrimSpace(period))
+}
+
+func cpuLimit(quota, period string) (float64, error) {
+	q, err := strconv.ParseUint(quota, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse the CPU quota '%s': %v", quota, err)
+	}
+	p, err := strconv.ParseUint(period, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse the CPU period '%s': %v", period, err)
+	}
+	if p == 0 {
+		return 0, errors.New("the CPU period is 0")
+	}
+	return float64(q) / float64(p), nil
+}
+
 func readUintCgroupsProp(
 	fs afero.Fs, v1Subpath, v2Subpath string,
 ) (uint64, error) {
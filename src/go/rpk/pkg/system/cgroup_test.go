@@ -271,3 +271,107 @@ func TestReadCgroupEmptyCgroup(t *testing.T) {
 		assert.EqualError(t, err, "no cgroup data found for the current process")
 	}
 }
+
+func TestReadCgroupCpuLimit(t *testing.T) {
+	type cgroupFile struct {
+		path  string
+		value string
+	}
+	tests := []struct {
+		name        string
+		files       []cgroupFile
+		cgroupsV2   bool
+		expected    float64
+		expectedErr string
+	}{
+		{
+			name: "it should read a limited quota (v1)",
+			files: []cgroupFile{
+				{"/cpu/cpu.cfs_quota_us", "150000"},
+				{"/cpu/cpu.cfs_period_us", "100000"},
+			},
+			expected: 1.5,
+		},
+		{
+			name: "it should read an unlimited quota (v1)",
+			files: []cgroupFile{
+				{"/cpu/cpu.cfs_quota_us", "-1"},
+				{"/cpu/cpu.cfs_period_us", "100000"},
+			},
+			expected: math.Inf(1),
+		},
+		{
+			name: "it should fail if the period is missing (v1)",
+			files: []cgroupFile{
+				{"/cpu/cpu.cfs_quota_us", "150000"},
+			},
+			expectedErr: "/sys/fs/cgroup/cpu/cpu.cfs_period_us: file does not exist",
+		},
+		{
+			name: "it should fail if the quota can't be parsed (v1)",
+			files: []cgroupFile{
+				{"/cpu/cpu.cfs_quota_us", "lots"},
+				{"/cpu/cpu.cfs_period_us", "100000"},
+			},
+			expectedErr: "couldn't parse the CPU quota 'lots': strconv.ParseUint: parsing \"lots\": invalid syntax",
+		},
+		{
+			name: "it should read a limited quota (v2)",
+			files: []cgroupFile{
+				{"/redpanda.slice/redpanda.service/cpu.max", "400000 100000"},
+			},
+			cgroupsV2: true,
+			expected:  4,
+		},
+		{
+			name: "it should read an unlimited quota (v2)",
+			files: []cgroupFile{
+				{"/redpanda.slice/redpanda.service/cpu.max", "max 100000"},
+			},
+			cgroupsV2: true,
+			expected:  math.Inf(1),
+		},
+		{
+			name: "it should read a fractional quota (v2)",
+			files: []cgroupFile{
+				{"/redpanda.slice/cpu.max", "50000 100000"},
+			},
+			cgroupsV2: true,
+			expected:  0.5,
+		},
+		{
+			name: "it should fail if cpu.max is invalid (v2)",
+			files: []cgroupFile{
+				{"/redpanda.slice/redpanda.service/cpu.max", "max"},
+			},
+			cgroupsV2:   true,
+			expectedErr: "invalid cpu.max value 'max'",
+		},
+		{
+			name: "it should fail if the period is 0 (v2)",
+			files: []cgroupFile{
+				{"/redpanda.slice/redpanda.service/cpu.max", "100000 0"},
+			},
+			cgroupsV2:   true,
+			expectedErr: "the CPU period is 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for _, f := range tt.files {
+				err := setUpCgroup(fs, f.path, f.value, tt.cgroupsV2)
+				assert.NoError(t, err)
+			}
+			limit, err := system.ReadCgroupCpuLimit(fs)
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, limit)
+		})
+	}
+}
@@ -10,6 +10,7 @@
 package filesystem
 
 import (
+	"os"
 	"path/filepath"
 	"syscall"
 
@@ -45,3 +46,35 @@ func GetFreeDiskSpaceGB(path string) (float64, error) {
 	}
 	return float64(statFs.Bfree*uint64(statFs.Bsize)) / units.GiB, nil
 }
+
+// GetDeviceID returns the ID of the device containing the given path, which
+// is the same for all the paths in a filesystem.
+func GetDeviceID(path string) (uint64, error) {
+	stat := syscall.Stat_t{}
+	err := syscall.Stat(path, &stat)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(stat.Dev), nil
+}
+
+// Ownership is the owner, group and permissions of a file or directory.
+type Ownership struct {
+	Uid  uint32
+	Gid  uint32
+	Mode os.FileMode
+}
+
+// GetOwnership returns the owner, group and permissions of the given path.
+func GetOwnership(path string) (Ownership, error) {
+	stat := syscall.Stat_t{}
+	err := syscall.Stat(path, &stat)
+	if err != nil {
+		return Ownership{}, err
+	}
+	return Ownership{
+		Uid:  stat.Uid,
+		Gid:  stat.Gid,
+		Mode: os.FileMode(stat.Mode) & os.ModePerm,
+	}, nil
+}
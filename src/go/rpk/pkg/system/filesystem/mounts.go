@@ -0,0 +1,140 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	mountsFile = "/proc/self/mounts"
+	fstabFile  = "/etc/fstab"
+)
+
+// The filesystem types backed by storage on another host.
+var networkFsTypes = map[string]bool{
+	"nfs":   true,
+	"nfs4":  true,
+	"cifs":  true,
+	"smbfs": true,
+	"smb3":  true,
+}
+
+// Mount points with special characters are written with octal escapes in
+// /proc/self/mounts.
+var mountPointUnescaper = strings.NewReplacer(
+	`\040`, " ",
+	`\011`, "\t",
+	`\012`, "\n",
+	`\134`, `\`,
+)
+
+var mountFieldEscaper = strings.NewReplacer(
+	`\`, `\134`,
+	" ", `\040`,
+	"\t", `\011`,
+	"\n", `\012`,
+)
+
+// Mount is an entry in /proc/self/mounts.
+type Mount struct {
+	Device     string
+	MountPoint string
+	FsType     string
+	// The mount options, e.g. "rw" and "noatime".
+	Options []string
+}
+
+// GetMount returns the entry of the closest mount point containing path, as
+// listed in /proc/self/mounts.
+func GetMount(fs afero.Fs, path string) (*Mount, error) {
+	return closestMount(fs, mountsFile, path)
+}
+
+// GetFstabEntry returns the entry of the closest mount point containing path,
+// as configured in /etc/fstab, whether it's mounted or not.
+func GetFstabEntry(fs afero.Fs, path string) (*Mount, error) {
+	return closestMount(fs, fstabFile, path)
+}
+
+// Returns the entry of the closest mount point containing path in the given
+// file, which has the format of /proc/self/mounts and /etc/fstab.
+func closestMount(fs afero.Fs, file, path string) (*Mount, error) {
+	bs, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	path = filepath.Clean(path)
+	var mount *Mount
+	for _, line := range strings.Split(string(bs), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		mp := mountPointUnescaper.Replace(fields[1])
+		if !isUnder(path, mp) {
+			continue
+		}
+		// Later mounts on the same mount point shadow the previous ones.
+		if mount == nil || len(mp) >= len(mount.MountPoint) {
+			mount = &Mount{
+				Device:     mountPointUnescaper.Replace(fields[0]),
+				MountPoint: mp,
+				FsType:     fields[2],
+			}
+			if len(fields) > 3 {
+				mount.Options = strings.Split(fields[3], ",")
+			}
+		}
+	}
+	if mount == nil {
+		return nil, fmt.Errorf(
+			"couldn't find the mount point of %s in %s",
+			path,
+			file,
+		)
+	}
+	return mount, nil
+}
+
+// GetMountFsType returns the type of the filesystem mounted at the closest
+// mount point containing path, as listed in /proc/self/mounts (e.g. "xfs",
+// "nfs4" or "fuse.sshfs").
+func GetMountFsType(fs afero.Fs, path string) (string, error) {
+	mount, err := GetMount(fs, path)
+	if err != nil {
+		return "", err
+	}
+	return mount.FsType, nil
+}
+
+// EscapeMountField escapes the characters which can't appear in a field of
+// /proc/self/mounts or /etc/fstab, such as the spaces in a mount point, with
+// their octal escapes.
+func EscapeMountField(field string) string {
+	return mountFieldEscaper.Replace(field)
+}
+
+// IsNetworkFs returns true if the given filesystem type, as listed in
+// /proc/self/mounts, is a network or FUSE filesystem.
+func IsNetworkFs(fsType string) bool {
+	return networkFsTypes[fsType] || strings.HasPrefix(fsType, "fuse.")
+}
+
+func isUnder(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package filesystem
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMountFsType(t *testing.T) {
+	const mounts = `/dev/nvme0n1p1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/nvme1n1 /var/lib/redpanda xfs rw,noatime 0 0
+10.0.0.5:/exports/data /var/lib/redpanda/nfs nfs4 rw,relatime 0 0
+sshfs#host:/data /mnt/my\040data fuse.sshfs rw,nosuid,nodev 0 0
+`
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "it should return the type of the closest mount point",
+			path:     "/var/lib/redpanda/data",
+			expected: "xfs",
+		},
+		{
+			name:     "it should return the type of a nested mount point",
+			path:     "/var/lib/redpanda/nfs/data/",
+			expected: "nfs4",
+		},
+		{
+			name:     "it shouldn't match mount points by string prefix",
+			path:     "/var/lib/redpanda-other",
+			expected: "ext4",
+		},
+		{
+			name:     "it should unescape the mount points",
+			path:     "/mnt/my data/redpanda",
+			expected: "fuse.sshfs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, mountsFile, []byte(mounts), 0644)
+			require.NoError(st, err)
+			fsType, err := GetMountFsType(fs, tt.path)
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, fsType)
+		})
+	}
+}
+
+func TestGetMount(t *testing.T) {
+	const mounts = `/dev/nvme0n1p1 / ext4 rw,relatime 0 0
+/dev/nvme1n1 /var/lib/redpanda xfs rw,noatime 0 0
+`
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, mountsFile, []byte(mounts), 0644)
+	require.NoError(t, err)
+	mount, err := GetMount(fs, "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.Equal(t, &Mount{
+		Device:     "/dev/nvme1n1",
+		MountPoint: "/var/lib/redpanda",
+		FsType:     "xfs",
+		Options:    []string{"rw", "noatime"},
+	}, mount)
+}
+
+func TestEscapeMountField(t *testing.T) {
+	require.Equal(t, "/dev/nvme1n1", EscapeMountField("/dev/nvme1n1"))
+	require.Equal(t, `/mnt/my\040data`, EscapeMountField("/mnt/my data"))
+	require.Equal(t, `/mnt/a\134b\011c`, EscapeMountField("/mnt/a\\b\tc"))
+}
+
+func TestGetFstabEntry(t *testing.T) {
+	const fstab = `# /etc/fstab: static file system information.
+UUID=2f9c1a7e / ext4 defaults 0 1
+/dev/nvme1n1 /var/lib/redpanda xfs defaults,noatime 0 0
+/swapfile none swap sw 0 0
+`
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, fstabFile, []byte(fstab), 0644)
+	require.NoError(t, err)
+	entry, err := GetFstabEntry(fs, "/var/lib/redpanda/data")
+	require.NoError(t, err)
+	require.Equal(t, &Mount{
+		Device:     "/dev/nvme1n1",
+		MountPoint: "/var/lib/redpanda",
+		FsType:     "xfs",
+		Options:    []string{"defaults", "noatime"},
+	}, entry)
+}
@@ -12,6 +12,7 @@ package system
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -27,6 +28,9 @@ const reachMask int64 = 1
 
 type NtpQuery interface {
 	IsNtpSynced() (bool, error)
+	// Returns the offset of the local clock with respect to the NTP time.
+	// It's positive if the local clock is ahead.
+	NtpOffset() (time.Duration, error)
 }
 
 func NewNtpQuery(timeout time.Duration, fs afero.Fs) NtpQuery {
@@ -143,3 +147,80 @@ func checkNtpqOutput(output []string) (bool, error) {
 	}
 	return synced, nil
 }
+
+func (q *ntpQuery) NtpOffset() (time.Duration, error) {
+	offset, err := q.offsetWithChronyc()
+	if err == nil {
+		return offset, nil
+	}
+	log.Debug(err)
+	offset, err = q.offsetWithNtpq()
+	if err == nil {
+		return offset, nil
+	}
+	log.Debug(err)
+	return 0, errors.New("couldn't get the NTP offset with chronyc or ntpq")
+}
+
+func (q *ntpQuery) offsetWithChronyc() (time.Duration, error) {
+	log.Debugf("Getting the NTP offset with chronyc")
+	output, err := q.proc.RunWithSystemLdPath(q.timeout, "chronyc", "tracking")
+	if err != nil {
+		return 0, err
+	}
+	return chronycTrackingOffset(output)
+}
+
+func (q *ntpQuery) offsetWithNtpq() (time.Duration, error) {
+	log.Debugf("Getting the NTP offset with ntpq")
+	output, err := q.proc.RunWithSystemLdPath(q.timeout, "ntpq", "-p")
+	if err != nil {
+		return 0, err
+	}
+	return ntpqOffset(output)
+}
+
+func chronycTrackingOffset(output []string) (time.Duration, error) {
+	// Example line from chronyc tracking:
+	// System time     : 0.000012345 seconds slow of NTP time
+	systemTimePattern := regexp.MustCompile(
+		`^System time\s*:\s*([0-9.]+) seconds (fast|slow) of NTP time`,
+	)
+	for _, line := range output {
+		matches := systemTimePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		secs, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			return 0, err
+		}
+		offset := time.Duration(math.Round(secs * float64(time.Second)))
+		if matches[2] == "slow" {
+			offset = -offset
+		}
+		return offset, nil
+	}
+	return 0, errors.New("NTP offset not found in chronyc output")
+}
+
+func ntpqOffset(output []string) (time.Duration, error) {
+	// The offset (in ms) is taken from the system peer, marked with '*' (see
+	// checkNtpqOutput for an example output). ntpq reports it as the peer's
+	// clock minus the local one, so its sign is flipped.
+	for _, line := range output {
+		if !strings.HasPrefix(line, "*") {
+			continue
+		}
+		columns := strings.Fields(line)
+		if len(columns) != 10 {
+			continue
+		}
+		ms, err := strconv.ParseFloat(columns[8], 64)
+		if err != nil {
+			return 0, err
+		}
+		return -time.Duration(math.Round(ms * float64(time.Millisecond))), nil
+	}
+	return 0, errors.New("no system peer found in ntpq output")
+}
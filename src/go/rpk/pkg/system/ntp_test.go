@@ -157,3 +157,86 @@ func TestCheckNtpqOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestChronycTrackingOffset(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   []string
+		expected time.Duration
+		expErr   bool
+	}{
+		{
+			name: "should return a negative offset if the clock is slow",
+			output: []string{
+				"Reference ID    : A9FEA97B (169.254.169.123)",
+				"Stratum         : 4",
+				"System time     : 0.000250000 seconds slow of NTP time",
+				"Last offset     : -0.000003164 seconds",
+			},
+			expected: -250 * time.Microsecond,
+		},
+		{
+			name: "should return a positive offset if the clock is fast",
+			output: []string{
+				"System time     : 1.500000000 seconds fast of NTP time",
+			},
+			expected: 1500 * time.Millisecond,
+		},
+		{
+			name:   "should fail if the offset isn't in the output",
+			output: []string{"506 Cannot talk to daemon"},
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, err := chronycTrackingOffset(tt.output)
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, offset)
+		})
+	}
+}
+
+func TestNtpqOffset(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   []string
+		expected time.Duration
+		expErr   bool
+	}{
+		{
+			name: "should return the system peer's offset",
+			output: []string{
+				"     remote           refid      st t when poll reach   delay   offset  jitter",
+				"==============================================================================",
+				"+metadata2.google 71.79.79.72     2 u  236 1024  377    0.738   -9.000   4.483",
+				"*metadata.google 71.79.79.71      2 u  236 1024  377    0.738   -0.500   4.483",
+			},
+			expected: 500 * time.Microsecond,
+		},
+		{
+			name: "should fail if there's no system peer",
+			output: []string{
+				"     remote           refid      st t when poll reach   delay   offset  jitter",
+				"==============================================================================",
+				" metadata.google 71.79.79.71      2 u  236 1024  377    0.738   -0.500   4.483",
+			},
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, err := ntpqOffset(tt.output)
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, offset)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+// The bit of CAP_SYS_ADMIN in the capability sets, see capabilities(7).
+const capSysAdmin = 21
+
+// IsPrivileged returns whether the process runs as root or has CAP_SYS_ADMIN
+// in its effective capabilities, which the tuners need to change the system's
+// settings.
+func IsPrivileged(fs afero.Fs) (bool, error) {
+	if os.Geteuid() == 0 {
+		return true, nil
+	}
+	return hasEffectiveCap(fs, capSysAdmin)
+}
+
+// Reads the effective capabilities from /proc/self/status. Systems without a
+// procfs (e.g. macOS) don't have capabilities, so it returns false there.
+func hasEffectiveCap(fs afero.Fs, capability uint) (bool, error) {
+	lines, err := utils.ReadFileLines(fs, "/proc/self/status")
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "CapEff:") {
+			continue
+		}
+		hex := strings.TrimSpace(strings.TrimPrefix(l, "CapEff:"))
+		caps, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil {
+			return false, fmt.Errorf("couldn't parse the capabilities '%s': %v", hex, err)
+		}
+		return caps&(1<<capability) != 0, nil
+	}
+	return false, nil
+}
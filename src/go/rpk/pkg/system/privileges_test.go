@@ -0,0 +1,70 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package system
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasEffectiveCap(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		expected bool
+		expErr   bool
+	}{
+		{
+			name:     "it should return true if CAP_SYS_ADMIN is set",
+			status:   "Name:\trpk\nCapInh:\t0000000000000000\nCapEff:\t0000000000200000\n",
+			expected: true,
+		},
+		{
+			name:     "it should return true for a full capability set",
+			status:   "CapEff:\t000001ffffffffff\n",
+			expected: true,
+		},
+		{
+			name:   "it should return false if CAP_SYS_ADMIN isn't set",
+			status: "CapEff:\t0000000000001000\n",
+		},
+		{
+			name:   "it should return false if the capabilities aren't listed",
+			status: "Name:\trpk\n",
+		},
+		{
+			name:   "it should fail if the capabilities can't be parsed",
+			status: "CapEff:\tzzz\n",
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, "/proc/self/status", []byte(tt.status), 0444)
+			require.NoError(st, err)
+			res, err := hasEffectiveCap(fs, capSysAdmin)
+			if tt.expErr {
+				require.Error(st, err)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, res)
+		})
+	}
+}
+
+func TestHasEffectiveCapWithoutProcfs(t *testing.T) {
+	res, err := hasEffectiveCap(afero.NewMemMapFs(), capSysAdmin)
+	require.NoError(t, err)
+	require.False(t, res)
+}
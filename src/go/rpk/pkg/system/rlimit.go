@@ -0,0 +1,40 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package system
+
+import "golang.org/x/sys/unix"
+
+// FdLimit is a process' limit on the number of file descriptors it can
+// have open (RLIMIT_NOFILE).
+type FdLimit struct {
+	Soft uint64
+	Hard uint64
+}
+
+// GetFdLimit returns the file descriptor limit of the current process, which
+// the processes it starts inherit (e.g. redpanda, which 'rpk redpanda start'
+// replaces itself with).
+func GetFdLimit() (FdLimit, error) {
+	var rl unix.Rlimit
+	err := unix.Getrlimit(unix.RLIMIT_NOFILE, &rl)
+	if err != nil {
+		return FdLimit{}, err
+	}
+	return FdLimit{Soft: uint64(rl.Cur), Hard: uint64(rl.Max)}, nil
+}
+
+// SetFdLimit sets the file descriptor limit of the current process. Only
+// privileged processes can raise the hard limit.
+func SetFdLimit(limit FdLimit) error {
+	return unix.Setrlimit(
+		unix.RLIMIT_NOFILE,
+		&unix.Rlimit{Cur: limit.Soft, Max: limit.Hard},
+	)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package system
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// UserIDs are the IDs the permissions of a user's processes are checked
+// against.
+type UserIDs struct {
+	Uid uint32
+	// The user's primary group, followed by its supplementary ones.
+	Gids []uint32
+}
+
+// LookupUser returns the IDs of the user with the given name. It returns a
+// user.UnknownUserError if there's no such user.
+func LookupUser(name string) (UserIDs, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return UserIDs{}, err
+	}
+	uid, err := parseID(u.Uid)
+	if err != nil {
+		return UserIDs{}, err
+	}
+	gids, err := u.GroupIds()
+	if err != nil {
+		return UserIDs{}, fmt.Errorf("couldn't get the groups of %s: %v", name, err)
+	}
+	ids := UserIDs{Uid: uid}
+	// GroupIds includes the primary group, but it's added first regardless.
+	for _, g := range append([]string{u.Gid}, gids...) {
+		gid, err := parseID(g)
+		if err != nil {
+			return UserIDs{}, err
+		}
+		ids.Gids = append(ids.Gids, gid)
+	}
+	return ids, nil
+}
+
+func parseID(id string) (uint32, error) {
+	n, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse the ID '%s': %v", id, err)
+	}
+	return uint32(n), nil
+}
@@ -11,27 +11,58 @@ package tuners
 
 import (
 	"fmt"
+	"os"
+	"runtime"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 )
 
-const maxAIOEvents = 1048576
-const maxAIOEventsFile = "/proc/sys/fs/aio-max-nr"
+const (
+	maxAIOEvents     = 1048576
+	maxAIOEventsFile = "/proc/sys/fs/aio-max-nr"
 
-func NewMaxAIOEventsChecker(fs afero.Fs) Checker {
+	// The AIO events each shard reserves: 1024 for the storage IO and
+	// 10000 for the networking IO.
+	aioEventsPerShard = 11024
+
+	// The file where fs.aio-max-nr is persisted, so that it's applied on
+	// boot.
+	AIOEventsSysctlFile = "/etc/sysctl.d/90-redpanda-aio-max-nr.conf"
+)
+
+// MaxAIOEventsTarget returns the value fs.aio-max-nr should be raised to:
+// enough for all the shards redpanda will run (rpk.smp, or one per CPU), but
+// no less than 1048576.
+func MaxAIOEventsTarget(conf config.RpkConfig) int {
+	shards := runtime.NumCPU()
+	if conf.SMP != nil && *conf.SMP > 0 {
+		shards = *conf.SMP
+	}
+	if target := shards * aioEventsPerShard; target > maxAIOEvents {
+		return target
+	}
+	return maxAIOEvents
+}
+
+func aioEventsSysctlConf(target int) string {
+	return fmt.Sprintf("# Generated by rpk\nfs.aio-max-nr = %d\n", target)
+}
+
+func NewMaxAIOEventsChecker(fs afero.Fs, target int) Checker {
 	return NewIntChecker(
 		MaxAIOEvents,
 		"Max AIO Events",
 		Warning,
 		func(current int) bool {
-			return current >= maxAIOEvents
+			return current >= target
 		},
 		func() string {
-			return fmt.Sprintf(">= %d", maxAIOEvents)
+			return fmt.Sprintf(">= %d", target)
 		},
 		func() (int, error) {
 			return utils.ReadIntFromFile(fs, maxAIOEventsFile)
@@ -39,26 +70,70 @@ func NewMaxAIOEventsChecker(fs afero.Fs) Checker {
 	)
 }
 
-func NewMaxAIOEventsTuner(fs afero.Fs, executor executors.Executor) Tunable {
-	return NewCheckedTunable(
-		NewMaxAIOEventsChecker(fs),
-		func() TuneResult {
-			log.Debugf("Setting max AIO events to %d", maxAIOEvents)
-			err := executor.Execute(
-				commands.NewWriteFileCmd(
-					fs,
-					maxAIOEventsFile,
-					fmt.Sprint(maxAIOEvents),
-				),
-			)
-			if err != nil {
-				return NewTuneError(err)
+func newMaxAIOEventsPersistedChecker(fs afero.Fs, content string) Checker {
+	return NewEqualityChecker(
+		MaxAIOEvents,
+		fmt.Sprintf("fs.aio-max-nr persisted in %s", AIOEventsSysctlFile),
+		Warning,
+		content,
+		func() (interface{}, error) {
+			current, err := afero.ReadFile(fs, AIOEventsSysctlFile)
+			if os.IsNotExist(err) {
+				return "", nil
 			}
-			return NewTuneResult(false)
+			return string(current), err
 		},
-		func() (bool, string) {
-			return true, ""
-		},
-		executor.IsLazy(),
 	)
 }
+
+// NewMaxAIOEventsTuner raises fs.aio-max-nr to MaxAIOEventsTarget if it's
+// below it. If rpk.persist_aio_events is set, the value is also written to
+// AIOEventsSysctlFile.
+func NewMaxAIOEventsTuner(
+	fs afero.Fs, conf config.RpkConfig, executor executors.Executor,
+) Tunable {
+	target := MaxAIOEventsTarget(conf)
+	supported := func() (bool, string) {
+		return true, ""
+	}
+	tunables := []Tunable{
+		NewCheckedTunable(
+			NewMaxAIOEventsChecker(fs, target),
+			func() TuneResult {
+				log.Debugf("Setting max AIO events to %d", target)
+				err := executor.Execute(
+					commands.NewWriteFileCmd(
+						fs,
+						maxAIOEventsFile,
+						fmt.Sprint(target),
+					),
+				)
+				if err != nil {
+					return NewTuneError(err)
+				}
+				return NewTuneResult(false)
+			},
+			supported,
+			executor.IsLazy(),
+		),
+	}
+	if conf.PersistAioEvents {
+		content := aioEventsSysctlConf(target)
+		tunables = append(tunables, NewCheckedTunable(
+			newMaxAIOEventsPersistedChecker(fs, content),
+			func() TuneResult {
+				log.Debugf("Persisting fs.aio-max-nr to %s", AIOEventsSysctlFile)
+				err := executor.Execute(
+					commands.NewWriteFileCmd(fs, AIOEventsSysctlFile, content),
+				)
+				if err != nil {
+					return NewTuneError(err)
+				}
+				return NewTuneResult(false)
+			},
+			supported,
+			executor.IsLazy(),
+		))
+	}
+	return NewAggregatedTunable(tunables)
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
@@ -79,7 +80,12 @@ func TestMaxAIOEventsCheck(t *testing.T) {
 				err := tt.before(fs)
 				require.NoError(st, err)
 			}
-			tuner := tuners.NewMaxAIOEventsTuner(fs, exec)
+			smp := 1
+			tuner := tuners.NewMaxAIOEventsTuner(
+				fs,
+				config.RpkConfig{SMP: &smp},
+				exec,
+			)
 			res := tuner.Tune()
 			if tt.expectedErrMsg != "" {
 				require.Contains(st, res.Error().Error(), tt.expectedErrMsg)
@@ -105,3 +111,78 @@ func TestMaxAIOEventsCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxAIOEventsTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		smp      int
+		expected int
+	}{
+		{
+			name:     "it should return the minimum for few shards",
+			smp:      4,
+			expected: 1048576,
+		},
+		{
+			name:     "it should scale with the shards",
+			smp:      128,
+			expected: 128 * 11024,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			smp := tt.smp
+			target := tuners.MaxAIOEventsTarget(config.RpkConfig{SMP: &smp})
+			require.Equal(st, tt.expected, target)
+		})
+	}
+}
+
+func TestMaxAIOEventsChecker(t *testing.T) {
+	const maxAIOEventsFile = "/proc/sys/fs/aio-max-nr"
+	fs := afero.NewMemMapFs()
+	_, err := utils.WriteBytes(fs, []byte("1048576\n"), maxAIOEventsFile)
+	require.NoError(t, err)
+
+	res := tuners.NewMaxAIOEventsChecker(fs, 1048576).Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+	require.Equal(t, "1048576", res.Current)
+
+	res = tuners.NewMaxAIOEventsChecker(fs, 128*11024).Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+}
+
+func TestMaxAIOEventsTunerPersist(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	const maxAIOEventsFile = "/proc/sys/fs/aio-max-nr"
+	smp := 128
+	conf := config.RpkConfig{SMP: &smp, PersistAioEvents: true}
+	fs := afero.NewMemMapFs()
+	_, err := utils.WriteBytes(fs, []byte("65536"), maxAIOEventsFile)
+	require.NoError(t, err)
+
+	tuner := tuners.NewMaxAIOEventsTuner(
+		fs,
+		conf,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.Contains(
+		t,
+		string(script),
+		"echo '1411072' > /proc/sys/fs/aio-max-nr\n"+
+			"echo '# Generated by rpk\n"+
+			"fs.aio-max-nr = 1411072\n"+
+			"' > /etc/sysctl.d/90-redpanda-aio-max-nr.conf\n",
+	)
+	// Nothing is changed when rendering the script.
+	exists, err := afero.Exists(fs, tuners.AIOEventsSysctlFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+)
+
+// NewBallastFileFilesystemChecker returns a checker which fails if the
+// ballast file isn't on the same filesystem as the data directory, in which
+// case deleting it wouldn't free any space for redpanda.
+func NewBallastFileFilesystemChecker(ballastPath, dataDir string) Checker {
+	return newBallastFileFilesystemChecker(
+		ballastPath,
+		dataDir,
+		filesystem.GetDeviceID,
+	)
+}
+
+func newBallastFileFilesystemChecker(
+	ballastPath, dataDir string, deviceID func(string) (uint64, error),
+) Checker {
+	return NewEqualityChecker(
+		BallastFileFilesystemChecker,
+		"Ballast file on the data directory filesystem",
+		Warning,
+		true,
+		func() (interface{}, error) {
+			ballastDev, err := deviceID(ballastPath)
+			// If the ballast file hasn't been created yet, it will be on
+			// the filesystem of the directory containing it.
+			if os.IsNotExist(err) {
+				ballastDev, err = deviceID(filepath.Dir(ballastPath))
+			}
+			if err != nil {
+				return false, err
+			}
+			dataDev, err := deviceID(dataDir)
+			if err != nil {
+				return false, err
+			}
+			return ballastDev == dataDev, nil
+		},
+	)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBallastFileFilesystemChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		devices     map[string]uint64
+		expectedOk  bool
+		expectedErr string
+	}{
+		{
+			name: "it should pass if the ballast is on the data filesystem",
+			devices: map[string]uint64{
+				"/var/lib/redpanda/ballast": 2049,
+				"/var/lib/redpanda/data":    2049,
+			},
+			expectedOk: true,
+		},
+		{
+			name: "it should fail if the ballast is on another filesystem",
+			devices: map[string]uint64{
+				"/var/lib/redpanda/ballast": 2050,
+				"/var/lib/redpanda/data":    2049,
+			},
+		},
+		{
+			name: "it should check the parent dir if the ballast doesn't exist",
+			devices: map[string]uint64{
+				"/var/lib/redpanda":      2049,
+				"/var/lib/redpanda/data": 2049,
+			},
+			expectedOk: true,
+		},
+		{
+			name: "it should fail if the data directory can't be stat'd",
+			devices: map[string]uint64{
+				"/var/lib/redpanda/ballast": 2049,
+			},
+			expectedErr: "stat /var/lib/redpanda/data: file does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			deviceID := func(path string) (uint64, error) {
+				dev, ok := tt.devices[path]
+				if !ok {
+					return 0, &os.PathError{
+						Op:   "stat",
+						Path: path,
+						Err:  os.ErrNotExist,
+					}
+				}
+				return dev, nil
+			}
+			checker := newBallastFileFilesystemChecker(
+				"/var/lib/redpanda/ballast",
+				"/var/lib/redpanda/data",
+				deviceID,
+			)
+			res := checker.Check()
+			if tt.expectedErr != "" {
+				require.EqualError(st, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.EqualValues(st, Warning, res.Severity)
+		})
+	}
+}
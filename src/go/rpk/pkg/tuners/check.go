@@ -46,3 +46,32 @@ func Check(
 	sort.Slice(results, func(i, j int) bool { return results[i].Desc < results[j].Desc })
 	return results, nil
 }
+
+// CheckReport is the serializable form of a CheckResult, which is how the
+// results are printed by 'rpk redpanda check --format json' and exchanged
+// between nodes. Id is the checker's name, e.g. 'data_dir_access'. Error is
+// only set if the check couldn't be run.
+type CheckReport struct {
+	Id          string `json:"id"`
+	Description string `json:"description"`
+	Required    string `json:"required"`
+	Current     string `json:"current"`
+	Ok          bool   `json:"ok"`
+	Severity    string `json:"severity"`
+	Error       string `json:"error,omitempty"`
+}
+
+func NewCheckReport(r CheckResult) CheckReport {
+	report := CheckReport{
+		Id:          r.CheckerId.String(),
+		Description: r.Desc,
+		Required:    r.Required,
+		Current:     r.Current,
+		Ok:          r.IsOk,
+		Severity:    r.Severity.String(),
+	}
+	if r.Err != nil {
+		report.Error = r.Err.Error()
+	}
+	return report
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+func TestNewCheckReport(t *testing.T) {
+	report := tuners.NewCheckReport(tuners.CheckResult{
+		CheckerId: tuners.DataDirAccessChecker,
+		Desc:      "Data directory is writable",
+		Required:  "true",
+		Current:   "false",
+		Severity:  tuners.Fatal,
+		Err:       errors.New("permission denied"),
+	})
+	bs, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.JSONEq(
+		t,
+		`{
+  "id": "data_dir_access",
+  "description": "Data directory is writable",
+  "required": "true",
+  "current": "false",
+  "ok": false,
+  "severity": "Fatal",
+  "error": "permission denied"
+}`,
+		string(bs),
+	)
+}
+
+func TestCheckerNames(t *testing.T) {
+	names := map[string]bool{}
+	for id := tuners.ConfigFileChecker; id <= tuners.MountOptionsChecker; id++ {
+		name := tuners.CheckerID(id).String()
+		require.NotContains(t, name, "checker_", "checker %d has no name", id)
+		require.False(t, names[name], "the name %s is used twice", name)
+		names[name] = true
+	}
+}
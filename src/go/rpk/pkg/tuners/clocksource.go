@@ -11,6 +11,7 @@ package tuners
 
 import (
 	"fmt"
+	"runtime"
 	"strings"
 
 	"github.com/spf13/afero"
@@ -18,52 +19,124 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 )
 
-const prefferedClkSource = "tsc"
+const (
+	currentClkSourceFile   = "/sys/devices/system/clocksource/clocksource0/current_clocksource"
+	availableClkSourceFile = "/sys/devices/system/clocksource/clocksource0/available_clocksource"
+)
+
+// The clocksource which should be used on each architecture, as long as it's
+// available.
+var archClkSources = map[string]string{
+	"amd64": "tsc",
+	"386":   "tsc",
+	"arm64": "arch_sys_counter",
+}
 
+// NewClockSourceChecker returns a checker which fails if the current
+// clocksource isn't the one preferred for the architecture (e.g. tsc on
+// amd64) while it's available, e.g. when the kernel fell back to hpet, or
+// when a VM's clocksource changed after a live migration.
 func NewClockSourceChecker(fs afero.Fs) Checker {
-	return NewEqualityChecker(
-		ClockSource,
-		"Clock Source",
-		Warning,
-		prefferedClkSource,
-		func() (interface{}, error) {
-			content, err := afero.ReadFile(fs,
-				"/sys/devices/system/clocksource/clocksource0/current_clocksource")
-			if err != nil {
-				return "", err
-			}
-			return strings.TrimSpace(string(content)), nil
-		},
-	)
+	return newClockSourceChecker(fs, runtime.GOARCH)
+}
+
+func newClockSourceChecker(fs afero.Fs, arch string) Checker {
+	return &clockSourceChecker{fs: fs, preferred: archClkSources[arch]}
+}
+
+type clockSourceChecker struct {
+	fs        afero.Fs
+	preferred string
+}
+
+func (c *clockSourceChecker) Id() CheckerID {
+	return ClockSource
+}
+
+func (c *clockSourceChecker) GetDesc() string {
+	return "Clock Source"
+}
+
+func (c *clockSourceChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *clockSourceChecker) GetRequiredAsString() string {
+	return c.preferred
+}
+
+func (c *clockSourceChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	content, err := afero.ReadFile(c.fs, currentClkSourceFile)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Current = strings.TrimSpace(string(content))
+	// There's nothing better to switch to if the architecture has no
+	// preferred clocksource, or if it isn't available.
+	res.IsOk = c.preferred == "" ||
+		res.Current == c.preferred ||
+		!isClockSourceAvailable(c.fs, c.preferred)
+	return res
 }
 
+// Returns false if the clocksource isn't listed as available, or if the
+// available ones can't be read.
+func isClockSourceAvailable(fs afero.Fs, clkSource string) bool {
+	content, err := afero.ReadFile(fs, availableClkSourceFile)
+	if err != nil {
+		return false
+	}
+	for _, src := range strings.Fields(string(content)) {
+		if src == clkSource {
+			return true
+		}
+	}
+	return false
+}
+
+// NewClockSourceTuner returns a tuner which sets the clocksource to the one
+// preferred for the architecture.
 func NewClockSourceTuner(fs afero.Fs, executor executors.Executor) Tunable {
+	return newClockSourceTuner(fs, runtime.GOARCH, executor)
+}
+
+func newClockSourceTuner(
+	fs afero.Fs, arch string, executor executors.Executor,
+) Tunable {
+	preferred := archClkSources[arch]
 	return NewCheckedTunable(
-		NewClockSourceChecker(fs),
+		newClockSourceChecker(fs, arch),
 		func() TuneResult {
 			err := executor.Execute(commands.NewWriteFileCmd(fs,
-				"/sys/devices/system/clocksource/clocksource0/current_clocksource",
-				prefferedClkSource))
+				currentClkSourceFile,
+				preferred))
 			if err != nil {
 				return NewTuneError(err)
 			}
 			return NewTuneResult(false)
 		},
 		func() (bool, string) {
-			content, err := afero.ReadFile(fs,
-				"/sys/devices/system/clocksource/clocksource0/available_clocksource")
-			if err != nil {
+			if preferred == "" {
+				return false, fmt.Sprintf(
+					"There's no preferred clocksource for the '%s' architecture",
+					arch,
+				)
+			}
+			if _, err := afero.ReadFile(fs, availableClkSourceFile); err != nil {
 				return false, err.Error()
 			}
-			availableSrcs := strings.Fields(string(content))
-
-			for _, src := range availableSrcs {
-				if src == prefferedClkSource {
-					return true, ""
-				}
+			if !isClockSourceAvailable(fs, preferred) {
+				return false, fmt.Sprintf(
+					"Preffered clocksource '%s' not avaialable", preferred)
 			}
-			return false, fmt.Sprintf(
-				"Preffered clocksource '%s' not avaialable", prefferedClkSource)
+			return true, ""
 		},
 		executor.IsLazy(),
 	)
@@ -0,0 +1,163 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func TestClockSourceChecker(t *testing.T) {
+	tests := []struct {
+		name             string
+		arch             string
+		current          string
+		available        string
+		expectedRequired string
+		expectedOk       bool
+	}{
+		{
+			name:             "it should pass if the clocksource is tsc",
+			arch:             "amd64",
+			current:          "tsc",
+			available:        "tsc hpet acpi_pm",
+			expectedRequired: "tsc",
+			expectedOk:       true,
+		},
+		{
+			name:             "it should fail if the clocksource is hpet",
+			arch:             "amd64",
+			current:          "hpet",
+			available:        "tsc hpet acpi_pm",
+			expectedRequired: "tsc",
+		},
+		{
+			name:             "it should fail if a VM uses kvm-clock while tsc is available",
+			arch:             "amd64",
+			current:          "kvm-clock",
+			available:        "kvm-clock tsc acpi_pm",
+			expectedRequired: "tsc",
+		},
+		{
+			name:             "it should pass if a VM uses tsc while kvm-clock is available",
+			arch:             "amd64",
+			current:          "tsc",
+			available:        "kvm-clock tsc",
+			expectedRequired: "tsc",
+			expectedOk:       true,
+		},
+		{
+			name:             "it should pass if the preferred clocksource isn't available",
+			arch:             "amd64",
+			current:          "kvm-clock",
+			available:        "kvm-clock acpi_pm",
+			expectedRequired: "tsc",
+			expectedOk:       true,
+		},
+		{
+			name:             "it should pass if arm64 uses arch_sys_counter",
+			arch:             "arm64",
+			current:          "arch_sys_counter",
+			available:        "arch_sys_counter",
+			expectedRequired: "arch_sys_counter",
+			expectedOk:       true,
+		},
+		{
+			name:             "it should fail if arm64 doesn't use arch_sys_counter",
+			arch:             "arm64",
+			current:          "jiffies",
+			available:        "arch_sys_counter jiffies",
+			expectedRequired: "arch_sys_counter",
+		},
+		{
+			name:       "it should pass if the architecture has no preferred clocksource",
+			arch:       "ppc64le",
+			current:    "timebase",
+			available:  "timebase",
+			expectedOk: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, currentClkSourceFile, []byte(tt.current+"\n"), 0644)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, availableClkSourceFile, []byte(tt.available+"\n"), 0644)
+			require.NoError(st, err)
+			res := newClockSourceChecker(fs, tt.arch).Check()
+			require.NoError(st, res.Err)
+			require.EqualValues(st, ClockSource, res.CheckerId)
+			require.EqualValues(st, Warning, res.Severity)
+			require.Equal(st, tt.expectedRequired, res.Required)
+			require.Equal(st, tt.current, res.Current)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+		})
+	}
+}
+
+func TestClockSourceTuner(t *testing.T) {
+	tests := []struct {
+		name           string
+		arch           string
+		available      string
+		expectedSource string
+		expectedReason string
+	}{
+		{
+			name:           "it should set tsc on amd64",
+			arch:           "amd64",
+			available:      "tsc hpet acpi_pm",
+			expectedSource: "tsc",
+		},
+		{
+			name:           "it should set arch_sys_counter on arm64",
+			arch:           "arm64",
+			available:      "arch_sys_counter",
+			expectedSource: "arch_sys_counter",
+		},
+		{
+			name:           "it shouldn't be supported if the preferred clocksource isn't available",
+			arch:           "arm64",
+			available:      "jiffies",
+			expectedReason: "Preffered clocksource 'arch_sys_counter' not avaialable",
+		},
+		{
+			name:           "it shouldn't be supported if the architecture has no preferred clocksource",
+			arch:           "ppc64le",
+			available:      "timebase",
+			expectedReason: "There's no preferred clocksource for the 'ppc64le' architecture",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, currentClkSourceFile, []byte("hpet\n"), 0644)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, availableClkSourceFile, []byte(tt.available+"\n"), 0644)
+			require.NoError(st, err)
+			tuner := newClockSourceTuner(fs, tt.arch, executors.NewDirectExecutor())
+			supported, reason := tuner.CheckIfSupported()
+			if tt.expectedReason != "" {
+				require.False(st, supported)
+				require.Equal(st, tt.expectedReason, reason)
+				return
+			}
+			require.True(st, supported, reason)
+			res := tuner.Tune()
+			require.False(st, res.IsFailed())
+			current, err := afero.ReadFile(fs, currentClkSourceFile)
+			require.NoError(st, err)
+			require.Equal(st, tt.expectedSource, string(current))
+		})
+	}
+}
@@ -52,7 +52,9 @@ func NewCoredumpTuner(
 }
 
 func (t *tuner) Tune() tuners.TuneResult {
-	script, err := renderTemplate(coredumpScriptTmpl, t.conf.Rpk)
+	rpk := t.conf.Rpk
+	rpk.CoredumpDir = t.conf.ExpandedCoredumpDir()
+	script, err := renderTemplate(coredumpScriptTmpl, rpk)
 	if err != nil {
 		return tuners.NewTuneError(err)
 	}
@@ -80,3 +80,17 @@ func TestTune(t *testing.T) {
 		})
 	}
 }
+
+func TestTuneExpandsCoredumpDir(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	conf := validConfig()
+	conf.Redpanda.Id = 3
+	conf.Rpk.CoredumpDir = "/var/lib/redpanda/coredumps/${node_id}"
+	tuner := NewCoredumpTuner(fs, *conf, executors.NewDirectExecutor())
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	script, err := afero.ReadFile(fs, scriptFilePath)
+	require.NoError(t, err)
+	require.Contains(t, string(script), "mkdir -p /var/lib/redpanda/coredumps/3\n")
+	require.NotContains(t, string(script), "${node_id}")
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+// NewCpuQuotaChecker returns a checker which warns if the cgroup's CPU quota
+// allows fewer CPUs than the ones redpanda will run on, which gets it
+// throttled, e.g. in Kubernetes pods with a CPU limit below their cpuset.
+func NewCpuQuotaChecker(
+	cpuLimit func() (float64, error), cpus func() (int, error),
+) Checker {
+	return &cpuQuotaChecker{cpuLimit: cpuLimit, cpus: cpus}
+}
+
+// Returns the number of CPUs redpanda will run on: --smp in
+// rpk.additional_start_flags, rpk.smp, or else every CPU in the cgroup's
+// cpuset.
+func RedpandaCpus(
+	conf config.RpkConfig, effectiveCpus func() (uint64, error),
+) (int, error) {
+	if smp := startFlagValue(conf.AdditionalStartFlags, "smp"); smp != "" {
+		n, err := strconv.Atoi(smp)
+		if err != nil {
+			return 0, fmt.Errorf("couldn't parse --smp '%s': %v", smp, err)
+		}
+		return n, nil
+	}
+	if conf.SMP != nil {
+		return *conf.SMP, nil
+	}
+	n, err := effectiveCpus()
+	return int(n), err
+}
+
+type cpuQuotaChecker struct {
+	cpuLimit func() (float64, error)
+	cpus     func() (int, error)
+}
+
+func (c *cpuQuotaChecker) Id() CheckerID {
+	return CpuQuotaChecker
+}
+
+func (c *cpuQuotaChecker) GetDesc() string {
+	return "CPU quota [CPUs]"
+}
+
+func (c *cpuQuotaChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *cpuQuotaChecker) GetRequiredAsString() string {
+	cpus, err := c.cpus()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(">= %d", cpus)
+}
+
+func (c *cpuQuotaChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+	}
+	cpus, err := c.cpus()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Required = fmt.Sprintf(">= %d", cpus)
+	limit, err := c.cpuLimit()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if math.IsInf(limit, 1) {
+		res.Current = "unlimited"
+		res.IsOk = true
+		return res
+	}
+	res.Current = strconv.FormatFloat(limit, 'f', -1, 64)
+	res.IsOk = limit >= float64(cpus)
+	return res
+}
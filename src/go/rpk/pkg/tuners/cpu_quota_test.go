@@ -0,0 +1,104 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+func TestCpuQuotaChecker(t *testing.T) {
+	tests := []struct {
+		name             string
+		limit            float64
+		limitErr         error
+		cpus             int
+		expectedOk       bool
+		expectedCurrent  string
+		expectedRequired string
+		expectedErr      string
+	}{
+		{
+			name:             "it should pass if the quota is unlimited",
+			limit:            math.Inf(1),
+			cpus:             8,
+			expectedOk:       true,
+			expectedCurrent:  "unlimited",
+			expectedRequired: ">= 8",
+		},
+		{
+			name:             "it should pass if the quota allows every CPU",
+			limit:            8,
+			cpus:             8,
+			expectedOk:       true,
+			expectedCurrent:  "8",
+			expectedRequired: ">= 8",
+		},
+		{
+			name:             "it should fail if the quota allows fewer CPUs",
+			limit:            2.5,
+			cpus:             4,
+			expectedCurrent:  "2.5",
+			expectedRequired: ">= 4",
+		},
+		{
+			name:        "it should fail if the quota can't be read",
+			limitErr:    errors.New("no such file or directory"),
+			cpus:        4,
+			expectedErr: "no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			res := tuners.NewCpuQuotaChecker(
+				func() (float64, error) { return tt.limit, tt.limitErr },
+				func() (int, error) { return tt.cpus, nil },
+			).Check()
+			if tt.expectedErr != "" {
+				require.EqualError(st, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.Equal(st, tt.expectedRequired, res.Required)
+			require.Equal(st, tuners.Severity(tuners.Warning), res.Severity)
+		})
+	}
+}
+
+func TestRedpandaCpus(t *testing.T) {
+	effectiveCpus := func() (uint64, error) { return 16, nil }
+
+	conf := config.Default().Rpk
+	cpus, err := tuners.RedpandaCpus(conf, effectiveCpus)
+	require.NoError(t, err)
+	require.Equal(t, 16, cpus)
+
+	smp := 4
+	conf.SMP = &smp
+	cpus, err = tuners.RedpandaCpus(conf, effectiveCpus)
+	require.NoError(t, err)
+	require.Equal(t, 4, cpus)
+
+	conf.AdditionalStartFlags = []string{"--smp=2"}
+	cpus, err = tuners.RedpandaCpus(conf, effectiveCpus)
+	require.NoError(t, err)
+	require.Equal(t, 2, cpus)
+
+	conf.AdditionalStartFlags = []string{"--smp=all"}
+	_, err = tuners.RedpandaCpus(conf, effectiveCpus)
+	require.Error(t, err)
+}
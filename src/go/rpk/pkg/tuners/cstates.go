@@ -0,0 +1,207 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+const (
+	// The deepest C-state the CPUs may enter if rpk.max_cstate isn't set.
+	// C1 (halt) adds little wakeup latency, unlike the deeper ones.
+	DefaultMaxCstate int = 1
+
+	cpuIdleStatesGlob  = "/sys/devices/system/cpu/cpu[0-9]*/cpuidle/state[0-9]*"
+	hypervisorTypeFile = "/sys/hypervisor/type"
+	cpuInfoFile        = "/proc/cpuinfo"
+)
+
+// MaxCstateTarget returns the deepest C-state the CPUs should be allowed to
+// enter.
+func MaxCstateTarget(conf config.RpkConfig) int {
+	if conf.MaxCstate != nil {
+		return *conf.MaxCstate
+	}
+	return DefaultMaxCstate
+}
+
+// A CPU idle state, as exposed by the cpuidle subsystem in
+// /sys/devices/system/cpu/cpu<N>/cpuidle/state<M>. The deeper the state, the
+// higher its index.
+type cpuIdleState struct {
+	dir      string
+	index    int
+	disabled bool
+}
+
+func (s cpuIdleState) disableFile() string {
+	return filepath.Join(s.dir, "disable")
+}
+
+// Returns the idle states of all the CPUs, sorted by their path.
+func readCpuIdleStates(fs afero.Fs) ([]cpuIdleState, error) {
+	dirs, err := afero.Glob(fs, cpuIdleStatesGlob)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	states := []cpuIdleState{}
+	for _, dir := range dirs {
+		index, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "state"))
+		if err != nil {
+			return nil, err
+		}
+		state := cpuIdleState{dir: dir, index: index}
+		content, err := afero.ReadFile(fs, state.disableFile())
+		if err != nil {
+			return nil, err
+		}
+		state.disabled = strings.TrimSpace(string(content)) == "1"
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// Returns the index of the deepest idle state enabled in any CPU.
+func deepestEnabledCstate(states []cpuIdleState) int {
+	deepest := 0
+	for _, s := range states {
+		if !s.disabled && s.index > deepest {
+			deepest = s.index
+		}
+	}
+	return deepest
+}
+
+// NewCstatesChecker returns a checker which warns if any CPU may enter an
+// idle state deeper than maxCstate.
+func NewCstatesChecker(fs afero.Fs, maxCstate int) Checker {
+	return &cstatesChecker{fs: fs, maxCstate: maxCstate}
+}
+
+type cstatesChecker struct {
+	fs        afero.Fs
+	maxCstate int
+}
+
+func (c *cstatesChecker) Id() CheckerID {
+	return CstatesChecker
+}
+
+func (c *cstatesChecker) GetDesc() string {
+	return "Deepest enabled CPU idle state"
+}
+
+func (c *cstatesChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *cstatesChecker) GetRequiredAsString() string {
+	return fmt.Sprintf("<= %d", c.maxCstate)
+}
+
+func (c *cstatesChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	states, err := readCpuIdleStates(c.fs)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	deepest := deepestEnabledCstate(states)
+	res.Current = strconv.Itoa(deepest)
+	res.IsOk = deepest <= c.maxCstate
+	return res
+}
+
+// NewCstatesTuner disables the CPU idle states deeper than maxCstate on every
+// CPU, since waking up from them adds latency. The states are disabled
+// through sysfs, so it doesn't persist across reboots. On VMs, the idle
+// states are up to the hypervisor, so it's reported as unsupported.
+func NewCstatesTuner(
+	fs afero.Fs, maxCstate int, executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewCstatesChecker(fs, maxCstate),
+		func() TuneResult {
+			states, err := readCpuIdleStates(fs)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			for _, s := range states {
+				if s.disabled || s.index <= maxCstate {
+					continue
+				}
+				log.Debugf("Disabling CPU idle state %s", s.dir)
+				err := executor.Execute(
+					commands.NewWriteFileCmd(fs, s.disableFile(), "1"),
+				)
+				if err != nil {
+					return NewTuneError(err)
+				}
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			if isVM(fs) {
+				return false, "The CPU idle states are managed by the" +
+					" hypervisor on VMs"
+			}
+			states, err := readCpuIdleStates(fs)
+			if err != nil {
+				return false, err.Error()
+			}
+			if len(states) == 0 {
+				return false, "The CPU idle states aren't exposed in" +
+					" /sys/devices/system/cpu/cpu*/cpuidle"
+			}
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+// Returns true if a hypervisor is detected, either through sysfs or through
+// the 'hypervisor' CPU flag.
+func isVM(fs afero.Fs) bool {
+	if content, err := afero.ReadFile(fs, hypervisorTypeFile); err == nil &&
+		strings.TrimSpace(string(content)) != "" {
+		return true
+	}
+	content, err := afero.ReadFile(fs, cpuInfoFile)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "flags") {
+			continue
+		}
+		for _, flag := range strings.Fields(line) {
+			if flag == "hypervisor" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+// Writes the idle states of the given CPUs, each one with its states' disable
+// values, e.g. {"0", "0", "1"} for state0 and state1 enabled, and state2
+// disabled.
+func writeCpuIdleStates(t *testing.T, fs afero.Fs, cpus [][]string) {
+	for cpu, states := range cpus {
+		for i, disabled := range states {
+			path := fmt.Sprintf(
+				"/sys/devices/system/cpu/cpu%d/cpuidle/state%d/disable",
+				cpu,
+				i,
+			)
+			err := afero.WriteFile(fs, path, []byte(disabled+"\n"), 0644)
+			require.NoError(t, err)
+		}
+	}
+}
+
+func TestCstatesChecker(t *testing.T) {
+	tests := []struct {
+		name            string
+		cpus            [][]string
+		maxCstate       int
+		expectedCurrent string
+		expectedOk      bool
+	}{
+		{
+			name:            "it should fail if a deeper state is enabled",
+			cpus:            [][]string{{"0", "0", "0", "0"}, {"0", "0", "0", "0"}},
+			maxCstate:       1,
+			expectedCurrent: "3",
+		},
+		{
+			name:            "it should fail if a deeper state is enabled in any CPU",
+			cpus:            [][]string{{"0", "0", "1", "1"}, {"0", "0", "0", "1"}},
+			maxCstate:       1,
+			expectedCurrent: "2",
+		},
+		{
+			name:            "it should pass if the deeper states are disabled",
+			cpus:            [][]string{{"0", "0", "1", "1"}, {"0", "0", "1", "1"}},
+			maxCstate:       1,
+			expectedCurrent: "1",
+			expectedOk:      true,
+		},
+		{
+			name:            "it should pass if there are no deeper states",
+			cpus:            [][]string{{"0", "0", "0"}},
+			maxCstate:       2,
+			expectedCurrent: "2",
+			expectedOk:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeCpuIdleStates(st, fs, tt.cpus)
+			res := NewCstatesChecker(fs, tt.maxCstate).Check()
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.Equal(st, fmt.Sprintf("<= %d", tt.maxCstate), res.Required)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+		})
+	}
+}
+
+func TestCstatesTuner(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeCpuIdleStates(t, fs, [][]string{{"0", "0", "0", "1"}, {"0", "0", "0", "0"}})
+	tuner := NewCstatesTuner(fs, 1, executors.NewDirectExecutor())
+	supported, reason := tuner.CheckIfSupported()
+	require.True(t, supported, reason)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+
+	expected := map[string]string{
+		"/sys/devices/system/cpu/cpu0/cpuidle/state1/disable": "0\n",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state2/disable": "1",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state3/disable": "1\n",
+		"/sys/devices/system/cpu/cpu1/cpuidle/state2/disable": "1",
+		"/sys/devices/system/cpu/cpu1/cpuidle/state3/disable": "1",
+	}
+	for path, content := range expected {
+		bs, err := afero.ReadFile(fs, path)
+		require.NoError(t, err)
+		require.Equal(t, content, string(bs), path)
+	}
+}
+
+func TestCstatesTunerRenderScript(t *testing.T) {
+	const scriptPath = "/tmp/tune.sh"
+	fs := afero.NewMemMapFs()
+	writeCpuIdleStates(t, fs, [][]string{{"0", "0", "0", "1"}, {"0", "0", "0", "0"}})
+	tuner := NewCstatesTuner(
+		fs,
+		1,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	// Only the enabled states deeper than the max are disabled.
+	require.Contains(
+		t,
+		string(script),
+		"echo '1' > /sys/devices/system/cpu/cpu0/cpuidle/state2/disable\n"+
+			"echo '1' > /sys/devices/system/cpu/cpu1/cpuidle/state2/disable\n"+
+			"echo '1' > /sys/devices/system/cpu/cpu1/cpuidle/state3/disable\n",
+	)
+	require.NotContains(t, string(script), "cpu0/cpuidle/state3")
+	// Nothing is changed when rendering the script.
+	bs, err := afero.ReadFile(fs, "/sys/devices/system/cpu/cpu0/cpuidle/state2/disable")
+	require.NoError(t, err)
+	require.Equal(t, "0\n", string(bs))
+}
+
+func TestCstatesTunerUnsupported(t *testing.T) {
+	tests := []struct {
+		name           string
+		cpus           [][]string
+		before         func(afero.Fs) error
+		expectedReason string
+	}{
+		{
+			name: "it should be unsupported on VMs",
+			cpus: [][]string{{"0", "0", "0"}},
+			before: func(fs afero.Fs) error {
+				return afero.WriteFile(fs, hypervisorTypeFile, []byte("xen\n"), 0644)
+			},
+			expectedReason: "The CPU idle states are managed by the hypervisor on VMs",
+		},
+		{
+			name: "it should be unsupported if the hypervisor CPU flag is set",
+			cpus: [][]string{{"0", "0", "0"}},
+			before: func(fs afero.Fs) error {
+				return afero.WriteFile(
+					fs,
+					cpuInfoFile,
+					[]byte("processor\t: 0\nflags\t\t: fpu vme tsc hypervisor\n"),
+					0644,
+				)
+			},
+			expectedReason: "The CPU idle states are managed by the hypervisor on VMs",
+		},
+		{
+			name:           "it should be unsupported if cpuidle isn't available",
+			before:         func(afero.Fs) error { return nil },
+			expectedReason: "The CPU idle states aren't exposed in /sys/devices/system/cpu/cpu*/cpuidle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeCpuIdleStates(st, fs, tt.cpus)
+			require.NoError(st, tt.before(fs))
+			tuner := NewCstatesTuner(fs, 1, executors.NewDirectExecutor())
+			supported, reason := tuner.CheckIfSupported()
+			require.False(st, supported)
+			require.Equal(st, tt.expectedReason, reason)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+)
+
+// The user the redpanda packages run redpanda as.
+const DefaultRedpandaUser = "redpanda"
+
+// RedpandaUser returns the user redpanda runs as: rpk.redpanda_user, or
+// DefaultRedpandaUser if it isn't set.
+func RedpandaUser(conf config.RpkConfig) string {
+	if conf.RedpandaUser != "" {
+		return conf.RedpandaUser
+	}
+	return DefaultRedpandaUser
+}
+
+// NewDataDirOwnerChecker returns a checker which warns if the given user
+// can't write to the data directory, going by its owner, group and
+// permissions. Unlike the data directory writability checker, it doesn't
+// depend on the user rpk runs as, which is usually root, while redpanda runs
+// as an unprivileged user. If the user doesn't exist, redpanda can't be
+// running as it, so the check passes. It's only a warning, since the
+// directory's ACLs and the search permissions of its parents aren't
+// evaluated.
+func NewDataDirOwnerChecker(
+	dataDir string,
+	username string,
+	lookupUser func(string) (system.UserIDs, error),
+	getOwnership func(string) (filesystem.Ownership, error),
+) Checker {
+	return &dataDirOwnerChecker{
+		dataDir:      dataDir,
+		username:     username,
+		lookupUser:   lookupUser,
+		getOwnership: getOwnership,
+	}
+}
+
+type dataDirOwnerChecker struct {
+	dataDir      string
+	username     string
+	lookupUser   func(string) (system.UserIDs, error)
+	getOwnership func(string) (filesystem.Ownership, error)
+}
+
+func (c *dataDirOwnerChecker) Id() CheckerID {
+	return DataDirOwnerChecker
+}
+
+func (c *dataDirOwnerChecker) GetDesc() string {
+	return fmt.Sprintf("Data directory is writable by %s", c.username)
+}
+
+func (c *dataDirOwnerChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *dataDirOwnerChecker) GetRequiredAsString() string {
+	return "true"
+}
+
+func (c *dataDirOwnerChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	ids, err := c.lookupUser(c.username)
+	if err != nil {
+		var unknown user.UnknownUserError
+		if errors.As(err, &unknown) {
+			log.Debugf("Skipping the data directory ownership check: %v", err)
+			res.Current = "true"
+			res.IsOk = true
+			return res
+		}
+		res.Err = err
+		return res
+	}
+	own, err := c.getOwnership(c.dataDir)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.IsOk = canWrite(ids, own)
+	if res.IsOk {
+		res.Current = "true"
+	} else {
+		res.Current = fmt.Sprintf(
+			"false (owned by %d:%d, mode %s)",
+			own.Uid,
+			own.Gid,
+			own.Mode,
+		)
+	}
+	return res
+}
+
+// Whether a process with the given IDs can create files in a directory with
+// the given ownership, which requires both the write and the search (x)
+// permissions of the class (owner, group or others) it falls in.
+func canWrite(ids system.UserIDs, own filesystem.Ownership) bool {
+	if ids.Uid == 0 {
+		return true
+	}
+	const writeSearch = 03
+	if ids.Uid == own.Uid {
+		return (own.Mode>>6)&writeSearch == writeSearch
+	}
+	for _, gid := range ids.Gids {
+		if gid == own.Gid {
+			return (own.Mode>>3)&writeSearch == writeSearch
+		}
+	}
+	return own.Mode&writeSearch == writeSearch
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"errors"
+	"os/user"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+func TestDataDirOwnerChecker(t *testing.T) {
+	redpanda := system.UserIDs{Uid: 101, Gids: []uint32{101, 4}}
+	tests := []struct {
+		name            string
+		ids             system.UserIDs
+		lookupErr       error
+		own             filesystem.Ownership
+		statErr         error
+		expectedOk      bool
+		expectedCurrent string
+		expectedErr     string
+	}{
+		{
+			name:            "it should pass if the user owns the directory",
+			ids:             redpanda,
+			own:             filesystem.Ownership{Uid: 101, Gid: 101, Mode: 0755},
+			expectedOk:      true,
+			expectedCurrent: "true",
+		},
+		{
+			name:            "it should fail if the owner can't write to it",
+			ids:             redpanda,
+			own:             filesystem.Ownership{Uid: 101, Gid: 101, Mode: 0555},
+			expectedCurrent: "false (owned by 101:101, mode -r-xr-xr-x)",
+		},
+		{
+			name:            "it should fail if root owns the directory",
+			ids:             redpanda,
+			own:             filesystem.Ownership{Uid: 0, Gid: 0, Mode: 0755},
+			expectedCurrent: "false (owned by 0:0, mode -rwxr-xr-x)",
+		},
+		{
+			name:            "it should pass if one of the user's groups can write to it",
+			ids:             redpanda,
+			own:             filesystem.Ownership{Uid: 0, Gid: 4, Mode: 0770},
+			expectedOk:      true,
+			expectedCurrent: "true",
+		},
+		{
+			name:            "it should pass if everyone can write to it",
+			ids:             redpanda,
+			own:             filesystem.Ownership{Uid: 0, Gid: 0, Mode: 0777},
+			expectedOk:      true,
+			expectedCurrent: "true",
+		},
+		{
+			name:            "it should fail if it can be written but not searched",
+			ids:             redpanda,
+			own:             filesystem.Ownership{Uid: 101, Gid: 101, Mode: 0600},
+			expectedCurrent: "false (owned by 101:101, mode -rw-------)",
+		},
+		{
+			name:            "it should pass if the user is root",
+			ids:             system.UserIDs{Uid: 0, Gids: []uint32{0}},
+			own:             filesystem.Ownership{Uid: 101, Gid: 101, Mode: 0700},
+			expectedOk:      true,
+			expectedCurrent: "true",
+		},
+		{
+			name:            "it should pass if the user doesn't exist",
+			lookupErr:       user.UnknownUserError("redpanda"),
+			expectedOk:      true,
+			expectedCurrent: "true",
+		},
+		{
+			name:        "it should fail if the directory can't be stat'd",
+			ids:         redpanda,
+			statErr:     errors.New("no such file or directory"),
+			expectedErr: "no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			checker := tuners.NewDataDirOwnerChecker(
+				"/var/lib/redpanda/data",
+				"redpanda",
+				func(name string) (system.UserIDs, error) {
+					require.Equal(st, "redpanda", name)
+					return tt.ids, tt.lookupErr
+				},
+				func(path string) (filesystem.Ownership, error) {
+					require.Equal(st, "/var/lib/redpanda/data", path)
+					return tt.own, tt.statErr
+				},
+			)
+			res := checker.Check()
+			if tt.expectedErr != "" {
+				require.EqualError(st, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.Equal(st, "Data directory is writable by redpanda", res.Desc)
+			require.Equal(st, tuners.Warning, res.Severity)
+		})
+	}
+}
+
+func TestRedpandaUser(t *testing.T) {
+	conf := config.Default().Rpk
+	require.Equal(t, tuners.DefaultRedpandaUser, tuners.RedpandaUser(conf))
+	conf.RedpandaUser = "kafka"
+	require.Equal(t, "kafka", tuners.RedpandaUser(conf))
+}
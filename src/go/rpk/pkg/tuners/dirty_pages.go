@@ -0,0 +1,173 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+const (
+	DefaultDirtyRatio           int = 10
+	DefaultDirtyBackgroundRatio int = 5
+
+	// The file where the settings are persisted, so that they're applied
+	// on boot.
+	DirtyPagesSysctlFile string = "/etc/sysctl.d/90-redpanda-dirty-pages.conf"
+
+	vmSysctlDir string = "/proc/sys/vm"
+)
+
+// A vm.dirty_* setting and the value it should be set to.
+type dirtyPagesSetting struct {
+	name  string
+	value int
+}
+
+func (s dirtyPagesSetting) file() string {
+	return filepath.Join(vmSysctlDir, s.name)
+}
+
+// Returns the vm.dirty_* settings to be tuned. The ratios are used by default,
+// and each one is replaced with its bytes variant if it's set.
+func dirtyPagesSettings(conf config.RpkConfig) []dirtyPagesSetting {
+	fg := dirtyPagesSetting{"dirty_ratio", DefaultDirtyRatio}
+	if conf.DirtyRatio != nil {
+		fg.value = *conf.DirtyRatio
+	}
+	if conf.DirtyBytes != nil {
+		fg = dirtyPagesSetting{"dirty_bytes", *conf.DirtyBytes}
+	}
+	bg := dirtyPagesSetting{"dirty_background_ratio", DefaultDirtyBackgroundRatio}
+	if conf.DirtyBackgroundRatio != nil {
+		bg.value = *conf.DirtyBackgroundRatio
+	}
+	if conf.DirtyBackgroundBytes != nil {
+		bg = dirtyPagesSetting{"dirty_background_bytes", *conf.DirtyBackgroundBytes}
+	}
+	return []dirtyPagesSetting{fg, bg}
+}
+
+// Returns the content of DirtyPagesSysctlFile for the given settings.
+func dirtyPagesSysctlConf(settings []dirtyPagesSetting) string {
+	lines := []string{"# Generated by rpk"}
+	for _, s := range settings {
+		lines = append(lines, fmt.Sprintf("vm.%s = %d", s.name, s.value))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func NewDirtyPagesCheckers(fs afero.Fs, conf config.RpkConfig) []Checker {
+	checkers := []Checker{}
+	for _, s := range dirtyPagesSettings(conf) {
+		checkers = append(checkers, newDirtyPagesChecker(fs, s))
+	}
+	return checkers
+}
+
+func newDirtyPagesChecker(fs afero.Fs, s dirtyPagesSetting) Checker {
+	return NewEqualityChecker(
+		DirtyPagesChecker,
+		fmt.Sprintf("vm.%s", s.name),
+		Warning,
+		s.value,
+		func() (interface{}, error) {
+			content, err := afero.ReadFile(fs, s.file())
+			if err != nil {
+				return -1, err
+			}
+			return strconv.Atoi(strings.TrimSpace(string(content)))
+		},
+	)
+}
+
+func newDirtyPagesPersistedChecker(fs afero.Fs, content string) Checker {
+	return NewEqualityChecker(
+		DirtyPagesChecker,
+		fmt.Sprintf("vm.dirty_* persisted in %s", DirtyPagesSysctlFile),
+		Warning,
+		content,
+		func() (interface{}, error) {
+			current, err := afero.ReadFile(fs, DirtyPagesSysctlFile)
+			if os.IsNotExist(err) {
+				return "", nil
+			}
+			return string(current), err
+		},
+	)
+}
+
+// NewDirtyPagesTuner sets vm.dirty_ratio (or vm.dirty_bytes) and
+// vm.dirty_background_ratio (or vm.dirty_background_bytes) to the values in
+// the config, or to the defaults. If rpk.persist_dirty_pages is set, the
+// values are also written to DirtyPagesSysctlFile.
+func NewDirtyPagesTuner(
+	fs afero.Fs, conf config.RpkConfig, executor executors.Executor,
+) Tunable {
+	supported := func() (bool, string) {
+		errs := config.CheckDirtyPages(conf)
+		if len(errs) > 0 {
+			msgs := make([]string, 0, len(errs))
+			for _, err := range errs {
+				msgs = append(msgs, err.Error())
+			}
+			return false, strings.Join(msgs, "; ")
+		}
+		return true, ""
+	}
+	settings := dirtyPagesSettings(conf)
+	tunables := []Tunable{}
+	for _, s := range settings {
+		s := s
+		tunables = append(tunables, NewCheckedTunable(
+			newDirtyPagesChecker(fs, s),
+			func() TuneResult {
+				log.Debugf("Setting vm.%s to %d", s.name, s.value)
+				err := executor.Execute(
+					commands.NewWriteFileCmd(fs, s.file(), fmt.Sprint(s.value)),
+				)
+				if err != nil {
+					return NewTuneError(err)
+				}
+				return NewTuneResult(false)
+			},
+			supported,
+			executor.IsLazy(),
+		))
+	}
+	if conf.PersistDirtyPages {
+		content := dirtyPagesSysctlConf(settings)
+		tunables = append(tunables, NewCheckedTunable(
+			newDirtyPagesPersistedChecker(fs, content),
+			func() TuneResult {
+				log.Debugf("Persisting the vm.dirty_* settings to %s", DirtyPagesSysctlFile)
+				err := executor.Execute(
+					commands.NewWriteFileCmd(fs, DirtyPagesSysctlFile, content),
+				)
+				if err != nil {
+					return NewTuneError(err)
+				}
+				return NewTuneResult(false)
+			},
+			supported,
+			executor.IsLazy(),
+		))
+	}
+	return NewAggregatedTunable(tunables)
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func writeDirtyPagesFiles(t *testing.T, fs afero.Fs) {
+	defaults := map[string]string{
+		"/proc/sys/vm/dirty_ratio":            "20\n",
+		"/proc/sys/vm/dirty_background_ratio": "10\n",
+		"/proc/sys/vm/dirty_bytes":            "0\n",
+		"/proc/sys/vm/dirty_background_bytes": "0\n",
+	}
+	for path, content := range defaults {
+		err := afero.WriteFile(fs, path, []byte(content), 0644)
+		require.NoError(t, err)
+	}
+}
+
+func TestDirtyPagesTuner(t *testing.T) {
+	bytes := 268435456
+	ratio := 15
+	tests := []struct {
+		name     string
+		conf     config.RpkConfig
+		expected map[string]string
+	}{
+		{
+			name: "it should set the default ratios",
+			expected: map[string]string{
+				"/proc/sys/vm/dirty_ratio":            "10",
+				"/proc/sys/vm/dirty_background_ratio": "5",
+			},
+		},
+		{
+			name: "it should set the configured values and persist them",
+			conf: config.RpkConfig{
+				DirtyRatio:           &ratio,
+				DirtyBackgroundBytes: &bytes,
+				PersistDirtyPages:    true,
+			},
+			expected: map[string]string{
+				"/proc/sys/vm/dirty_ratio":            "15",
+				"/proc/sys/vm/dirty_background_bytes": "268435456",
+				DirtyPagesSysctlFile: "# Generated by rpk\n" +
+					"vm.dirty_ratio = 15\n" +
+					"vm.dirty_background_bytes = 268435456\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeDirtyPagesFiles(st, fs)
+			tuner := NewDirtyPagesTuner(fs, tt.conf, executors.NewDirectExecutor())
+			supported, reason := tuner.CheckIfSupported()
+			require.True(st, supported, reason)
+			res := tuner.Tune()
+			require.NoError(st, res.Error())
+			for path, content := range tt.expected {
+				bs, err := afero.ReadFile(fs, path)
+				require.NoError(st, err)
+				require.Equal(st, content, string(bs))
+			}
+		})
+	}
+}
+
+func TestDirtyPagesTunerRenderScript(t *testing.T) {
+	const scriptPath = "/tmp/tune.sh"
+	bytes := 536870912
+	fs := afero.NewMemMapFs()
+	writeDirtyPagesFiles(t, fs)
+	conf := config.RpkConfig{
+		DirtyBytes:        &bytes,
+		PersistDirtyPages: true,
+	}
+	tuner := NewDirtyPagesTuner(
+		fs,
+		conf,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.Contains(
+		t,
+		string(script),
+		"echo '536870912' > /proc/sys/vm/dirty_bytes\n"+
+			"echo '5' > /proc/sys/vm/dirty_background_ratio\n"+
+			"echo '# Generated by rpk\n"+
+			"vm.dirty_bytes = 536870912\n"+
+			"vm.dirty_background_ratio = 5\n"+
+			"' > /etc/sysctl.d/90-redpanda-dirty-pages.conf\n",
+	)
+	// Nothing is changed when rendering the script.
+	bs, err := afero.ReadFile(fs, "/proc/sys/vm/dirty_bytes")
+	require.NoError(t, err)
+	require.Equal(t, "0\n", string(bs))
+	exists, err := afero.Exists(fs, DirtyPagesSysctlFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestDirtyPagesTunerConflictingSettings(t *testing.T) {
+	ratio, bytes := 10, 268435456
+	tests := []struct {
+		name   string
+		conf   config.RpkConfig
+		reason string
+	}{
+		{
+			name: "it should fail if dirty_ratio and dirty_bytes are set",
+			conf: config.RpkConfig{
+				DirtyRatio: &ratio,
+				DirtyBytes: &bytes,
+			},
+			reason: "rpk.dirty_ratio and rpk.dirty_bytes can't both be set",
+		},
+		{
+			name: "it should fail if both background settings are set",
+			conf: config.RpkConfig{
+				DirtyBackgroundRatio: &ratio,
+				DirtyBackgroundBytes: &bytes,
+			},
+			reason: "rpk.dirty_background_ratio and rpk.dirty_background_bytes" +
+				" can't both be set",
+		},
+		{
+			name: "it should list every conflicting pair",
+			conf: config.RpkConfig{
+				DirtyRatio:           &ratio,
+				DirtyBytes:           &bytes,
+				DirtyBackgroundRatio: &ratio,
+				DirtyBackgroundBytes: &bytes,
+			},
+			reason: "rpk.dirty_ratio and rpk.dirty_bytes can't both be set;" +
+				" rpk.dirty_background_ratio and rpk.dirty_background_bytes" +
+				" can't both be set",
+		},
+		{
+			name: "it should fail if a ratio is out of range",
+			conf: config.RpkConfig{
+				DirtyRatio: func() *int { r := 101; return &r }(),
+			},
+			reason: "rpk.dirty_ratio must be between 0 and 100, but got 101",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			tuner := NewDirtyPagesTuner(fs, tt.conf, executors.NewDirectExecutor())
+			supported, reason := tuner.CheckIfSupported()
+			require.False(st, supported)
+			require.Equal(st, tt.reason, reason)
+		})
+	}
+}
@@ -33,6 +33,9 @@ type DeviceFeatures interface {
 	GetSchedulerFeatureFile(device string) (string, error)
 	GetWriteCache(device string) (string, error)
 	GetWriteCacheFeatureFile(device string) (string, error)
+	GetNrRequests(device string) (int, error)
+	GetNrRequestsFeatureFile(device string) (string, error)
+	GetMaxNrRequests(device string) (int, error)
 }
 
 func NewDeviceFeatures(fs afero.Fs, blockDevices BlockDevices) DeviceFeatures {
@@ -108,6 +111,48 @@ func (d *deviceFeatures) GetWriteCacheFeatureFile(
 	return d.getQueueFeatureFile(deviceNode(device), "write_cache")
 }
 
+func (d *deviceFeatures) GetNrRequests(device string) (int, error) {
+	log.Debugf("Getting '%s' nr_requests", device)
+	featureFile, err := d.GetNrRequestsFeatureFile(device)
+	if err != nil {
+		return 0, err
+	}
+	log.Debugf("Feature file %s", featureFile)
+	bytes, err := afero.ReadFile(d.fs, featureFile)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(bytes)))
+}
+
+func (d *deviceFeatures) GetNrRequestsFeatureFile(
+	device string,
+) (string, error) {
+	return d.getQueueFeatureFile(deviceNode(device), "nr_requests")
+}
+
+// Returns the depth of the device's hardware queue, which is the most
+// nr_requests can be set to, or 0 if it's unknown (e.g. for the devices which
+// don't use blk-mq).
+func (d *deviceFeatures) GetMaxNrRequests(device string) (int, error) {
+	log.Debugf("Getting '%s' max nr_requests", device)
+	tagsFile, err := d.getDeviceFile(
+		deviceNode(device),
+		filepath.Join("mq", "0", "nr_tags"),
+	)
+	if err != nil {
+		return 0, err
+	}
+	if tagsFile == "" {
+		return 0, nil
+	}
+	bytes, err := afero.ReadFile(d.fs, tagsFile)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(bytes)))
+}
+
 func (d *deviceFeatures) getSchedulerOptions(
 	device string,
 ) (*system.RuntimeOptions, error) {
@@ -122,18 +167,26 @@ func (d *deviceFeatures) getSchedulerOptions(
 
 func (d *deviceFeatures) getQueueFeatureFile(
 	deviceNode string, featureType string,
+) (string, error) {
+	return d.getDeviceFile(deviceNode, filepath.Join("queue", featureType))
+}
+
+// Returns the path of the given file under the device's sysfs directory, or
+// under its parent's if the device doesn't have it (e.g. for partitions).
+func (d *deviceFeatures) getDeviceFile(
+	deviceNode string, file string,
 ) (string, error) {
 	device, err := d.blockDevices.GetDeviceFromPath(deviceNode)
 	if err != nil {
 		log.Error(err.Error())
 		return "", nil
 	}
-	featureFile := filepath.Join(device.Syspath(), "queue", featureType)
+	featureFile := filepath.Join(device.Syspath(), file)
 	log.Debugf("Trying to open feature file '%s'", featureFile)
 	if exists, _ := afero.Exists(d.fs, featureFile); exists {
 		return featureFile, nil
 	} else if device.Parent() != nil {
-		return d.getQueueFeatureFile(device.Parent().Devnode(), featureType)
+		return d.getDeviceFile(device.Parent().Devnode(), file)
 	} else {
 		return "", nil
 	}
@@ -148,3 +148,56 @@ func TestDeviceFeatures_GetWriteCache(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, cache, CachePolicyWriteBack)
 }
+
+func TestDeviceFeatures_GetNrRequests(t *testing.T) {
+	// given
+	blockDevices := &blockDevicesMock{
+		getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+			return &blockDevice{
+				devnode: "/dev/fake",
+				syspath: testDevicePath,
+			}, nil
+		},
+	}
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll(testDevicePath+"/queue", 0644)
+	afero.WriteFile(fs,
+		testDevicePath+"/queue/nr_requests",
+		[]byte("64\n"), 0644)
+	deviceFeatures := NewDeviceFeatures(fs, blockDevices)
+	// when
+	nrRequests, err := deviceFeatures.GetNrRequests("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 64, nrRequests)
+}
+
+func TestDeviceFeatures_GetMaxNrRequests(t *testing.T) {
+	// given
+	blockDevices := &blockDevicesMock{
+		getBlockDeviceFromPath: func(path string) (BlockDevice, error) {
+			return &blockDevice{
+				devnode: "/dev/fake",
+				syspath: testDevicePath,
+			}, nil
+		},
+	}
+	fs := afero.NewMemMapFs()
+	deviceFeatures := NewDeviceFeatures(fs, blockDevices)
+	// when the device doesn't use blk-mq
+	depth, err := deviceFeatures.GetMaxNrRequests("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 0, depth)
+
+	// given
+	fs.MkdirAll(testDevicePath+"/mq/0", 0644)
+	afero.WriteFile(fs,
+		testDevicePath+"/mq/0/nr_tags",
+		[]byte("1023\n"), 0644)
+	// when
+	depth, err = deviceFeatures.GetMaxNrRequests("fake")
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 1023, depth)
+}
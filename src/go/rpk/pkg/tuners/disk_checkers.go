@@ -11,6 +11,7 @@ package tuners
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/disk"
@@ -87,6 +88,55 @@ func checkDeviceNomerges(
 	return nomerges == 2, nil
 }
 
+func NewDeviceNrRequestsChecker(
+	device string, target int, deviceFeatures disk.DeviceFeatures,
+) Checker {
+	return NewIntChecker(
+		NrRequestsChecker,
+		fmt.Sprintf("Disk '%s' nr_requests", device),
+		Warning,
+		func(current int) bool {
+			return current == deviceNrRequestsTarget(deviceFeatures, device, target)
+		},
+		func() string {
+			return strconv.Itoa(deviceNrRequestsTarget(deviceFeatures, device, target))
+		},
+		func() (int, error) {
+			return deviceFeatures.GetNrRequests(device)
+		},
+	)
+}
+
+func NewDirectoryNrRequestsChecker(
+	dir string,
+	target int,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) Checker {
+	return NewEqualityChecker(
+		NrRequestsChecker,
+		fmt.Sprintf("Dir '%s' nr_requests tuned", dir),
+		Warning,
+		true,
+		func() (interface{}, error) {
+			devices, err := blockDevices.GetDirectoryDevices(dir)
+			if err != nil {
+				return false, err
+			}
+			tuned := true
+			for _, device := range devices {
+				nrRequests, err := deviceFeatures.GetNrRequests(device)
+				if err != nil {
+					return false, err
+				}
+				tuned = tuned &&
+					nrRequests == deviceNrRequestsTarget(deviceFeatures, device, target)
+			}
+			return tuned, nil
+		},
+	)
+}
+
 func NewDeviceSchedulerChecker(
 	fs afero.Fs, device string, deviceFeatures disk.DeviceFeatures,
 ) Checker {
@@ -0,0 +1,144 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// The depth the disks' request queues are set to if rpk.disk_nr_requests
+// isn't set. It's clamped to each disk's hardware queue depth.
+const DefaultDiskNrRequests int = 1024
+
+// NrRequestsTarget returns the nr_requests the disks should be tuned to.
+func NrRequestsTarget(conf config.RpkConfig) int {
+	if conf.DiskNrRequests != nil {
+		return *conf.DiskNrRequests
+	}
+	return DefaultDiskNrRequests
+}
+
+// Returns the nr_requests the device should be tuned to: the target, clamped
+// to the depth of the device's hardware queue (e.g. 1023 for NVMe disks),
+// since the kernel refuses higher values.
+func deviceNrRequestsTarget(
+	deviceFeatures disk.DeviceFeatures, device string, target int,
+) int {
+	depth, err := deviceFeatures.GetMaxNrRequests(device)
+	if err != nil {
+		log.Debugf("Couldn't read the max nr_requests for '%s': %v", device, err)
+		return target
+	}
+	if depth > 0 && target > depth {
+		log.Debugf(
+			"Clamping '%s' nr_requests to its queue depth %d",
+			device,
+			depth,
+		)
+		return depth
+	}
+	return target
+}
+
+func NewDeviceNrRequestsTuner(
+	fs afero.Fs,
+	device string,
+	target int,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewDeviceNrRequestsChecker(device, target, deviceFeatures),
+		func() TuneResult {
+			return tuneNrRequests(fs, device, target, deviceFeatures, executor)
+		},
+		func() (bool, string) {
+			return checkNrRequestsWritable(fs, device, deviceFeatures)
+		},
+		executor.IsLazy(),
+	)
+}
+
+// Some devices (e.g. some virtual ones) expose nr_requests as read-only, in
+// which case it can't be tuned.
+func checkNrRequestsWritable(
+	fs afero.Fs, device string, deviceFeatures disk.DeviceFeatures,
+) (bool, string) {
+	featureFile, err := deviceFeatures.GetNrRequestsFeatureFile(device)
+	if err != nil {
+		return false, err.Error()
+	}
+	if featureFile == "" {
+		return false, fmt.Sprintf("Couldn't find nr_requests for disk '%s'", device)
+	}
+	info, err := fs.Stat(featureFile)
+	if err != nil {
+		return false, err.Error()
+	}
+	if info.Mode().Perm()&0222 == 0 {
+		return false, fmt.Sprintf("nr_requests is read-only for disk '%s'", device)
+	}
+	return true, ""
+}
+
+func tuneNrRequests(
+	fs afero.Fs,
+	device string,
+	target int,
+	deviceFeatures disk.DeviceFeatures,
+	executor executors.Executor,
+) TuneResult {
+	featureFile, err := deviceFeatures.GetNrRequestsFeatureFile(device)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	nrRequests := deviceNrRequestsTarget(deviceFeatures, device, target)
+	log.Debugf("Setting '%s' nr_requests to %d", device, nrRequests)
+	err = executor.Execute(
+		commands.NewWriteFileCmd(fs, featureFile, fmt.Sprint(nrRequests)),
+	)
+	if err != nil {
+		return NewTuneError(err)
+	}
+	return NewTuneResult(false)
+}
+
+func NewNrRequestsTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	target int,
+	blockDevices disk.BlockDevices,
+	executor executors.Executor,
+) Tunable {
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+	return NewDiskTuner(
+		fs,
+		directories,
+		devices,
+		blockDevices,
+		func(device string) Tunable {
+			return NewDeviceNrRequestsTuner(
+				fs,
+				device,
+				target,
+				deviceFeatures,
+				executor,
+			)
+		},
+	)
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+const nrRequestsFile = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue/nr_requests"
+
+func nrRequestsFeatures(fs afero.Fs) *deviceFeaturesMock {
+	return &deviceFeaturesMock{
+		getNrRequestsFeatureFile: func(string) (string, error) {
+			return nrRequestsFile, nil
+		},
+		getNrRequests: func(string) (int, error) {
+			bs, err := afero.ReadFile(fs, nrRequestsFile)
+			if err != nil {
+				return 0, err
+			}
+			return strconv.Atoi(strings.TrimSpace(string(bs)))
+		},
+		getMaxNrRequests: func(string) (int, error) {
+			return 0, nil
+		},
+	}
+}
+
+func TestNrRequestsTarget(t *testing.T) {
+	conf := config.Default().Rpk
+	require.Equal(t, DefaultDiskNrRequests, NrRequestsTarget(conf))
+	nrRequests := 256
+	conf.DiskNrRequests = &nrRequests
+	require.Equal(t, 256, NrRequestsTarget(conf))
+}
+
+func TestDeviceNrRequestsChecker(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, nrRequestsFile, []byte("64\n"), 0644)
+	require.NoError(t, err)
+	checker := NewDeviceNrRequestsChecker("fake", 1024, nrRequestsFeatures(fs))
+	res := checker.Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+	require.Equal(t, "64", res.Current)
+	require.Equal(t, "1024", res.Required)
+}
+
+func TestDeviceNrRequestsTuner_Tune(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, nrRequestsFile, []byte("64\n"), 0644)
+	require.NoError(t, err)
+	tuner := NewDeviceNrRequestsTuner(
+		fs,
+		"fake",
+		1024,
+		nrRequestsFeatures(fs),
+		executors.NewDirectExecutor(),
+	)
+	supported, reason := tuner.CheckIfSupported()
+	require.True(t, supported, reason)
+	res := tuner.Tune()
+	require.False(t, res.IsFailed())
+	setValue, err := afero.ReadFile(fs, nrRequestsFile)
+	require.NoError(t, err)
+	require.Equal(t, "1024", string(setValue))
+}
+
+func TestDeviceNrRequestsTuner_ClampsToQueueDepth(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, nrRequestsFile, []byte("64\n"), 0644)
+	require.NoError(t, err)
+	features := nrRequestsFeatures(fs)
+	// NVMe disks have a queue depth of 1023.
+	features.getMaxNrRequests = func(string) (int, error) {
+		return 1023, nil
+	}
+
+	checker := NewDeviceNrRequestsChecker("fake", 1024, features)
+	res := checker.Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+	require.Equal(t, "1023", res.Required)
+
+	tuner := NewDeviceNrRequestsTuner(
+		fs,
+		"fake",
+		1024,
+		features,
+		executors.NewDirectExecutor(),
+	)
+	tuneRes := tuner.Tune()
+	require.False(t, tuneRes.IsFailed())
+	setValue, err := afero.ReadFile(fs, nrRequestsFile)
+	require.NoError(t, err)
+	require.Equal(t, "1023", string(setValue))
+
+	res = checker.Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+}
+
+func TestDeviceNrRequestsTuner_CheckIfSupported(t *testing.T) {
+	tests := []struct {
+		name           string
+		before         func(afero.Fs)
+		expectedReason string
+	}{
+		{
+			name: "it shouldn't be supported if nr_requests is read-only",
+			before: func(fs afero.Fs) {
+				afero.WriteFile(fs, nrRequestsFile, []byte("64\n"), 0444)
+			},
+			expectedReason: "nr_requests is read-only for disk 'fake'",
+		},
+		{
+			name:           "it shouldn't be supported if nr_requests doesn't exist",
+			before:         func(afero.Fs) {},
+			expectedReason: "Couldn't find nr_requests for disk 'fake'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			tt.before(fs)
+			features := nrRequestsFeatures(fs)
+			features.getNrRequestsFeatureFile = func(string) (string, error) {
+				// Mimic the device features, which return an empty path
+				// if the feature file doesn't exist.
+				if exists, _ := afero.Exists(fs, nrRequestsFile); !exists {
+					return "", nil
+				}
+				return nrRequestsFile, nil
+			}
+			tuner := NewDeviceNrRequestsTuner(
+				fs,
+				"fake",
+				1024,
+				features,
+				executors.NewDirectExecutor(),
+			)
+			supported, reason := tuner.CheckIfSupported()
+			require.False(st, supported)
+			require.Equal(st, tt.expectedReason, reason)
+		})
+	}
+}
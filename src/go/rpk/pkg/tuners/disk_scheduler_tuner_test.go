@@ -27,6 +27,9 @@ type deviceFeaturesMock struct {
 	getScheduler             func(string) (string, error)
 	getWriteCacheFeatureFile func(string) (string, error)
 	getWriteCache            func(string) (string, error)
+	getNrRequestsFeatureFile func(string) (string, error)
+	getNrRequests            func(string) (int, error)
+	getMaxNrRequests         func(string) (int, error)
 }
 
 func (m *deviceFeaturesMock) GetScheduler(device string) (string, error) {
@@ -65,6 +68,20 @@ func (m *deviceFeaturesMock) GetWriteCache(device string) (string, error) {
 	return m.getWriteCache(device)
 }
 
+func (m *deviceFeaturesMock) GetNrRequestsFeatureFile(
+	device string,
+) (string, error) {
+	return m.getNrRequestsFeatureFile(device)
+}
+
+func (m *deviceFeaturesMock) GetNrRequests(device string) (int, error) {
+	return m.getNrRequests(device)
+}
+
+func (m *deviceFeaturesMock) GetMaxNrRequests(device string) (int, error) {
+	return m.getMaxNrRequests(device)
+}
+
 func TestDeviceSchedulerTuner_Tune(t *testing.T) {
 	// given
 	deviceFeatures := &deviceFeaturesMock{
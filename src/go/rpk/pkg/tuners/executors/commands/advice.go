@@ -0,0 +1,44 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type adviceCommand struct {
+	Command
+	lines []string
+}
+
+// NewAdviceCmd returns a command which doesn't change anything, for the
+// tuners whose changes are too risky to apply automatically. Instead, the
+// given lines, e.g. the steps to apply the change by hand, are logged when
+// it's executed, and rendered as comments in the script.
+func NewAdviceCmd(lines ...string) Command {
+	return &adviceCommand{lines: lines}
+}
+
+func (c *adviceCommand) Execute() error {
+	for _, l := range c.lines {
+		log.Warn(l)
+	}
+	return nil
+}
+
+func (c *adviceCommand) RenderScript(w *bufio.Writer) error {
+	for _, l := range c.lines {
+		fmt.Fprintf(w, "# %s\n", l)
+	}
+	return w.Flush()
+}
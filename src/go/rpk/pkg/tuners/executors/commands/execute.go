@@ -42,9 +42,12 @@ func (c *executeCommand) Execute() error {
 }
 
 func (c *executeCommand) RenderScript(w *bufio.Writer) error {
-	fmt.Fprintf(w, "%s \\\n", c.cmd)
+	fmt.Fprint(w, c.cmd)
 	for _, arg := range c.args {
-		fmt.Fprintf(w, " %s \\\n", arg)
+		fmt.Fprintf(w, " \\\n %s", arg)
 	}
+	// The last line can't end with a backslash, otherwise it would be
+	// joined with the next command in the script.
+	fmt.Fprintln(w)
 	return w.Flush()
 }
@@ -44,6 +44,37 @@ var (
 		"swappiness":            (*tunersFactory).newSwappinessTuner,
 		"transparent_hugepages": (*tunersFactory).newTHPTuner,
 		"coredump":              (*tunersFactory).newCoredumpTuner,
+		"swapfile":              (*tunersFactory).newSwapfileTuner,
+		"dirty_pages":           (*tunersFactory).newDirtyPagesTuner,
+		"disk_nr_requests":      (*tunersFactory).newDiskNrRequestsTuner,
+		"nic_queues":            (*tunersFactory).newNicQueuesTuner,
+		"cstates":               (*tunersFactory).newCstatesTuner,
+		"max_map_count":         (*tunersFactory).newMaxMapCountTuner,
+		"fd_limit":              (*tunersFactory).newFdLimitTuner,
+		"mount_options":         (*tunersFactory).newMountOptionsTuner,
+	}
+
+	tunerDescriptions = map[string]string{
+		"disk_irq":              "Distributes the disks' IRQs across the CPUs",
+		"disk_scheduler":        "Sets the disks' I/O scheduler to 'none' or 'noop'",
+		"disk_nomerges":         "Disables merging adjacent I/O requests to the disks",
+		"disk_write_cache":      "Sets the write cache of GCP local SSDs to 'write through'",
+		"fstrim":                "Periodically discards the filesystem's unused blocks",
+		"net":                   "Distributes the NICs' IRQs and queues across the CPUs",
+		"cpu":                   "Sets the CPU governor to 'performance' and disables power saving",
+		"aio_events":            "Raises the maximum number of outstanding async I/O operations",
+		"clocksource":           "Sets the clock source to one readable through the vDSO",
+		"swappiness":            "Keeps process data in memory instead of swapping it out",
+		"transparent_hugepages": "Enables transparent huge pages",
+		"coredump":              "Sets the directory redpanda's core dumps are written to",
+		"swapfile":              "Creates and enables a swap file if swap isn't enabled",
+		"dirty_pages":           "Sets the thresholds at which dirty pages are flushed",
+		"disk_nr_requests":      "Sets the depth of the disks' request queues (nr_requests)",
+		"nic_queues":            "Sets the NIC's combined (RSS) queues to the number of CPUs",
+		"cstates":               "Disables the CPU idle states deeper than max_cstate",
+		"max_map_count":         "Raises the maximum number of memory map areas (vm.max_map_count)",
+		"fd_limit":              "Raises the soft file descriptor limit redpanda starts with to the hard one",
+		"mount_options":         "Reports the recommended mount options for the data directory's filesystem",
 	}
 )
 
@@ -54,6 +85,10 @@ type TunerParams struct {
 	Disks         []string
 	Directories   []string
 	Nics          []string
+	// Set when the tuners run in the process which then starts redpanda
+	// ('rpk redpanda start --tune'), so that the limits they set on it
+	// apply to redpanda.
+	StartsRedpanda bool
 }
 
 type TunersFactory interface {
@@ -71,6 +106,7 @@ type tunersFactory struct {
 	proc              os.Proc
 	grub              system.Grub
 	executor          executors.Executor
+	timeout           time.Duration
 }
 
 func NewDirectExecutorTunersFactory(
@@ -113,6 +149,7 @@ func newTunersFactory(
 		grub:              system.NewGrub(os.NewCommands(proc), proc, fs, executor, timeout),
 		proc:              proc,
 		executor:          executor,
+		timeout:           timeout,
 	}
 }
 
@@ -124,6 +161,12 @@ func AvailableTuners() []string {
 	return keys
 }
 
+// TunerDescription returns a one-line description of what the given tuner
+// does, or an empty string if it isn't available.
+func TunerDescription(tuner string) string {
+	return tunerDescriptions[tuner]
+}
+
 func IsTunerAvailable(tuner string) bool {
 	return allTuners[tuner] != nil
 }
@@ -154,6 +197,22 @@ func IsTunerEnabled(tuner string, rpkConfig config.RpkConfig) bool {
 		return rpkConfig.TuneTransparentHugePages
 	case "coredump":
 		return rpkConfig.TuneCoredump
+	case "swapfile":
+		return rpkConfig.TuneSwapfile
+	case "dirty_pages":
+		return rpkConfig.TuneDirtyPages
+	case "disk_nr_requests":
+		return rpkConfig.TuneDiskNrRequests
+	case "nic_queues":
+		return rpkConfig.TuneNicQueues
+	case "cstates":
+		return rpkConfig.TuneCstates
+	case "max_map_count":
+		return rpkConfig.TuneMaxMapCount
+	case "fd_limit":
+		return rpkConfig.TuneFdLimit
+	case "mount_options":
+		return rpkConfig.TuneMountOptions
 	}
 	return false
 }
@@ -208,6 +267,19 @@ func (factory *tunersFactory) newDiskNomergesTuner(
 	)
 }
 
+func (factory *tunersFactory) newDiskNrRequestsTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewNrRequestsTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		tuners.NrRequestsTarget(factory.conf.Rpk),
+		factory.blockDevices,
+		factory.executor,
+	)
+}
+
 func (factory *tunersFactory) newGcpWriteCacheTuner(
 	params *TunerParams,
 ) tuners.Tunable {
@@ -259,7 +331,7 @@ func (factory *tunersFactory) newCpuTuner(params *TunerParams) tuners.Tunable {
 func (factory *tunersFactory) newMaxAIOEventsTuner(
 	params *TunerParams,
 ) tuners.Tunable {
-	return tuners.NewMaxAIOEventsTuner(factory.fs, factory.executor)
+	return tuners.NewMaxAIOEventsTuner(factory.fs, factory.conf.Rpk, factory.executor)
 }
 
 func (factory *tunersFactory) newClockSourceTuner(
@@ -274,6 +346,34 @@ func (factory *tunersFactory) newSwappinessTuner(
 	return tuners.NewSwappinessTuner(factory.fs, factory.executor)
 }
 
+func (factory *tunersFactory) newMaxMapCountTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewMaxMapCountTuner(factory.fs, factory.executor)
+}
+
+func (factory *tunersFactory) newFdLimitTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewFdLimitTuner(
+		tuners.MinFdLimitTarget(factory.conf.Rpk),
+		system.GetFdLimit,
+		system.SetFdLimit,
+		params.StartsRedpanda,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newMountOptionsTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewMountOptionsTuner(
+		factory.fs,
+		factory.conf.Redpanda.Directory,
+		factory.executor,
+	)
+}
+
 func (factory *tunersFactory) newTHPTuner(_ *TunerParams) tuners.Tunable {
 	return tuners.NewEnableTHPTuner(factory.fs, factory.executor)
 }
@@ -284,6 +384,47 @@ func (factory *tunersFactory) newCoredumpTuner(
 	return coredump.NewCoredumpTuner(factory.fs, factory.conf, factory.executor)
 }
 
+func (factory *tunersFactory) newSwapfileTuner(_ *TunerParams) tuners.Tunable {
+	return tuners.NewSwapfileTuner(
+		factory.fs,
+		factory.conf.Rpk,
+		factory.proc,
+		factory.executor,
+		factory.timeout,
+	)
+}
+
+func (factory *tunersFactory) newDirtyPagesTuner(_ *TunerParams) tuners.Tunable {
+	return tuners.NewDirtyPagesTuner(factory.fs, factory.conf.Rpk, factory.executor)
+}
+
+func (factory *tunersFactory) newNicQueuesTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewNicQueuesTuner(
+		factory.conf.Rpk.NicQueuesInterface,
+		func() (int, error) {
+			mask, err := factory.cpuMasks.BaseCpuMask(params.CpuMask)
+			if err != nil {
+				return 0, err
+			}
+			pus, err := factory.cpuMasks.GetNumberOfPUs(mask)
+			return int(pus), err
+		},
+		factory.proc,
+		factory.executor,
+		factory.timeout,
+	)
+}
+
+func (factory *tunersFactory) newCstatesTuner(_ *TunerParams) tuners.Tunable {
+	return tuners.NewCstatesTuner(
+		factory.fs,
+		tuners.MaxCstateTarget(factory.conf.Rpk),
+		factory.executor,
+	)
+}
+
 func MergeTunerParamsConfig(
 	params *TunerParams, conf *config.Config,
 ) (*TunerParams, error) {
@@ -10,6 +10,7 @@
 package factory_test
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -66,3 +67,25 @@ func TestMergeTunerParamsConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestTunerDescription(t *testing.T) {
+	for _, tuner := range factory.AvailableTuners() {
+		require.NotEmpty(t, factory.TunerDescription(tuner), tuner)
+	}
+	require.Empty(t, factory.TunerDescription("unknown"))
+}
+
+func TestModeTuners(t *testing.T) {
+	available := factory.AvailableTuners()
+	sort.Strings(available)
+	require.Equal(t, available, config.TunerNames())
+	for _, tuner := range available {
+		conf, err := config.SetMode(config.ModeDev+":"+tuner, config.Default())
+		require.NoError(t, err, tuner)
+		require.True(t, factory.IsTunerEnabled(tuner, conf.Rpk), tuner)
+
+		conf, err = config.SetMode(config.ModeProd+":no-"+tuner, config.Default())
+		require.NoError(t, err, tuner)
+		require.False(t, factory.IsTunerEnabled(tuner, conf.Rpk), tuner)
+	}
+}
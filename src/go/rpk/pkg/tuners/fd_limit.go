@@ -0,0 +1,135 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+// The minimum file descriptor limit if rpk.min_fd_limit isn't set. Redpanda
+// keeps every segment file open, so the usual default (1024) runs out.
+const DefaultMinFdLimit = 1048576
+
+// MinFdLimitTarget returns the minimum soft file descriptor limit redpanda
+// should run with.
+func MinFdLimitTarget(conf config.RpkConfig) int {
+	if conf.MinFdLimit != nil {
+		return *conf.MinFdLimit
+	}
+	return DefaultMinFdLimit
+}
+
+// NewFdLimitChecker returns a checker which warns if the soft file descriptor
+// limit, as returned by getLimit, is below target. Redpanda inherits the
+// limit of the rpk process which starts it, so it's the one checked.
+func NewFdLimitChecker(
+	target int, getLimit func() (system.FdLimit, error),
+) Checker {
+	return &fdLimitChecker{target: target, getLimit: getLimit}
+}
+
+type fdLimitChecker struct {
+	target   int
+	getLimit func() (system.FdLimit, error)
+}
+
+func (c *fdLimitChecker) Id() CheckerID {
+	return FdLimitChecker
+}
+
+func (c *fdLimitChecker) GetDesc() string {
+	return "File descriptor limit (soft)"
+}
+
+func (c *fdLimitChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *fdLimitChecker) GetRequiredAsString() string {
+	return fmt.Sprintf(">= %d", c.target)
+}
+
+func (c *fdLimitChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	limit, err := c.getLimit()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.IsOk = limit.Soft >= uint64(c.target)
+	res.Current = fmt.Sprint(limit.Soft)
+	return res
+}
+
+// NewFdLimitTuner raises the soft file descriptor limit up to the hard one
+// if it's below target. The limit only applies to rpk's process and the ones
+// it starts, so the tuner is reported as unsupported unless startsRedpanda is
+// set, i.e. unless it's run by 'rpk redpanda start --tune'. It's also
+// unsupported if the hard limit is below target, since only a privileged
+// process can raise it.
+func NewFdLimitTuner(
+	target int,
+	getLimit func() (system.FdLimit, error),
+	setLimit func(system.FdLimit) error,
+	startsRedpanda bool,
+	executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewFdLimitChecker(target, getLimit),
+		func() TuneResult {
+			limit, err := getLimit()
+			if err != nil {
+				return NewTuneError(err)
+			}
+			log.Debugf(
+				"Raising the soft file descriptor limit from %d to %d",
+				limit.Soft,
+				limit.Hard,
+			)
+			err = setLimit(system.FdLimit{Soft: limit.Hard, Hard: limit.Hard})
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			if !startsRedpanda || executor.IsLazy() {
+				return false, "The file descriptor limit applies to rpk's" +
+					" process, so it can only be raised by 'rpk redpanda" +
+					" start --tune'"
+			}
+			limit, err := getLimit()
+			if err != nil {
+				return false, err.Error()
+			}
+			if limit.Hard < uint64(target) {
+				return false, fmt.Sprintf(
+					"The hard file descriptor limit (%d) is below %d."+
+						" Raise it, e.g. with LimitNOFILE in redpanda's"+
+						" systemd unit",
+					limit.Hard,
+					target,
+				)
+			}
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+// A process' file descriptor limit, which the tuner can raise as a process
+// would with setrlimit(2).
+type fakeFdLimit struct {
+	limit  system.FdLimit
+	getErr error
+	setErr error
+}
+
+func (f *fakeFdLimit) get() (system.FdLimit, error) {
+	return f.limit, f.getErr
+}
+
+func (f *fakeFdLimit) set(l system.FdLimit) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	if l.Soft > l.Hard || l.Hard > f.limit.Hard {
+		return errors.New("operation not permitted")
+	}
+	f.limit = l
+	return nil
+}
+
+func TestMinFdLimitTarget(t *testing.T) {
+	conf := config.Default()
+	require.Equal(t, tuners.DefaultMinFdLimit, tuners.MinFdLimitTarget(conf.Rpk))
+	min := 65536
+	conf.Rpk.MinFdLimit = &min
+	require.Equal(t, 65536, tuners.MinFdLimitTarget(conf.Rpk))
+}
+
+func TestFdLimitChecker(t *testing.T) {
+	tests := []struct {
+		name            string
+		limit           fakeFdLimit
+		expectedOk      bool
+		expectedCurrent string
+		expectedErr     string
+	}{
+		{
+			name:            "it should pass if the soft limit is the minimum",
+			limit:           fakeFdLimit{limit: system.FdLimit{Soft: 1048576, Hard: 1048576}},
+			expectedOk:      true,
+			expectedCurrent: "1048576",
+		},
+		{
+			name:            "it should fail if the soft limit is below the minimum",
+			limit:           fakeFdLimit{limit: system.FdLimit{Soft: 1024, Hard: 1048576}},
+			expectedCurrent: "1024",
+		},
+		{
+			name:        "it should fail if the limit can't be read",
+			limit:       fakeFdLimit{getErr: errors.New("invalid argument")},
+			expectedErr: "invalid argument",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			res := tuners.NewFdLimitChecker(1048576, tt.limit.get).Check()
+			if tt.expectedErr != "" {
+				require.EqualError(st, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.Equal(st, ">= 1048576", res.Required)
+			require.EqualValues(st, tuners.Warning, res.Severity)
+		})
+	}
+}
+
+func TestFdLimitTuner(t *testing.T) {
+	tests := []struct {
+		name              string
+		limit             fakeFdLimit
+		notStart          bool
+		expectedSupported bool
+		expectedReason    string
+		expectedLimit     system.FdLimit
+		expectedErr       string
+	}{
+		{
+			name:              "it should raise the soft limit to the hard one",
+			limit:             fakeFdLimit{limit: system.FdLimit{Soft: 1024, Hard: 2097152}},
+			expectedSupported: true,
+			expectedLimit:     system.FdLimit{Soft: 2097152, Hard: 2097152},
+		},
+		{
+			name:              "it shouldn't change the limit if it's above the minimum",
+			limit:             fakeFdLimit{limit: system.FdLimit{Soft: 1048576, Hard: 2097152}},
+			expectedSupported: true,
+			expectedLimit:     system.FdLimit{Soft: 1048576, Hard: 2097152},
+		},
+		{
+			name:           "it should be unsupported if the hard limit is below the minimum",
+			limit:          fakeFdLimit{limit: system.FdLimit{Soft: 1024, Hard: 4096}},
+			expectedReason: "The hard file descriptor limit (4096) is below 1048576. Raise it, e.g. with LimitNOFILE in redpanda's systemd unit",
+		},
+		{
+			name:           "it should be unsupported outside of start",
+			limit:          fakeFdLimit{limit: system.FdLimit{Soft: 1024, Hard: 2097152}},
+			notStart:       true,
+			expectedReason: "The file descriptor limit applies to rpk's process, so it can only be raised by 'rpk redpanda start --tune'",
+		},
+		{
+			name: "it should fail if the limit can't be set",
+			limit: fakeFdLimit{
+				limit:  system.FdLimit{Soft: 1024, Hard: 2097152},
+				setErr: errors.New("operation not permitted"),
+			},
+			expectedSupported: true,
+			expectedErr:       "operation not permitted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			limit := tt.limit
+			tuner := tuners.NewFdLimitTuner(
+				1048576,
+				limit.get,
+				limit.set,
+				!tt.notStart,
+				executors.NewDirectExecutor(),
+			)
+			supported, reason := tuner.CheckIfSupported()
+			require.Equal(st, tt.expectedSupported, supported)
+			require.Equal(st, tt.expectedReason, reason)
+			if !supported {
+				return
+			}
+			res := tuner.Tune()
+			if tt.expectedErr != "" {
+				require.EqualError(st, res.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, res.Error())
+			require.False(st, res.IsRebootRequired())
+			require.Equal(st, tt.expectedLimit, limit.limit)
+		})
+	}
+}
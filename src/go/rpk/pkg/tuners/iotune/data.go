@@ -11,6 +11,7 @@ package iotune
 
 import (
 	"fmt"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cloud/vendor"
@@ -56,6 +57,24 @@ func DataForVendor(
 	return DataFor(mountpoint, v.Name(), vmType, "default")
 }
 
+// WellKnownIos returns the sorted list of <vendor>:<vm>:<storage> settings
+// for which there's precompiled data, as accepted by rpk.well_known_io.
+func WellKnownIos() []string {
+	settings := []string{}
+	for v, vms := range precompiledData() {
+		for vm, storages := range vms {
+			for storage := range storages {
+				settings = append(
+					settings,
+					fmt.Sprintf("%s:%s:%s", v, vm, storage),
+				)
+			}
+		}
+	}
+	sort.Strings(settings)
+	return settings
+}
+
 func ToYaml(props IoProperties) (string, error) {
 	type ioPropertiesWrapper struct {
 		Disks []IoProperties `yaml:"disks"`
@@ -0,0 +1,90 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package iotune
+
+import (
+	"fmt"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// The fields each disk in the IO properties file must have, and which must be
+// positive.
+var requiredProperties = []string{
+	"read_iops",
+	"read_bandwidth",
+	"write_iops",
+	"write_bandwidth",
+}
+
+// ValidateFile reads the IO properties file at path, as generated by iotune,
+// and returns the problems found in it, if any.
+func ValidateFile(fs afero.Fs, path string) []error {
+	bs, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return []error{err}
+	}
+	props := struct {
+		Disks []map[string]interface{} `yaml:"disks"`
+	}{}
+	err = yaml.Unmarshal(bs, &props)
+	if err != nil {
+		return []error{fmt.Errorf("couldn't parse the file: %v", err)}
+	}
+	if len(props.Disks) == 0 {
+		return []error{fmt.Errorf("no disks found")}
+	}
+	errs := []error{}
+	for i, disk := range props.Disks {
+		if mp, ok := disk["mountpoint"].(string); !ok || mp == "" {
+			errs = append(errs, fmt.Errorf("disks.%d.mountpoint is missing", i))
+		}
+		for _, field := range requiredProperties {
+			val, ok := disk[field]
+			if !ok || val == nil {
+				errs = append(errs, fmt.Errorf("disks.%d.%s is missing", i, field))
+				continue
+			}
+			n, ok := toInt64(val)
+			if !ok {
+				errs = append(errs, fmt.Errorf(
+					"disks.%d.%s must be an integer, but got '%v'",
+					i,
+					field,
+					val,
+				))
+				continue
+			}
+			if n <= 0 {
+				errs = append(errs, fmt.Errorf(
+					"disks.%d.%s must be positive, but got %d",
+					i,
+					field,
+					n,
+				))
+			}
+		}
+	}
+	return errs
+}
+
+func toInt64(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		// yaml.v2 only decodes values greater than math.MaxInt64 as uint64.
+		return int64(^uint64(0) >> 1), true
+	}
+	return 0, false
+}
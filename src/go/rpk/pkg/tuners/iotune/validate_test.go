@@ -0,0 +1,111 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package iotune_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/iotune"
+)
+
+func TestValidateFile(t *testing.T) {
+	const path = "/etc/redpanda/io-config.yaml"
+	tests := []struct {
+		name        string
+		content     string
+		noFile      bool
+		expectedErr []string
+	}{
+		{
+			name: "it should pass for a valid file",
+			content: `disks:
+- mountpoint: /var/lib/redpanda/data
+  read_iops: 411200
+  read_bandwidth: 2015342735
+  write_iops: 181500
+  write_bandwidth: 808775652
+`,
+		},
+		{
+			name: "it should pass for the files generated by rpk",
+			content: func() string {
+				props, err := iotune.DataFor("/mnt/data", "aws", "i3.metal", "default")
+				require.NoError(t, err)
+				yaml, err := iotune.ToYaml(*props)
+				require.NoError(t, err)
+				return yaml
+			}(),
+		},
+		{
+			name:        "it should fail if the file doesn't exist",
+			noFile:      true,
+			expectedErr: []string{"open " + path + ": file does not exist"},
+		},
+		{
+			name:        "it should fail if the file isn't valid YAML",
+			content:     "disks: [",
+			expectedErr: []string{"couldn't parse the file: yaml:"},
+		},
+		{
+			name:        "it should fail if there are no disks",
+			content:     "disks: []\n",
+			expectedErr: []string{"no disks found"},
+		},
+		{
+			name: "it should fail if fields are missing",
+			content: `disks:
+- read_iops: 411200
+  write_iops: 181500
+`,
+			expectedErr: []string{
+				"disks.0.mountpoint is missing",
+				"disks.0.read_bandwidth is missing",
+				"disks.0.write_bandwidth is missing",
+			},
+		},
+		{
+			name: "it should fail if values aren't positive integers",
+			content: `disks:
+- mountpoint: /var/lib/redpanda/data
+  read_iops: 411200
+  read_bandwidth: 2015342735
+  write_iops: 181500
+  write_bandwidth: 808775652
+- mountpoint: /mnt/data
+  read_iops: 0
+  read_bandwidth: fast
+  write_iops: -10
+  write_bandwidth: 1.5
+`,
+			expectedErr: []string{
+				"disks.1.read_iops must be positive, but got 0",
+				"disks.1.read_bandwidth must be an integer, but got 'fast'",
+				"disks.1.write_iops must be positive, but got -10",
+				"disks.1.write_bandwidth must be an integer, but got '1.5'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if !tt.noFile {
+				err := afero.WriteFile(fs, path, []byte(tt.content), 0644)
+				require.NoError(t, err)
+			}
+			errs := iotune.ValidateFile(fs, path)
+			require.Len(t, errs, len(tt.expectedErr))
+			for i, err := range errs {
+				require.Contains(t, err.Error(), tt.expectedErr[i])
+			}
+		})
+	}
+}
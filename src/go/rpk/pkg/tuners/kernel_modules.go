@@ -0,0 +1,167 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+)
+
+const (
+	procModulesFile = "/proc/modules"
+	sysModuleDir    = "/sys/module"
+)
+
+// The kernel modules backing each filesystem type.
+var fsTypeModules = map[string]string{
+	"xfs":   "xfs",
+	"ext4":  "ext4",
+	"btrfs": "btrfs",
+}
+
+// The kernel modules backing each kind of block device, keyed by the prefix
+// of the device's name.
+var devicePrefixModules = []struct {
+	prefix string
+	module string
+}{
+	{"nvme", "nvme"},
+	{"xvd", "xen_blkfront"},
+	{"vd", "virtio_blk"},
+	{"sd", "sd_mod"},
+}
+
+// Returns the kernel modules needed for the given device (e.g.
+// '/dev/nvme0n1p1') and filesystem type.
+func requiredKernelModules(device, fsType string) []string {
+	modules := []string{}
+	if m, ok := fsTypeModules[fsType]; ok {
+		modules = append(modules, m)
+	}
+	name := filepath.Base(device)
+	for _, dm := range devicePrefixModules {
+		if strings.HasPrefix(name, dm.prefix) {
+			modules = append(modules, dm.module)
+			break
+		}
+	}
+	return modules
+}
+
+// NewKernelModulesChecker returns a checker which warns if the filesystem
+// the data directory is on, as configured in /etc/fstab, isn't mounted and the
+// kernel modules needed to mount it (e.g. 'xfs' and 'nvme') aren't loaded, as
+// can happen on minimal distros. Once the filesystem is mounted, the modules
+// are necessarily loaded.
+func NewKernelModulesChecker(fs afero.Fs, dataDir string) Checker {
+	return &kernelModulesChecker{fs: fs, dataDir: dataDir}
+}
+
+type kernelModulesChecker struct {
+	fs      afero.Fs
+	dataDir string
+}
+
+func (c *kernelModulesChecker) Id() CheckerID {
+	return KernelModulesChecker
+}
+
+func (c *kernelModulesChecker) GetDesc() string {
+	return "Data directory's kernel modules loaded"
+}
+
+func (c *kernelModulesChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *kernelModulesChecker) GetRequiredAsString() string {
+	return "loaded"
+}
+
+func (c *kernelModulesChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	entry, err := filesystem.GetFstabEntry(c.fs, c.dataDir)
+	if err != nil {
+		log.Debugf("Couldn't get the data directory's fstab entry: %v", err)
+		res.Current = string(filesystem.Unknown)
+		res.IsOk = true
+		return res
+	}
+	mount, err := filesystem.GetMount(c.fs, c.dataDir)
+	if err == nil && mount.MountPoint == entry.MountPoint {
+		res.Current = "mounted"
+		res.IsOk = true
+		return res
+	}
+	required := requiredKernelModules(entry.Device, entry.FsType)
+	if len(required) == 0 {
+		res.Current = "none required"
+		res.IsOk = true
+		return res
+	}
+	res.Required = strings.Join(required, ", ")
+	loaded, err := c.loadedModules()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	missing := []string{}
+	for _, m := range required {
+		if !loaded[m] && !c.isBuiltin(m) {
+			missing = append(missing, m)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		res.Current = fmt.Sprintf(
+			"%s isn't mounted, missing: %s",
+			entry.MountPoint,
+			strings.Join(missing, ", "),
+		)
+		return res
+	}
+	res.Current = res.Required
+	res.IsOk = true
+	return res
+}
+
+// Returns the modules listed in /proc/modules.
+func (c *kernelModulesChecker) loadedModules() (map[string]bool, error) {
+	bs, err := afero.ReadFile(c.fs, procModulesFile)
+	if err != nil {
+		return nil, err
+	}
+	loaded := map[string]bool{}
+	for _, line := range strings.Split(string(bs), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			loaded[fields[0]] = true
+		}
+	}
+	return loaded, nil
+}
+
+// Modules built into the kernel aren't listed in /proc/modules, but most of
+// them have a directory under /sys/module.
+func (c *kernelModulesChecker) isBuiltin(module string) bool {
+	exists, _ := afero.DirExists(c.fs, filepath.Join(sysModuleDir, module))
+	return exists
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredKernelModules(t *testing.T) {
+	require.Equal(t, []string{"xfs", "nvme"}, requiredKernelModules("/dev/nvme0n1p1", "xfs"))
+	require.Equal(t, []string{"ext4", "sd_mod"}, requiredKernelModules("/dev/sdb1", "ext4"))
+	require.Equal(t, []string{"xfs", "xen_blkfront"}, requiredKernelModules("/dev/xvdb", "xfs"))
+	require.Equal(t, []string{}, requiredKernelModules("tmpfs", "tmpfs"))
+}
+
+func TestKernelModulesChecker(t *testing.T) {
+	const (
+		dataDir = "/var/lib/redpanda/data"
+		fstab   = `UUID=2f9c1a7e / ext4 defaults 0 1
+/dev/nvme0n1 /var/lib/redpanda xfs defaults,noatime 0 0
+`
+		unmounted = "/dev/sda1 / ext4 rw 0 0\n"
+	)
+	tests := []struct {
+		name            string
+		fstab           string
+		mounts          string
+		modules         string
+		builtin         []string
+		expectedOk      bool
+		expectedCurrent string
+	}{
+		{
+			name:   "it should pass if the filesystem is mounted",
+			fstab:  fstab,
+			mounts: unmounted + "/dev/nvme0n1 /var/lib/redpanda xfs rw,noatime 0 0\n",
+			// Mounting it needs the modules, whatever /proc/modules says.
+			modules:         "",
+			expectedOk:      true,
+			expectedCurrent: "mounted",
+		},
+		{
+			name:   "it should pass if the modules are loaded",
+			fstab:  fstab,
+			mounts: unmounted,
+			modules: `xfs 1503232 1 - Live 0x0000000000000000
+nvme 49152 2 - Live 0x0000000000000000
+nvme_core 126976 3 nvme, Live 0x0000000000000000
+`,
+			expectedOk:      true,
+			expectedCurrent: "xfs, nvme",
+		},
+		{
+			name:   "it should fail if the modules of an unmounted filesystem aren't loaded",
+			fstab:  fstab,
+			mounts: unmounted,
+			modules: `nvme 49152 2 - Live 0x0000000000000000
+nvme_core 126976 3 nvme, Live 0x0000000000000000
+`,
+			expectedCurrent: "/var/lib/redpanda isn't mounted, missing: xfs",
+		},
+		{
+			name:            "it should pass if the modules are built into the kernel",
+			fstab:           fstab,
+			mounts:          unmounted,
+			modules:         "nvme 49152 2 - Live 0x0000000000000000\n",
+			builtin:         []string{"xfs"},
+			expectedOk:      true,
+			expectedCurrent: "xfs, nvme",
+		},
+		{
+			name:            "it should pass if fstab can't be read",
+			mounts:          unmounted,
+			expectedOk:      true,
+			expectedCurrent: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.fstab != "" {
+				err := afero.WriteFile(fs, "/etc/fstab", []byte(tt.fstab), 0644)
+				require.NoError(st, err)
+			}
+			err := afero.WriteFile(fs, "/proc/self/mounts", []byte(tt.mounts), 0644)
+			require.NoError(st, err)
+			err = afero.WriteFile(fs, procModulesFile, []byte(tt.modules), 0644)
+			require.NoError(st, err)
+			for _, m := range tt.builtin {
+				err = fs.MkdirAll(sysModuleDir+"/"+m, 0755)
+				require.NoError(st, err)
+			}
+			res := NewKernelModulesChecker(fs, dataDir).Check()
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+		})
+	}
+}
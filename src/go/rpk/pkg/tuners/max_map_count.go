@@ -0,0 +1,71 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+const (
+	// The minimum number of memory map areas a process may have. Seastar
+	// maps memory per shard, and the default (65530) can be exhausted,
+	// crashing redpanda with "too many open memory mappings".
+	MinMaxMapCount  = 1048576
+	maxMapCountFile = "/proc/sys/vm/max_map_count"
+)
+
+func NewMaxMapCountChecker(fs afero.Fs) Checker {
+	return NewIntChecker(
+		MaxMapCountChecker,
+		"Max memory map areas (vm.max_map_count)",
+		Warning,
+		func(current int) bool {
+			return current >= MinMaxMapCount
+		},
+		func() string {
+			return fmt.Sprintf(">= %d", MinMaxMapCount)
+		},
+		func() (int, error) {
+			return utils.ReadIntFromFile(fs, maxMapCountFile)
+		},
+	)
+}
+
+// NewMaxMapCountTuner raises vm.max_map_count to MinMaxMapCount if it's below
+// it.
+func NewMaxMapCountTuner(fs afero.Fs, executor executors.Executor) Tunable {
+	return NewCheckedTunable(
+		NewMaxMapCountChecker(fs),
+		func() TuneResult {
+			log.Debugf("Setting vm.max_map_count to %d", MinMaxMapCount)
+			err := executor.Execute(
+				commands.NewWriteFileCmd(
+					fs,
+					maxMapCountFile,
+					fmt.Sprint(MinMaxMapCount),
+				),
+			)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+const maxMapCountFile = "/proc/sys/vm/max_map_count"
+
+func TestMaxMapCountChecker(t *testing.T) {
+	tests := []struct {
+		name            string
+		current         string
+		expectOk        bool
+		expectedCurrent string
+		expectErr       bool
+	}{
+		{
+			name:            "it should pass if the value is above the minimum",
+			current:         "2097152",
+			expectOk:        true,
+			expectedCurrent: "2097152",
+		},
+		{
+			name:            "it should pass if the value is the minimum",
+			current:         fmt.Sprint(tuners.MinMaxMapCount),
+			expectOk:        true,
+			expectedCurrent: fmt.Sprint(tuners.MinMaxMapCount),
+		},
+		{
+			name:            "it should fail if the value is the kernel's default",
+			current:         "65530",
+			expectedCurrent: "65530",
+		},
+		{
+			name:      "it should fail if the file doesn't exist",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.current != "" {
+				_, err := utils.WriteBytes(fs, []byte(tt.current), maxMapCountFile)
+				require.NoError(st, err)
+			}
+			res := tuners.NewMaxMapCountChecker(fs).Check()
+			if tt.expectErr {
+				require.Error(st, res.Err)
+				return
+			}
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.EqualValues(st, tuners.Warning, res.Severity)
+			require.Equal(st, ">= 1048576", res.Required)
+		})
+	}
+}
+
+func TestMaxMapCountTuner(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "it should leave the value if it's above the minimum",
+			current:  "2097152",
+			expected: "2097152",
+		},
+		{
+			name:     "it should raise the value if it's below the minimum",
+			current:  "65530",
+			expected: fmt.Sprint(tuners.MinMaxMapCount),
+		},
+		{
+			name:      "it should fail if the file doesn't exist",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.current != "" {
+				_, err := utils.WriteBytes(fs, []byte(tt.current), maxMapCountFile)
+				require.NoError(st, err)
+			}
+			tuner := tuners.NewMaxMapCountTuner(fs, executors.NewDirectExecutor())
+			res := tuner.Tune()
+			if tt.expectErr {
+				require.Error(st, res.Error())
+				return
+			}
+			require.NoError(st, res.Error())
+			lines, err := utils.ReadFileLines(fs, maxMapCountFile)
+			require.NoError(st, err)
+			require.Equal(st, []string{tt.expected}, lines)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+)
+
+const (
+	// The share of the memory left to the OS and the page cache below which
+	// the headroom checker warns, unless rpk.os_memory_headroom_percent is
+	// set.
+	DefaultOSMemoryHeadroomPercent = 10
+
+	// Seastar reserves 7% of the memory (and at least 1.5GiB) for the OS
+	// when --memory and --reserve-memory aren't set.
+	seastarReservePercent = 7
+	seastarMinReserveMB   = 1536
+)
+
+// Returns the minimum share of the memory, as a percentage, redpanda should
+// leave to the OS.
+func OSMemoryHeadroomTarget(conf config.RpkConfig) int {
+	if conf.OSMemoryHeadroomPercent != nil {
+		return *conf.OSMemoryHeadroomPercent
+	}
+	return DefaultOSMemoryHeadroomPercent
+}
+
+// NewMemoryHeadroomChecker returns a checker which warns if the memory
+// allocated to redpanda, through --memory (or the total minus
+// --reserve-memory) in rpk.additional_start_flags, leaves less than
+// minPercent of the total memory to the OS and the page cache.
+func NewMemoryHeadroomChecker(
+	memTotalMB func() (int, error), startFlags []string, minPercent int,
+) Checker {
+	memory := startFlagValue(startFlags, "memory")
+	reserve := startFlagValue(startFlags, "reserve-memory")
+	return NewIntChecker(
+		MemoryHeadroomChecker,
+		"Memory left to the OS [%]",
+		Warning,
+		func(current int) bool {
+			return current >= minPercent
+		},
+		func() string {
+			return fmt.Sprintf(">= %d", minPercent)
+		},
+		func() (int, error) {
+			totalMB, err := memTotalMB()
+			if err != nil {
+				return 0, err
+			}
+			if totalMB <= 0 {
+				return 0, fmt.Errorf("invalid total memory %dMB", totalMB)
+			}
+			redpandaMB, err := redpandaMemoryMB(memory, reserve, totalMB)
+			if err != nil {
+				return 0, err
+			}
+			return (totalMB - redpandaMB) * 100 / totalMB, nil
+		},
+	)
+}
+
+// Returns the memory redpanda will allocate, the same way Seastar resolves
+// it from --memory and --reserve-memory.
+func redpandaMemoryMB(memory, reserve string, totalMB int) (int, error) {
+	if memory != "" {
+		bytes, err := units.RAMInBytes(memory)
+		if err != nil {
+			return 0, fmt.Errorf("couldn't parse --memory '%s': %v", memory, err)
+		}
+		return int(bytes / units.MiB), nil
+	}
+	if reserve != "" {
+		bytes, err := units.RAMInBytes(reserve)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"couldn't parse --reserve-memory '%s': %v",
+				reserve,
+				err,
+			)
+		}
+		return totalMB - int(bytes/units.MiB), nil
+	}
+	reserveMB := totalMB * seastarReservePercent / 100
+	if reserveMB < seastarMinReserveMB {
+		reserveMB = seastarMinReserveMB
+	}
+	return totalMB - reserveMB, nil
+}
+
+// Returns the value of the given flag in a list of start flags, whether it's
+// passed as --name=value or --name value, or an empty string if it's not set.
+func startFlagValue(flags []string, name string) string {
+	for i, f := range flags {
+		if !strings.HasPrefix(f, "-") {
+			continue
+		}
+		trimmed := strings.Trim(f, " -")
+		if strings.HasPrefix(trimmed, name+"=") {
+			return strings.TrimSpace(strings.TrimPrefix(trimmed, name+"="))
+		}
+		if parts := strings.Fields(trimmed); len(parts) == 2 && parts[0] == name {
+			return parts[1]
+		}
+		if trimmed == name && i+1 < len(flags) &&
+			!strings.HasPrefix(flags[i+1], "-") {
+			return strings.TrimSpace(flags[i+1])
+		}
+	}
+	return ""
+}
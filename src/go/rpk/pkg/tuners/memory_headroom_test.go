@@ -0,0 +1,115 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+func TestMemoryHeadroomChecker(t *testing.T) {
+	// 16GiB in total.
+	memTotal := func() (int, error) { return 16384, nil }
+	tests := []struct {
+		name            string
+		startFlags      []string
+		minPercent      int
+		memTotal        func() (int, error)
+		expectedOk      bool
+		expectedCurrent string
+		expectedErr     string
+	}{
+		{
+			name:            "it should pass if --memory leaves enough for the OS",
+			startFlags:      []string{"--memory=12G"},
+			minPercent:      10,
+			expectedOk:      true,
+			expectedCurrent: "25",
+		},
+		{
+			name:            "it should fail if --memory takes all of the memory",
+			startFlags:      []string{"--smp", "4", "--memory", "16G"},
+			minPercent:      10,
+			expectedCurrent: "0",
+		},
+		{
+			name:            "it should fail if --memory leaves less than the minimum",
+			startFlags:      []string{"--memory=15G"},
+			minPercent:      10,
+			expectedCurrent: "6",
+		},
+		{
+			name:            "it should use the total minus --reserve-memory",
+			startFlags:      []string{"--reserve-memory=4G"},
+			minPercent:      20,
+			expectedOk:      true,
+			expectedCurrent: "25",
+		},
+		{
+			name:            "it should use Seastar's reservation if --memory isn't set",
+			minPercent:      10,
+			expectedOk:      false,
+			expectedCurrent: "9",
+		},
+		{
+			name:        "it should fail if --memory can't be parsed",
+			startFlags:  []string{"--memory=lots"},
+			minPercent:  10,
+			expectedErr: "couldn't parse --memory 'lots'",
+		},
+		{
+			name:       "it should fail if the total memory can't be read",
+			startFlags: []string{"--memory=12G"},
+			minPercent: 10,
+			memTotal: func() (int, error) {
+				return 0, errors.New("no such file or directory")
+			},
+			expectedErr: "no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			total := memTotal
+			if tt.memTotal != nil {
+				total = tt.memTotal
+			}
+			res := tuners.NewMemoryHeadroomChecker(
+				total,
+				tt.startFlags,
+				tt.minPercent,
+			).Check()
+			if tt.expectedErr != "" {
+				require.Error(st, res.Err)
+				require.Contains(st, res.Err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.Equal(st, tuners.Severity(tuners.Warning), res.Severity)
+		})
+	}
+}
+
+func TestOSMemoryHeadroomTarget(t *testing.T) {
+	conf := config.Default()
+	require.Equal(
+		t,
+		tuners.DefaultOSMemoryHeadroomPercent,
+		tuners.OSMemoryHeadroomTarget(conf.Rpk),
+	)
+	percent := 25
+	conf.Rpk.OSMemoryHeadroomPercent = &percent
+	require.Equal(t, 25, tuners.OSMemoryHeadroomTarget(conf.Rpk))
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// The options which set when the access times are updated. noatime, which
+// also implies nodiratime, replaces them in the recommended options.
+var atimeMountOptions = map[string]bool{
+	"atime":         true,
+	"noatime":       true,
+	"relatime":      true,
+	"norelatime":    true,
+	"strictatime":   true,
+	"nostrictatime": true,
+	"diratime":      true,
+	"nodiratime":    true,
+}
+
+// The options which disable the write barriers, which the filesystem relies
+// on to keep its journal consistent if the power is lost.
+var noBarrierMountOptions = map[string]bool{
+	"nobarrier": true,
+	"barrier=0": true,
+}
+
+// Returns what's wrong with the given mount options.
+func mountOptionsIssues(options []string) []string {
+	issues := []string{}
+	noatime := false
+	for _, o := range options {
+		if o == "noatime" {
+			noatime = true
+		}
+		if noBarrierMountOptions[o] {
+			issues = append(issues, fmt.Sprintf("write barriers disabled (%s)", o))
+		}
+	}
+	if !noatime {
+		issues = append(issues, "noatime not set")
+	}
+	return issues
+}
+
+// Returns the given mount options with noatime instead of the other access
+// time options, and without the ones which disable the write barriers.
+func recommendedMountOptions(options []string) []string {
+	recommended := []string{}
+	for _, o := range options {
+		if atimeMountOptions[o] || noBarrierMountOptions[o] {
+			continue
+		}
+		recommended = append(recommended, o)
+	}
+	if len(recommended) == 0 {
+		recommended = append(recommended, "defaults")
+	}
+	return append(recommended, "noatime")
+}
+
+// Returns the /etc/fstab entry which mounts the filesystem with the
+// recommended options.
+func recommendedFstabEntry(mount *filesystem.Mount) string {
+	return fmt.Sprintf(
+		"%s %s %s %s 0 0",
+		filesystem.EscapeMountField(mount.Device),
+		filesystem.EscapeMountField(mount.MountPoint),
+		mount.FsType,
+		strings.Join(recommendedMountOptions(mount.Options), ","),
+	)
+}
+
+// NewMountOptionsChecker returns a checker which warns if the filesystem the
+// data directory is on is mounted without noatime, which makes every read
+// update the files' access time, or with its write barriers disabled.
+func NewMountOptionsChecker(fs afero.Fs, dataDir string) Checker {
+	return &mountOptionsChecker{fs: fs, dataDir: dataDir}
+}
+
+type mountOptionsChecker struct {
+	fs      afero.Fs
+	dataDir string
+}
+
+func (c *mountOptionsChecker) Id() CheckerID {
+	return MountOptionsChecker
+}
+
+func (c *mountOptionsChecker) GetDesc() string {
+	return "Data directory mount options"
+}
+
+func (c *mountOptionsChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *mountOptionsChecker) GetRequiredAsString() string {
+	return "noatime, with write barriers"
+}
+
+func (c *mountOptionsChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	mount, err := filesystem.GetMount(c.fs, c.dataDir)
+	if err != nil {
+		log.Debugf("Couldn't get the data directory's mount options: %v", err)
+		res.Current = string(filesystem.Unknown)
+		res.IsOk = true
+		return res
+	}
+	issues := mountOptionsIssues(mount.Options)
+	res.IsOk = len(issues) == 0
+	res.Current = strings.Join(mount.Options, ",")
+	if !res.IsOk {
+		res.Current = fmt.Sprintf("%s (%s)", res.Current, strings.Join(issues, ", "))
+	}
+	return res
+}
+
+// NewMountOptionsTuner returns an advisory tuner for the data directory's
+// mount options: since remounting the filesystem redpanda's data is on is
+// risky, it doesn't change them, but reports the /etc/fstab entry with the
+// recommended ones, which is rendered as a comment in the tuning script.
+func NewMountOptionsTuner(
+	fs afero.Fs, dataDir string, executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewMountOptionsChecker(fs, dataDir),
+		func() TuneResult {
+			mount, err := filesystem.GetMount(fs, dataDir)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			err = executor.Execute(commands.NewAdviceCmd(
+				fmt.Sprintf(
+					"%s isn't remounted automatically. To mount it with the"+
+						" recommended options, set its entry in /etc/fstab to"+
+						" the following, and remount it while redpanda isn't"+
+						" running:",
+					mount.MountPoint,
+				),
+				recommendedFstabEntry(mount),
+			))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		// The options don't change, so the check would still fail.
+		true,
+	)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+const mountsFile = "/proc/self/mounts"
+
+func TestMountOptionsChecker(t *testing.T) {
+	tests := []struct {
+		name            string
+		mounts          string
+		expectedOk      bool
+		expectedCurrent string
+	}{
+		{
+			name: "it should pass if noatime is set",
+			mounts: `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/nvme1n1 /var/lib/redpanda xfs rw,noatime,attr2,inode64,noquota 0 0
+`,
+			expectedOk:      true,
+			expectedCurrent: "rw,noatime,attr2,inode64,noquota",
+		},
+		{
+			name: "it should fail if relatime is set instead",
+			mounts: `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/nvme1n1 /var/lib/redpanda xfs rw,relatime,attr2,inode64,noquota 0 0
+`,
+			expectedCurrent: "rw,relatime,attr2,inode64,noquota (noatime not set)",
+		},
+		{
+			name: "it should fail if only nodiratime is set",
+			mounts: `/dev/nvme1n1 /var/lib/redpanda/data ext4 rw,nodiratime 0 0
+`,
+			expectedCurrent: "rw,nodiratime (noatime not set)",
+		},
+		{
+			name: "it should fail if the write barriers are disabled",
+			mounts: `/dev/nvme1n1 /var/lib/redpanda xfs rw,noatime,nobarrier 0 0
+`,
+			expectedCurrent: "rw,noatime,nobarrier (write barriers disabled (nobarrier))",
+		},
+		{
+			name: "it should check the closest mount point",
+			mounts: `/dev/sda1 / ext4 rw,noatime 0 0
+/dev/sdb1 /var/lib ext4 rw,noatime 0 0
+/dev/nvme1n1 /var/lib/redpanda ext4 rw,barrier=0 0 0
+`,
+			expectedCurrent: "rw,barrier=0 (write barriers disabled (barrier=0), noatime not set)",
+		},
+		{
+			name:            "it should pass if the mounts can't be read",
+			expectedOk:      true,
+			expectedCurrent: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.mounts != "" {
+				err := afero.WriteFile(fs, mountsFile, []byte(tt.mounts), 0644)
+				require.NoError(st, err)
+			}
+			res := tuners.NewMountOptionsChecker(fs, "/var/lib/redpanda/data").Check()
+			require.NoError(st, res.Err)
+			require.EqualValues(st, tuners.Warning, res.Severity)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+		})
+	}
+}
+
+func TestMountOptionsTuner(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	tests := []struct {
+		name           string
+		mounts         string
+		dataDir        string
+		expectedScript string
+	}{
+		{
+			name: "it should render the fstab entry with the recommended options",
+			mounts: `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/nvme1n1 /var/lib/redpanda xfs rw,relatime,nobarrier,attr2,inode64 0 0
+`,
+			expectedScript: `# /var/lib/redpanda isn't remounted automatically. To mount it with the recommended options, set its entry in /etc/fstab to the following, and remount it while redpanda isn't running:
+# /dev/nvme1n1 /var/lib/redpanda xfs rw,attr2,inode64,noatime 0 0
+`,
+		},
+		{
+			name: "it should escape the mount point",
+			mounts: `/dev/nvme1n1 /mnt/redpanda\040data xfs relatime 0 0
+`,
+			dataDir: "/mnt/redpanda data",
+			expectedScript: `# /mnt/redpanda data isn't remounted automatically. To mount it with the recommended options, set its entry in /etc/fstab to the following, and remount it while redpanda isn't running:
+# /dev/nvme1n1 /mnt/redpanda\040data xfs defaults,noatime 0 0
+`,
+		},
+		{
+			name: "it shouldn't render anything if the options are right",
+			mounts: `/dev/nvme1n1 / xfs rw,noatime 0 0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, mountsFile, []byte(tt.mounts), 0644)
+			require.NoError(st, err)
+			executor := executors.NewScriptRenderingExecutor(fs, scriptPath)
+			dataDir := "/var/lib/redpanda/data"
+			if tt.dataDir != "" {
+				dataDir = tt.dataDir
+			}
+			tuner := tuners.NewMountOptionsTuner(fs, dataDir, executor)
+			supported, _ := tuner.CheckIfSupported()
+			require.True(st, supported)
+			res := tuner.Tune()
+			require.NoError(st, res.Error())
+			require.False(st, res.IsRebootRequired())
+
+			script, err := afero.ReadFile(fs, scriptPath)
+			require.NoError(st, err)
+			header := `#!/bin/bash
+
+# Redpanda Tuning Script
+# ----------------------------------
+# This file was autogenerated by RPK
+
+`
+			require.Equal(st, header+tt.expectedScript, string(script))
+			// The mounts aren't changed.
+			mounts, err := afero.ReadFile(fs, mountsFile)
+			require.NoError(st, err)
+			require.Equal(st, tt.mounts, string(mounts))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+)
+
+// NewNetworkFilesystemChecker returns a checker which fails if the data
+// directory is on a network (e.g. NFS or CIFS) or FUSE filesystem, which
+// can't provide the durability and performance redpanda relies on.
+func NewNetworkFilesystemChecker(fs afero.Fs, dataDir string) Checker {
+	return &networkFilesystemChecker{fs: fs, dataDir: dataDir}
+}
+
+type networkFilesystemChecker struct {
+	fs      afero.Fs
+	dataDir string
+}
+
+func (c *networkFilesystemChecker) Id() CheckerID {
+	return NetworkFsChecker
+}
+
+func (c *networkFilesystemChecker) GetDesc() string {
+	return "Data directory on local storage"
+}
+
+func (c *networkFilesystemChecker) GetSeverity() Severity {
+	return Fatal
+}
+
+func (c *networkFilesystemChecker) GetRequiredAsString() string {
+	return "local filesystem"
+}
+
+func (c *networkFilesystemChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	fsType, err := filesystem.GetMountFsType(c.fs, c.dataDir)
+	if err != nil {
+		// Not being able to tell the filesystem type shouldn't keep
+		// redpanda from starting.
+		log.Debugf("Couldn't get the data directory's filesystem type: %v", err)
+		res.Current = string(filesystem.Unknown)
+		res.IsOk = true
+		return res
+	}
+	res.Current = fsType
+	res.IsOk = !filesystem.IsNetworkFs(fsType)
+	return res
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkFilesystemChecker(t *testing.T) {
+	tests := []struct {
+		name            string
+		mounts          string
+		expectedOk      bool
+		expectedCurrent string
+	}{
+		{
+			name: "it should fail if the data directory is on NFS",
+			mounts: `/dev/sda1 / ext4 rw 0 0
+10.0.0.5:/exports/redpanda /var/lib/redpanda nfs rw,relatime 0 0
+`,
+			expectedCurrent: "nfs",
+		},
+		{
+			name: "it should pass if the data directory is on XFS",
+			mounts: `/dev/sda1 / ext4 rw 0 0
+/dev/nvme0n1 /var/lib/redpanda/data xfs rw,noatime 0 0
+`,
+			expectedOk:      true,
+			expectedCurrent: "xfs",
+		},
+		{
+			name: "it should fail if the data directory is on a FUSE mount",
+			mounts: `/dev/sda1 / ext4 rw 0 0
+bucket /var/lib/redpanda fuse.s3fs rw,nosuid,nodev 0 0
+`,
+			expectedCurrent: "fuse.s3fs",
+		},
+		{
+			name:            "it should pass if the mounts can't be read",
+			expectedOk:      true,
+			expectedCurrent: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.mounts != "" {
+				err := afero.WriteFile(fs, "/proc/self/mounts", []byte(tt.mounts), 0644)
+				require.NoError(st, err)
+			}
+			res := NewNetworkFilesystemChecker(fs, "/var/lib/redpanda/data").Check()
+			require.EqualValues(st, Fatal, res.Severity)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.NoError(st, res.Err)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// The combined (RX and TX) channels of a NIC, as reported by 'ethtool -l'.
+type NicChannels struct {
+	Current int
+	Max     int
+}
+
+// Returns the number of combined queues the NIC should have: one per CPU
+// redpanda runs on, capped to the maximum the NIC supports.
+func (c NicChannels) target(cpus int) int {
+	if cpus > c.Max {
+		return c.Max
+	}
+	return cpus
+}
+
+// ReadNicChannels reads the NIC's combined channels with 'ethtool -l'.
+func ReadNicChannels(
+	proc os.Proc, timeout time.Duration, nic string,
+) (NicChannels, error) {
+	lines, err := proc.RunWithSystemLdPath(timeout, "ethtool", "-l", nic)
+	if err != nil {
+		return NicChannels{}, err
+	}
+	return ParseNicChannels(lines)
+}
+
+// ParseNicChannels parses the output of 'ethtool -l <nic>', e.g.
+//
+//	Channel parameters for eth0:
+//	Pre-set maximums:
+//	RX:             0
+//	TX:             0
+//	Other:          1
+//	Combined:       8
+//	Current hardware settings:
+//	RX:             0
+//	TX:             0
+//	Other:          1
+//	Combined:       4
+//
+// Newer ethtool versions print 'n/a' for the unsupported values, which are
+// parsed as 0.
+func ParseNicChannels(lines []string) (NicChannels, error) {
+	channels := NicChannels{}
+	var section *int
+	maxFound, currentFound := false, false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Pre-set maximums"):
+			section = &channels.Max
+			maxFound = true
+			continue
+		case strings.HasPrefix(line, "Current hardware settings"):
+			section = &channels.Current
+			currentFound = true
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if section == nil || len(parts) != 2 || parts[0] != "Combined" {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		if value == "n/a" {
+			continue
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return NicChannels{}, fmt.Errorf(
+				"couldn't parse the combined channels count '%s': %v",
+				value,
+				err,
+			)
+		}
+		*section = count
+	}
+	if !maxFound || !currentFound {
+		return NicChannels{}, fmt.Errorf(
+			"couldn't find the combined channels in the ethtool output:\n%s",
+			strings.Join(lines, "\n"),
+		)
+	}
+	return channels, nil
+}
+
+// NewNicQueuesCmd returns the command which sets the NIC's combined channels
+// count.
+func NewNicQueuesCmd(
+	proc os.Proc, timeout time.Duration, nic string, count int,
+) commands.Command {
+	return commands.NewLaunchCmd(
+		proc,
+		timeout,
+		"ethtool",
+		"-L",
+		nic,
+		"combined",
+		strconv.Itoa(count),
+	)
+}
+
+// NewNicQueuesChecker returns a checker which warns if the NIC's combined
+// queue count doesn't match the number of CPUs redpanda runs on (or the
+// maximum the NIC supports, if lower).
+func NewNicQueuesChecker(
+	nic string,
+	cpus func() (int, error),
+	channels func() (NicChannels, error),
+) Checker {
+	return &nicQueuesChecker{nic: nic, cpus: cpus, channels: channels}
+}
+
+type nicQueuesChecker struct {
+	nic      string
+	cpus     func() (int, error)
+	channels func() (NicChannels, error)
+}
+
+func (c *nicQueuesChecker) Id() CheckerID {
+	return NicQueuesChecker
+}
+
+func (c *nicQueuesChecker) GetDesc() string {
+	return fmt.Sprintf("NIC '%s' combined queues", c.nic)
+}
+
+func (c *nicQueuesChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *nicQueuesChecker) GetRequiredAsString() string {
+	cpus, err := c.cpus()
+	if err != nil {
+		return ""
+	}
+	channels, err := c.channels()
+	if err != nil {
+		return strconv.Itoa(cpus)
+	}
+	return strconv.Itoa(channels.target(cpus))
+}
+
+func (c *nicQueuesChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+	}
+	cpus, err := c.cpus()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	channels, err := c.channels()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if channels.Max == 0 {
+		res.Err = fmt.Errorf(
+			"NIC '%s' doesn't support setting its combined queues",
+			c.nic,
+		)
+		return res
+	}
+	target := channels.target(cpus)
+	res.Required = strconv.Itoa(target)
+	res.Current = strconv.Itoa(channels.Current)
+	res.IsOk = channels.Current == target
+	return res
+}
+
+// NewNicQueuesTuner sets the combined (RSS) queue count of the given NIC to
+// the number of CPUs redpanda runs on, so that the packets are distributed
+// across all of them. NICs which don't support changing it are reported as
+// unsupported.
+func NewNicQueuesTuner(
+	nic string,
+	cpus func() (int, error),
+	proc os.Proc,
+	executor executors.Executor,
+	timeout time.Duration,
+) Tunable {
+	channels := func() (NicChannels, error) {
+		return ReadNicChannels(proc, timeout, nic)
+	}
+	return NewCheckedTunable(
+		NewNicQueuesChecker(nic, cpus, channels),
+		func() TuneResult {
+			cpuCount, err := cpus()
+			if err != nil {
+				return NewTuneError(err)
+			}
+			current, err := channels()
+			if err != nil {
+				return NewTuneError(err)
+			}
+			target := current.target(cpuCount)
+			log.Debugf(
+				"Setting NIC '%s' combined queues to %d",
+				nic,
+				target,
+			)
+			err = executor.Execute(NewNicQueuesCmd(proc, timeout, nic, target))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			if nic == "" {
+				return false, "rpk.nic_queues_interface isn't set"
+			}
+			current, err := channels()
+			if err != nil {
+				if strings.Contains(err.Error(), "Operation not supported") {
+					return false, fmt.Sprintf(
+						"NIC '%s' doesn't support setting its combined queues",
+						nic,
+					)
+				}
+				return false, err.Error()
+			}
+			if current.Max == 0 {
+				return false, fmt.Sprintf(
+					"NIC '%s' doesn't support setting its combined queues",
+					nic,
+				)
+			}
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
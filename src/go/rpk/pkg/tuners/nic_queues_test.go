@@ -0,0 +1,189 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func ethtoolChannels(max, current string) []string {
+	return []string{
+		"Channel parameters for eth0:",
+		"Pre-set maximums:",
+		"RX:		0",
+		"TX:		0",
+		"Other:		1",
+		"Combined:	" + max,
+		"Current hardware settings:",
+		"RX:		0",
+		"TX:		0",
+		"Other:		1",
+		"Combined:	" + current,
+	}
+}
+
+// Returns the given 'ethtool -l' output and records the other commands.
+type ethtoolProc struct {
+	recordingProc
+	channels []string
+	err      error
+}
+
+func (p *ethtoolProc) RunWithSystemLdPath(
+	timeout time.Duration, cmd string, args ...string,
+) ([]string, error) {
+	if cmd == "ethtool" && len(args) > 0 && args[0] == "-l" {
+		return p.channels, p.err
+	}
+	return p.recordingProc.RunWithSystemLdPath(timeout, cmd, args...)
+}
+
+func TestParseNicChannels(t *testing.T) {
+	tests := []struct {
+		name        string
+		lines       []string
+		expected    NicChannels
+		expectedErr string
+	}{
+		{
+			name:     "it should parse the current and maximum combined channels",
+			lines:    ethtoolChannels("8", "4"),
+			expected: NicChannels{Current: 4, Max: 8},
+		},
+		{
+			name:     "it should parse 'n/a' as 0",
+			lines:    ethtoolChannels("n/a", "n/a"),
+			expected: NicChannels{},
+		},
+		{
+			name:        "it should fail if a count isn't a number",
+			lines:       ethtoolChannels("8", "many"),
+			expectedErr: "couldn't parse the combined channels count 'many'",
+		},
+		{
+			name:        "it should fail if the sections are missing",
+			lines:       []string{"Channel parameters for eth0:"},
+			expectedErr: "couldn't find the combined channels",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			channels, err := ParseNicChannels(tt.lines)
+			if tt.expectedErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, channels)
+		})
+	}
+}
+
+func TestNicQueuesCmd(t *testing.T) {
+	proc := &recordingProc{}
+	err := NewNicQueuesCmd(proc, time.Second, "eth0", 16).Execute()
+	require.NoError(t, err)
+	require.Equal(t, []string{"ethtool -L eth0 combined 16"}, proc.calls)
+}
+
+func TestNicQueuesTuner(t *testing.T) {
+	cpus := func() (int, error) { return 8, nil }
+	tests := []struct {
+		name           string
+		nic            string
+		channels       []string
+		channelsErr    error
+		expectedCalls  []string
+		expectedReason string
+	}{
+		{
+			name:     "it shouldn't do anything if the count already matches",
+			nic:      "eth0",
+			channels: ethtoolChannels("16", "8"),
+		},
+		{
+			name:          "it should set the count to the number of CPUs",
+			nic:           "eth0",
+			channels:      ethtoolChannels("16", "2"),
+			expectedCalls: []string{"ethtool -L eth0 combined 8"},
+		},
+		{
+			name:          "it should cap the count to the NIC's maximum",
+			nic:           "eth0",
+			channels:      ethtoolChannels("4", "2"),
+			expectedCalls: []string{"ethtool -L eth0 combined 4"},
+		},
+		{
+			name:           "it should be unsupported if the NIC isn't set",
+			expectedReason: "rpk.nic_queues_interface isn't set",
+		},
+		{
+			name:           "it should be unsupported if the NIC has no combined channels",
+			nic:            "eth0",
+			channels:       ethtoolChannels("n/a", "n/a"),
+			expectedReason: "NIC 'eth0' doesn't support setting its combined queues",
+		},
+		{
+			name: "it should be unsupported if ethtool can't read the channels",
+			nic:  "eth0",
+			channelsErr: errors.New(
+				"err=exit status 1, stderr=netlink error: Operation not supported",
+			),
+			expectedReason: "NIC 'eth0' doesn't support setting its combined queues",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			proc := &ethtoolProc{channels: tt.channels, err: tt.channelsErr}
+			tuner := NewNicQueuesTuner(
+				tt.nic,
+				cpus,
+				proc,
+				executors.NewDirectExecutor(),
+				time.Second,
+			)
+			supported, reason := tuner.CheckIfSupported()
+			if tt.expectedReason != "" {
+				require.False(st, supported)
+				require.Equal(st, tt.expectedReason, reason)
+				return
+			}
+			require.True(st, supported)
+			res := tuner.Tune()
+			require.NoError(st, res.Error())
+			require.Equal(
+				st,
+				strings.Join(tt.expectedCalls, "\n"),
+				strings.Join(proc.calls, "\n"),
+			)
+		})
+	}
+}
+
+func TestNicQueuesChecker(t *testing.T) {
+	channels := NicChannels{Current: 2, Max: 16}
+	res := NewNicQueuesChecker(
+		"eth0",
+		func() (int, error) { return 8, nil },
+		func() (NicChannels, error) { return channels, nil },
+	).Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+	require.Equal(t, "2", res.Current)
+	require.Equal(t, "8", res.Required)
+	require.Equal(t, Severity(Warning), res.Severity)
+}
@@ -11,6 +11,7 @@ package tuners
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/spf13/afero"
@@ -59,8 +60,75 @@ const (
 	Swappiness
 	KernelVersion
 	WriteCachePolicyChecker
+	DirtyPagesChecker
+	BallastFileFilesystemChecker
+	NetworkFsChecker
+	NrRequestsChecker
+	KernelModulesChecker
+	MemoryHeadroomChecker
+	NicQueuesChecker
+	CpuQuotaChecker
+	CstatesChecker
+	DataDirOwnerChecker
+	MaxMapCountChecker
+	FdLimitChecker
+	MountOptionsChecker
 )
 
+// The names the checkers are reported with, e.g. in the JSON output of
+// 'rpk redpanda check'. Unlike the IDs, which depend on the order they're
+// declared in, they must not change.
+var checkerNames = map[CheckerID]string{
+	ConfigFileChecker:             "config_file",
+	DataDirAccessChecker:          "data_dir_access",
+	DiskSpaceChecker:              "disk_space",
+	FreeMemChecker:                "free_memory",
+	SwapChecker:                   "swap",
+	FsTypeChecker:                 "fs_type",
+	IoConfigFileChecker:           "io_config_file",
+	TransparentHugePagesChecker:   "transparent_hugepages",
+	NtpChecker:                    "ntp",
+	SchedulerChecker:              "disk_scheduler",
+	NomergesChecker:               "disk_nomerges",
+	DiskIRQsAffinityStaticChecker: "disk_irqs_affinity_static",
+	DiskIRQsAffinityChecker:       "disk_irqs_affinity",
+	FstrimChecker:                 "fstrim",
+	NicIRQsAffinitChecker:         "nic_irqs_affinity",
+	NicIRQsAffinitStaticChecker:   "nic_irqs_affinity_static",
+	NicRfsChecker:                 "nic_rfs",
+	NicXpsChecker:                 "nic_xps",
+	NicRpsChecker:                 "nic_rps",
+	NicNTupleChecker:              "nic_ntuple",
+	RfsTableEntriesChecker:        "rfs_table_entries",
+	ListenBacklogChecker:          "listen_backlog",
+	SynBacklogChecker:             "syn_backlog",
+	MaxAIOEvents:                  "max_aio_events",
+	ClockSource:                   "clocksource",
+	Swappiness:                    "swappiness",
+	KernelVersion:                 "kernel_version",
+	WriteCachePolicyChecker:       "disk_write_cache",
+	DirtyPagesChecker:             "dirty_pages",
+	BallastFileFilesystemChecker:  "ballast_file_filesystem",
+	NetworkFsChecker:              "network_fs",
+	NrRequestsChecker:             "disk_nr_requests",
+	KernelModulesChecker:          "kernel_modules",
+	MemoryHeadroomChecker:         "memory_headroom",
+	NicQueuesChecker:              "nic_queues",
+	CpuQuotaChecker:               "cpu_quota",
+	CstatesChecker:                "cstates",
+	DataDirOwnerChecker:           "data_dir_owner",
+	MaxMapCountChecker:            "max_map_count",
+	FdLimitChecker:                "fd_limit",
+	MountOptionsChecker:           "mount_options",
+}
+
+func (id CheckerID) String() string {
+	if name, ok := checkerNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("checker_%d", int(id))
+}
+
 func NewConfigChecker(conf *config.Config) Checker {
 	return NewEqualityChecker(
 		ConfigFileChecker,
@@ -218,6 +286,28 @@ func RedpandaCheckers(
 	if err != nil {
 		return nil, err
 	}
+	memoryHeadroomChecker := NewMemoryHeadroomChecker(
+		func() (int, error) { return system.GetMemTotalMB(fs) },
+		config.Rpk.AdditionalStartFlags,
+		OSMemoryHeadroomTarget(config.Rpk),
+	)
+	cpuQuotaChecker := NewCpuQuotaChecker(
+		func() (float64, error) { return system.ReadCgroupCpuLimit(fs) },
+		func() (int, error) {
+			return RedpandaCpus(
+				config.Rpk,
+				func() (uint64, error) {
+					return system.ReadCgroupEffectiveCpusNo(fs)
+				},
+			)
+		},
+	)
+	dataDirOwnerChecker := NewDataDirOwnerChecker(
+		config.Redpanda.Directory,
+		RedpandaUser(config.Rpk),
+		system.LookupUser,
+		filesystem.GetOwnership,
+	)
 	netCheckersFactory := NewNetCheckersFactory(
 		fs, irqProcFile, irqDeviceInfo, ethtool, balanceService, cpuMasks)
 	checkers := map[CheckerID][]Checker{
@@ -226,8 +316,12 @@ func RedpandaCheckers(
 		FreeMemChecker:                {NewMemoryChecker(fs)},
 		SwapChecker:                   {NewSwapChecker(fs)},
 		DataDirAccessChecker:          {NewDataDirWritableChecker(fs, config.Redpanda.Directory)},
+		DataDirOwnerChecker:           {dataDirOwnerChecker},
 		DiskSpaceChecker:              {NewFreeDiskSpaceChecker(config.Redpanda.Directory)},
 		FsTypeChecker:                 {NewFilesystemTypeChecker(config.Redpanda.Directory)},
+		NetworkFsChecker:              {NewNetworkFilesystemChecker(fs, config.Redpanda.Directory)},
+		MountOptionsChecker:           {NewMountOptionsChecker(fs, config.Redpanda.Directory)},
+		KernelModulesChecker:          {NewKernelModulesChecker(fs, config.Redpanda.Directory)},
 		TransparentHugePagesChecker:   {NewTransparentHugePagesChecker(fs)},
 		NtpChecker:                    {NewNTPSyncChecker(timeout, fs)},
 		SchedulerChecker:              {schedulerChecker},
@@ -243,10 +337,64 @@ func RedpandaCheckers(
 		NicRpsChecker:                 netCheckersFactory.NewNicRpsSetCheckers(interfaces, irq.Default, "all"),
 		NicRfsChecker:                 netCheckersFactory.NewNicRfsCheckers(interfaces),
 		NicXpsChecker:                 netCheckersFactory.NewNicXpsCheckers(interfaces),
-		MaxAIOEvents:                  {NewMaxAIOEventsChecker(fs)},
+		MaxAIOEvents:                  {NewMaxAIOEventsChecker(fs, MaxAIOEventsTarget(config.Rpk))},
 		ClockSource:                   {NewClockSourceChecker(fs)},
 		Swappiness:                    {NewSwappinessChecker(fs)},
+		MaxMapCountChecker:            {NewMaxMapCountChecker(fs)},
+		FdLimitChecker:                {NewFdLimitChecker(MinFdLimitTarget(config.Rpk), system.GetFdLimit)},
 		KernelVersion:                 {NewKernelVersionChecker(GetKernelVersion)},
+		MemoryHeadroomChecker:         {memoryHeadroomChecker},
+		CpuQuotaChecker:               {cpuQuotaChecker},
+	}
+
+	if config.Rpk.TuneDirtyPages {
+		checkers[DirtyPagesChecker] = NewDirtyPagesCheckers(fs, config.Rpk)
+	}
+
+	if config.Rpk.TuneDiskNrRequests {
+		checkers[NrRequestsChecker] = []Checker{
+			NewDirectoryNrRequestsChecker(
+				config.Redpanda.Directory,
+				NrRequestsTarget(config.Rpk),
+				deviceFeatures,
+				blockDevices,
+			),
+		}
+	}
+
+	if config.Rpk.TuneNicQueues && config.Rpk.NicQueuesInterface != "" {
+		nic := config.Rpk.NicQueuesInterface
+		checkers[NicQueuesChecker] = []Checker{
+			NewNicQueuesChecker(
+				nic,
+				func() (int, error) {
+					mask, err := cpuMasks.BaseCpuMask("all")
+					if err != nil {
+						return 0, err
+					}
+					pus, err := cpuMasks.GetNumberOfPUs(mask)
+					return int(pus), err
+				},
+				func() (NicChannels, error) {
+					return ReadNicChannels(proc, timeout, nic)
+				},
+			),
+		}
+	}
+
+	if config.Rpk.TuneCstates {
+		checkers[CstatesChecker] = []Checker{
+			NewCstatesChecker(fs, MaxCstateTarget(config.Rpk)),
+		}
+	}
+
+	if config.Rpk.BallastFilePath != "" {
+		checkers[BallastFileFilesystemChecker] = []Checker{
+			NewBallastFileFilesystemChecker(
+				config.Rpk.BallastFilePath,
+				config.Redpanda.Directory,
+			),
+		}
 	}
 
 	v, err := cloud.AvailableVendor()
@@ -0,0 +1,62 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import "errors"
+
+var ErrSimulationUnsupported = errors.New(
+	"this tuner can't tell what it would change without running",
+)
+
+// Simulatable is implemented by the tunables which can report what they
+// would change without changing anything. Each result holds the current value
+// and the one the tunable would set, for every check it's associated with.
+type Simulatable interface {
+	Simulate() ([]*CheckResult, error)
+}
+
+// Simulate runs the checks associated with the given tunable, without tuning
+// anything. It returns ErrSimulationUnsupported if the tunable doesn't
+// implement Simulatable.
+func Simulate(t Tunable) ([]*CheckResult, error) {
+	s, ok := t.(Simulatable)
+	if !ok {
+		return nil, ErrSimulationUnsupported
+	}
+	return s.Simulate()
+}
+
+func (t *checkedTunable) Simulate() ([]*CheckResult, error) {
+	result := t.checker.Check()
+	if result.Required == "" {
+		result.Required = t.checker.GetRequiredAsString()
+	}
+	return []*CheckResult{result}, nil
+}
+
+func (t *aggregatedTunable) Simulate() ([]*CheckResult, error) {
+	var results []*CheckResult
+	for _, tunable := range t.tunables {
+		res, err := Simulate(tunable)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+func (tuner *diskTuner) Simulate() ([]*CheckResult, error) {
+	tunables, err := tuner.createDeviceTuners()
+	if err != nil {
+		return nil, err
+	}
+	return Simulate(NewAggregatedTunable(tunables))
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+const (
+	DefaultSwapfilePath string = "/swapfile"
+	DefaultSwapfileSize string = "1G"
+)
+
+// NewSwapfileTuner creates a swap file of rpk.swapfile_size at
+// rpk.swapfile_path and enables it, if swap isn't enabled already.
+func NewSwapfileTuner(
+	fs afero.Fs,
+	conf config.RpkConfig,
+	proc os.Proc,
+	executor executors.Executor,
+	timeout time.Duration,
+) Tunable {
+	return newSwapfileTuner(
+		NewSwapChecker(fs),
+		conf,
+		proc,
+		executor,
+		timeout,
+	)
+}
+
+func newSwapfileTuner(
+	checker Checker,
+	conf config.RpkConfig,
+	proc os.Proc,
+	executor executors.Executor,
+	timeout time.Duration,
+) Tunable {
+	path := conf.SwapfilePath
+	if path == "" {
+		path = DefaultSwapfilePath
+	}
+	size := conf.SwapfileSize
+	if size == "" {
+		size = DefaultSwapfileSize
+	}
+	return NewCheckedTunable(
+		checker,
+		func() TuneResult {
+			log.Debugf("Creating a %s swap file at %s", size, path)
+			cmds := []commands.Command{
+				commands.NewLaunchCmd(proc, timeout, "fallocate", "-l", size, path),
+				commands.NewLaunchCmd(proc, timeout, "chmod", "600", path),
+				commands.NewLaunchCmd(proc, timeout, "mkswap", path),
+				commands.NewLaunchCmd(proc, timeout, "swapon", path),
+			}
+			for _, cmd := range cmds {
+				err := executor.Execute(cmd)
+				if err != nil {
+					log.Errorf("got an error while creating the swap file %s: %v", path, err)
+					return NewTuneError(err)
+				}
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+type recordingProc struct {
+	calls []string
+}
+
+func (p *recordingProc) RunWithSystemLdPath(
+	_ time.Duration, cmd string, args ...string,
+) ([]string, error) {
+	p.calls = append(p.calls, strings.Join(append([]string{cmd}, args...), " "))
+	return []string{}, nil
+}
+
+func (*recordingProc) IsRunning(_ time.Duration, _ string) bool {
+	return true
+}
+
+// Returns a checker like NewSwapChecker's, reporting the given swap status.
+func swapChecker(enabled bool) Checker {
+	return NewEqualityChecker(
+		SwapChecker,
+		"Swap enabled",
+		Warning,
+		true,
+		func() (interface{}, error) {
+			return enabled, nil
+		},
+	)
+}
+
+func TestSwapfileTuner(t *testing.T) {
+	tests := []struct {
+		name          string
+		conf          config.RpkConfig
+		swapEnabled   bool
+		expectedCalls []string
+	}{
+		{
+			name:        "it shouldn't do anything if swap is already enabled",
+			swapEnabled: true,
+		},
+		{
+			name: "it should create the swap file with the default values",
+			expectedCalls: []string{
+				"fallocate -l 1G /swapfile",
+				"chmod 600 /swapfile",
+				"mkswap /swapfile",
+				"swapon /swapfile",
+			},
+		},
+		{
+			name: "it should create the swap file with the configured values",
+			conf: config.RpkConfig{
+				SwapfilePath: "/var/lib/redpanda/swap",
+				SwapfileSize: "8G",
+			},
+			expectedCalls: []string{
+				"fallocate -l 8G /var/lib/redpanda/swap",
+				"chmod 600 /var/lib/redpanda/swap",
+				"mkswap /var/lib/redpanda/swap",
+				"swapon /var/lib/redpanda/swap",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := &recordingProc{}
+			checker := swapChecker(tt.swapEnabled)
+			tuner := newSwapfileTuner(
+				checker,
+				tt.conf,
+				proc,
+				executors.NewDirectExecutor(),
+				time.Second,
+			)
+			res := tuner.Tune()
+			require.NoError(t, res.Error())
+			require.Equal(t, tt.expectedCalls, proc.calls)
+		})
+	}
+}
+
+func TestSwapfileTunerScriptRendering(t *testing.T) {
+	const script = "/tune.sh"
+	fs := afero.NewMemMapFs()
+	checker := swapChecker(false)
+	tuner := newSwapfileTuner(
+		checker,
+		config.RpkConfig{SwapfileSize: "2G"},
+		&recordingProc{},
+		executors.NewScriptRenderingExecutor(fs, script),
+		time.Second,
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+
+	expected := `#!/bin/bash
+
+# Redpanda Tuning Script
+# ----------------------------------
+# This file was autogenerated by RPK
+
+fallocate \
+ -l \
+ 2G \
+ /swapfile
+chmod \
+ 600 \
+ /swapfile
+mkswap \
+ /swapfile
+swapon \
+ /swapfile
+`
+	actual, err := afero.ReadFile(fs, script)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(actual))
+}
@@ -123,7 +123,7 @@ func BackupFile(fs afero.Fs, filePath string) (string, error) {
 	bkFilePath := fmt.Sprintf("%s.vectorized.%s.bk", filePath, md5)
 	err = CopyFile(fs, filePath, bkFilePath)
 	if err != nil {
-		return "", fmt.Errorf("unable to create backup of %s", filePath)
+		return "", fmt.Errorf("unable to create backup of %s: %w", filePath, err)
 	}
 	return bkFilePath, nil
 }